@@ -0,0 +1,155 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/auth/apikey"
+	"github.com/project-alvarium/go-store/pkg/http/client"
+	"github.com/project-alvarium/go-store/pkg/http/requestor"
+
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+const usage = "usage: apikey [flags] create | revoke <id> | list"
+
+// keys is the subset of key management both a running service and a local keys file provide.
+type keys interface {
+	Create(name string, scopes []auth.Scope, tenant string) (apikey.Issued, error)
+	Revoke(id string) (bool, error)
+	List() []apikey.Key
+}
+
+// remote manages the keys of a running service through its client.
+type remote struct {
+	client interface {
+		CreateKey(name string, scopes []auth.Scope, tenant string) (apikey.Issued, status.Value)
+		RevokeKey(id string) status.Value
+		Keys() ([]apikey.Key, status.Value)
+	}
+}
+
+// Create asks the service to create a key.
+func (r remote) Create(name string, scopes []auth.Scope, tenant string) (apikey.Issued, error) {
+	issued, result := r.client.CreateKey(name, scopes, tenant)
+	if result != status.Success {
+		return apikey.Issued{}, fmt.Errorf("create failed (status %d)", result)
+	}
+	return issued, nil
+}
+
+// Revoke asks the service to revoke a key.
+func (r remote) Revoke(id string) (bool, error) {
+	switch result := r.client.RevokeKey(id); result {
+	case status.Success:
+		return true, nil
+	case status.NotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("revoke failed (status %d)", result)
+	}
+}
+
+// List asks the service for its keys.
+func (r remote) List() []apikey.Key {
+	result, value := r.client.Keys()
+	if value != status.Success {
+		log.Fatalf("list failed (status %d)", value)
+	}
+	return result
+}
+
+// output writes v to stdout as indented JSON.
+func output(v interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// main creates, revokes and lists API keys, either through a running service (authenticating with an admin key) or
+// directly in a keys file while the service is stopped, which is how the first admin key is issued.
+func main() {
+	var serverURL, key, keysPath, name, scopes, tenant string
+	var options requestor.TLS
+	flag.StringVar(&serverURL, "server", "http://localhost:8080", "Server URL (http://localhost:8080)")
+	flag.StringVar(&key, "key", os.Getenv("ALVARIUM_API_KEY"), "Admin API key ($ALVARIUM_API_KEY)")
+	flag.StringVar(&options.CAFile, "ca", "", "PEM CA certificates trusted for https (none)")
+	flag.StringVar(&options.CertFile, "cert", "", "PEM client certificate presented over https (none)")
+	flag.StringVar(&options.KeyFile, "certKey", "", "PEM key of the client certificate (none)")
+	flag.StringVar(&keysPath, "keys", "", "Edit this keys file instead of a running service (none)")
+	flag.StringVar(&name, "name", "", "Name of the key to create (none)")
+	flag.StringVar(&scopes, "scopes", string(auth.ScopeRead), "Comma-separated scopes of the key to create (read)")
+	flag.StringVar(&tenant, "tenant", "", "Tenant the key to create is bound to (none)")
+	flag.Parse()
+
+	var target keys
+	if keysPath != "" {
+		local, err := apikey.New(keysPath)
+		if err != nil {
+			log.Fatalf("unable to load api keys: %v", err)
+		}
+		target = local
+	} else {
+		config, err := requestor.NewTLSConfig(options)
+		if err != nil {
+			log.Fatalf("invalid tls options: %v", err)
+		}
+		r := requestor.New(serverURL)
+		r.SetTLSConfig(config)
+		r.SetAPIKey(key)
+		target = remote{
+			client: client.New(r.Handler, metadataFactory.New([]metadataFactory.Contract{}), identityFactory.New()),
+		}
+	}
+
+	switch flag.Arg(0) {
+	case "create":
+		var requested []auth.Scope
+		for _, scope := range strings.Split(scopes, ",") {
+			requested = append(requested, auth.Scope(strings.TrimSpace(scope)))
+		}
+		issued, err := target.Create(name, requested, tenant)
+		if err != nil {
+			log.Fatal(err)
+		}
+		output(issued)
+	case "revoke":
+		if flag.NArg() != 2 {
+			log.Fatal(usage)
+		}
+		revoked, err := target.Revoke(flag.Arg(1))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !revoked {
+			log.Fatalf("key %s not found", flag.Arg(1))
+		}
+	case "list":
+		output(target.List())
+	default:
+		log.Fatal(usage)
+	}
+}
@@ -17,48 +17,48 @@ package main
 import (
 	"context"
 	"flag"
+	"log"
+	"sync"
 
 	"github.com/project-alvarium/go-store/internal/pkg"
-	"github.com/project-alvarium/go-store/internal/pkg/routable"
-	"github.com/project-alvarium/go-store/internal/pkg/routes/append"
-	"github.com/project-alvarium/go-store/internal/pkg/routes/create"
-	"github.com/project-alvarium/go-store/internal/pkg/routes/find"
-
-	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
-	"github.com/project-alvarium/go-sdk/pkg/annotation/store/memory"
-	assessMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata/factory"
-	pkiMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata/factory"
-	publishMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata/factory"
-	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-store/internal/pkg/config"
+	"github.com/project-alvarium/go-store/internal/pkg/service"
 
 	"github.com/gorilla/mux"
 )
 
 // main is the service's entry point.
 func main() {
-	var serverAddress string
+	var serverAddress, grpcAddress, configPath string
+	var legacyStatus bool
 	flag.StringVar(&serverAddress, "server", "localhost:8080", "Server address (localhost:8080)")
+	flag.StringVar(&grpcAddress, "grpc", "localhost:9090", "gRPC server address; empty disables (localhost:9090)")
+	flag.StringVar(&configPath, "config", "", "Configuration file (none)")
+	flag.BoolVar(&legacyStatus, "legacyStatus", false, "Report results with the original status codes (false)")
 	flag.Parse()
 
-	s := memory.New()
-	mFactory := metadataFactory.New(
-		[]metadataFactory.Contract{
-			assessMetadataFactory.NewDefault(),
-			pkiMetadataFactory.NewDefault(),
-			publishMetadataFactory.NewDefault(),
-		},
-	)
-	iFactory := identityFactory.New()
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("unable to load configuration: %v", err)
+	}
+	legacyStatus = legacyStatus || cfg.LegacyStatus
+
+	wired, err := service.New(cfg, legacyStatus, grpcAddress)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer wired.Close()
+
+	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(context.Background())
 	pkg.Run(
 		ctx,
 		cancel,
+		&wg,
 		mux.NewRouter().UseEncodedPath(),
-		[]routable.Contract{
-			find.New(s).Init,
-			create.New(s, mFactory, iFactory).Init,
-			append.New(s, mFactory, iFactory).Init,
-		},
+		wired.Routables,
+		wired.Runnables,
 		&serverAddress,
+		wired.TLSConfig,
 	)
 }
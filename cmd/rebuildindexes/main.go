@@ -0,0 +1,55 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/project-alvarium/go-store/pkg/http/client"
+	"github.com/project-alvarium/go-store/pkg/http/requestor"
+
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+// main asks a running service to rebuild its secondary indexes from existing data; rebuilding requires the admin
+// scope, presented as an API key or a bearer token.
+func main() {
+	var serverURL, key, token string
+	var options requestor.TLS
+	flag.StringVar(&serverURL, "server", "http://localhost:8080", "Server URL (http://localhost:8080)")
+	flag.StringVar(&key, "key", os.Getenv("ALVARIUM_API_KEY"), "Admin API key ($ALVARIUM_API_KEY)")
+	flag.StringVar(&token, "token", os.Getenv("ALVARIUM_TOKEN"), "Admin bearer token ($ALVARIUM_TOKEN)")
+	flag.StringVar(&options.CAFile, "ca", "", "PEM CA certificates trusted for https (none)")
+	flag.StringVar(&options.CertFile, "cert", "", "PEM client certificate presented over https (none)")
+	flag.StringVar(&options.KeyFile, "certKey", "", "PEM key of the client certificate (none)")
+	flag.Parse()
+
+	config, err := requestor.NewTLSConfig(options)
+	if err != nil {
+		log.Fatalf("invalid tls options: %v", err)
+	}
+	r := requestor.New(serverURL)
+	r.SetTLSConfig(config)
+	r.SetAPIKey(key)
+	r.SetBearerToken(token)
+	c := client.New(r.Handler, metadataFactory.New([]metadataFactory.Contract{}), identityFactory.New())
+	if result := c.RebuildIndexes(); result != status.Success {
+		log.Fatalf("index rebuild failed (status %d)", result)
+	}
+}
@@ -0,0 +1,50 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/project-alvarium/go-store/internal/pkg/audit"
+)
+
+// main verifies the hash chain of audit log files, given oldest first, and reports the first tampered record.
+func main() {
+	var previous string
+	flag.StringVar(&previous, "previous", "", "Hash of the record before the first file's, if it was discarded (none)")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: verifyaudit [-previous hash] file...")
+	}
+
+	total := 0
+	for _, path := range flag.Args() {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("unable to open %s: %v", path, err)
+		}
+		count, last, err := audit.Verify(file, previous)
+		_ = file.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		total += count
+		previous = last
+	}
+	fmt.Printf("%d records verified; last hash %s\n", total, previous)
+}
@@ -0,0 +1,91 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	OutcomeAllow   = "allow"
+	OutcomeDeny    = "deny"
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Event describes a security-relevant decision or operation: who (Subject, authenticated by Method, bound to Tenant,
+// connecting from Address) attempted Action on Resource, and its Outcome and Reason.  Events recording requests also
+// carry the request's ID, the Unique of the annotation stored, the response Status and the Latency in milliseconds.
+// Previous and Hash chain the events of a Log.
+type Event struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"requestId,omitempty"`
+	Type      string    `json:"type"`
+	Subject   string    `json:"subject,omitempty"`
+	Method    string    `json:"method,omitempty"`
+	Tenant    string    `json:"tenant,omitempty"`
+	Address   string    `json:"address,omitempty"`
+	Action    string    `json:"action"`
+	Resource  string    `json:"resource,omitempty"`
+	Unique    string    `json:"unique,omitempty"`
+	Outcome   string    `json:"outcome"`
+	Reason    string    `json:"reason,omitempty"`
+	Status    int       `json:"status,omitempty"`
+	Latency   float64   `json:"latency,omitempty"`
+	Previous  string    `json:"previous,omitempty"`
+	Hash      string    `json:"hash,omitempty"`
+}
+
+// Contract defines the audit log; Record must not block for long and reports its own failures.
+type Contract interface {
+	// Record appends event to the log.
+	Record(event Event)
+}
+
+// writer is a receiver that encapsulates required dependencies.
+type writer struct {
+	m       sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewWriter is a factory function that returns an audit log writing events to w as JSON lines.
+func NewWriter(w io.Writer) *writer {
+	return &writer{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// Record writes event as a JSON line; write failures are dropped.
+func (w *writer) Record(event Event) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	_ = w.encoder.Encode(event)
+}
+
+// discard is a receiver that drops every event.
+type discard struct{}
+
+// NewDiscard is a factory function that returns an audit log that drops every event; it is the default log of
+// components whose events are only recorded when auditing is enabled.
+func NewDiscard() discard {
+	return discard{}
+}
+
+// Record drops event.
+func (discard) Record(Event) {}
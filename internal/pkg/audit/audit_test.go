@@ -0,0 +1,49 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWriter_Record tests writing events as JSON lines.
+func TestWriter_Record(t *testing.T) {
+	var buffer bytes.Buffer
+	sut := NewWriter(&buffer)
+	events := []Event{
+		{Time: time.Now().UTC(), Type: "authorization", Subject: "a", Action: "create", Outcome: OutcomeAllow},
+		{Time: time.Now().UTC(), Type: "authorization", Action: "find", Outcome: OutcomeDeny, Reason: "no rule"},
+	}
+
+	for _, event := range events {
+		sut.Record(event)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	assert.Len(t, lines, len(events))
+	for index, line := range lines {
+		var event Event
+		assert.Nil(t, json.Unmarshal([]byte(line), &event))
+		assert.True(t, events[index].Time.Equal(event.Time))
+		event.Time = events[index].Time
+		assert.Equal(t, events[index], event)
+	}
+}
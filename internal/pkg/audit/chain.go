@@ -0,0 +1,160 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// hashField precedes the hash that ends each record of a Log.
+const hashField = `,"hash":"`
+
+// ErrTampered is returned by Verify for a record that was changed, inserted, removed or reordered.
+var ErrTampered = errors.New("audit record does not match its chain")
+
+// Sink is where a Log appends its records.
+type Sink interface {
+	// Write appends record, a JSON object without a trailing newline.
+	Write(record []byte) error
+
+	// Last returns the hash of the last record appended by an earlier Log, or an empty string if there is none.
+	Last() (string, error)
+}
+
+// Recorder is called with each record that could not be appended; the record is lost.
+type Recorder func(err error)
+
+// chain is a receiver that encapsulates required dependencies.
+type chain struct {
+	m        sync.Mutex
+	sink     Sink
+	last     string
+	recorder Recorder
+}
+
+// NewLog is a factory function that returns an audit log appending events to sink as hash-chained JSON records:
+// each record carries the hash of the record before it in Previous, and its own Hash is the SHA-256 of the record
+// without it, so changing, removing or reordering a record breaks the chain from that record on.  The chain continues
+// from the last record already in sink.
+func NewLog(sink Sink) (*chain, error) {
+	last, err := sink.Last()
+	if err != nil {
+		return nil, err
+	}
+	return &chain{
+		sink:     sink,
+		last:     last,
+		recorder: func(err error) {},
+	}, nil
+}
+
+// SetRecorder provides for method injection of the function told about lost records; the default discards them.
+func (c *chain) SetRecorder(recorder Recorder) {
+	c.recorder = recorder
+}
+
+// Record appends event to the chain.
+func (c *chain) Record(event Event) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	event.Previous = c.last
+	event.Hash = ""
+	body, err := json.Marshal(event)
+	if err != nil {
+		c.recorder(err)
+		return
+	}
+	record, hash := seal(body)
+	if err := c.sink.Write(record); err != nil {
+		c.recorder(err)
+		return
+	}
+	c.last = hash
+}
+
+// seal returns body, a JSON object, with its hash appended, and the hash.
+func seal(body []byte) ([]byte, string) {
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:])
+	record := make([]byte, 0, len(body)+len(hashField)+len(hash)+1)
+	record = append(record, body[:len(body)-1]...)
+	record = append(record, hashField...)
+	record = append(record, hash...)
+	return append(record, '"', '}'), hash
+}
+
+// unseal returns the event in record and the body its hash was computed from.
+func unseal(record []byte) (Event, []byte, error) {
+	var event Event
+	index := bytes.LastIndex(record, []byte(hashField))
+	if index < 0 || !bytes.HasSuffix(record, []byte(`"}`)) {
+		return event, nil, ErrTampered
+	}
+	body := append(append([]byte{}, record[:index]...), '}')
+	if err := json.Unmarshal(record, &event); err != nil {
+		return event, nil, ErrTampered
+	}
+	if event.Hash != string(record[index+len(hashField):len(record)-2]) {
+		return event, nil, ErrTampered
+	}
+	return event, body, nil
+}
+
+// hashOf returns the hash of record, or an empty string if record is not sealed.
+func hashOf(record []byte) string {
+	event, _, err := unseal(record)
+	if err != nil {
+		return ""
+	}
+	return event.Hash
+}
+
+// Verify reads the records of a Log from r, whose first record must follow the record hashed previous (empty for
+// the start of the chain), and returns the number of records and the hash of the last; ErrTampered is returned,
+// wrapped with the position of the first record that does not match its chain, if the records were tampered with.
+func Verify(r io.Reader, previous string) (int, string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	count := 0
+	for scanner.Scan() {
+		record := scanner.Bytes()
+		if len(record) == 0 {
+			continue
+		}
+		count++
+		event, body, err := unseal(record)
+		if err != nil || event.Previous != previous {
+			return count, previous, fmt.Errorf("record %d: %w", count, ErrTampered)
+		}
+		sum := sha256.Sum256(body)
+		if hex.EncodeToString(sum[:]) != event.Hash {
+			return count, previous, fmt.Errorf("record %d: %w", count, ErrTampered)
+		}
+		previous = event.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return count, previous, err
+	}
+	return count, previous, nil
+}
@@ -0,0 +1,332 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memory is a sink that keeps records in memory.
+type memory struct {
+	records [][]byte
+	last    string
+	err     error
+}
+
+// Write keeps record unless the sink is failing.
+func (m *memory) Write(record []byte) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.records = append(m.records, append([]byte{}, record...))
+	return nil
+}
+
+// Last returns the hash the sink was created with.
+func (m *memory) Last() (string, error) {
+	return m.last, nil
+}
+
+// String returns the records as JSON lines.
+func (m *memory) String() string {
+	var buffer bytes.Buffer
+	for _, record := range m.records {
+		buffer.Write(record)
+		buffer.WriteByte('\n')
+	}
+	return buffer.String()
+}
+
+// newEvent returns an event with a distinct resource.
+func newEvent(resource string) Event {
+	return Event{Time: time.Now().UTC(), Type: requestType, Action: "create", Resource: resource, Outcome: OutcomeSuccess}
+}
+
+// newChain returns a log appending to sink.
+func newChain(t *testing.T, sink Sink) *chain {
+	sut, err := NewLog(sink)
+	if err != nil {
+		assert.FailNow(t, "Unexpected NewLog failure:", err.Error())
+	}
+	return sut
+}
+
+// TestChain tests hash chaining records and detecting tampering.
+func TestChain(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	record := func(sink *memory, count int) {
+		sut := newChain(t, sink)
+		for index := 0; index < count; index++ {
+			sut.Record(newEvent(string(rune('a' + index))))
+		}
+	}
+
+	cases := []testCase{
+		{
+			name: "untouched records verify",
+			test: func(t *testing.T) {
+				sink := &memory{}
+				record(sink, 3)
+
+				count, last, err := Verify(strings.NewReader(sink.String()), "")
+
+				assert.Nil(t, err)
+				assert.Equal(t, 3, count)
+				assert.Equal(t, hashOf(sink.records[2]), last)
+			},
+		},
+		{
+			name: "each record carries the previous record's hash",
+			test: func(t *testing.T) {
+				sink := &memory{}
+				record(sink, 2)
+
+				first, _, err := unseal(sink.records[0])
+				assert.Nil(t, err)
+				second, _, err := unseal(sink.records[1])
+				assert.Nil(t, err)
+				assert.Equal(t, "", first.Previous)
+				assert.NotEqual(t, "", first.Hash)
+				assert.Equal(t, first.Hash, second.Previous)
+				assert.Equal(t, "b", second.Resource)
+			},
+		},
+		{
+			name: "chain continues from the sink's last record",
+			test: func(t *testing.T) {
+				sink := &memory{last: "earlier"}
+				record(sink, 1)
+
+				_, _, err := Verify(strings.NewReader(sink.String()), "")
+				assert.True(t, errors.Is(err, ErrTampered))
+				count, _, err := Verify(strings.NewReader(sink.String()), "earlier")
+				assert.Nil(t, err)
+				assert.Equal(t, 1, count)
+			},
+		},
+		{
+			name: "changed record is detected",
+			test: func(t *testing.T) {
+				sink := &memory{}
+				record(sink, 3)
+				sink.records[1] = bytes.Replace(sink.records[1], []byte(`"resource":"b"`), []byte(`"resource":"z"`), 1)
+
+				count, _, err := Verify(strings.NewReader(sink.String()), "")
+
+				assert.True(t, errors.Is(err, ErrTampered))
+				assert.Equal(t, 2, count)
+			},
+		},
+		{
+			name: "added field is detected",
+			test: func(t *testing.T) {
+				sink := &memory{}
+				record(sink, 1)
+				sink.records[0] = bytes.Replace(sink.records[0], []byte(`{`), []byte(`{"extra":1,`), 1)
+
+				_, _, err := Verify(strings.NewReader(sink.String()), "")
+
+				assert.True(t, errors.Is(err, ErrTampered))
+			},
+		},
+		{
+			name: "removed record is detected",
+			test: func(t *testing.T) {
+				sink := &memory{}
+				record(sink, 3)
+				sink.records = append(sink.records[:1], sink.records[2:]...)
+
+				count, _, err := Verify(strings.NewReader(sink.String()), "")
+
+				assert.True(t, errors.Is(err, ErrTampered))
+				assert.Equal(t, 2, count)
+			},
+		},
+		{
+			name: "reordered records are detected",
+			test: func(t *testing.T) {
+				sink := &memory{}
+				record(sink, 2)
+				sink.records[0], sink.records[1] = sink.records[1], sink.records[0]
+
+				count, _, err := Verify(strings.NewReader(sink.String()), "")
+
+				assert.True(t, errors.Is(err, ErrTampered))
+				assert.Equal(t, 1, count)
+			},
+		},
+		{
+			name: "unsealed record is detected",
+			test: func(t *testing.T) {
+				_, _, err := Verify(strings.NewReader(`{"type":"request"}`+"\n"), "")
+
+				assert.True(t, errors.Is(err, ErrTampered))
+			},
+		},
+		{
+			name: "failed write is recorded and does not advance the chain",
+			test: func(t *testing.T) {
+				sink := &memory{}
+				sut := newChain(t, sink)
+				var failures []error
+				sut.SetRecorder(func(err error) { failures = append(failures, err) })
+				sut.Record(newEvent("a"))
+				sink.err = errors.New("disk full")
+				sut.Record(newEvent("b"))
+				sink.err = nil
+				sut.Record(newEvent("c"))
+
+				assert.Equal(t, []error{errors.New("disk full")}, failures)
+				count, _, err := Verify(strings.NewReader(sink.String()), "")
+				assert.Nil(t, err)
+				assert.Equal(t, 2, count)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
+
+// TestFile tests appending records to a rotating file.
+func TestFile(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	newFile := func(t *testing.T, config Config) *file {
+		sut, err := NewFile(config)
+		if err != nil {
+			assert.FailNow(t, "Unexpected NewFile failure:", err.Error())
+		}
+		return sut
+	}
+
+	verify := func(t *testing.T, previous string, paths ...string) int {
+		total := 0
+		for _, path := range paths {
+			body, err := ioutil.ReadFile(path)
+			assert.Nil(t, err)
+			count, last, err := Verify(bytes.NewReader(body), previous)
+			assert.Nil(t, err, path)
+			total, previous = total+count, last
+		}
+		return total
+	}
+
+	cases := []testCase{
+		{
+			name: "invalid config",
+			test: func(t *testing.T) {
+				_, err := NewFile(Config{Path: filepath.Join(t.TempDir(), "audit.log"), MaxFiles: -1})
+
+				assert.NotNil(t, err)
+			},
+		},
+		{
+			name: "rotation without kept files",
+			test: func(t *testing.T) {
+				_, err := NewFile(Config{Path: filepath.Join(t.TempDir(), "audit.log"), MaxSize: 1})
+
+				assert.NotNil(t, err)
+			},
+		},
+		{
+			name: "chain resumes after reopening",
+			test: func(t *testing.T) {
+				config := Config{Path: filepath.Join(t.TempDir(), "audit.log")}
+				sink := newFile(t, config)
+				newChain(t, sink).Record(newEvent("a"))
+				assert.Nil(t, sink.Close())
+
+				sink = newFile(t, config)
+				defer func() { _ = sink.Close() }()
+				newChain(t, sink).Record(newEvent("b"))
+
+				assert.Equal(t, 2, verify(t, "", config.Path))
+			},
+		},
+		{
+			name: "rotation keeps MaxFiles files and the chain across them",
+			test: func(t *testing.T) {
+				config := Config{Path: filepath.Join(t.TempDir(), "audit.log"), MaxSize: 1, MaxFiles: 2}
+				sink := newFile(t, config)
+				defer func() { _ = sink.Close() }()
+				sut := newChain(t, sink)
+				for _, resource := range []string{"a", "b", "c", "d"} {
+					sut.Record(newEvent(resource))
+				}
+
+				_, err := os.Stat(config.Path + ".3")
+				assert.True(t, os.IsNotExist(err))
+				line, err := lastLine(config.Path + ".2")
+				assert.Nil(t, err)
+				oldest, _, err := unseal(line)
+				assert.Nil(t, err)
+				assert.Equal(t, "b", oldest.Resource)
+				assert.NotEqual(t, "", oldest.Previous)
+				assert.Equal(t, 3, verify(t, oldest.Previous, config.Path+".2", config.Path+".1", config.Path))
+			},
+		},
+		{
+			name: "chain resumes from the first rotation when the file is empty",
+			test: func(t *testing.T) {
+				config := Config{Path: filepath.Join(t.TempDir(), "audit.log"), MaxSize: 1, MaxFiles: 1}
+				sink := newFile(t, config)
+				newChain(t, sink).Record(newEvent("a"))
+				assert.Nil(t, sink.Close())
+				assert.Nil(t, os.Rename(config.Path, config.Path+".1"))
+
+				sink = newFile(t, config)
+				defer func() { _ = sink.Close() }()
+				newChain(t, sink).Record(newEvent("b"))
+
+				assert.Equal(t, 2, verify(t, "", config.Path+".1", config.Path))
+			},
+		},
+		{
+			name: "tampered last record prevents resuming",
+			test: func(t *testing.T) {
+				config := Config{Path: filepath.Join(t.TempDir(), "audit.log")}
+				assert.Nil(t, ioutil.WriteFile(config.Path, []byte(`{"type":"request"}`+"\n"), 0600))
+				sink := newFile(t, config)
+				defer func() { _ = sink.Close() }()
+
+				_, err := NewLog(sink)
+
+				assert.True(t, errors.Is(err, ErrTampered))
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
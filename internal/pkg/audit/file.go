@@ -0,0 +1,200 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Config enables a Log appending to the JSON lines file at Path.  The file is rotated before it grows beyond MaxSize
+// bytes (zero never rotates it): it is renamed Path.1, earlier rotations are renumbered, and only MaxFiles of them,
+// at least one if the file rotates, are kept.  The chain continues across rotations, so the kept files verify in order from the oldest.
+type Config struct {
+	Path     string `json:"path"`
+	MaxSize  int64  `json:"maxSize"`
+	MaxFiles int    `json:"maxFiles"`
+}
+
+// NewDefaultConfig returns the default configuration: events are not logged to a file.
+func NewDefaultConfig() Config {
+	return Config{
+		MaxSize:  10 * 1024 * 1024,
+		MaxFiles: 10,
+	}
+}
+
+// Enabled returns whether the configuration logs events to a file.
+func (c Config) Enabled() bool {
+	return c.Path != ""
+}
+
+// Validate returns an error if the configuration is not usable.
+func (c Config) Validate() error {
+	if c.MaxSize < 0 || c.MaxFiles < 0 {
+		return errors.New("maxSize and maxFiles must not be negative")
+	}
+	if c.MaxSize > 0 && c.MaxFiles == 0 {
+		return errors.New("maxFiles must be positive when maxSize is, or rotation would discard the log")
+	}
+	return nil
+}
+
+// file is a receiver that encapsulates required dependencies.
+type file struct {
+	m      sync.Mutex
+	config Config
+	handle *os.File
+	size   int64
+}
+
+// NewFile is a factory function that returns a sink appending records to the file config names, creating it if
+// necessary; the file is only ever appended to.
+func NewFile(config Config) (*file, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	f := &file{config: config}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the file for appending.
+func (f *file) open() error {
+	handle, err := os.OpenFile(f.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := handle.Stat()
+	if err != nil {
+		_ = handle.Close()
+		return err
+	}
+	f.handle = handle
+	f.size = info.Size()
+	return nil
+}
+
+// rotated returns the name of the nth rotation of the file.
+func (f *file) rotated(n int) string {
+	return f.config.Path + "." + strconv.Itoa(n)
+}
+
+// rotate renames the file to its first rotation, renumbering and discarding earlier ones, and opens a new file.
+func (f *file) rotate() error {
+	if err := f.handle.Close(); err != nil {
+		return err
+	}
+	if err := os.Remove(f.rotated(f.config.MaxFiles)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for n := f.config.MaxFiles - 1; n > 0; n-- {
+		if err := os.Rename(f.rotated(n), f.rotated(n+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(f.config.Path, f.rotated(1)); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Write appends record as a line, first rotating the file if the line would grow it beyond MaxSize.
+func (f *file) Write(record []byte) error {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	line := append(append(make([]byte, 0, len(record)+1), record...), '\n')
+	if f.config.MaxSize > 0 && f.size > 0 && f.size+int64(len(line)) > f.config.MaxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := f.handle.Write(line)
+	f.size += int64(n)
+	return err
+}
+
+// Last returns the hash of the last record in the file or, if it is empty, in its first rotation.
+func (f *file) Last() (string, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	for _, path := range []string{f.config.Path, f.rotated(1)} {
+		record, err := lastLine(path)
+		if err != nil {
+			return "", err
+		}
+		if record != nil {
+			if hash := hashOf(record); hash != "" {
+				return hash, nil
+			}
+			return "", fmt.Errorf("%s: %w", path, ErrTampered)
+		}
+	}
+	return "", nil
+}
+
+// Close closes the file.
+func (f *file) Close() error {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	return f.handle.Close()
+}
+
+// lastLine returns the last non-empty line of the file at path, or nil if there is none.
+func lastLine(path string) ([]byte, error) {
+	handle, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = handle.Close() }()
+
+	info, err := handle.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	for block := int64(4096); ; block *= 2 {
+		if block > size {
+			block = size
+		}
+		buffer := make([]byte, block)
+		if _, err := handle.ReadAt(buffer, size-block); err != nil {
+			return nil, err
+		}
+		trimmed := bytes.TrimRight(buffer, "\n")
+		if index := bytes.LastIndexByte(trimmed, '\n'); index >= 0 {
+			return trimmed[index+1:], nil
+		}
+		if block == size {
+			if len(trimmed) == 0 {
+				return nil, nil
+			}
+			return trimmed, nil
+		}
+	}
+}
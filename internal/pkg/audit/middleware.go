@@ -0,0 +1,154 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package audit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/logging"
+	"github.com/project-alvarium/go-store/internal/pkg/recorder"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// requestType is the type of events recording requests.
+	requestType = "request"
+)
+
+// uniqueKey holds, in a request context, where the Unique of the annotation the request stores is noted.
+type uniqueKey struct{}
+
+// SetUnique notes, for the event recording the request ctx belongs to, the Unique of the annotation it stores.
+func SetUnique(ctx context.Context, unique string) {
+	if noted, ok := ctx.Value(uniqueKey{}).(*string); ok {
+		*noted = unique
+	}
+}
+
+// NewEvent returns an event recording a request, started at started, for action on resource by the principal
+// authenticated in ctx, if any; the caller completes it with the request's outcome.  Transports other than HTTP use it
+// to record each write they receive.
+func NewEvent(ctx context.Context, started time.Time, action, resource string) Event {
+	event := Event{
+		Time:      started.UTC(),
+		RequestID: logging.RequestID(ctx),
+		Type:      requestType,
+		Action:    action,
+		Resource:  resource,
+	}
+	if principal, ok := auth.FromContext(ctx); ok {
+		event.Subject = principal.Subject
+		event.Method = principal.Method
+		event.Tenant = principal.Tenant
+	}
+	return event
+}
+
+// Latency returns the milliseconds elapsed between started and finished.
+func Latency(started, finished time.Time) float64 {
+	return float64(finished.Sub(started).Microseconds()) / 1000
+}
+
+// Host returns the host of address, or address if it carries no port.
+func Host(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
+
+// middleware is a receiver that encapsulates required dependencies.
+type middleware struct {
+	log   Contract
+	clock func() time.Time
+}
+
+// NewMiddleware is a factory function that returns middleware recording each request that changes state in log.
+func NewMiddleware(log Contract) *middleware {
+	return &middleware{
+		log:   log,
+		clock: time.Now,
+	}
+}
+
+// SetClock provides for method injection of the clock events are timed by; the default is time.Now.
+func (m *middleware) SetClock(clock func() time.Time) {
+	m.clock = clock
+}
+
+// Init installs the audit middleware on muxRouter; it must be installed after the authentication middleware.
+func (m *middleware) Init(muxRouter *mux.Router) {
+	muxRouter.Use(m.Middleware)
+}
+
+// Middleware records every request with a method other than GET, HEAD or OPTIONS, whatever its outcome: the
+// principal that made it, the address it came from, its route and identity, the Unique of the annotation it stores,
+// the status code it was answered with and how long that took.
+func (m *middleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			started := m.clock()
+			var unique string
+			recorded := recorder.New(w)
+			next.ServeHTTP(recorded, r.WithContext(context.WithValue(r.Context(), uniqueKey{}, &unique)))
+
+			event := NewEvent(r.Context(), started, action(r), resource(r))
+			event.Address = Host(r.RemoteAddr)
+			event.Unique = unique
+			event.Outcome = OutcomeSuccess
+			event.Status = recorded.Code()
+			event.Latency = Latency(started, m.clock())
+			if recorded.Code() >= http.StatusBadRequest {
+				event.Outcome = OutcomeFailure
+			}
+			m.log.Record(event)
+		},
+	)
+}
+
+// action returns the name of r's route or, if it has none, its method and path template.
+func action(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return r.Method + " " + r.URL.Path
+	}
+	if name := route.GetName(); name != "" {
+		return name
+	}
+	if template, err := route.GetPathTemplate(); err == nil {
+		return r.Method + " " + template
+	}
+	return r.Method + " " + r.URL.Path
+}
+
+// resource returns the identity r acts on or, if its route carries none, its path.
+func resource(r *http.Request) string {
+	if id, exists := urlIdentity.FromRequest(r); exists {
+		return id
+	}
+	return r.URL.Path
+}
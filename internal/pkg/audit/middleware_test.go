@@ -0,0 +1,161 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package audit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// log is an audit log that keeps events in memory.
+type log struct {
+	events []Event
+}
+
+// Record keeps event.
+func (l *log) Record(event Event) {
+	l.events = append(l.events, event)
+}
+
+// authenticate is a routable that authenticates every request, setting its address.
+func authenticate(muxRouter *mux.Router) {
+	// as the service does, so that identities may contain escaped slashes.
+	muxRouter.UseEncodedPath()
+	muxRouter.Use(
+		func(next http.Handler) http.Handler {
+			return http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					principal := &auth.Principal{Subject: "alice", Method: "apikey", Tenant: "acme"}
+					r.RemoteAddr = "192.0.2.1:1234"
+					next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+				},
+			)
+		},
+	)
+}
+
+// routes is a routable serving a named route that notes the Unique it stores and an unnamed route that fails.
+func routes(muxRouter *mux.Router) {
+	muxRouter.HandleFunc(
+		"/create/{identity}",
+		func(w http.ResponseWriter, r *http.Request) {
+			SetUnique(r.Context(), "unique")
+			w.WriteHeader(http.StatusCreated)
+		},
+	).Name("create")
+	muxRouter.HandleFunc(
+		"/things/{name}",
+		func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) },
+	).Methods(http.MethodDelete)
+}
+
+// TestMiddleware tests recording requests that change state.
+func TestMiddleware(t *testing.T) {
+	type testCase struct {
+		name     string
+		method   string
+		url      string
+		expected []Event
+	}
+
+	started := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []testCase{
+		{
+			name:   "create",
+			method: http.MethodPost,
+			url:    "/create/a%2Fb",
+			expected: []Event{
+				{
+					Time:     started,
+					Type:     requestType,
+					Subject:  "alice",
+					Method:   "apikey",
+					Tenant:   "acme",
+					Address:  "192.0.2.1",
+					Action:   "create",
+					Resource: "a/b",
+					Unique:   "unique",
+					Outcome:  OutcomeSuccess,
+					Status:   http.StatusCreated,
+					Latency:  1.5,
+				},
+			},
+		},
+		{
+			name:   "failed request",
+			method: http.MethodDelete,
+			url:    "/things/x",
+			expected: []Event{
+				{
+					Time:     started,
+					Type:     requestType,
+					Subject:  "alice",
+					Method:   "apikey",
+					Tenant:   "acme",
+					Address:  "192.0.2.1",
+					Action:   "DELETE /things/{name}",
+					Resource: "/things/x",
+					Outcome:  OutcomeFailure,
+					Status:   http.StatusNotFound,
+					Latency:  1.5,
+				},
+			},
+		},
+		{
+			name:   "read is not recorded",
+			method: http.MethodGet,
+			url:    "/create/a",
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				events := &log{}
+				sut := NewMiddleware(events)
+				now := started
+				sut.SetClock(
+					func() time.Time {
+						result := now
+						now = now.Add(1500 * time.Microsecond)
+						return result
+					},
+				)
+				cancel, wg, muxRouter := testInternal.NewSUT(
+					pkg.Run,
+					[]routable.Contract{authenticate, sut.Init, routes},
+				)
+				defer func() {
+					cancel()
+					wg.Wait()
+				}()
+
+				testInternal.SendRequestWithoutBody(t, muxRouter, cases[i].method, cases[i].url)
+
+				assert.Equal(t, cases[i].expected, events.events)
+			},
+		)
+	}
+}
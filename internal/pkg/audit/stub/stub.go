@@ -0,0 +1,48 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package stub
+
+import (
+	"sync"
+
+	"github.com/project-alvarium/go-store/internal/pkg/audit"
+)
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	m      sync.Mutex
+	events []audit.Event
+}
+
+// New is a factory function that returns an audit log that keeps its events in memory.
+func New() *instance {
+	return &instance{}
+}
+
+// Record keeps event.
+func (i *instance) Record(event audit.Event) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.events = append(i.events, event)
+}
+
+// Events returns the events recorded so far.
+func (i *instance) Events() []audit.Event {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	return append([]audit.Event(nil), i.events...)
+}
@@ -0,0 +1,210 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/persist"
+	"github.com/project-alvarium/go-store/pkg/http/header"
+	"github.com/project-alvarium/go-store/pkg/http/resource"
+)
+
+const (
+	Header     = header.APIKey
+	Method     = "apikey"
+	prefix     = "ak_"
+	separator  = "."
+	idSize     = 8
+	secretSize = 32
+)
+
+var (
+	// ErrInvalidScopes reports a key request that does not name at least one known scope.
+	ErrInvalidScopes = errors.New("scopes must include at least one of read, write and admin")
+
+	// errInvalidKey reports credentials that do not identify a key.
+	errInvalidKey = errors.New("invalid api key")
+)
+
+// Key describes an API key; only a hash of its secret is kept.  A key with a Tenant only grants access to that
+// tenant's identities.
+type Key = resource.Key
+
+// Issued is a newly created key and the credentials that present it, which are not available again.
+type Issued = resource.Issued
+
+// Contract defines the management of API keys.
+type Contract interface {
+	// Create returns a new key named name that grants scopes, bound to tenant unless it is empty.
+	Create(name string, scopes []auth.Scope, tenant string) (Issued, error)
+
+	// Revoke deletes the key with the given ID and returns whether it existed.
+	Revoke(id string) (bool, error)
+
+	// List returns every key, without its hash, in order of creation.
+	List() []Key
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	m    sync.RWMutex
+	path string
+	keys map[string]Key
+}
+
+// New is a factory function that returns instance holding the keys in the JSON file at path; an empty path keeps keys
+// in memory only.
+func New(path string) (*instance, error) {
+	i := &instance{
+		path: path,
+		keys: make(map[string]Key),
+	}
+	if err := persist.Load(path, &i.keys); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// Create returns a new key named name that grants scopes, bound to tenant unless it is empty.
+func (i *instance) Create(name string, scopes []auth.Scope, tenant string) (Issued, error) {
+	if len(scopes) == 0 {
+		return Issued{}, ErrInvalidScopes
+	}
+	for _, scope := range scopes {
+		if !scope.Valid() {
+			return Issued{}, ErrInvalidScopes
+		}
+	}
+
+	id, err := random(idSize)
+	if err != nil {
+		return Issued{}, err
+	}
+	secret, err := random(secretSize)
+	if err != nil {
+		return Issued{}, err
+	}
+	key := Key{
+		ID:      id,
+		Name:    name,
+		Scopes:  scopes,
+		Tenant:  tenant,
+		Created: time.Now().UTC(),
+		Hash:    hash(secret),
+	}
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.keys[id] = key
+	if err := persist.Save(i.path, i.keys); err != nil {
+		delete(i.keys, id)
+		return Issued{}, err
+	}
+
+	key.Hash = ""
+	return Issued{Key: key, Secret: prefix + id + separator + secret}, nil
+}
+
+// Revoke deletes the key with the given ID and returns whether it existed.
+func (i *instance) Revoke(id string) (bool, error) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	key, ok := i.keys[id]
+	if !ok {
+		return false, nil
+	}
+	delete(i.keys, id)
+	if err := persist.Save(i.path, i.keys); err != nil {
+		i.keys[id] = key
+		return false, err
+	}
+	return true, nil
+}
+
+// List returns every key, without its hash, in order of creation.
+func (i *instance) List() []Key {
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	result := make([]Key, 0, len(i.keys))
+	for _, key := range i.keys {
+		key.Hash = ""
+		result = append(result, key)
+	}
+	sort.Slice(
+		result,
+		func(a, b int) bool {
+			if result[a].Created.Equal(result[b].Created) {
+				return result[a].ID < result[b].ID
+			}
+			return result[a].Created.Before(result[b].Created)
+		},
+	)
+	return result
+}
+
+// Authenticate returns the principal of the key presented in r's X-API-Key header.
+func (i *instance) Authenticate(r *http.Request) (*auth.Principal, error) {
+	credentials := r.Header.Get(Header)
+	if credentials == "" {
+		return nil, nil
+	}
+
+	id, secret, ok := strings.Cut(strings.TrimPrefix(credentials, prefix), separator)
+	if !ok || !strings.HasPrefix(credentials, prefix) {
+		return nil, errInvalidKey
+	}
+
+	i.m.RLock()
+	key, exists := i.keys[id]
+	i.m.RUnlock()
+	if !exists || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hash(secret))) != 1 {
+		return nil, errInvalidKey
+	}
+	return &auth.Principal{Subject: key.ID, Method: Method, Scopes: key.Scopes, Tenant: key.Tenant}, nil
+}
+
+// Challenge returns the WWW-Authenticate challenge that asks for an API key.
+func (*instance) Challenge() string {
+	return `ApiKey header="` + Header + `"`
+}
+
+// random returns size random bytes encoded as hex.
+func random(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hash returns the hex encoded SHA-256 digest of secret.
+func hash(secret string) string {
+	digest := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,213 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package apikey
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSUT returns a new system under test whose keys are kept in path.
+func newSUT(t *testing.T, path string) *instance {
+	sut, err := New(path)
+	if err != nil {
+		assert.FailNow(t, "Unexpected New failure:", err.Error())
+	}
+	return sut
+}
+
+// create returns a new key with scopes.
+func create(t *testing.T, sut *instance, scopes ...auth.Scope) Issued {
+	issued, err := sut.Create(test.FactoryRandomString(), scopes, "")
+	if err != nil {
+		assert.FailNow(t, "Unexpected Create failure:", err.Error())
+	}
+	return issued
+}
+
+// request returns a request presenting credentials; empty credentials are not presented.
+func request(credentials string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if credentials != "" {
+		r.Header.Set(Header, credentials)
+	}
+	return r
+}
+
+// TestInstance tests API key management and authentication.
+func TestInstance(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "invalid scopes",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+
+				_, noScopes := sut.Create(test.FactoryRandomString(), nil, "")
+				_, unknownScope := sut.Create(test.FactoryRandomString(), []auth.Scope{auth.ScopeRead, "unknown"}, "")
+
+				assert.Equal(t, ErrInvalidScopes, noScopes)
+				assert.Equal(t, ErrInvalidScopes, unknownScope)
+				assert.Empty(t, sut.List())
+			},
+		},
+		{
+			name: "create and authenticate",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				issued := create(t, sut, auth.ScopeRead, auth.ScopeWrite)
+
+				principal, err := sut.Authenticate(request(issued.Secret))
+
+				assert.Nil(t, err)
+				assert.Equal(t, &auth.Principal{Subject: issued.ID, Method: Method, Scopes: issued.Scopes}, principal)
+				assert.Empty(t, issued.Hash)
+				assert.True(t, strings.HasPrefix(issued.Secret, prefix+issued.ID+separator))
+			},
+		},
+		{
+			name: "tenant binds principal",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				tenant := test.FactoryRandomString()
+				issued, err := sut.Create(test.FactoryRandomString(), []auth.Scope{auth.ScopeWrite}, tenant)
+				if err != nil {
+					assert.FailNow(t, "Unexpected Create failure:", err.Error())
+				}
+
+				principal, err := sut.Authenticate(request(issued.Secret))
+
+				assert.Nil(t, err)
+				assert.Equal(t, tenant, issued.Tenant)
+				assert.Equal(t, tenant, principal.Tenant)
+			},
+		},
+		{
+			name: "list omits hashes",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				first := create(t, sut, auth.ScopeRead)
+				second := create(t, sut, auth.ScopeAdmin)
+
+				keys := sut.List()
+
+				assert.Len(t, keys, 2)
+				assert.ElementsMatch(t, []Key{first.Key, second.Key}, keys)
+				for _, key := range keys {
+					assert.Empty(t, key.Hash)
+				}
+			},
+		},
+		{
+			name: "no credentials",
+			test: func(t *testing.T) {
+				principal, err := newSUT(t, "").Authenticate(request(""))
+
+				assert.Nil(t, principal)
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name: "invalid credentials",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				issued := create(t, sut, auth.ScopeRead)
+
+				for _, credentials := range []string{
+					test.FactoryRandomString(),
+					strings.TrimPrefix(issued.Secret, prefix),
+					prefix + issued.ID,
+					prefix + issued.ID + separator + test.FactoryRandomString(),
+					prefix + test.FactoryRandomString() + separator + test.FactoryRandomString(),
+				} {
+					principal, err := sut.Authenticate(request(credentials))
+
+					assert.Nil(t, principal, credentials)
+					assert.Equal(t, errInvalidKey, err, credentials)
+				}
+			},
+		},
+		{
+			name: "revoke",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				issued := create(t, sut, auth.ScopeRead)
+
+				revoked, revokeErr := sut.Revoke(issued.ID)
+				again, againErr := sut.Revoke(issued.ID)
+				principal, err := sut.Authenticate(request(issued.Secret))
+
+				assert.True(t, revoked)
+				assert.Nil(t, revokeErr)
+				assert.False(t, again)
+				assert.Nil(t, againErr)
+				assert.Nil(t, principal)
+				assert.Equal(t, errInvalidKey, err)
+				assert.Empty(t, sut.List())
+			},
+		},
+		{
+			name: "persisted",
+			test: func(t *testing.T) {
+				directory, err := ioutil.TempDir("", "apikey")
+				if err != nil {
+					assert.FailNow(t, "Unexpected ioutil.TempDir failure:", err.Error())
+				}
+				defer func() { _ = os.RemoveAll(directory) }()
+				path := filepath.Join(directory, "keys.json")
+				kept := create(t, newSUT(t, path), auth.ScopeWrite)
+				sut := newSUT(t, path)
+				revoked := create(t, sut, auth.ScopeRead)
+				_, _ = sut.Revoke(revoked.ID)
+
+				reloaded := newSUT(t, path)
+				principal, err := reloaded.Authenticate(request(kept.Secret))
+				_, revokedErr := reloaded.Authenticate(request(revoked.Secret))
+				contents, readErr := ioutil.ReadFile(path)
+
+				assert.Nil(t, err)
+				assert.Equal(t, kept.ID, principal.Subject)
+				assert.Equal(t, errInvalidKey, revokedErr)
+				assert.Nil(t, readErr)
+				assert.NotContains(t, string(contents), strings.TrimPrefix(kept.Secret, prefix+kept.ID+separator))
+			},
+		},
+		{
+			name: "challenge",
+			test: func(t *testing.T) {
+				assert.Equal(t, `ApiKey header="X-API-Key"`, newSUT(t, "").Challenge())
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
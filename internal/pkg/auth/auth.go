@@ -0,0 +1,241 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/logging"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+	"github.com/project-alvarium/go-store/pkg/http/resource"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	challengeHeader = "WWW-Authenticate"
+)
+
+// Scope grants access to a class of operations; each scope includes those ranked below it.
+type Scope = resource.Scope
+
+const (
+	ScopeRead  = resource.ScopeRead
+	ScopeWrite = resource.ScopeWrite
+	ScopeAdmin = resource.ScopeAdmin
+)
+
+// Principal is the authenticated caller of a request; Method names the kind of credentials it presented.  Roles are
+// the roles its credentials assert, if any, and Prefixes restricts the identities it may access; a nil Prefixes
+// places no restriction.  Tenant, if not empty, names the only tenant whose identities it may access.
+type Principal struct {
+	Subject  string   `json:"subject"`
+	Method   string   `json:"method"`
+	Scopes   []Scope  `json:"scopes"`
+	Roles    []string `json:"roles,omitempty"`
+	Prefixes []string `json:"prefixes,omitempty"`
+	Tenant   string   `json:"tenant,omitempty"`
+}
+
+// Allows returns whether one of the principal's scopes grants access to operations that require required.
+func (p *Principal) Allows(required Scope) bool {
+	for _, scope := range p.Scopes {
+		if scope.Includes(required) {
+			return true
+		}
+	}
+	return false
+}
+
+// Permits returns whether the principal's prefixes allow it to access id.
+func (p *Principal) Permits(id string) bool {
+	if p.Prefixes == nil {
+		return true
+	}
+	for _, prefix := range p.Prefixes {
+		if strings.HasPrefix(id, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// contextKey identifies the principal in a request context.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying p.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal carried by ctx, if any.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok
+}
+
+// Authenticator defines a kind of credentials.
+type Authenticator interface {
+	// Authenticate returns the principal whose credentials r carries; it returns nil and no error if r carries no
+	// credentials of this kind.
+	Authenticate(r *http.Request) (*Principal, error)
+
+	// Challenge returns the WWW-Authenticate challenge that asks for credentials of this kind, or an empty string if
+	// they are not requested through HTTP.
+	Challenge() string
+}
+
+// Config enables authentication; KeysPath is the file holding API keys, which are only kept in memory if it is
+// empty.
+type Config struct {
+	Enabled  bool   `json:"enabled"`
+	KeysPath string `json:"keysPath"`
+}
+
+// Policy assigns the scope each request requires by path prefix.  Requests under Public need no credentials,
+// requests under Admin require the admin scope, and requests under Read, or made with a safe method, require the read
+// scope; every other request requires the write scope.
+type Policy struct {
+	Public []string
+	Read   []string
+	Admin  []string
+}
+
+// required returns the scope r requires, or false if r is public.
+func (p Policy) required(r *http.Request) (Scope, bool) {
+	switch {
+	case under(r.URL.Path, p.Public):
+		return "", false
+	case under(r.URL.Path, p.Admin):
+		return ScopeAdmin, true
+	case under(r.URL.Path, p.Read):
+		return ScopeRead, true
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ScopeRead, true
+	}
+	return ScopeWrite, true
+}
+
+// under returns whether path is one of prefixes or lies beneath one of them.
+func under(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	config         Config
+	policy         Policy
+	authenticators []Authenticator
+}
+
+// New is a factory function that returns instance; authenticators are tried in order.
+func New(config Config, policy Policy, authenticators []Authenticator) *instance {
+	return &instance{
+		config:         config,
+		policy:         policy,
+		authenticators: authenticators,
+	}
+}
+
+// Init installs the authentication middleware on muxRouter if authentication is enabled; it must be installed before
+// any other middleware that should only see authenticated requests.
+func (i *instance) Init(muxRouter *mux.Router) {
+	if i.config.Enabled {
+		muxRouter.Use(i.Middleware)
+	}
+}
+
+// Middleware rejects requests that do not carry credentials granting the scope policy requires, or whose route
+// carries an identity outside the principal's prefixes, and passes the principal to next in the request context.
+func (i *instance) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			required, protected := i.policy.required(r)
+			if !protected {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, failure := i.authenticate(r)
+			if failure != nil {
+				for _, authenticator := range i.authenticators {
+					if challenge := authenticator.Challenge(); challenge != "" {
+						w.Header().Add(challengeHeader, challenge)
+					}
+				}
+				problem.Write(w, r, failure)
+				return
+			}
+			if !principal.Allows(required) {
+				problem.Write(w, r, problem.ErrForbidden.WithDetail("requires the "+string(required)+" scope"))
+				return
+			}
+			if id, exists := urlIdentity.FromRequest(r); exists && !principal.Permits(id) {
+				problem.Write(w, r, problem.ErrForbidden.WithDetail("identity is outside the principal's prefixes"))
+				return
+			}
+			logging.SetSubject(r.Context(), principal.Subject)
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), principal)))
+		},
+	)
+}
+
+// Authorize returns the principal whose credentials r carries, or the problem that rejects r if they do not grant the
+// scope policy requires; it returns neither if authentication is disabled or r is public.
+func (i *instance) Authorize(r *http.Request) (*Principal, *problem.Instance) {
+	if !i.config.Enabled {
+		return nil, nil
+	}
+	required, protected := i.policy.required(r)
+	if !protected {
+		return nil, nil
+	}
+
+	principal, failure := i.authenticate(r)
+	if failure != nil {
+		return nil, failure
+	}
+	if !principal.Allows(required) {
+		return nil, problem.ErrForbidden.WithDetail("requires the " + string(required) + " scope")
+	}
+	return principal, nil
+}
+
+// authenticate returns the principal identified by the first credentials r carries.
+func (i *instance) authenticate(r *http.Request) (*Principal, *problem.Instance) {
+	for _, authenticator := range i.authenticators {
+		principal, err := authenticator.Authenticate(r)
+		if err != nil {
+			return nil, problem.ErrUnauthenticated.WithDetail(err.Error())
+		}
+		if principal != nil {
+			return principal, nil
+		}
+	}
+	return nil, problem.ErrUnauthenticated.WithDetail("no credentials presented")
+}
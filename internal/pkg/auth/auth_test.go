@@ -0,0 +1,273 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	credentialsHeader = "X-Test-Credentials"
+	challenge         = "Test"
+)
+
+// authenticator is a stub that accepts the principals it holds by name and rejects other credentials.
+type authenticator struct {
+	principals map[string]*Principal
+}
+
+// Authenticate returns the principal named by r's credentials header.
+func (a authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	credentials := r.Header.Get(credentialsHeader)
+	if credentials == "" {
+		return nil, nil
+	}
+	if principal, ok := a.principals[credentials]; ok {
+		return principal, nil
+	}
+	return nil, errors.New("unknown credentials")
+}
+
+// Challenge returns a fixed challenge.
+func (authenticator) Challenge() string {
+	return challenge
+}
+
+// TestPolicy tests the scope each request requires.
+func TestPolicy(t *testing.T) {
+	policy := Policy{Public: []string{"/public"}, Read: []string{"/query"}, Admin: []string{"/admin/"}}
+
+	type testCase struct {
+		method    string
+		path      string
+		required  Scope
+		protected bool
+	}
+
+	cases := []testCase{
+		{method: http.MethodGet, path: "/public", protected: false},
+		{method: http.MethodPost, path: "/public/nested", protected: false},
+		{method: http.MethodGet, path: "/publicity", required: ScopeRead, protected: true},
+		{method: http.MethodPost, path: "/query", required: ScopeRead, protected: true},
+		{method: http.MethodGet, path: "/admin", required: ScopeAdmin, protected: true},
+		{method: http.MethodGet, path: "/admin/nested", required: ScopeAdmin, protected: true},
+		{method: http.MethodGet, path: "/data", required: ScopeRead, protected: true},
+		{method: http.MethodHead, path: "/data", required: ScopeRead, protected: true},
+		{method: http.MethodPut, path: "/data", required: ScopeWrite, protected: true},
+		{method: http.MethodDelete, path: "/data", required: ScopeWrite, protected: true},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].method+" "+cases[i].path,
+			func(t *testing.T) {
+				required, protected := policy.required(httptest.NewRequest(cases[i].method, cases[i].path, nil))
+
+				assert.Equal(t, cases[i].required, required)
+				assert.Equal(t, cases[i].protected, protected)
+			},
+		)
+	}
+}
+
+// TestMiddleware tests the authentication middleware.
+func TestMiddleware(t *testing.T) {
+	reader := &Principal{Subject: "reader", Method: "test", Scopes: []Scope{ScopeRead}}
+	admin := &Principal{Subject: "admin", Method: "test", Scopes: []Scope{ScopeAdmin}}
+	scoped := &Principal{Subject: "scoped", Method: "test", Scopes: []Scope{ScopeWrite}, Prefixes: []string{"site-a"}}
+	unscoped := &Principal{Subject: "unscoped", Method: "test", Scopes: []Scope{ScopeWrite}, Prefixes: []string{}}
+	stub := authenticator{
+		principals: map[string]*Principal{
+			reader.Subject:   reader,
+			admin.Subject:    admin,
+			scoped.Subject:   scoped,
+			unscoped.Subject: unscoped,
+		},
+	}
+	policy := Policy{Public: []string{"/public"}, Admin: []string{"/admin"}}
+
+	var seen *Principal
+	handler := func(muxRouter *mux.Router) {
+		muxRouter.HandleFunc(
+			"/items/{identity}",
+			func(w http.ResponseWriter, r *http.Request) {
+				seen, _ = FromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			},
+		)
+		muxRouter.PathPrefix("/").HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				seen, _ = FromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			},
+		)
+	}
+
+	type testCase struct {
+		name        string
+		enabled     bool
+		method      string
+		path        string
+		credentials string
+		expected    int
+		principal   *Principal
+		code        string
+	}
+
+	cases := []testCase{
+		{name: "disabled", method: http.MethodPut, path: "/data", expected: http.StatusOK},
+		{name: "public", enabled: true, method: http.MethodPut, path: "/public", expected: http.StatusOK},
+		{
+			name:     "no credentials",
+			enabled:  true,
+			method:   http.MethodGet,
+			path:     "/data",
+			expected: http.StatusUnauthorized,
+			code:     problem.CodeUnauthenticated,
+		},
+		{
+			name:        "invalid credentials",
+			enabled:     true,
+			method:      http.MethodGet,
+			path:        "/data",
+			credentials: "unknown",
+			expected:    http.StatusUnauthorized,
+			code:        problem.CodeUnauthenticated,
+		},
+		{
+			name:        "read",
+			enabled:     true,
+			method:      http.MethodGet,
+			path:        "/data",
+			credentials: reader.Subject,
+			expected:    http.StatusOK,
+			principal:   reader,
+		},
+		{
+			name:        "write without scope",
+			enabled:     true,
+			method:      http.MethodPut,
+			path:        "/data",
+			credentials: reader.Subject,
+			expected:    http.StatusForbidden,
+			code:        problem.CodeForbidden,
+		},
+		{
+			name:        "admin without scope",
+			enabled:     true,
+			method:      http.MethodGet,
+			path:        "/admin",
+			credentials: reader.Subject,
+			expected:    http.StatusForbidden,
+			code:        problem.CodeForbidden,
+		},
+		{
+			name:        "admin",
+			enabled:     true,
+			method:      http.MethodPut,
+			path:        "/admin",
+			credentials: admin.Subject,
+			expected:    http.StatusOK,
+			principal:   admin,
+		},
+		{
+			name:        "identity within prefixes",
+			enabled:     true,
+			method:      http.MethodPut,
+			path:        "/items/site-a-1",
+			credentials: scoped.Subject,
+			expected:    http.StatusOK,
+			principal:   scoped,
+		},
+		{
+			name:        "identity outside prefixes",
+			enabled:     true,
+			method:      http.MethodPut,
+			path:        "/items/site-b-1",
+			credentials: scoped.Subject,
+			expected:    http.StatusForbidden,
+			code:        problem.CodeForbidden,
+		},
+		{
+			name:        "empty prefixes",
+			enabled:     true,
+			method:      http.MethodGet,
+			path:        "/items/site-a-1",
+			credentials: unscoped.Subject,
+			expected:    http.StatusForbidden,
+			code:        problem.CodeForbidden,
+		},
+		{
+			name:        "prefixes without identity",
+			enabled:     true,
+			method:      http.MethodPut,
+			path:        "/data",
+			credentials: unscoped.Subject,
+			expected:    http.StatusOK,
+			principal:   unscoped,
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				seen = nil
+				sut := New(Config{Enabled: cases[i].enabled}, policy, []Authenticator{stub})
+				cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, []routable.Contract{sut.Init, handler})
+				defer func() {
+					cancel()
+					wg.Wait()
+				}()
+				header := http.Header{}
+				if cases[i].credentials != "" {
+					header.Set(credentialsHeader, cases[i].credentials)
+				}
+
+				response := testInternal.SendRequestWithHeader(
+					t,
+					muxRouter,
+					cases[i].method,
+					cases[i].path,
+					header,
+					nil,
+				)
+
+				assert.Equal(t, cases[i].expected, response.Code)
+				assert.Equal(t, cases[i].principal, seen)
+				if cases[i].code == "" {
+					return
+				}
+				failure, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, cases[i].code, failure.Code)
+				if cases[i].expected == http.StatusUnauthorized {
+					assert.Equal(t, challenge, response.Header().Get(challengeHeader))
+				}
+			},
+		)
+	}
+}
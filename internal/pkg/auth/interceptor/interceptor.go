@@ -0,0 +1,115 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package interceptor
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Authorizer applies authentication and the scope policy to requests.
+type Authorizer interface {
+	// Authorize returns the principal whose credentials r carries, or the problem that rejects r; it returns
+	// neither if r needs no credentials.
+	Authorize(r *http.Request) (*auth.Principal, *problem.Instance)
+}
+
+// stream is a grpc.ServerStream whose context carries the caller's principal.
+type stream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the stream's context.
+func (s *stream) Context() context.Context {
+	return s.ctx
+}
+
+// request returns the HTTP request that stands for a gRPC call to method made with ctx, so that the policy and
+// authenticators applied to HTTP requests apply to the call: its path is the full method name, its headers are the
+// call's metadata, and it carries the connection's TLS state.
+func request(ctx context.Context, method string) *http.Request {
+	r := (&http.Request{Method: http.MethodPost, URL: &url.URL{Path: method}, Header: make(http.Header)}).WithContext(ctx)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			for _, value := range values {
+				r.Header.Add(key, value)
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			r.TLS = &info.State
+		}
+	}
+	return r
+}
+
+// authorize returns ctx carrying the principal whose credentials a gRPC call to method carries, or the status that
+// rejects the call if a does not authorize it.  Calls only reach the default namespace, so principals bound to a
+// tenant are rejected.
+func authorize(ctx context.Context, a Authorizer, method string) (context.Context, error) {
+	principal, failure := a.Authorize(request(ctx, method))
+	switch {
+	case failure != nil && failure.Code == problem.CodeUnauthenticated:
+		return nil, status.Error(codes.Unauthenticated, failure.Error())
+	case failure != nil:
+		return nil, status.Error(codes.PermissionDenied, failure.Detail)
+	case principal == nil:
+		return ctx, nil
+	case principal.Tenant != "":
+		return nil, status.Error(codes.PermissionDenied, "tenants are only served over HTTP")
+	}
+	return auth.NewContext(ctx, principal), nil
+}
+
+// Unary returns an interceptor that applies a to unary gRPC calls.
+func Unary(a Authorizer) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		ctx, err := authorize(ctx, a, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns an interceptor that applies a to streaming gRPC calls.
+func Stream(a Authorizer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), a, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &stream{ServerStream: ss, ctx: ctx})
+	}
+}
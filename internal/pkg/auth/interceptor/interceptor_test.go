@@ -0,0 +1,154 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const credentialsHeader = "X-Test-Credentials"
+
+// authenticator is a stub that accepts the principals it holds by name and rejects other credentials.
+type authenticator struct {
+	principals map[string]*auth.Principal
+}
+
+// Authenticate returns the principal named by r's credentials header.
+func (a authenticator) Authenticate(r *http.Request) (*auth.Principal, error) {
+	credentials := r.Header.Get(credentialsHeader)
+	if credentials == "" {
+		return nil, nil
+	}
+	if principal, ok := a.principals[credentials]; ok {
+		return principal, nil
+	}
+	return nil, errors.New("unknown credentials")
+}
+
+// Challenge returns no challenge.
+func (authenticator) Challenge() string {
+	return ""
+}
+
+// serverStream is a stub grpc.ServerStream carrying ctx.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the stream's context.
+func (s serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// TestInterceptors tests the gRPC interceptors.
+func TestInterceptors(t *testing.T) {
+	reader := &auth.Principal{Subject: "reader", Method: "test", Scopes: []auth.Scope{auth.ScopeRead}}
+	tenant := &auth.Principal{Subject: "tenant", Method: "test", Scopes: []auth.Scope{auth.ScopeRead}, Tenant: "a"}
+	stub := authenticator{principals: map[string]*auth.Principal{reader.Subject: reader, tenant.Subject: tenant}}
+	policy := auth.Policy{Read: []string{"/service/Find"}}
+
+	type testCase struct {
+		name        string
+		enabled     bool
+		method      string
+		credentials string
+		expected    codes.Code
+		principal   *auth.Principal
+	}
+
+	cases := []testCase{
+		{name: "disabled", method: "/service/Create", expected: codes.OK},
+		{name: "no credentials", enabled: true, method: "/service/Find", expected: codes.Unauthenticated},
+		{
+			name:        "invalid credentials",
+			enabled:     true,
+			method:      "/service/Find",
+			credentials: "unknown",
+			expected:    codes.Unauthenticated,
+		},
+		{
+			name:        "read",
+			enabled:     true,
+			method:      "/service/Find",
+			credentials: reader.Subject,
+			expected:    codes.OK,
+			principal:   reader,
+		},
+		{
+			name:        "write without scope",
+			enabled:     true,
+			method:      "/service/Create",
+			credentials: reader.Subject,
+			expected:    codes.PermissionDenied,
+		},
+		{
+			name:        "bound to a tenant",
+			enabled:     true,
+			method:      "/service/Find",
+			credentials: tenant.Subject,
+			expected:    codes.PermissionDenied,
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				sut := auth.New(auth.Config{Enabled: cases[i].enabled}, policy, []auth.Authenticator{stub})
+				ctx := context.Background()
+				if cases[i].credentials != "" {
+					ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(credentialsHeader, cases[i].credentials))
+				}
+
+				var unary, streamed *auth.Principal
+				_, err := Unary(sut)(
+					ctx,
+					nil,
+					&grpc.UnaryServerInfo{FullMethod: cases[i].method},
+					func(ctx context.Context, _ interface{}) (interface{}, error) {
+						unary, _ = auth.FromContext(ctx)
+						return nil, nil
+					},
+				)
+				assert.Equal(t, cases[i].expected, status.Code(err))
+				assert.Equal(t, cases[i].principal, unary)
+
+				err = Stream(sut)(
+					nil,
+					serverStream{ctx: ctx},
+					&grpc.StreamServerInfo{FullMethod: cases[i].method},
+					func(_ interface{}, ss grpc.ServerStream) error {
+						streamed, _ = auth.FromContext(ss.Context())
+						return nil
+					},
+				)
+				assert.Equal(t, cases[i].expected, status.Code(err))
+				assert.Equal(t, cases[i].principal, streamed)
+			},
+		)
+	}
+}
@@ -0,0 +1,458 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+)
+
+const (
+	Method              = "jwt"
+	authorizationHeader = "Authorization"
+	bearer              = "Bearer "
+)
+
+var (
+	// errMalformed reports a bearer token that is not a well-formed signed JWT.
+	errMalformed = errors.New("malformed token")
+
+	// errUnknownKey reports a token signed by a key the JWKS does not contain.
+	errUnknownKey = errors.New("token signed by an unknown key")
+
+	// errAlgorithm reports a token signed with an unsupported algorithm or one its key does not allow.
+	errAlgorithm = errors.New("token signed with an unsupported algorithm")
+
+	// errSignature reports a token whose signature does not verify.
+	errSignature = errors.New("invalid token signature")
+
+	// errIssuer reports a token from another issuer.
+	errIssuer = errors.New("token issued by an unexpected issuer")
+
+	// errAudience reports a token meant for another audience.
+	errAudience = errors.New("token not meant for this audience")
+
+	// errExpired reports a token that is expired or has no expiry.
+	errExpired = errors.New("token expired")
+
+	// errNotYetValid reports a token used before its nbf claim.
+	errNotYetValid = errors.New("token not yet valid")
+
+	// errSubject reports a token without a subject.
+	errSubject = errors.New("token has no subject")
+
+	// errTenant reports a token that names more than one tenant.
+	errTenant = errors.New("token names more than one tenant")
+)
+
+// algorithm describes how a JWS algorithm verifies signatures.
+type algorithm struct {
+	hash   crypto.Hash
+	verify func(public crypto.PublicKey, hash crypto.Hash, digest, signature []byte) bool
+}
+
+// verifyPKCS1 verifies an RSASSA-PKCS1-v1_5 signature.
+func verifyPKCS1(public crypto.PublicKey, hash crypto.Hash, digest, signature []byte) bool {
+	key, ok := public.(*rsa.PublicKey)
+	return ok && rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil
+}
+
+// verifyPSS verifies an RSASSA-PSS signature.
+func verifyPSS(public crypto.PublicKey, hash crypto.Hash, digest, signature []byte) bool {
+	key, ok := public.(*rsa.PublicKey)
+	options := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}
+	return ok && rsa.VerifyPSS(key, hash, digest, signature, options) == nil
+}
+
+// curveHashes maps each curve to the hash of the only ECDSA algorithm that may use it (RFC 7518 section 3.4).
+var curveHashes = map[string]crypto.Hash{
+	"P-256": crypto.SHA256,
+	"P-384": crypto.SHA384,
+	"P-521": crypto.SHA512,
+}
+
+// verifyECDSA verifies an ECDSA signature encoded as the concatenation of r and s.
+func verifyECDSA(public crypto.PublicKey, hash crypto.Hash, digest, signature []byte) bool {
+	key, ok := public.(*ecdsa.PublicKey)
+	if !ok || curveHashes[key.Curve.Params().Name] != hash {
+		return false
+	}
+	size := (key.Curve.Params().BitSize + 7) / 8
+	if len(signature) != 2*size {
+		return false
+	}
+	r := new(big.Int).SetBytes(signature[:size])
+	s := new(big.Int).SetBytes(signature[size:])
+	return ecdsa.Verify(key, digest, r, s)
+}
+
+// algorithms maps the supported JWS algorithms; symmetric algorithms and "none" are deliberately absent.
+var algorithms = map[string]algorithm{
+	"RS256": {hash: crypto.SHA256, verify: verifyPKCS1},
+	"RS384": {hash: crypto.SHA384, verify: verifyPKCS1},
+	"RS512": {hash: crypto.SHA512, verify: verifyPKCS1},
+	"PS256": {hash: crypto.SHA256, verify: verifyPSS},
+	"PS384": {hash: crypto.SHA384, verify: verifyPSS},
+	"PS512": {hash: crypto.SHA512, verify: verifyPSS},
+	"ES256": {hash: crypto.SHA256, verify: verifyECDSA},
+	"ES384": {hash: crypto.SHA384, verify: verifyECDSA},
+	"ES512": {hash: crypto.SHA512, verify: verifyECDSA},
+}
+
+// Config enables bearer token authentication when JWKSPath names a JWKS file, which is read again every
+// RefreshInterval milliseconds (zero disables refreshing).  Tokens must carry Issuer as iss, Audience in aud and an
+// exp in the future; Leeway milliseconds of clock skew are tolerated.  The values of RolesClaim (a string of space
+// separated roles or an array of roles, found by a dotted path such as realm_access.roles) grant the scopes Roles
+// maps them to, and the values of PrefixesClaim restrict the identities the principal may access; tokens without
+// that claim are not restricted.  A token whose TenantClaim names a tenant only grants access to that tenant's
+// identities.
+type Config struct {
+	JWKSPath        string                  `json:"jwksPath"`
+	RefreshInterval int                     `json:"refreshInterval"`
+	Issuer          string                  `json:"issuer"`
+	Audience        string                  `json:"audience"`
+	Leeway          int                     `json:"leeway"`
+	RolesClaim      string                  `json:"rolesClaim"`
+	Roles           map[string][]auth.Scope `json:"roles"`
+	PrefixesClaim   string                  `json:"prefixesClaim"`
+	TenantClaim     string                  `json:"tenantClaim"`
+}
+
+// NewDefaultConfig returns the default configuration: bearer tokens are not accepted.
+func NewDefaultConfig() Config {
+	return Config{
+		RefreshInterval: 300000,
+		Leeway:          30000,
+		RolesClaim:      "roles",
+		Roles:           map[string][]auth.Scope{},
+		PrefixesClaim:   "prefixes",
+		TenantClaim:     "tenant",
+	}
+}
+
+// Enabled returns whether the configuration enables bearer token authentication.
+func (c Config) Enabled() bool {
+	return c.JWKSPath != ""
+}
+
+// Validate returns an error if the configuration is not usable.
+func (c Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.Issuer == "" || c.Audience == "" {
+		return errors.New("issuer and audience must both be set")
+	}
+	if c.RefreshInterval < 0 || c.Leeway < 0 {
+		return errors.New("refreshInterval and leeway must not be negative")
+	}
+	for role, scopes := range c.Roles {
+		for _, scope := range scopes {
+			if !scope.Valid() {
+				return fmt.Errorf("unknown scope %q for role %s", scope, role)
+			}
+		}
+	}
+	return nil
+}
+
+// Recorder is called with each failed refresh; the previous keys stay in use.
+type Recorder func(err error)
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	config   Config
+	recorder Recorder
+	now      func() time.Time
+	m        sync.RWMutex
+	keys     map[string]key
+}
+
+// New is a factory function that returns instance with the keys of config's JWKS file loaded.
+func New(config Config) (*instance, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	i := &instance{
+		config:   config,
+		recorder: func(error) {},
+		now:      time.Now,
+	}
+	if err := i.Refresh(); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// SetRecorder provides for method injection of the function told about failed refreshes; the default discards them.
+func (i *instance) SetRecorder(recorder Recorder) {
+	i.recorder = recorder
+}
+
+// Refresh reads the JWKS file again; if it fails to load, the previous keys stay in use.
+func (i *instance) Refresh() error {
+	keys, err := loadKeys(i.config.JWKSPath)
+	if err != nil {
+		return err
+	}
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.keys = keys
+	return nil
+}
+
+// Run refreshes the keys every refresh interval until ctx is done.
+func (i *instance) Run(ctx context.Context, wg *sync.WaitGroup) {
+	if i.config.RefreshInterval == 0 {
+		return
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(time.Millisecond * time.Duration(i.config.RefreshInterval))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := i.Refresh(); err != nil {
+					i.recorder(err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Authenticate returns the principal of the bearer token in r's Authorization header.
+func (i *instance) Authenticate(r *http.Request) (*auth.Principal, error) {
+	header := r.Header.Get(authorizationHeader)
+	if len(header) < len(bearer) || !strings.EqualFold(header[:len(bearer)], bearer) {
+		return nil, nil
+	}
+
+	claims, err := i.verify(strings.TrimSpace(header[len(bearer):]))
+	if err != nil {
+		return nil, err
+	}
+	if err := i.validate(claims); err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, errSubject
+	}
+	tenants := values(claims, i.config.TenantClaim)
+	if len(tenants) > 1 {
+		return nil, errTenant
+	}
+	roles := values(claims, i.config.RolesClaim)
+	principal := &auth.Principal{
+		Subject:  subject,
+		Method:   Method,
+		Scopes:   i.scopes(roles),
+		Roles:    roles,
+		Prefixes: values(claims, i.config.PrefixesClaim),
+	}
+	if len(tenants) == 1 {
+		principal.Tenant = tenants[0]
+	}
+	return principal, nil
+}
+
+// Challenge returns the WWW-Authenticate challenge that asks for a bearer token.
+func (*instance) Challenge() string {
+	return "Bearer"
+}
+
+// verify checks token's signature and returns its claims.
+func (i *instance) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errMalformed
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decode(parts[0], &header); err != nil {
+		return nil, errMalformed
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errMalformed
+	}
+
+	alg, ok := algorithms[header.Alg]
+	if !ok {
+		return nil, errAlgorithm
+	}
+	k, ok := i.key(header.Kid)
+	if !ok {
+		return nil, errUnknownKey
+	}
+	if k.alg != "" && k.alg != header.Alg {
+		return nil, errAlgorithm
+	}
+
+	digest := alg.hash.New()
+	_, _ = digest.Write([]byte(parts[0] + "." + parts[1]))
+	if !alg.verify(k.public, alg.hash, digest.Sum(nil), signature) {
+		return nil, errSignature
+	}
+
+	var claims map[string]interface{}
+	if err := decode(parts[1], &claims); err != nil {
+		return nil, errMalformed
+	}
+	return claims, nil
+}
+
+// key returns the key with the given ID; a token without a key ID may use the only key of a single-key set.
+func (i *instance) key(kid string) (key, bool) {
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	if k, ok := i.keys[kid]; ok {
+		return k, true
+	}
+	if kid == "" && len(i.keys) == 1 {
+		for _, k := range i.keys {
+			return k, true
+		}
+	}
+	return key{}, false
+}
+
+// validate checks claims' issuer, audience and validity period.
+func (i *instance) validate(claims map[string]interface{}) error {
+	if issuer, _ := claims["iss"].(string); issuer != i.config.Issuer {
+		return errIssuer
+	}
+
+	audience := false
+	for _, value := range values(claims, "aud") {
+		audience = audience || value == i.config.Audience
+	}
+	if !audience {
+		return errAudience
+	}
+
+	now := i.now()
+	leeway := time.Millisecond * time.Duration(i.config.Leeway)
+	expiry, ok := timestamp(claims["exp"])
+	if !ok || !now.Before(expiry.Add(leeway)) {
+		return errExpired
+	}
+	if notBefore, ok := timestamp(claims["nbf"]); ok && now.Add(leeway).Before(notBefore) {
+		return errNotYetValid
+	}
+	return nil
+}
+
+// scopes returns the scopes roles grant.
+func (i *instance) scopes(roles []string) []auth.Scope {
+	var result []auth.Scope
+	granted := make(map[auth.Scope]bool)
+	for _, role := range roles {
+		for _, scope := range i.config.Roles[role] {
+			if !granted[scope] {
+				granted[scope] = true
+				result = append(result, scope)
+			}
+		}
+	}
+	return result
+}
+
+// decode decodes a base64url encoded JSON segment into v, keeping numbers exact.
+func decode(segment string, v interface{}) error {
+	body, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// values returns the strings of the claim at the dotted path: the space separated values of a string or the string
+// elements of an array.  A claim that is present but holds no strings, including null, yields an empty, not nil,
+// result, so that a token whose prefixes claim is empty, null or malformed grants access to no identities.
+func values(claims map[string]interface{}, path string) []string {
+	if path == "" {
+		return nil
+	}
+
+	var value interface{} = claims
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value, ok = object[name]; !ok {
+			return nil
+		}
+	}
+
+	switch typed := value.(type) {
+	case string:
+		return append([]string{}, strings.Fields(typed)...)
+	case []interface{}:
+		result := []string{}
+		for _, element := range typed {
+			if s, ok := element.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return []string{}
+}
+
+// timestamp returns the time of a NumericDate claim.
+func timestamp(value interface{}) (time.Time, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
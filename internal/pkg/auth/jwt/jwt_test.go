@@ -0,0 +1,450 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	issuer   = "https://issuer.example.org"
+	audience = "go-store"
+	subject  = "sensor"
+)
+
+// keys holds the signing keys shared by the tests.
+type keys struct {
+	rsa   *rsa.PrivateKey
+	ec    *ecdsa.PrivateKey
+	other *ecdsa.PrivateKey
+}
+
+// newKeys returns new signing keys.
+func newKeys(t *testing.T) keys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		assert.FailNow(t, "Unexpected rsa.GenerateKey failure:", err.Error())
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		assert.FailNow(t, "Unexpected ecdsa.GenerateKey failure:", err.Error())
+	}
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		assert.FailNow(t, "Unexpected ecdsa.GenerateKey failure:", err.Error())
+	}
+	return keys{rsa: rsaKey, ec: ecKey, other: other}
+}
+
+// newConfig returns a configuration that reads the JWKS at path.
+func newConfig(path string) Config {
+	config := NewDefaultConfig()
+	config.JWKSPath = path
+	config.Issuer = issuer
+	config.Audience = audience
+	config.Leeway = 0
+	config.Roles = map[string][]auth.Scope{"reader": {auth.ScopeRead}, "writer": {auth.ScopeRead, auth.ScopeWrite}}
+	return config
+}
+
+// newSUT returns a new system under test.
+func newSUT(t *testing.T, config Config) *instance {
+	sut, err := New(config)
+	if err != nil {
+		assert.FailNow(t, "Unexpected New failure:", err.Error())
+	}
+	return sut
+}
+
+// claims returns valid claims, modified by change.
+func claims(change func(claims map[string]interface{})) map[string]interface{} {
+	result := map[string]interface{}{
+		"iss":   issuer,
+		"aud":   audience,
+		"sub":   subject,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"writer"},
+	}
+	if change != nil {
+		change(result)
+	}
+	return result
+}
+
+// request returns a request carrying authorization.
+func request(authorization string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if authorization != "" {
+		r.Header.Set(authorizationHeader, authorization)
+	}
+	return r
+}
+
+// TestConfig_Validate tests configuration validation.
+func TestConfig_Validate(t *testing.T) {
+	valid := newConfig("jwks.json")
+	missingAudience := valid
+	missingAudience.Audience = ""
+	negative := valid
+	negative.Leeway = -1
+	unknownScope := valid
+	unknownScope.Roles = map[string][]auth.Scope{"role": {"unknown"}}
+
+	assert.Nil(t, NewDefaultConfig().Validate())
+	assert.Nil(t, valid.Validate())
+	assert.NotNil(t, missingAudience.Validate())
+	assert.NotNil(t, negative.Validate())
+	assert.NotNil(t, unknownScope.Validate())
+}
+
+// TestNew tests loading a JWKS.
+func TestNew(t *testing.T) {
+	directory, err := ioutil.TempDir("", "jwt")
+	if err != nil {
+		assert.FailNow(t, "Unexpected ioutil.TempDir failure:", err.Error())
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+
+	write := func(name, body string) string {
+		path := filepath.Join(directory, name)
+		if err := ioutil.WriteFile(path, []byte(body), 0600); err != nil {
+			assert.FailNow(t, "Unexpected ioutil.WriteFile failure:", err.Error())
+		}
+		return path
+	}
+
+	cases := map[string]string{
+		"missing":           filepath.Join(directory, "missing.json"),
+		"malformed":         write("malformed.json", "{"),
+		"empty":             write("empty.json", `{"keys": []}`),
+		"encryption only":   write("enc.json", `{"keys": [{"kty": "RSA", "use": "enc", "n": "AQAB", "e": "AQAB"}]}`),
+		"unsupported type":  write("oct.json", `{"keys": [{"kty": "oct", "k": "AQAB"}]}`),
+		"unsupported curve": write("curve.json", `{"keys": [{"kty": "EC", "crv": "P-192", "x": "AQ", "y": "AQ"}]}`),
+		"off curve":         write("point.json", `{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`),
+		"duplicate kid": write(
+			"duplicate.json",
+			`{"keys": [{"kty": "RSA", "kid": "a", "n": "AQAB", "e": "AQAB"}, `+
+				`{"kty": "RSA", "kid": "a", "n": "AQAB", "e": "AQAB"}]}`,
+		),
+	}
+
+	for name, path := range cases {
+		t.Run(
+			name,
+			func(t *testing.T) {
+				_, err := New(newConfig(path))
+
+				assert.NotNil(t, err)
+			},
+		)
+	}
+}
+
+// TestInstance_Authenticate tests bearer token authentication.
+func TestInstance_Authenticate(t *testing.T) {
+	directory, err := ioutil.TempDir("", "jwt")
+	if err != nil {
+		assert.FailNow(t, "Unexpected ioutil.TempDir failure:", err.Error())
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+	k := newKeys(t)
+	path := filepath.Join(directory, "jwks.json")
+	testInternal.WriteJWKS(t, path, map[string]crypto.Signer{"rsa": k.rsa, "ec": k.ec})
+
+	sign := func(key crypto.Signer, alg, kid string, change func(claims map[string]interface{})) string {
+		return "Bearer " + testInternal.SignToken(t, key, alg, kid, claims(change))
+	}
+	writer := &auth.Principal{
+		Subject: subject,
+		Method:  Method,
+		Scopes:  []auth.Scope{auth.ScopeRead, auth.ScopeWrite},
+		Roles:   []string{"writer"},
+	}
+
+	type testCase struct {
+		name          string
+		authorization string
+		config        func(config *Config)
+		expected      *auth.Principal
+		err           error
+	}
+
+	cases := []testCase{
+		{name: "no credentials"},
+		{name: "other scheme", authorization: "Basic " + base64.StdEncoding.EncodeToString([]byte("a:b"))},
+		{name: "RS256", authorization: sign(k.rsa, "RS256", "rsa", nil), expected: writer},
+		{name: "PS256", authorization: sign(k.rsa, "PS256", "rsa", nil), expected: writer},
+		{name: "ES256", authorization: sign(k.ec, "ES256", "ec", nil), expected: writer},
+		{
+			name:          "lower case scheme",
+			authorization: "bearer " + strings.TrimPrefix(sign(k.ec, "ES256", "ec", nil), bearer),
+			expected:      writer,
+		},
+		{name: "malformed", authorization: "Bearer abc.def", err: errMalformed},
+		{name: "malformed header", authorization: "Bearer !.e30.c2ln", err: errMalformed},
+		{name: "unknown kid", authorization: sign(k.ec, "ES256", "unknown", nil), err: errUnknownKey},
+		{name: "no kid with several keys", authorization: sign(k.ec, "ES256", "", nil), err: errUnknownKey},
+		{name: "wrong key", authorization: sign(k.other, "ES256", "ec", nil), err: errSignature},
+		{name: "key type mismatch", authorization: sign(k.ec, "ES256", "rsa", nil), err: errSignature},
+		{name: "curve mismatch", authorization: sign(k.ec, "ES384", "ec", nil), err: errSignature},
+		{
+			name: "none",
+			authorization: "Bearer " + base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+				strings.Split(sign(k.ec, "ES256", "ec", nil), ".")[1] + ".",
+			err: errAlgorithm,
+		},
+		{
+			name: "HS256",
+			authorization: "Bearer " + base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","kid":"rsa"}`)) +
+				"." + strings.Split(sign(k.ec, "ES256", "ec", nil), ".")[1] + ".c2ln",
+			err: errAlgorithm,
+		},
+		{
+			name:          "wrong issuer",
+			authorization: sign(k.ec, "ES256", "ec", func(c map[string]interface{}) { c["iss"] = "other" }),
+			err:           errIssuer,
+		},
+		{
+			name:          "wrong audience",
+			authorization: sign(k.ec, "ES256", "ec", func(c map[string]interface{}) { c["aud"] = "other" }),
+			err:           errAudience,
+		},
+		{
+			name: "audience array",
+			authorization: sign(
+				k.ec,
+				"ES256",
+				"ec",
+				func(c map[string]interface{}) { c["aud"] = []string{"other", audience} },
+			),
+			expected: writer,
+		},
+		{
+			name: "expired",
+			authorization: sign(
+				k.ec,
+				"ES256",
+				"ec",
+				func(c map[string]interface{}) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+			),
+			err: errExpired,
+		},
+		{
+			name: "expired within leeway",
+			authorization: sign(
+				k.ec,
+				"ES256",
+				"ec",
+				func(c map[string]interface{}) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+			),
+			config:   func(config *Config) { config.Leeway = 120000 },
+			expected: writer,
+		},
+		{
+			name:          "no expiry",
+			authorization: sign(k.ec, "ES256", "ec", func(c map[string]interface{}) { delete(c, "exp") }),
+			err:           errExpired,
+		},
+		{
+			name: "not yet valid",
+			authorization: sign(
+				k.ec,
+				"ES256",
+				"ec",
+				func(c map[string]interface{}) { c["nbf"] = time.Now().Add(time.Minute).Unix() },
+			),
+			err: errNotYetValid,
+		},
+		{
+			name:          "no subject",
+			authorization: sign(k.ec, "ES256", "ec", func(c map[string]interface{}) { delete(c, "sub") }),
+			err:           errSubject,
+		},
+		{
+			name: "nested roles and prefixes",
+			authorization: sign(
+				k.ec,
+				"ES256",
+				"ec",
+				func(c map[string]interface{}) {
+					delete(c, "roles")
+					c["realm_access"] = map[string]interface{}{"roles": []string{"reader", "unmapped"}}
+					c["prefixes"] = "site-a/ site-b/"
+				},
+			),
+			config: func(config *Config) { config.RolesClaim = "realm_access.roles" },
+			expected: &auth.Principal{
+				Subject:  subject,
+				Method:   Method,
+				Scopes:   []auth.Scope{auth.ScopeRead},
+				Roles:    []string{"reader", "unmapped"},
+				Prefixes: []string{"site-a/", "site-b/"},
+			},
+		},
+		{
+			name:          "empty prefixes",
+			authorization: sign(k.ec, "ES256", "ec", func(c map[string]interface{}) { c["prefixes"] = "" }),
+			expected: &auth.Principal{
+				Subject:  subject,
+				Method:   Method,
+				Scopes:   writer.Scopes,
+				Roles:    writer.Roles,
+				Prefixes: []string{},
+			},
+		},
+		{
+			name:          "null prefixes",
+			authorization: sign(k.ec, "ES256", "ec", func(c map[string]interface{}) { c["prefixes"] = nil }),
+			expected: &auth.Principal{
+				Subject:  subject,
+				Method:   Method,
+				Scopes:   writer.Scopes,
+				Roles:    writer.Roles,
+				Prefixes: []string{},
+			},
+		},
+		{
+			name:          "prefixes without strings",
+			authorization: sign(k.ec, "ES256", "ec", func(c map[string]interface{}) { c["prefixes"] = []int{1} }),
+			expected: &auth.Principal{
+				Subject:  subject,
+				Method:   Method,
+				Scopes:   writer.Scopes,
+				Roles:    writer.Roles,
+				Prefixes: []string{},
+			},
+		},
+		{
+			name:          "no roles",
+			authorization: sign(k.ec, "ES256", "ec", func(c map[string]interface{}) { delete(c, "roles") }),
+			expected:      &auth.Principal{Subject: subject, Method: Method},
+		},
+		{
+			name:          "tenant",
+			authorization: sign(k.ec, "ES256", "ec", func(c map[string]interface{}) { c["tenant"] = "acme" }),
+			expected: &auth.Principal{
+				Subject: subject,
+				Method:  Method,
+				Scopes:  writer.Scopes,
+				Roles:   writer.Roles,
+				Tenant:  "acme",
+			},
+		},
+		{
+			name:          "several tenants",
+			authorization: sign(k.ec, "ES256", "ec", func(c map[string]interface{}) { c["tenant"] = "acme other" }),
+			err:           errTenant,
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				config := newConfig(path)
+				if cases[i].config != nil {
+					cases[i].config(&config)
+				}
+
+				principal, err := newSUT(t, config).Authenticate(request(cases[i].authorization))
+
+				assert.Equal(t, cases[i].expected, principal)
+				assert.Equal(t, cases[i].err, err)
+			},
+		)
+	}
+}
+
+// TestInstance_Refresh tests key rotation.
+func TestInstance_Refresh(t *testing.T) {
+	directory, err := ioutil.TempDir("", "jwt")
+	if err != nil {
+		assert.FailNow(t, "Unexpected ioutil.TempDir failure:", err.Error())
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+	k := newKeys(t)
+	path := filepath.Join(directory, "jwks.json")
+	testInternal.WriteJWKS(t, path, map[string]crypto.Signer{"old": k.ec})
+	config := newConfig(path)
+	config.RefreshInterval = 10
+	sut := newSUT(t, config)
+	failures := make(chan error, 1)
+	sut.SetRecorder(
+		func(err error) {
+			select {
+			case failures <- err:
+			default:
+			}
+		},
+	)
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	sut.Run(ctx, &wg)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	old := "Bearer " + testInternal.SignToken(t, k.ec, "ES256", "old", claims(nil))
+	rotated := "Bearer " + testInternal.SignToken(t, k.other, "ES256", "new", claims(nil))
+
+	_, before := sut.Authenticate(request(rotated))
+	testInternal.WriteJWKS(t, path, map[string]crypto.Signer{"new": k.other})
+
+	assert.Equal(t, errUnknownKey, before)
+	assert.Eventually(
+		t,
+		func() bool {
+			principal, err := sut.Authenticate(request(rotated))
+			return err == nil && principal != nil
+		},
+		time.Second*5,
+		time.Millisecond*20,
+	)
+	_, retired := sut.Authenticate(request(old))
+	assert.Equal(t, errUnknownKey, retired)
+
+	if err := ioutil.WriteFile(path, []byte("{"), 0600); err != nil {
+		assert.FailNow(t, "Unexpected ioutil.WriteFile failure:", err.Error())
+	}
+	select {
+	case err := <-failures:
+		assert.NotNil(t, err)
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "failed refresh not recorded")
+	}
+	principal, err := sut.Authenticate(request(rotated))
+	assert.Nil(t, err)
+	assert.NotNil(t, principal)
+}
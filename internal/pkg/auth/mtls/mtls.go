@@ -0,0 +1,124 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package mtls
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+)
+
+const (
+	Method        = "mtls"
+	SourceSAN     = "san"
+	SourceSubject = "subject"
+	anySubject    = "*"
+)
+
+var (
+	// errUnverified reports a client certificate that was presented but not verified against the client CAs.
+	errUnverified = errors.New("client certificate is not verified")
+
+	// errNoIdentity reports a verified client certificate that carries no identity of the configured source.
+	errNoIdentity = errors.New("client certificate carries no identity")
+)
+
+// Config maps verified client certificates to principals.  Source selects the certificate field the principal's
+// subject comes from: san (the first URI, then DNS, then email subject alternative name) or subject (the common
+// name).  Scopes grants scopes by subject; the "*" entry applies to subjects not otherwise listed.
+type Config struct {
+	Source string                  `json:"source"`
+	Scopes map[string][]auth.Scope `json:"scopes"`
+}
+
+// NewDefaultConfig returns the default configuration: subjects from subject alternative names, with no scopes.
+func NewDefaultConfig() Config {
+	return Config{
+		Source: SourceSAN,
+		Scopes: map[string][]auth.Scope{},
+	}
+}
+
+// Validate returns an error if the configuration is not usable.
+func (c Config) Validate() error {
+	if c.Source != SourceSAN && c.Source != SourceSubject {
+		return fmt.Errorf("unknown source %q", c.Source)
+	}
+	for subject, scopes := range c.Scopes {
+		for _, scope := range scopes {
+			if !scope.Valid() {
+				return fmt.Errorf("unknown scope %q for %s", scope, subject)
+			}
+		}
+	}
+	return nil
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	config Config
+}
+
+// New is a factory function that returns instance.
+func New(config Config) *instance {
+	return &instance{
+		config: config,
+	}
+}
+
+// Authenticate returns the principal of the verified client certificate r's connection presented.
+func (i *instance) Authenticate(r *http.Request) (*auth.Principal, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, nil
+	}
+	if len(r.TLS.VerifiedChains) == 0 {
+		return nil, errUnverified
+	}
+
+	subject := i.subject(r.TLS.PeerCertificates[0])
+	if subject == "" {
+		return nil, errNoIdentity
+	}
+	scopes, ok := i.config.Scopes[subject]
+	if !ok {
+		scopes = i.config.Scopes[anySubject]
+	}
+	return &auth.Principal{Subject: subject, Method: Method, Scopes: scopes}, nil
+}
+
+// subject returns the identity certificate carries in the configured source.
+func (i *instance) subject(certificate *x509.Certificate) string {
+	if i.config.Source == SourceSubject {
+		return certificate.Subject.CommonName
+	}
+
+	switch {
+	case len(certificate.URIs) > 0:
+		return certificate.URIs[0].String()
+	case len(certificate.DNSNames) > 0:
+		return certificate.DNSNames[0]
+	case len(certificate.EmailAddresses) > 0:
+		return certificate.EmailAddresses[0]
+	}
+	return ""
+}
+
+// Challenge returns no challenge; client certificates are requested during the TLS handshake.
+func (*instance) Challenge() string {
+	return ""
+}
@@ -0,0 +1,136 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// request returns a request whose connection presented certificate, verified if verified is set.
+func request(certificate *x509.Certificate, verified bool) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{certificate}}
+	if verified {
+		r.TLS.VerifiedChains = [][]*x509.Certificate{{certificate}}
+	}
+	return r
+}
+
+// TestConfig_Validate tests configuration validation.
+func TestConfig_Validate(t *testing.T) {
+	assert.Nil(t, NewDefaultConfig().Validate())
+	assert.Nil(t, Config{Source: SourceSubject, Scopes: map[string][]auth.Scope{"a": {auth.ScopeRead}}}.Validate())
+	assert.NotNil(t, Config{Source: "unknown"}.Validate())
+	assert.NotNil(t, Config{Source: SourceSAN, Scopes: map[string][]auth.Scope{"a": {"unknown"}}}.Validate())
+}
+
+// TestInstance_Authenticate tests mapping client certificates to principals.
+func TestInstance_Authenticate(t *testing.T) {
+	uri, _ := url.Parse("spiffe://example.org/sensor")
+	full := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "sensor"},
+		URIs:           []*url.URL{uri},
+		DNSNames:       []string{"sensor.example.org"},
+		EmailAddresses: []string{"sensor@example.org"},
+	}
+	scopes := map[string][]auth.Scope{
+		uri.String(): {auth.ScopeWrite},
+		"sensor":     {auth.ScopeAdmin},
+		anySubject:   {auth.ScopeRead},
+	}
+
+	type testCase struct {
+		name     string
+		config   Config
+		request  *http.Request
+		expected *auth.Principal
+		err      error
+	}
+
+	cases := []testCase{
+		{
+			name:    "no tls",
+			config:  NewDefaultConfig(),
+			request: httptest.NewRequest(http.MethodGet, "/", nil),
+		},
+		{
+			name:    "no certificate",
+			config:  NewDefaultConfig(),
+			request: func() *http.Request { r := request(full, false); r.TLS.PeerCertificates = nil; return r }(),
+		},
+		{
+			name:    "unverified",
+			config:  NewDefaultConfig(),
+			request: request(full, false),
+			err:     errUnverified,
+		},
+		{
+			name:     "uri san",
+			config:   Config{Source: SourceSAN, Scopes: scopes},
+			request:  request(full, true),
+			expected: &auth.Principal{Subject: uri.String(), Method: Method, Scopes: []auth.Scope{auth.ScopeWrite}},
+		},
+		{
+			name:    "dns san",
+			config:  Config{Source: SourceSAN, Scopes: scopes},
+			request: request(&x509.Certificate{DNSNames: full.DNSNames, EmailAddresses: full.EmailAddresses}, true),
+			expected: &auth.Principal{
+				Subject: full.DNSNames[0],
+				Method:  Method,
+				Scopes:  []auth.Scope{auth.ScopeRead},
+			},
+		},
+		{
+			name:     "email san",
+			config:   NewDefaultConfig(),
+			request:  request(&x509.Certificate{EmailAddresses: full.EmailAddresses}, true),
+			expected: &auth.Principal{Subject: full.EmailAddresses[0], Method: Method},
+		},
+		{
+			name:    "no san",
+			config:  NewDefaultConfig(),
+			request: request(&x509.Certificate{Subject: full.Subject}, true),
+			err:     errNoIdentity,
+		},
+		{
+			name:     "subject",
+			config:   Config{Source: SourceSubject, Scopes: scopes},
+			request:  request(full, true),
+			expected: &auth.Principal{Subject: "sensor", Method: Method, Scopes: []auth.Scope{auth.ScopeAdmin}},
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				principal, err := New(cases[i].config).Authenticate(cases[i].request)
+
+				assert.Equal(t, cases[i].expected, principal)
+				assert.Equal(t, cases[i].err, err)
+			},
+		)
+	}
+}
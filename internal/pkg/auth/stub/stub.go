@@ -0,0 +1,40 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package stub
+
+import (
+	"net/http"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+
+	"github.com/gorilla/mux"
+)
+
+// Authenticated returns a routable that installs a middleware passing principal in the context of every request, as
+// the authentication middleware does for the principal it authenticates.
+func Authenticated(principal *auth.Principal) routable.Contract {
+	return func(muxRouter *mux.Router) {
+		muxRouter.Use(
+			func(next http.Handler) http.Handler {
+				return http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+					},
+				)
+			},
+		)
+	}
+}
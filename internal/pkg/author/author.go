@@ -0,0 +1,114 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package author
+
+import (
+	"context"
+	"sync"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+)
+
+// Author is the principal that stored an annotation: its Subject, the Method that authenticated it and the Tenant it
+// is bound to, if any.
+type Author struct {
+	Subject string `json:"subject"`
+	Method  string `json:"method"`
+	Tenant  string `json:"tenant,omitempty"`
+}
+
+// Contract defines the record of who stored each annotation.
+type Contract interface {
+	// Record notes the principal authenticated in ctx, if any, as the author of m.
+	Record(ctx context.Context, m *annotation.Instance)
+
+	// Find returns the author of m and whether one was recorded.
+	Find(m *annotation.Instance) (Author, bool)
+}
+
+// key identifies an annotation by its own identity and unique; uniques alone are not unique, as providers seeded
+// alike issue the same ones.
+type key struct {
+	identity string
+	unique   string
+}
+
+// keyOf returns the key of m.
+func keyOf(m *annotation.Instance) key {
+	k := key{unique: m.Unique}
+	if m.CurrentIdentity != nil {
+		k.identity = m.CurrentIdentity.Printable()
+	}
+	return k
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	m       sync.RWMutex
+	authors map[key]Author
+}
+
+// New is a factory function that returns instance; it keeps the authors of a single store's annotations in memory,
+// as the store keeps the annotations.
+func New() *instance {
+	return &instance{
+		authors: make(map[key]Author),
+	}
+}
+
+// Record notes the principal authenticated in ctx, if any, as the author of m.  An annotation keeps the author first
+// recorded for it, so storing it again does not change it.
+func (i *instance) Record(ctx context.Context, m *annotation.Instance) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return
+	}
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	k := keyOf(m)
+	if _, exists := i.authors[k]; !exists {
+		i.authors[k] = Author{Subject: principal.Subject, Method: principal.Method, Tenant: principal.Tenant}
+	}
+}
+
+// Find returns the author of m and whether one was recorded.
+func (i *instance) Find(m *annotation.Instance) (Author, bool) {
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	a, ok := i.authors[keyOf(m)]
+	return a, ok
+}
+
+// discard is a receiver that records no authors.
+type discard struct{}
+
+// NewDiscard is a factory function that returns a record that keeps no authors; it is the default record of
+// components that only note authors when given one.
+func NewDiscard() discard {
+	return discard{}
+}
+
+// Record does nothing.
+func (discard) Record(context.Context, *annotation.Instance) {}
+
+// Find reports that no author was recorded.
+func (discard) Find(*annotation.Instance) (Author, bool) {
+	return Author{}, false
+}
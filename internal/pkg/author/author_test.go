@@ -0,0 +1,106 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package author
+
+import (
+	"context"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInstance tests recording and finding authors.
+func TestInstance(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	id := hash.New(test.FactoryRandomFixedLengthAlphanumericByteSlice(8))
+	m := annotation.New(test.FactoryRandomString(), id, nil, metadataStub.NewNullObject())
+	principal := &auth.Principal{Subject: test.FactoryRandomString(), Method: "jwt", Tenant: test.FactoryRandomString()}
+	ctx := auth.NewContext(context.Background(), principal)
+	cases := []testCase{
+		{
+			name: "records principal",
+			test: func(t *testing.T) {
+				sut := New()
+
+				sut.Record(ctx, m)
+				a, ok := sut.Find(m)
+
+				assert.True(t, ok)
+				assert.Equal(t, Author{Subject: principal.Subject, Method: "jwt", Tenant: principal.Tenant}, a)
+			},
+		},
+		{
+			name: "keeps first author",
+			test: func(t *testing.T) {
+				sut := New()
+
+				sut.Record(ctx, m)
+				sut.Record(auth.NewContext(context.Background(), &auth.Principal{Subject: "other"}), m)
+				a, _ := sut.Find(m)
+
+				assert.Equal(t, principal.Subject, a.Subject)
+			},
+		},
+		{
+			name: "same unique on another identity",
+			test: func(t *testing.T) {
+				sut := New()
+				other := annotation.New(m.Unique, hash.New(test.FactoryRandomByteSlice()), nil, m.Metadata)
+
+				sut.Record(ctx, m)
+				_, ok := sut.Find(other)
+
+				assert.False(t, ok)
+			},
+		},
+		{
+			name: "anonymous writes have no author",
+			test: func(t *testing.T) {
+				sut := New()
+
+				sut.Record(context.Background(), m)
+				_, ok := sut.Find(m)
+
+				assert.False(t, ok)
+			},
+		},
+		{
+			name: "discard records nothing",
+			test: func(t *testing.T) {
+				sut := NewDiscard()
+
+				sut.Record(ctx, m)
+				_, ok := sut.Find(m)
+
+				assert.False(t, ok)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
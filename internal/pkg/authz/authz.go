@@ -0,0 +1,256 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package authz
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/audit"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/logging"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	eventType = "authorization"
+)
+
+// Config enables authorization when PolicyPath names a policy file, which is checked for changes every
+// ReloadInterval milliseconds (zero disables reloading).
+type Config struct {
+	PolicyPath     string `json:"policyPath"`
+	ReloadInterval int    `json:"reloadInterval"`
+}
+
+// NewDefaultConfig returns the default configuration: authorization is disabled.
+func NewDefaultConfig() Config {
+	return Config{
+		ReloadInterval: 10000,
+	}
+}
+
+// Enabled returns whether the configuration enables authorization.
+func (c Config) Enabled() bool {
+	return c.PolicyPath != ""
+}
+
+// Validate returns an error if the configuration is not usable.
+func (c Config) Validate() error {
+	if c.ReloadInterval < 0 {
+		return errors.New("reloadInterval must not be negative")
+	}
+	return nil
+}
+
+// Contract decides whether principals may use routes on identities; routes that act on many identities, or on
+// identities their paths do not carry, ask it about each identity they would expose or change.
+type Contract interface {
+	// Allows returns whether principal may use route on id.
+	Allows(principal *auth.Principal, route, id string) bool
+}
+
+// prefixes is a receiver that allows principals to use any route on the identities their prefixes permit.
+type prefixes struct{}
+
+// NewDefault is a factory function that returns the decisions made when no policy is configured: principals, if
+// authenticated, may use any route on the identities their prefixes permit.
+func NewDefault() prefixes {
+	return prefixes{}
+}
+
+// Allows returns whether principal is nil or its prefixes permit id.
+func (prefixes) Allows(principal *auth.Principal, _, id string) bool {
+	return principal == nil || principal.Permits(id)
+}
+
+// Recorder is called with each failed reload; the previous policy stays in use.
+type Recorder func(err error)
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	config   Config
+	routes   []string
+	audit    audit.Contract
+	recorder Recorder
+	m        sync.RWMutex
+	policy   Policy
+	version  os.FileInfo
+}
+
+// New is a factory function that returns instance guarding the routes named routes with the policy in config's
+// policy file; decisions are recorded in log.
+func New(config Config, routes []string, log audit.Contract) (*instance, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	i := &instance{
+		config:   config,
+		routes:   routes,
+		audit:    log,
+		recorder: func(error) {},
+	}
+	if err := i.Reload(); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// SetRecorder provides for method injection of the function told about failed reloads; the default discards them.
+func (i *instance) SetRecorder(recorder Recorder) {
+	i.recorder = recorder
+}
+
+// Reload reads the policy file again; if it fails to load, the previous policy stays in use.
+func (i *instance) Reload() error {
+	version, err := os.Stat(i.config.PolicyPath)
+	if err != nil {
+		return err
+	}
+	policy, err := load(i.config.PolicyPath, i.routes)
+	if err != nil {
+		return err
+	}
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.policy = policy
+	i.version = version
+	return nil
+}
+
+// changed returns whether the policy file differs from the version last loaded.
+func (i *instance) changed() bool {
+	version, err := os.Stat(i.config.PolicyPath)
+	if err != nil {
+		return true
+	}
+
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	return !version.ModTime().Equal(i.version.ModTime()) || version.Size() != i.version.Size()
+}
+
+// Run reloads the policy whenever its file changes until ctx is done.
+func (i *instance) Run(ctx context.Context, wg *sync.WaitGroup) {
+	if i.config.ReloadInterval == 0 {
+		return
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(time.Millisecond * time.Duration(i.config.ReloadInterval))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if !i.changed() {
+					continue
+				}
+				if err := i.Reload(); err != nil {
+					i.recorder(err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Init installs the authorization middleware on muxRouter; it must be installed after the authentication middleware.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.Use(i.Middleware)
+}
+
+// Middleware rejects requests to guarded routes that the policy does not allow the request's principal to make on the
+// request's identity, and records each decision in the audit log.  Requests to guarded routes whose paths carry no
+// identity are passed to next, which asks Allows about each identity it acts on.
+func (i *instance) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			route := mux.CurrentRoute(r)
+			if route == nil || !contains(i.routes, route.GetName()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			id, exists := urlIdentity.FromRequest(r)
+			if !exists {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, _ := auth.FromContext(r.Context())
+			event := audit.Event{
+				Time:      time.Now().UTC(),
+				RequestID: logging.RequestID(r.Context()),
+				Type:      eventType,
+				Action:    route.GetName(),
+				Resource:  id,
+			}
+			if principal != nil {
+				event.Subject = principal.Subject
+				event.Method = principal.Method
+			}
+
+			event.Reason = i.decide(principal, route.GetName(), id)
+			if event.Reason != "" {
+				event.Outcome = audit.OutcomeDeny
+				i.audit.Record(event)
+				problem.Write(w, r, problem.ErrForbidden.WithDetail(event.Reason))
+				return
+			}
+
+			event.Outcome = audit.OutcomeAllow
+			i.audit.Record(event)
+			next.ServeHTTP(w, r)
+		},
+	)
+}
+
+// Allows returns whether the policy allows principal to use route on id; the decision is not recorded.
+func (i *instance) Allows(principal *auth.Principal, route, id string) bool {
+	return i.decide(principal, route, id) == ""
+}
+
+// decide returns why principal may not use route on id, or an empty string if it may.
+func (i *instance) decide(principal *auth.Principal, route, id string) string {
+	if principal == nil {
+		return "no authenticated principal"
+	}
+	if !principal.Permits(id) {
+		return "identity is outside the principal's prefixes"
+	}
+
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	if !i.policy.Allows(principal.Subject, principal.Roles, route, id) {
+		return "no rule allows " + route + " on " + id
+	}
+	return ""
+}
@@ -0,0 +1,319 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package authz
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/audit"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	routeCreate = "create"
+	routeFind   = "find"
+)
+
+// log is an audit log that keeps the events it records.
+type log struct {
+	m      sync.Mutex
+	events []audit.Event
+}
+
+// Record keeps event.
+func (l *log) Record(event audit.Event) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	l.events = append(l.events, event)
+}
+
+// writePolicy writes policy to path.
+func writePolicy(t *testing.T, path string, policy Policy) {
+	if err := ioutil.WriteFile(path, testInternal.Marshal(t, policy), 0600); err != nil {
+		assert.FailNow(t, "Unexpected ioutil.WriteFile failure:", err.Error())
+	}
+}
+
+// TestPolicy tests rule evaluation.
+func TestPolicy(t *testing.T) {
+	policy := Policy{
+		Rules: []Rule{
+			{Roles: []string{"writer"}, Routes: []string{routeCreate}, Identities: []string{"device/plant-3/*"}},
+			{Subjects: []string{"auditor"}, Routes: []string{wildcard}, Identities: []string{"device/plant-3/a"}},
+		},
+	}
+
+	assert.True(t, policy.Allows("someone", []string{"reader", "writer"}, routeCreate, "device/plant-3/a"))
+	assert.False(t, policy.Allows("someone", []string{"writer"}, routeFind, "device/plant-3/a"))
+	assert.False(t, policy.Allows("someone", []string{"writer"}, routeCreate, "device/plant-4/a"))
+	assert.False(t, policy.Allows("someone", []string{"reader"}, routeCreate, "device/plant-3/a"))
+	assert.True(t, policy.Allows("auditor", nil, routeFind, "device/plant-3/a"))
+	assert.False(t, policy.Allows("auditor", nil, routeFind, "device/plant-3/ab"))
+	assert.True(t, Matches("*", "anything"))
+
+	routes := []string{routeCreate, routeFind}
+	assert.Nil(t, policy.Validate(routes))
+	assert.NotNil(t, Policy{Rules: []Rule{{Routes: routes, Identities: []string{"*"}}}}.Validate(routes))
+	assert.NotNil(t, Policy{Rules: []Rule{{Roles: []string{"r"}, Identities: []string{"*"}}}}.Validate(routes))
+	assert.NotNil(t, Policy{Rules: []Rule{{Roles: []string{"r"}, Routes: routes}}}.Validate(routes))
+	assert.NotNil(
+		t,
+		Policy{Rules: []Rule{{Roles: []string{"r"}, Routes: []string{"unknown"}, Identities: []string{"*"}}}}.Validate(
+			routes,
+		),
+	)
+}
+
+// TestMiddleware tests authorizing guarded routes.
+func TestMiddleware(t *testing.T) {
+	directory, err := ioutil.TempDir("", "authz")
+	if err != nil {
+		assert.FailNow(t, "Unexpected ioutil.TempDir failure:", err.Error())
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+	path := filepath.Join(directory, "policy.json")
+	writePolicy(
+		t,
+		path,
+		Policy{
+			Rules: []Rule{
+				{Roles: []string{"writer"}, Routes: []string{routeCreate}, Identities: []string{"device/plant-3/*"}},
+				{Roles: []string{"reader"}, Routes: []string{routeFind}, Identities: []string{"*"}},
+			},
+		},
+	)
+
+	type testCase struct {
+		name      string
+		principal *auth.Principal
+		route     string
+		id        string
+		expected  int
+		reason    string
+	}
+
+	cases := []testCase{
+		{
+			name:      "allowed",
+			principal: &auth.Principal{Subject: "a", Roles: []string{"writer"}},
+			route:     routeCreate,
+			id:        "device/plant-3/pump",
+			expected:  http.StatusOK,
+		},
+		{
+			name:      "other prefix",
+			principal: &auth.Principal{Subject: "a", Roles: []string{"writer"}},
+			route:     routeCreate,
+			id:        "device/plant-4/pump",
+			expected:  http.StatusForbidden,
+			reason:    "no rule allows create on device/plant-4/pump",
+		},
+		{
+			name:      "other route",
+			principal: &auth.Principal{Subject: "a", Roles: []string{"writer"}},
+			route:     routeFind,
+			id:        "device/plant-3/pump",
+			expected:  http.StatusForbidden,
+			reason:    "no rule allows find on device/plant-3/pump",
+		},
+		{
+			name:      "principal prefixes",
+			principal: &auth.Principal{Subject: "a", Roles: []string{"reader"}, Prefixes: []string{"device/plant-3/"}},
+			route:     routeFind,
+			id:        "device/plant-4/pump",
+			expected:  http.StatusForbidden,
+			reason:    "identity is outside the principal's prefixes",
+		},
+		{
+			name:      "within principal prefixes",
+			principal: &auth.Principal{Subject: "a", Roles: []string{"reader"}, Prefixes: []string{"device/plant-3/"}},
+			route:     routeFind,
+			id:        "device/plant-3/pump",
+			expected:  http.StatusOK,
+		},
+		{
+			name:     "no principal",
+			route:    routeFind,
+			id:       "device/plant-3/pump",
+			expected: http.StatusForbidden,
+			reason:   "no authenticated principal",
+		},
+		{
+			name:     "unguarded route",
+			route:    "other",
+			id:       "device/plant-3/pump",
+			expected: http.StatusOK,
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				decisions := &log{}
+				sut, err := New(Config{PolicyPath: path}, []string{routeCreate, routeFind}, decisions)
+				if err != nil {
+					assert.FailNow(t, "Unexpected New failure:", err.Error())
+				}
+				handled := false
+				principal := func(muxRouter *mux.Router) {
+					muxRouter.Use(
+						func(next http.Handler) http.Handler {
+							return http.HandlerFunc(
+								func(w http.ResponseWriter, r *http.Request) {
+									if cases[i].principal != nil {
+										r = r.WithContext(auth.NewContext(r.Context(), cases[i].principal))
+									}
+									next.ServeHTTP(w, r)
+								},
+							)
+						},
+					)
+				}
+				routes := func(muxRouter *mux.Router) {
+					for _, name := range []string{routeCreate, routeFind, "other"} {
+						muxRouter.HandleFunc(
+							"/"+name+"/{"+urlIdentity.Param+"}",
+							func(w http.ResponseWriter, r *http.Request) {
+								handled = true
+								w.WriteHeader(http.StatusOK)
+							},
+						).Name(name)
+					}
+				}
+				cancel, wg, muxRouter := testInternal.NewSUT(
+					pkg.Run,
+					[]routable.Contract{principal, sut.Init, routes},
+				)
+				defer func() {
+					cancel()
+					wg.Wait()
+				}()
+
+				response := testInternal.SendRequestWithoutBody(
+					t,
+					muxRouter,
+					http.MethodGet,
+					"/"+cases[i].route+"/"+url.PathEscape(cases[i].id),
+				)
+
+				assert.Equal(t, cases[i].expected, response.Code)
+				assert.Equal(t, cases[i].expected == http.StatusOK, handled)
+				if cases[i].route == "other" {
+					assert.Empty(t, decisions.events)
+					return
+				}
+				if !assert.Len(t, decisions.events, 1) {
+					return
+				}
+				event := decisions.events[0]
+				assert.Equal(t, eventType, event.Type)
+				assert.Equal(t, cases[i].route, event.Action)
+				assert.Equal(t, cases[i].id, event.Resource)
+				assert.Equal(t, cases[i].reason, event.Reason)
+				if cases[i].principal != nil {
+					assert.Equal(t, cases[i].principal.Subject, event.Subject)
+				}
+				if cases[i].expected == http.StatusOK {
+					assert.Equal(t, audit.OutcomeAllow, event.Outcome)
+					return
+				}
+				assert.Equal(t, audit.OutcomeDeny, event.Outcome)
+				failure, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeForbidden, failure.Code)
+				assert.Equal(t, cases[i].reason, failure.Detail)
+			},
+		)
+	}
+}
+
+// TestInstance_Run tests reloading the policy file when it changes.
+func TestInstance_Run(t *testing.T) {
+	directory, err := ioutil.TempDir("", "authz")
+	if err != nil {
+		assert.FailNow(t, "Unexpected ioutil.TempDir failure:", err.Error())
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+	path := filepath.Join(directory, "policy.json")
+	writePolicy(t, path, Policy{})
+	reader := &auth.Principal{Subject: "a", Roles: []string{"reader"}}
+
+	_, missing := New(Config{PolicyPath: filepath.Join(directory, "missing.json")}, []string{routeFind}, &log{})
+	sut, err := New(Config{PolicyPath: path, ReloadInterval: 10}, []string{routeFind}, &log{})
+	if err != nil {
+		assert.FailNow(t, "Unexpected New failure:", err.Error())
+	}
+	failures := make(chan error, 1)
+	sut.SetRecorder(
+		func(err error) {
+			select {
+			case failures <- err:
+			default:
+			}
+		},
+	)
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	sut.Run(ctx, &wg)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	assert.NotNil(t, missing)
+	assert.NotEmpty(t, sut.decide(reader, routeFind, "x"))
+	writePolicy(
+		t,
+		path,
+		Policy{Rules: []Rule{{Roles: []string{"reader"}, Routes: []string{routeFind}, Identities: []string{"*"}}}},
+	)
+	assert.Eventually(
+		t,
+		func() bool { return sut.decide(reader, routeFind, "x") == "" },
+		time.Second*5,
+		time.Millisecond*20,
+	)
+
+	writePolicy(
+		t,
+		path,
+		Policy{Rules: []Rule{{Roles: []string{"reader"}, Routes: []string{"unknown"}, Identities: []string{"*"}}}},
+	)
+	select {
+	case err := <-failures:
+		assert.NotNil(t, err)
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "failed reload not recorded")
+	}
+	assert.Empty(t, sut.decide(reader, routeFind, "x"))
+}
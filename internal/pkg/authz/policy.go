@@ -0,0 +1,129 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package authz
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const wildcard = "*"
+
+// Rule allows principals holding one of Roles, or whose subject is one of Subjects, to use Routes on identities
+// matching one of Identities.  Routes are route names (see the route packages' Name constants) or "*" for every
+// guarded route; identity patterns ending in "*" match identities that start with the rest of the pattern, and other
+// patterns match a single identity.
+type Rule struct {
+	Roles      []string `json:"roles"`
+	Subjects   []string `json:"subjects"`
+	Routes     []string `json:"routes"`
+	Identities []string `json:"identities"`
+}
+
+// Policy is the content of a policy file; a request is allowed if any rule allows it.
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// contains returns whether values holds value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns whether pattern matches id.
+func Matches(pattern, id string) bool {
+	if strings.HasSuffix(pattern, wildcard) {
+		return strings.HasPrefix(id, strings.TrimSuffix(pattern, wildcard))
+	}
+	return pattern == id
+}
+
+// applies returns whether r applies to a principal with subject and roles.
+func (r Rule) applies(subject string, roles []string) bool {
+	if contains(r.Subjects, subject) {
+		return true
+	}
+	for _, role := range roles {
+		if contains(r.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows returns whether r allows route on id.
+func (r Rule) allows(route, id string) bool {
+	if !contains(r.Routes, route) && !contains(r.Routes, wildcard) {
+		return false
+	}
+	for _, pattern := range r.Identities {
+		if Matches(pattern, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows returns whether p allows a principal with subject and roles to use route on id.
+func (p Policy) Allows(subject string, roles []string, route, id string) bool {
+	for _, rule := range p.Rules {
+		if rule.applies(subject, roles) && rule.allows(route, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if a rule can never apply or names a route other than routes.
+func (p Policy) Validate(routes []string) error {
+	for index, rule := range p.Rules {
+		if len(rule.Roles) == 0 && len(rule.Subjects) == 0 {
+			return fmt.Errorf("rule %d: names no roles or subjects", index)
+		}
+		if len(rule.Routes) == 0 || len(rule.Identities) == 0 {
+			return fmt.Errorf("rule %d: names no routes or identities", index)
+		}
+		for _, route := range rule.Routes {
+			if route != wildcard && !contains(routes, route) {
+				return fmt.Errorf("rule %d: unknown route %q", index, route)
+			}
+		}
+	}
+	return nil
+}
+
+// load returns the policy in the JSON file at path, validated against routes.
+func load(path string, routes []string) (Policy, error) {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(body, &policy); err != nil {
+		return Policy{}, err
+	}
+	if err := policy.Validate(routes); err != nil {
+		return Policy{}, err
+	}
+	return policy, nil
+}
@@ -0,0 +1,251 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package certificate
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	ClientAuthNone     = "none"
+	ClientAuthOptional = "optional"
+	ClientAuthRequired = "required"
+)
+
+// clientAuth maps the configured client certificate modes to their TLS equivalents; presented certificates are always
+// verified against the client CAs.
+var clientAuth = map[string]tls.ClientAuthType{
+	ClientAuthNone:     tls.NoClientCert,
+	ClientAuthOptional: tls.VerifyClientCertIfGiven,
+	ClientAuthRequired: tls.RequireAndVerifyClientCert,
+}
+
+// Config enables HTTPS when CertFile and KeyFile name the server's PEM certificate and key.  ClientAuth selects
+// whether clients present certificates (none, optional or required), which must be issued by a CA in ClientCAFile.
+// The files are checked for changes every ReloadInterval milliseconds; zero disables reloading.
+type Config struct {
+	CertFile       string `json:"certFile"`
+	KeyFile        string `json:"keyFile"`
+	ClientCAFile   string `json:"clientCAFile"`
+	ClientAuth     string `json:"clientAuth"`
+	ReloadInterval int    `json:"reloadInterval"`
+}
+
+// NewDefaultConfig returns the default configuration: plain HTTP.
+func NewDefaultConfig() Config {
+	return Config{
+		ClientAuth:     ClientAuthNone,
+		ReloadInterval: 10000,
+	}
+}
+
+// Enabled returns whether the configuration enables HTTPS.
+func (c Config) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// Validate returns an error if the configuration is not usable.
+func (c Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return errors.New("certFile and keyFile must both be set")
+	}
+	if _, ok := clientAuth[c.ClientAuth]; !ok {
+		return fmt.Errorf("unknown clientAuth %q", c.ClientAuth)
+	}
+	if c.ClientAuth != ClientAuthNone && c.ClientCAFile == "" {
+		return errors.New("clientCAFile must be set to verify client certificates")
+	}
+	if c.ReloadInterval < 0 {
+		return errors.New("reloadInterval must not be negative")
+	}
+	return nil
+}
+
+// Recorder is called with each failed reload; the previous certificates stay in use.
+type Recorder func(err error)
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	config      Config
+	recorder    Recorder
+	m           sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	versions    map[string]version
+}
+
+// version identifies the content of a file without reading it.
+type version struct {
+	modified time.Time
+	size     int64
+}
+
+// New is a factory function that returns instance with the certificates config names loaded.
+func New(config Config) (*instance, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	i := &instance{
+		config:   config,
+		recorder: func(error) {},
+	}
+	if err := i.Reload(); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// SetRecorder provides for method injection of the function told about failed reloads; the default discards them.
+func (i *instance) SetRecorder(recorder Recorder) {
+	i.recorder = recorder
+}
+
+// files returns the files the certificates are loaded from.
+func (i *instance) files() []string {
+	files := []string{i.config.CertFile, i.config.KeyFile}
+	if i.config.ClientCAFile != "" {
+		files = append(files, i.config.ClientCAFile)
+	}
+	return files
+}
+
+// stat returns the current versions of the certificate files.
+func (i *instance) stat() (map[string]version, error) {
+	versions := make(map[string]version)
+	for _, file := range i.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		versions[file] = version{modified: info.ModTime(), size: info.Size()}
+	}
+	return versions, nil
+}
+
+// changed returns whether any certificate file differs from the version last loaded.
+func (i *instance) changed() bool {
+	versions, err := i.stat()
+	if err != nil {
+		return true
+	}
+
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	for file := range versions {
+		if versions[file] != i.versions[file] {
+			return true
+		}
+	}
+	return false
+}
+
+// Reload loads the certificates from disk; if any fails to load, the previous certificates stay in use.
+func (i *instance) Reload() error {
+	versions, err := i.stat()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(i.config.CertFile, i.config.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	var clientCAs *x509.CertPool
+	if i.config.ClientCAFile != "" {
+		body, err := ioutil.ReadFile(i.config.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(body) {
+			return fmt.Errorf("no certificates found in %s", i.config.ClientCAFile)
+		}
+	}
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.certificate = &certificate
+	i.clientCAs = clientCAs
+	i.versions = versions
+	return nil
+}
+
+// nextProtos lists the application protocols the server negotiates; http.Server and gRPC only add "h2" to the base
+// configuration, which the per-client configuration replaces.
+var nextProtos = []string{"h2", "http/1.1"}
+
+// TLSConfig returns the server TLS configuration; each handshake uses the most recently loaded certificates.
+func (i *instance) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: nextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			i.m.RLock()
+			defer i.m.RUnlock()
+
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   nextProtos,
+				Certificates: []tls.Certificate{*i.certificate},
+				ClientAuth:   clientAuth[i.config.ClientAuth],
+				ClientCAs:    i.clientCAs,
+			}, nil
+		},
+	}
+}
+
+// Run reloads the certificates whenever their files change until ctx is done.
+func (i *instance) Run(ctx context.Context, wg *sync.WaitGroup) {
+	if i.config.ReloadInterval == 0 {
+		return
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(time.Millisecond * time.Duration(i.config.ReloadInterval))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if !i.changed() {
+					continue
+				}
+				if err := i.Reload(); err != nil {
+					i.recorder(err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,310 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package certificate
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newDirectory returns a new temporary directory and a function that removes it.
+func newDirectory(t *testing.T) (string, func()) {
+	directory, err := ioutil.TempDir("", "certificate")
+	if err != nil {
+		assert.FailNow(t, "Unexpected ioutil.TempDir failure:", err.Error())
+	}
+	return directory, func() { _ = os.RemoveAll(directory) }
+}
+
+// newSUT returns a new system under test.
+func newSUT(t *testing.T, config Config) *instance {
+	sut, err := New(config)
+	if err != nil {
+		assert.FailNow(t, "Unexpected New failure:", err.Error())
+	}
+	return sut
+}
+
+// handshake connects to a TLS listener using server's configuration and returns the certificate the server presented
+// and the client certificate the server verified, if any.
+func handshake(
+	t *testing.T,
+	server *tls.Config,
+	roots *x509.Certificate,
+	client *testInternal.Issued) (*x509.Certificate, *x509.Certificate, error) {
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	if err != nil {
+		assert.FailNow(t, "Unexpected tls.Listen failure:", err.Error())
+	}
+	defer func() { _ = listener.Close() }()
+
+	verified := make(chan *x509.Certificate, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			verified <- nil
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		tlsConn := conn.(*tls.Conn)
+		if tlsConn.Handshake() != nil || len(tlsConn.ConnectionState().VerifiedChains) == 0 {
+			verified <- nil
+			return
+		}
+		verified <- tlsConn.ConnectionState().VerifiedChains[0][0]
+	}()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(roots)
+	config := &tls.Config{RootCAs: pool, ServerName: "localhost"}
+	if client != nil {
+		certificate, err := tls.LoadX509KeyPair(client.CertFile, client.KeyFile)
+		if err != nil {
+			assert.FailNow(t, "Unexpected tls.LoadX509KeyPair failure:", err.Error())
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second * 5}, "tcp", listener.Addr().String(), config)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = conn.Close() }()
+	// the server reports a rejected client certificate after the client's handshake completes.
+	_, _ = conn.Write([]byte{0})
+	presented := conn.ConnectionState().PeerCertificates[0]
+	return presented, <-verified, nil
+}
+
+// TestConfig_Validate tests configuration validation.
+func TestConfig_Validate(t *testing.T) {
+	type testCase struct {
+		name   string
+		config Config
+		valid  bool
+	}
+
+	cases := []testCase{
+		{name: "default", config: NewDefaultConfig(), valid: true},
+		{name: "server only", config: Config{CertFile: "c", KeyFile: "k", ClientAuth: ClientAuthNone}, valid: true},
+		{
+			name:   "client certificates",
+			config: Config{CertFile: "c", KeyFile: "k", ClientCAFile: "ca", ClientAuth: ClientAuthRequired},
+			valid:  true,
+		},
+		{name: "missing key", config: Config{CertFile: "c", ClientAuth: ClientAuthNone}},
+		{name: "unknown client auth", config: Config{CertFile: "c", KeyFile: "k", ClientAuth: "unknown"}},
+		{name: "missing client ca", config: Config{CertFile: "c", KeyFile: "k", ClientAuth: ClientAuthOptional}},
+		{
+			name:   "negative reload interval",
+			config: Config{CertFile: "c", KeyFile: "k", ClientAuth: ClientAuthNone, ReloadInterval: -1},
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				err := cases[i].config.Validate()
+
+				assert.Equal(t, cases[i].valid, err == nil)
+			},
+		)
+	}
+}
+
+// TestInstance tests serving and reloading certificates.
+func TestInstance(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T, directory string)
+	}
+
+	cases := []testCase{
+		{
+			name: "missing files",
+			test: func(t *testing.T, directory string) {
+				_, err := New(Config{CertFile: directory + "/missing.pem", KeyFile: directory + "/missing-key.pem"})
+
+				assert.NotNil(t, err)
+			},
+		},
+		{
+			name: "server certificate",
+			test: func(t *testing.T, directory string) {
+				authority, _ := testInternal.NewAuthority(t, directory, "ca")
+				server := authority.IssueServer(t, directory, "server")
+				sut := newSUT(t, Config{CertFile: server.CertFile, KeyFile: server.KeyFile, ClientAuth: ClientAuthNone})
+
+				presented, verified, err := handshake(t, sut.TLSConfig(), authority.Certificate, nil)
+
+				assert.Nil(t, err)
+				assert.Equal(t, server.Certificate.Raw, presented.Raw)
+				assert.Nil(t, verified)
+			},
+		},
+		{
+			name: "negotiates h2",
+			test: func(t *testing.T, directory string) {
+				authority, _ := testInternal.NewAuthority(t, directory, "ca")
+				server := authority.IssueServer(t, directory, "server")
+				sut := newSUT(t, Config{CertFile: server.CertFile, KeyFile: server.KeyFile, ClientAuth: ClientAuthNone})
+				listener, err := tls.Listen("tcp", "127.0.0.1:0", sut.TLSConfig())
+				if err != nil {
+					assert.FailNow(t, "Unexpected tls.Listen failure:", err.Error())
+				}
+				defer func() { _ = listener.Close() }()
+				go func() {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					defer func() { _ = conn.Close() }()
+					_ = conn.(*tls.Conn).Handshake()
+				}()
+				pool := x509.NewCertPool()
+				pool.AddCert(authority.Certificate)
+
+				conn, err := tls.Dial(
+					"tcp",
+					listener.Addr().String(),
+					&tls.Config{RootCAs: pool, ServerName: "localhost", NextProtos: []string{"h2"}},
+				)
+
+				if assert.Nil(t, err) {
+					defer func() { _ = conn.Close() }()
+					assert.Equal(t, "h2", conn.ConnectionState().NegotiatedProtocol)
+				}
+			},
+		},
+		{
+			name: "client certificate required",
+			test: func(t *testing.T, directory string) {
+				authority, caFile := testInternal.NewAuthority(t, directory, "ca")
+				server := authority.IssueServer(t, directory, "server")
+				client := authority.IssueClient(t, directory, "client")
+				other, _ := testInternal.NewAuthority(t, directory, "other")
+				stranger := other.IssueClient(t, directory, "stranger")
+				sut := newSUT(
+					t,
+					Config{
+						CertFile:     server.CertFile,
+						KeyFile:      server.KeyFile,
+						ClientCAFile: caFile,
+						ClientAuth:   ClientAuthRequired,
+					},
+				)
+
+				_, verified, err := handshake(t, sut.TLSConfig(), authority.Certificate, &client)
+				_, anonymous, _ := handshake(t, sut.TLSConfig(), authority.Certificate, nil)
+				_, untrusted, _ := handshake(t, sut.TLSConfig(), authority.Certificate, &stranger)
+
+				assert.Nil(t, err)
+				assert.Equal(t, client.Certificate.Raw, verified.Raw)
+				assert.Nil(t, anonymous)
+				assert.Nil(t, untrusted)
+			},
+		},
+		{
+			name: "reload",
+			test: func(t *testing.T, directory string) {
+				authority, _ := testInternal.NewAuthority(t, directory, "ca")
+				first := authority.IssueServer(t, directory, "server")
+				sut := newSUT(t, Config{CertFile: first.CertFile, KeyFile: first.KeyFile, ClientAuth: ClientAuthNone})
+				config := sut.TLSConfig()
+				second := authority.IssueServer(t, directory, "server")
+
+				err := sut.Reload()
+				presented, _, handshakeErr := handshake(t, config, authority.Certificate, nil)
+
+				assert.Nil(t, err)
+				assert.Nil(t, handshakeErr)
+				assert.Equal(t, second.Certificate.Raw, presented.Raw)
+			},
+		},
+		{
+			name: "failed reload keeps certificate",
+			test: func(t *testing.T, directory string) {
+				authority, _ := testInternal.NewAuthority(t, directory, "ca")
+				server := authority.IssueServer(t, directory, "server")
+				sut := newSUT(t, Config{CertFile: server.CertFile, KeyFile: server.KeyFile, ClientAuth: ClientAuthNone})
+				if err := ioutil.WriteFile(server.KeyFile, []byte("invalid"), 0600); err != nil {
+					assert.FailNow(t, "Unexpected ioutil.WriteFile failure:", err.Error())
+				}
+
+				err := sut.Reload()
+				presented, _, handshakeErr := handshake(t, sut.TLSConfig(), authority.Certificate, nil)
+
+				assert.NotNil(t, err)
+				assert.Nil(t, handshakeErr)
+				assert.Equal(t, server.Certificate.Raw, presented.Raw)
+			},
+		},
+		{
+			name: "run reloads changed files",
+			test: func(t *testing.T, directory string) {
+				authority, _ := testInternal.NewAuthority(t, directory, "ca")
+				first := authority.IssueServer(t, directory, "server")
+				sut := newSUT(
+					t,
+					Config{CertFile: first.CertFile, KeyFile: first.KeyFile, ClientAuth: ClientAuthNone, ReloadInterval: 10},
+				)
+				var wg sync.WaitGroup
+				ctx, cancel := context.WithCancel(context.Background())
+				sut.Run(ctx, &wg)
+				defer func() {
+					cancel()
+					wg.Wait()
+				}()
+				// a reload between writing the certificate and its key fails and is retried on the next tick.
+				second := authority.IssueServer(t, directory, "server")
+
+				assert.Eventually(
+					t,
+					func() bool {
+						presented, _, err := handshake(t, sut.TLSConfig(), authority.Certificate, nil)
+						return err == nil && string(presented.Raw) == string(second.Certificate.Raw)
+					},
+					time.Second*5,
+					time.Millisecond*20,
+				)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				directory, remove := newDirectory(t)
+				defer remove()
+
+				cases[i].test(t, directory)
+			},
+		)
+	}
+}
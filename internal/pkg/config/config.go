@@ -0,0 +1,98 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/project-alvarium/go-store/internal/pkg/audit"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/auth/jwt"
+	"github.com/project-alvarium/go-store/internal/pkg/auth/mtls"
+	"github.com/project-alvarium/go-store/internal/pkg/authz"
+	"github.com/project-alvarium/go-store/internal/pkg/certificate"
+	"github.com/project-alvarium/go-store/internal/pkg/graph"
+	"github.com/project-alvarium/go-store/internal/pkg/index"
+	"github.com/project-alvarium/go-store/internal/pkg/ingest"
+	"github.com/project-alvarium/go-store/internal/pkg/logging"
+	"github.com/project-alvarium/go-store/internal/pkg/metadata/registry"
+	"github.com/project-alvarium/go-store/internal/pkg/metrics"
+	"github.com/project-alvarium/go-store/internal/pkg/mqtt"
+	"github.com/project-alvarium/go-store/internal/pkg/openapi"
+	"github.com/project-alvarium/go-store/internal/pkg/ratelimit"
+	"github.com/project-alvarium/go-store/internal/pkg/score"
+	"github.com/project-alvarium/go-store/internal/pkg/tenant"
+	"github.com/project-alvarium/go-store/internal/pkg/webhook"
+)
+
+// Instance defines the service's configuration file.
+type Instance struct {
+	Indexes             []index.Definition `json:"indexes"`
+	ScorePolicy         *score.Policy      `json:"scorePolicy"`
+	SubscriptionHistory int                `json:"subscriptionHistory"`
+	Webhooks            webhook.Config     `json:"webhooks"`
+	MQTT                mqtt.Config        `json:"mqtt"`
+	GraphQL             graph.Limits       `json:"graphql"`
+	OpenAPI             openapi.Config     `json:"openapi"`
+	LegacyStatus        bool               `json:"legacyStatus"`
+	Ingest              ingest.Config      `json:"ingest"`
+	Metadata            registry.Config    `json:"metadata"`
+	Auth                auth.Config        `json:"auth"`
+	TLS                 certificate.Config `json:"tls"`
+	ClientIdentity      mtls.Config        `json:"clientIdentity"`
+	JWT                 jwt.Config         `json:"jwt"`
+	Authorization       authz.Config       `json:"authorization"`
+	Tenants             tenant.Config      `json:"tenants"`
+	RateLimit           ratelimit.Config   `json:"rateLimit"`
+	Audit               audit.Config       `json:"audit"`
+	Logging             logging.Config     `json:"logging"`
+	Metrics             metrics.Config     `json:"metrics"`
+}
+
+// New is a factory function that returns the default configuration.
+func New() *Instance {
+	return &Instance{
+		Indexes:        []index.Definition{},
+		Webhooks:       webhook.NewDefaultConfig(),
+		GraphQL:        graph.NewDefaultLimits(),
+		Ingest:         ingest.NewDefaultConfig(),
+		Metadata:       registry.NewDefaultConfig(),
+		TLS:            certificate.NewDefaultConfig(),
+		ClientIdentity: mtls.NewDefaultConfig(),
+		JWT:            jwt.NewDefaultConfig(),
+		Authorization:  authz.NewDefaultConfig(),
+		RateLimit:      ratelimit.NewDefaultConfig(),
+		Audit:          audit.NewDefaultConfig(),
+		Logging:        logging.NewDefaultConfig(),
+	}
+}
+
+// Load returns the configuration read from the JSON file at path; an empty path returns the default configuration.
+func Load(path string) (*Instance, error) {
+	result := New()
+	if path == "" {
+		return result, nil
+	}
+
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
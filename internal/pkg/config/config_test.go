@@ -0,0 +1,123 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg/index"
+	"github.com/project-alvarium/go-store/internal/pkg/webhook"
+
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFile writes content to a temporary file and returns its path; callers remove the file.
+func writeFile(t *testing.T, content string) string {
+	f, err := ioutil.TempFile("", "config*.json")
+	if err != nil {
+		assert.FailNow(t, "Unexpected ioutil.TempFile failure:", err.Error())
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if _, err := f.WriteString(content); err != nil {
+		assert.FailNow(t, "Unexpected WriteString failure:", err.Error())
+	}
+	return f.Name()
+}
+
+// TestLoad tests Load.
+func TestLoad(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "default",
+			test: func(t *testing.T) {
+				result, err := Load("")
+
+				assert.Nil(t, err)
+				assert.Equal(t, New(), result)
+			},
+		},
+		{
+			name: "missing file",
+			test: func(t *testing.T) {
+				result, err := Load(test.FactoryRandomString())
+
+				assert.NotNil(t, err)
+				assert.Nil(t, result)
+			},
+		},
+		{
+			name: "malformed file",
+			test: func(t *testing.T) {
+				path := writeFile(t, "{")
+				defer func() { _ = os.Remove(path) }()
+
+				result, err := Load(path)
+
+				assert.NotNil(t, err)
+				assert.Nil(t, result)
+			},
+		},
+		{
+			name: "indexes",
+			test: func(t *testing.T) {
+				path := writeFile(
+					t,
+					`{"indexes":[{"name":"failed","metadataKind":"assessment","path":"$.assessorMetadata.result"}]}`,
+				)
+				defer func() { _ = os.Remove(path) }()
+
+				result, err := Load(path)
+
+				assert.Nil(t, err)
+				assert.Equal(
+					t,
+					[]index.Definition{{Name: "failed", MetadataKind: "assessment", Path: "$.assessorMetadata.result"}},
+					result.Indexes,
+				)
+			},
+		},
+		{
+			name: "webhooks override defaults",
+			test: func(t *testing.T) {
+				path := writeFile(t, `{"webhooks":{"directory":"/var/lib/store","maxAttempts":2}}`)
+				defer func() { _ = os.Remove(path) }()
+
+				result, err := Load(path)
+
+				expected := webhook.NewDefaultConfig()
+				expected.Directory = "/var/lib/store"
+				expected.MaxAttempts = 2
+				assert.Nil(t, err)
+				assert.Equal(t, expected, result.Webhooks)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
@@ -0,0 +1,35 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package deprecation
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const (
+	Header     = "Deprecation"
+	LinkHeader = "Link"
+)
+
+// Handler returns handler decorated to mark its responses deprecated in favour of the route successor returns for
+// the same request.
+func Handler(handler http.HandlerFunc, successor func(r *http.Request) string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(Header, "true")
+		w.Header().Set(LinkHeader, fmt.Sprintf("<%s>; rel=\"successor-version\"", successor(r)))
+		handler(w, r)
+	}
+}
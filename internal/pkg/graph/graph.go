@@ -0,0 +1,119 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package graph
+
+import (
+	"context"
+
+	"github.com/project-alvarium/go-store/internal/pkg/author"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Request is a GraphQL request.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Limits bounds the cost of a single query.  Depth counts nested fields; complexity counts the fields a query can
+// resolve, multiplying each list's selection by the number of items it can return.
+type Limits struct {
+	MaxDepth      int `json:"maxDepth"`
+	MaxComplexity int `json:"maxComplexity"`
+}
+
+// NewDefaultLimits is a factory function that returns the default query limits.
+func NewDefaultLimits() Limits {
+	return Limits{
+		MaxDepth:      10,
+		MaxComplexity: 10000,
+	}
+}
+
+// Contract defines the GraphQL query abstraction.
+type Contract interface {
+	// Execute runs request and returns its result; errors are reported within the result.
+	Execute(ctx context.Context, request Request) *graphql.Result
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	source  Source
+	authors author.Contract
+	limits  Limits
+	schema  graphql.Schema
+}
+
+// New is a factory function that returns instance; queries are answered by looking up the identities they name in
+// source rather than by reading the whole store.
+func New(source Source, limits Limits) (*instance, error) {
+	schema, err := newSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	return &instance{
+		source:  source,
+		authors: author.NewDiscard(),
+		limits:  limits,
+		schema:  schema,
+	}, nil
+}
+
+// SetAuthors provides for method injection of the record annotations' authors are looked up in; by default, no
+// annotation has an author.
+func (i *instance) SetAuthors(authors author.Contract) {
+	i.authors = authors
+}
+
+// failure returns a result reporting err.
+func failure(err error) *graphql.Result {
+	return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+}
+
+// Execute runs request and returns its result; errors are reported within the result.
+func (i *instance) Execute(ctx context.Context, request Request) *graphql.Result {
+	document, err := parser.Parse(
+		parser.ParseParams{
+			Source: source.NewSource(&source.Source{Body: []byte(request.Query), Name: "GraphQL request"}),
+		},
+	)
+	if err != nil {
+		return failure(err)
+	}
+
+	if validation := graphql.ValidateDocument(&i.schema, document, nil); !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	if _, _, err := measure(document, request.Variables, i.limits); err != nil {
+		return failure(err)
+	}
+
+	return graphql.Execute(
+		graphql.ExecuteParams{
+			Schema:        i.schema,
+			AST:           document,
+			OperationName: request.OperationName,
+			Args:          request.Variables,
+			Context:       context.WithValue(ctx, viewKey{}, newView(ctx, i.source, i.authors)),
+		},
+	)
+}
@@ -0,0 +1,236 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package graph
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	firstArgument = "first"
+	defaultFirst  = 100
+	ceiling       = math.MaxInt32
+)
+
+// lists defines the fields that return lists bounded by their first argument.
+var lists = map[string]bool{
+	"identities":  true,
+	"annotations": true,
+	"previous":    true,
+	"next":        true,
+}
+
+// cost is the depth and complexity of a selection set.
+type cost struct {
+	depth      int
+	complexity int
+}
+
+// measurer is a receiver that encapsulates the state required to measure a document.  Each fragment is measured
+// once, however many times it is spread.
+type measurer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	limits    Limits
+	visiting  map[string]bool
+	costs     map[string]cost
+}
+
+// measure returns the greatest depth and the total complexity of document's operations; it fails as soon as either
+// passes its limit (a limit of zero is unbounded).
+func measure(
+	document *ast.Document,
+	variables map[string]interface{},
+	limits Limits) (depth, complexity int, err error) {
+
+	m := &measurer{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+		limits:    limits,
+		visiting:  make(map[string]bool),
+		costs:     make(map[string]cost),
+	}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			m.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	maxDepth, maxComplexity := bound(limits.MaxDepth), bound(limits.MaxComplexity)
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		d, c, err := m.selectionSet(operation.SelectionSet, maxDepth, remaining(maxComplexity, complexity))
+		if err != nil {
+			return 0, 0, err
+		}
+		if d > depth {
+			depth = d
+		}
+		complexity = add(complexity, c)
+	}
+	return depth, complexity, nil
+}
+
+// bound returns limit, or ceiling if limit is unbounded.
+func bound(limit int) int {
+	if limit <= 0 {
+		return ceiling
+	}
+	return limit
+}
+
+// remaining returns what is left of limit once used has been spent; an unbounded limit stays unbounded.
+func remaining(limit, used int) int {
+	if limit == ceiling {
+		return ceiling
+	}
+	return limit - used
+}
+
+// add returns a+b, saturating at ceiling.
+func add(a, b int) int {
+	if a > ceiling-b {
+		return ceiling
+	}
+	return a + b
+}
+
+// multiply returns a*b, saturating at ceiling.
+func multiply(a, b int) int {
+	if a != 0 && b > ceiling/a {
+		return ceiling
+	}
+	return a * b
+}
+
+// selectionSet returns the depth and complexity of set, failing as soon as either passes what remains of its limit;
+// a remaining complexity of ceiling is unbounded.
+func (m *measurer) selectionSet(set *ast.SelectionSet, maxDepth, maxComplexity int) (depth, complexity int, err error) {
+	if set == nil {
+		return 0, 0, nil
+	}
+
+	for _, selection := range set.Selections {
+		var d, c int
+		switch s := selection.(type) {
+		case *ast.Field:
+			multiplier, err := m.multiplier(s)
+			if err != nil {
+				return 0, 0, err
+			}
+			childComplexity := ceiling
+			if multiplier > 0 && maxComplexity < ceiling {
+				childComplexity = (maxComplexity - 1) / multiplier
+			}
+			if d, c, err = m.selectionSet(s.SelectionSet, maxDepth-1, childComplexity); err != nil {
+				return 0, 0, err
+			}
+			d, c = d+1, add(1, multiply(multiplier, c))
+		case *ast.InlineFragment:
+			if d, c, err = m.selectionSet(s.SelectionSet, maxDepth, remaining(maxComplexity, complexity)); err != nil {
+				return 0, 0, err
+			}
+		case *ast.FragmentSpread:
+			measured, err := m.fragment(s.Name.Value)
+			if err != nil {
+				return 0, 0, err
+			}
+			d, c = measured.depth, measured.complexity
+		}
+
+		if d > depth {
+			depth = d
+		}
+		complexity = add(complexity, c)
+		if depth > maxDepth {
+			return 0, 0, fmt.Errorf("query depth exceeds limit %d", m.limits.MaxDepth)
+		}
+		if complexity > maxComplexity && maxComplexity < ceiling {
+			return 0, 0, fmt.Errorf("query complexity exceeds limit %d", m.limits.MaxComplexity)
+		}
+	}
+	return depth, complexity, nil
+}
+
+// fragment returns the cost of the named fragment, measuring it the first time it is spread.
+func (m *measurer) fragment(name string) (cost, error) {
+	if measured, ok := m.costs[name]; ok {
+		return measured, nil
+	}
+
+	fragment, ok := m.fragments[name]
+	if !ok || m.visiting[name] {
+		return cost{}, fmt.Errorf("invalid fragment %q", name)
+	}
+	m.visiting[name] = true
+	d, c, err := m.selectionSet(fragment.SelectionSet, ceiling, ceiling)
+	m.visiting[name] = false
+	if err != nil {
+		return cost{}, err
+	}
+
+	m.costs[name] = cost{depth: d, complexity: c}
+	return m.costs[name], nil
+}
+
+// multiplier returns the number of items field can return for each item of its parent.
+func (m *measurer) multiplier(field *ast.Field) (int, error) {
+	if !lists[field.Name.Value] {
+		return 1, nil
+	}
+
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != firstArgument {
+			continue
+		}
+
+		var value int
+		switch v := argument.Value.(type) {
+		case *ast.IntValue:
+			parsed, err := strconv.Atoi(v.Value)
+			if err != nil {
+				return 0, fmt.Errorf("invalid %s: %s", firstArgument, v.Value)
+			}
+			value = parsed
+		case *ast.Variable:
+			switch variable := m.variables[v.Name.Value].(type) {
+			case nil:
+				return defaultFirst, nil
+			case int:
+				value = variable
+			case float64:
+				value = int(variable)
+			default:
+				return 0, fmt.Errorf("invalid %s: %v", firstArgument, variable)
+			}
+		default:
+			return defaultFirst, nil
+		}
+
+		if value < 0 {
+			return 0, fmt.Errorf("invalid %s: %d", firstArgument, value)
+		}
+		return value, nil
+	}
+	return defaultFirst, nil
+}
@@ -0,0 +1,143 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMeasure tests measure.
+func TestMeasure(t *testing.T) {
+	type testCase struct {
+		name               string
+		query              string
+		variables          map[string]interface{}
+		limits             Limits
+		expectedDepth      int
+		expectedComplexity int
+		expectedError      bool
+	}
+
+	// each fragment spreads the next twice, so measuring every spread would take 2^30 steps.
+	var fragments strings.Builder
+	for n := 0; n < 30; n++ {
+		fmt.Fprintf(&fragments, "fragment f%d on Identity { ...f%d ...f%d }\n", n, n+1, n+1)
+	}
+	fragments.WriteString("fragment f30 on Identity { id }")
+
+	cases := []testCase{
+		{
+			name:               "scalar fields",
+			query:              `{ identity(id: "x") { id } }`,
+			expectedDepth:      2,
+			expectedComplexity: 2,
+		},
+		{
+			name:               "default first",
+			query:              `{ identities { id } }`,
+			expectedDepth:      2,
+			expectedComplexity: 1 + defaultFirst,
+		},
+		{
+			name:               "literal first",
+			query:              `{ identities(first: 3) { id annotations(first: 2) { unique created } } }`,
+			expectedDepth:      3,
+			expectedComplexity: 1 + 3*(1+1+2*2),
+		},
+		{
+			name:               "variable first",
+			query:              `query($n: Int) { identities(first: $n) { id } }`,
+			variables:          map[string]interface{}{"n": float64(5)},
+			expectedDepth:      2,
+			expectedComplexity: 6,
+		},
+		{
+			name: "fragments",
+			query: `{ identities(first: 1) { ...f ... on Identity { id } } }
+				fragment f on Identity { next(first: 2) { id } }`,
+			expectedDepth:      3,
+			expectedComplexity: 1 + (1 + 2) + 1,
+		},
+		{
+			name:               "saturates",
+			query:              `{ identities(first: 2147483647) { next(first: 2147483647) { id } } }`,
+			expectedDepth:      3,
+			expectedComplexity: ceiling,
+		},
+		{
+			name:               "repeated fragments",
+			query:              `{ identity(id: "x") { ...f0 } }` + fragments.String(),
+			expectedDepth:      2,
+			expectedComplexity: 1 + 1<<30,
+		},
+		{
+			name:               "within limits",
+			query:              `{ identities(first: 3) { id } }`,
+			limits:             Limits{MaxDepth: 2, MaxComplexity: 4},
+			expectedDepth:      2,
+			expectedComplexity: 4,
+		},
+		{
+			name:          "depth limit",
+			query:         `{ identities { next { next { id } } } }`,
+			limits:        Limits{MaxDepth: 3},
+			expectedError: true,
+		},
+		{
+			name:          "complexity limit",
+			query:         `{ identities(first: 3) { id } }`,
+			limits:        Limits{MaxComplexity: 3},
+			expectedError: true,
+		},
+		{
+			name:          "complexity limit through fragments",
+			query:         `{ identity(id: "x") { ...f0 } }` + fragments.String(),
+			limits:        Limits{MaxComplexity: 1000},
+			expectedError: true,
+		},
+		{
+			name:          "negative first",
+			query:         `{ identities(first: -1) { id } }`,
+			expectedError: true,
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				document, err := parser.Parse(parser.ParseParams{Source: cases[i].query})
+				if err != nil {
+					assert.FailNow(t, "Unexpected parser.Parse failure:", err.Error())
+				}
+
+				depth, complexity, err := measure(document, cases[i].variables, cases[i].limits)
+
+				if cases[i].expectedError {
+					assert.NotNil(t, err)
+					return
+				}
+				assert.Nil(t, err)
+				assert.Equal(t, cases[i].expectedDepth, depth)
+				assert.Equal(t, cases[i].expectedComplexity, complexity)
+			},
+		)
+	}
+}
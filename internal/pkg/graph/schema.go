@@ -0,0 +1,502 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package graph
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/project-alvarium/go-store/internal/pkg/author"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/metadata"
+	assessMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
+	publishMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// jsonScalar is an output-only scalar that carries arbitrary JSON.
+var jsonScalar = graphql.NewScalar(
+	graphql.ScalarConfig{
+		Name:        "JSON",
+		Description: "Arbitrary JSON value.",
+		Serialize: func(value interface{}) interface{} {
+			data, ok := value.(json.RawMessage)
+			if !ok {
+				return nil
+			}
+			var result interface{}
+			if err := json.Unmarshal(data, &result); err != nil {
+				return nil
+			}
+			return result
+		},
+		ParseValue: func(value interface{}) interface{} {
+			return value
+		},
+		ParseLiteral: func(ast.Value) interface{} {
+			return nil
+		},
+	},
+)
+
+// authorType describes the principal that stored an annotation.
+var authorType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name:        "Author",
+		Description: "The authenticated principal that stored an annotation.",
+		Fields: graphql.Fields{
+			"subject": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(author.Author).Subject, nil
+				},
+			},
+			"method": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(author.Author).Method, nil
+				},
+			},
+			"tenant": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if tenant := p.Source.(author.Author).Tenant; tenant != "" {
+						return tenant, nil
+					}
+					return nil, nil
+				},
+			},
+		},
+	},
+)
+
+// encode returns value marshaled as JSON, or nil if it cannot be.
+func encode(value interface{}) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// result returns the result reported by nested metadata, if any.
+func result(value metadata.Contract) interface{} {
+	var nested struct {
+		Result *string `json:"result"`
+	}
+	if err := json.Unmarshal(encode(value), &nested); err != nil || nested.Result == nil {
+		return nil
+	}
+	return *nested.Result
+}
+
+// bytes returns value encoded as base64.
+func bytes(value []byte) interface{} {
+	if value == nil {
+		return nil
+	}
+	return base64.StdEncoding.EncodeToString(value)
+}
+
+// first returns the number of items of a list of length n to return, bounded by p's first argument.
+func first(p graphql.ResolveParams, n int) int {
+	limit := defaultFirst
+	if value, ok := p.Args[firstArgument].(int); ok {
+		limit = value
+	}
+
+	switch {
+	case limit < 0:
+		return 0
+	case limit < n:
+		return limit
+	default:
+		return n
+	}
+}
+
+// metadataField returns a resolver that applies fn to an annotation's metadata.
+func metadataField(fn func(m metadata.Contract) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(*annotation.Instance).Metadata), nil
+	}
+}
+
+// annotationFields returns the fields shared by every annotation type.
+func annotationFields() graphql.Fields {
+	source := func(p graphql.ResolveParams) *annotation.Instance {
+		return p.Source.(*annotation.Instance)
+	}
+
+	return graphql.Fields{
+		"unique": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return source(p).Unique, nil
+			},
+		},
+		"created": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return source(p).Created, nil
+			},
+		},
+		"identity": &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if source(p).CurrentIdentity == nil {
+					return nil, nil
+				}
+				return source(p).CurrentIdentity.Printable(), nil
+			},
+		},
+		"identityType": &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return source(p).CurrentIdentityKind, nil
+			},
+		},
+		"previousIdentity": &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if source(p).PreviousIdentity == nil {
+					return nil, nil
+				}
+				return source(p).PreviousIdentity.Printable(), nil
+			},
+		},
+		"previousIdentityType": &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return source(p).PreviousIdentityKind, nil
+			},
+		},
+		"metadataType": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return source(p).MetadataKind, nil
+			},
+		},
+		"metadata": &graphql.Field{
+			Type: jsonScalar,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return encode(source(p).Metadata), nil
+			},
+		},
+		"author": &graphql.Field{
+			Type:        authorType,
+			Description: "The principal that stored the annotation; null if it was stored anonymously or over MQTT.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if a, ok := fromContext(p.Context).authors.Find(source(p)); ok {
+					return a, nil
+				}
+				return nil, nil
+			},
+		},
+	}
+}
+
+// withFields returns the shared annotation fields extended with extra.
+func withFields(extra graphql.Fields) graphql.Fields {
+	fields := annotationFields()
+	for name := range extra {
+		fields[name] = extra[name]
+	}
+	return fields
+}
+
+// newSchema is a factory function that returns the service's GraphQL schema.
+func newSchema() (graphql.Schema, error) {
+	annotationInterface := graphql.NewInterface(
+		graphql.InterfaceConfig{
+			Name:        "Annotation",
+			Description: "An annotation stored against an identity.",
+			Fields:      annotationFields(),
+		},
+	)
+
+	assessType := graphql.NewObject(
+		graphql.ObjectConfig{
+			Name:        "AssessAnnotation",
+			Description: "An annotation recording an assessment.",
+			Interfaces:  []*graphql.Interface{annotationInterface},
+			Fields: withFields(
+				graphql.Fields{
+					"assessorType": &graphql.Field{
+						Type: graphql.String,
+						Resolve: metadataField(func(m metadata.Contract) interface{} {
+							return m.(*assessMetadata.Instance).AssessorKind
+						}),
+					},
+					"assessorMetadata": &graphql.Field{
+						Type: jsonScalar,
+						Resolve: metadataField(func(m metadata.Contract) interface{} {
+							return encode(m.(*assessMetadata.Instance).AssessorMetadata)
+						}),
+					},
+					"result": &graphql.Field{
+						Type: graphql.String,
+						Resolve: metadataField(func(m metadata.Contract) interface{} {
+							return result(m.(*assessMetadata.Instance).AssessorMetadata)
+						}),
+					},
+				},
+			),
+		},
+	)
+
+	pkiType := graphql.NewObject(
+		graphql.ObjectConfig{
+			Name:        "PkiAnnotation",
+			Description: "An annotation recording a signature.",
+			Interfaces:  []*graphql.Interface{annotationInterface},
+			Fields: withFields(
+				graphql.Fields{
+					"signerType": &graphql.Field{
+						Type: graphql.String,
+						Resolve: metadataField(func(m metadata.Contract) interface{} {
+							return m.(*pkiMetadata.Instance).SignerKind
+						}),
+					},
+					"identitySignature": &graphql.Field{
+						Type: graphql.String,
+						Resolve: metadataField(func(m metadata.Contract) interface{} {
+							return bytes(m.(*pkiMetadata.Instance).IdentitySignature)
+						}),
+					},
+					"dataSignature": &graphql.Field{
+						Type: graphql.String,
+						Resolve: metadataField(func(m metadata.Contract) interface{} {
+							return bytes(m.(*pkiMetadata.Instance).DataSignature)
+						}),
+					},
+					"publicKey": &graphql.Field{
+						Type: graphql.String,
+						Resolve: metadataField(func(m metadata.Contract) interface{} {
+							return bytes(m.(*pkiMetadata.Instance).PublicKey)
+						}),
+					},
+					"signerMetadata": &graphql.Field{
+						Type: jsonScalar,
+						Resolve: metadataField(func(m metadata.Contract) interface{} {
+							return encode(m.(*pkiMetadata.Instance).SignerMetadata)
+						}),
+					},
+					"result": &graphql.Field{
+						Type: graphql.String,
+						Resolve: metadataField(func(m metadata.Contract) interface{} {
+							return result(m.(*pkiMetadata.Instance).SignerMetadata)
+						}),
+					},
+				},
+			),
+		},
+	)
+
+	publishType := graphql.NewObject(
+		graphql.ObjectConfig{
+			Name:        "PublishAnnotation",
+			Description: "An annotation recording a publication.",
+			Interfaces:  []*graphql.Interface{annotationInterface},
+			Fields: withFields(
+				graphql.Fields{
+					"publisherType": &graphql.Field{
+						Type: graphql.String,
+						Resolve: metadataField(func(m metadata.Contract) interface{} {
+							return m.(*publishMetadata.Instance).PublisherKind
+						}),
+					},
+					"publisherMetadata": &graphql.Field{
+						Type: jsonScalar,
+						Resolve: metadataField(func(m metadata.Contract) interface{} {
+							return encode(m.(*publishMetadata.Instance).PublisherMetadata)
+						}),
+					},
+					"result": &graphql.Field{
+						Type: graphql.String,
+						Resolve: metadataField(func(m metadata.Contract) interface{} {
+							return result(m.(*publishMetadata.Instance).PublisherMetadata)
+						}),
+					},
+				},
+			),
+		},
+	)
+
+	otherType := graphql.NewObject(
+		graphql.ObjectConfig{
+			Name:        "OtherAnnotation",
+			Description: "An annotation whose metadata kind has no dedicated type.",
+			Interfaces:  []*graphql.Interface{annotationInterface},
+			Fields:      annotationFields(),
+		},
+	)
+
+	annotationInterface.ResolveType = func(p graphql.ResolveTypeParams) *graphql.Object {
+		switch p.Value.(*annotation.Instance).Metadata.(type) {
+		case *assessMetadata.Instance:
+			return assessType
+		case *pkiMetadata.Instance:
+			return pkiType
+		case *publishMetadata.Instance:
+			return publishType
+		default:
+			return otherType
+		}
+	}
+
+	identityType := graphql.NewObject(
+		graphql.ObjectConfig{
+			Name:        "Identity",
+			Description: "A stored identity.",
+			Fields:      graphql.Fields{},
+		},
+	)
+	identityList := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(identityType)))
+	firstArgumentConfig := &graphql.ArgumentConfig{
+		Type:        graphql.Int,
+		Description: "Maximum number of items returned (100 if omitted).",
+	}
+
+	identityType.AddFieldConfig(
+		"id",
+		&graphql.Field{
+			Type: graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source, nil
+			},
+		},
+	)
+	identityType.AddFieldConfig(
+		"annotations",
+		&graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(annotationInterface))),
+			Description: "Annotations stored directly against the identity, oldest first.",
+			Args: graphql.FieldConfigArgument{
+				"kind": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "Only annotations with this metadata kind.",
+				},
+				"createdAfter": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "Only annotations created after this time.",
+				},
+				"createdBefore": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "Only annotations created before this time.",
+				},
+				firstArgument: firstArgumentConfig,
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				kind, _ := p.Args["kind"].(string)
+				after, _ := p.Args["createdAfter"].(string)
+				before, _ := p.Args["createdBefore"].(string)
+
+				annotations := fromContext(p.Context).annotationsOf(p.Source.(string))
+				matches := make([]*annotation.Instance, 0, len(annotations))
+				for i := range annotations {
+					switch {
+					case kind != "" && annotations[i].MetadataKind != kind:
+					case after != "" && annotations[i].Created <= after:
+					case before != "" && annotations[i].Created >= before:
+					default:
+						matches = append(matches, annotations[i])
+					}
+				}
+				return matches[:first(p, len(matches))], nil
+			},
+		},
+	)
+	identityType.AddFieldConfig(
+		"previous",
+		&graphql.Field{
+			Type:        identityList,
+			Description: "Stored identities this identity was derived from.",
+			Args:        graphql.FieldConfigArgument{firstArgument: firstArgumentConfig},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				ids := fromContext(p.Context).previousOf(p.Source.(string))
+				return ids[:first(p, len(ids))], nil
+			},
+		},
+	)
+	identityType.AddFieldConfig(
+		"next",
+		&graphql.Field{
+			Type:        identityList,
+			Description: "Stored identities derived from this identity.",
+			Args:        graphql.FieldConfigArgument{firstArgument: firstArgumentConfig},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				ids := fromContext(p.Context).nextOf(p.Source.(string))
+				return ids[:first(p, len(ids))], nil
+			},
+		},
+	)
+
+	query := graphql.NewObject(
+		graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"identity": &graphql.Field{
+					Type:        identityType,
+					Description: "The identity with the given id, or null if it is not stored.",
+					Args: graphql.FieldConfigArgument{
+						"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						id := p.Args["id"].(string)
+						if !fromContext(p.Context).exists(id) {
+							return nil, nil
+						}
+						return id, nil
+					},
+				},
+				"identities": &graphql.Field{
+					Type:        identityList,
+					Description: "Stored identities, in creation order.",
+					Args: graphql.FieldConfigArgument{
+						"prefix": &graphql.ArgumentConfig{
+							Type:        graphql.String,
+							Description: "Only identities beginning with this prefix.",
+						},
+						firstArgument: firstArgumentConfig,
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						prefix, _ := p.Args["prefix"].(string)
+						return fromContext(p.Context).identities(prefix, first(p, ceiling)), nil
+					},
+				},
+			},
+		},
+	)
+
+	schema, err := graphql.NewSchema(
+		graphql.SchemaConfig{
+			Query: query,
+			Types: []graphql.Type{assessType, pkiType, publishType, otherType},
+		},
+	)
+	if err != nil {
+		return graphql.Schema{}, errors.New("invalid schema: " + err.Error())
+	}
+	return schema, nil
+}
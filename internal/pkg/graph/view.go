@@ -0,0 +1,170 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package graph
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/project-alvarium/go-store/internal/pkg/author"
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/index"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+// Source defines the lookups queries are resolved through: identity lookups against the store and the lineage its
+// index maintains.
+type Source interface {
+	index.Lineage
+
+	// FindByIdentity returns the annotations stored against identity, its own before its predecessors', and status.
+	FindByIdentity(id identity.Contract) ([]*annotation.Instance, status.Value)
+}
+
+// viewKey is the context key under which a request's view is stored.
+type viewKey struct{}
+
+// permitKey is the context key under which the identities a request may see are decided.
+type permitKey struct{}
+
+// NewContext returns a copy of ctx in which queries only see the identities permit allows; identities it does not
+// allow appear not to be stored.
+func NewContext(ctx context.Context, permit func(id string) bool) context.Context {
+	return context.WithValue(ctx, permitKey{}, permit)
+}
+
+// view resolves a single request's lookups, remembering each identity's node so that the request sees it
+// consistently; permit, if not nil, decides which identities the request may see.
+type view struct {
+	m       sync.Mutex
+	source  Source
+	authors author.Contract
+	permit  func(id string) bool
+	nodes   map[string]index.Node
+}
+
+// newView is a factory function that returns a view of source, whose annotations' authors are recorded in authors,
+// for a request made with ctx.
+func newView(ctx context.Context, source Source, authors author.Contract) *view {
+	permit, _ := ctx.Value(permitKey{}).(func(id string) bool)
+	return &view{
+		source:  source,
+		authors: authors,
+		permit:  permit,
+		nodes:   make(map[string]index.Node),
+	}
+}
+
+// permitted returns whether the request may see id.
+func (v *view) permitted(id string) bool {
+	return v.permit == nil || v.permit(id)
+}
+
+// fromContext returns the view stored in ctx.
+func fromContext(ctx context.Context) *view {
+	return ctx.Value(viewKey{}).(*view)
+}
+
+// node returns the node recorded for id and whether id is stored and may be seen.
+func (v *view) node(id string) (index.Node, bool) {
+	if !v.permitted(id) {
+		return index.Node{}, false
+	}
+
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	if n, ok := v.nodes[id]; ok {
+		return n, true
+	}
+	n, ok := v.source.Node(id)
+	if ok {
+		v.nodes[id] = n
+	}
+	return n, ok
+}
+
+// exists returns whether id is stored and may be seen.
+func (v *view) exists(id string) bool {
+	_, ok := v.node(id)
+	return ok
+}
+
+// identities returns up to limit stored identities beginning with prefix that may be seen, in creation order.
+func (v *view) identities(prefix string, limit int) []string {
+	if v.permit == nil {
+		return v.source.Identities(prefix, limit)
+	}
+
+	result := make([]string, 0)
+	for _, id := range v.source.Identities(prefix, math.MaxInt) {
+		if len(result) == limit {
+			break
+		}
+		if v.permit(id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// annotationsOf returns the annotations stored directly against id.
+func (v *view) annotationsOf(id string) []*annotation.Instance {
+	n, ok := v.node(id)
+	if !ok {
+		return nil
+	}
+
+	annotations, result := v.source.FindByIdentity(urlIdentity.New(id))
+	if result != status.Success {
+		return nil
+	}
+	if len(annotations) > n.Annotations {
+		annotations = annotations[:n.Annotations]
+	}
+	return annotations
+}
+
+// previousOf returns the stored identities id was derived from.
+func (v *view) previousOf(id string) []string {
+	n, _ := v.node(id)
+	result := make([]string, 0, len(n.Previous))
+	for i := range n.Previous {
+		if v.exists(n.Previous[i]) {
+			result = append(result, n.Previous[i])
+		}
+	}
+	return result
+}
+
+// nextOf returns the stored identities derived from id that may be seen.
+func (v *view) nextOf(id string) []string {
+	n, _ := v.node(id)
+	if v.permit == nil {
+		return n.Next
+	}
+
+	result := make([]string, 0, len(n.Next))
+	for i := range n.Next {
+		if v.permit(n.Next[i]) {
+			result = append(result, n.Next[i])
+		}
+	}
+	return result
+}
@@ -21,7 +21,8 @@ type identity struct {
 	id string
 }
 
-// New is a factory function that returns an initialized identity.
+// New is a factory function that returns an initialized identity; id is the unescaped printable form under which
+// every transport stores and looks up the identity.
 func New(id string) *identity {
 	return &identity{
 		id: id,
@@ -0,0 +1,41 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package url
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+)
+
+// Param is the path variable in which routes carry an identity.
+const Param = "identity"
+
+// Unescape returns the identity named by segment, an escaped url path segment; the service's router matches encoded
+// paths, so that identities may contain slashes, and route variables therefore hold identities escaped.  A segment
+// that is not validly escaped names itself.
+func Unescape(segment string) string {
+	if unescaped, err := url.PathUnescape(segment); err == nil {
+		return unescaped
+	}
+	return segment
+}
+
+// FromRequest returns the identity r's route carries in Param, unescaped, and whether it carries one.
+func FromRequest(r *http.Request) (string, bool) {
+	segment, exists := mux.Vars(r)[Param]
+	return Unescape(segment), exists
+}
@@ -0,0 +1,67 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package url
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUnescape tests Unescape.
+func TestUnescape(t *testing.T) {
+	type testCase struct {
+		name     string
+		segment  string
+		expected string
+	}
+
+	cases := []testCase{
+		{name: "escaped slash", segment: "a%2Fb", expected: "a/b"},
+		{name: "plus kept", segment: "a+b", expected: "a+b"},
+		{name: "invalid escape", segment: "a%zz", expected: "a%zz"},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				assert.Equal(t, cases[i].expected, Unescape(cases[i].segment))
+			},
+		)
+	}
+}
+
+// TestFromRequest tests FromRequest.
+func TestFromRequest(t *testing.T) {
+	var id string
+	var exists bool
+	handler := func(_ http.ResponseWriter, r *http.Request) { id, exists = FromRequest(r) }
+	muxRouter := mux.NewRouter().UseEncodedPath()
+	muxRouter.HandleFunc("/items/{"+Param+"}", handler)
+	muxRouter.HandleFunc("/items", handler)
+
+	muxRouter.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/a%2Fb", nil))
+
+	assert.True(t, exists)
+	assert.Equal(t, "a/b", id)
+
+	muxRouter.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+
+	assert.False(t, exists)
+}
@@ -0,0 +1,190 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package index
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/project-alvarium/go-store/pkg/http/resource"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+// Definition declares a secondary index over a JSON path into the metadata of a given metadata kind.  An empty
+// MetadataKind indexes the metadata of every kind.
+type Definition struct {
+	Name         string `json:"name"`
+	MetadataKind string `json:"metadataKind"`
+	Path         string `json:"path"`
+}
+
+// Entry identifies an annotation matched by an index lookup.
+type Entry = resource.Entry
+
+// Contract defines the secondary index abstraction.
+type Contract interface {
+	// Find returns the entries whose indexed value matches value and status.
+	Find(name, value string) ([]Entry, status.Value)
+
+	// Rebuild discards and recomputes every index from the data held by the underlying store and returns status.
+	Rebuild() status.Value
+}
+
+// Walker defines the store capability required to rebuild indexes from existing data.
+type Walker interface {
+	// Walk calls fn with each stored identity and the annotations stored directly against it.
+	Walk(fn func(id identity.Contract, annotations []*annotation.Instance))
+}
+
+// values defines the map of indexed value to matching entries.
+type values map[string][]Entry
+
+// index is a receiver that encapsulates a single secondary index.
+type index struct {
+	definition Definition
+	path       []string
+	values     values
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	m       sync.RWMutex
+	store   store.Contract
+	indexes map[string]*index
+	lineage lineage
+}
+
+// New is a factory function that returns instance; instance decorates store and maintains the declared indexes, and
+// the lineage of the identities stored, as annotations are written through it.
+func New(store store.Contract, definitions []Definition) *instance {
+	indexes := make(map[string]*index, len(definitions))
+	for key := range definitions {
+		indexes[definitions[key].Name] = &index{
+			definition: definitions[key],
+			path:       segments(definitions[key].Path),
+			values:     make(values),
+		}
+	}
+
+	return &instance{
+		m:       sync.RWMutex{},
+		store:   store,
+		indexes: indexes,
+		lineage: newLineage(),
+	}
+}
+
+// add records m, stored against id, in every applicable index.  Callers must hold the write lock.
+func (i *instance) add(id identity.Contract, m *annotation.Instance) {
+	if m == nil {
+		return
+	}
+
+	data, err := json.Marshal(m.Metadata)
+	if err != nil {
+		return
+	}
+
+	for name := range i.indexes {
+		x := i.indexes[name]
+		if x.definition.MetadataKind != "" && x.definition.MetadataKind != m.MetadataKind {
+			continue
+		}
+
+		value, ok := evaluate(x.path, data)
+		if !ok {
+			continue
+		}
+		x.values[value] = append(x.values[value], Entry{Identity: id.Printable(), Unique: m.Unique})
+	}
+}
+
+// FindByIdentity returns annotations and status corresponding to identity.
+func (i *instance) FindByIdentity(id identity.Contract) ([]*annotation.Instance, status.Value) {
+	return i.store.FindByIdentity(id)
+}
+
+// Create stores annotations corresponding to a new identity, updates indexes, and returns status.
+func (i *instance) Create(id identity.Contract, m *annotation.Instance) status.Value {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	result := i.store.Create(id, m)
+	if result == status.Success {
+		i.lineage.create(id)
+		i.lineage.link(id, m)
+		i.add(id, m)
+	}
+	return result
+}
+
+// Append stores annotations corresponding to identity, updates indexes, and returns status.
+func (i *instance) Append(id identity.Contract, m *annotation.Instance) status.Value {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	result := i.store.Append(id, m)
+	if result == status.Success {
+		i.lineage.link(id, m)
+		i.add(id, m)
+	}
+	return result
+}
+
+// Find returns the entries whose indexed value matches value and status.
+func (i *instance) Find(name, value string) ([]Entry, status.Value) {
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	x, exists := i.indexes[name]
+	if !exists {
+		return nil, status.NotFound
+	}
+
+	result := make([]Entry, len(x.values[value]))
+	copy(result, x.values[value])
+	return result, status.Success
+}
+
+// Rebuild discards and recomputes every index, and the lineage, from the data held by the underlying store and
+// returns status.
+func (i *instance) Rebuild() status.Value {
+	walker, ok := i.store.(Walker)
+	if !ok {
+		return status.Unknown
+	}
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	for name := range i.indexes {
+		i.indexes[name].values = make(values)
+	}
+	i.lineage = newLineage()
+	walker.Walk(
+		func(id identity.Contract, annotations []*annotation.Instance) {
+			i.lineage.create(id)
+			for key := range annotations {
+				i.lineage.link(id, annotations[key])
+				i.add(id, annotations[key])
+			}
+		},
+	)
+	return status.Success
+}
@@ -0,0 +1,234 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package index
+
+import (
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	sdkMemory "github.com/project-alvarium/go-sdk/pkg/annotation/store/memory"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAnnotation returns a new annotation whose stub metadata of kind holds value.
+func newAnnotation(kind string, value interface{}) (*hash.Identity, *annotation.Instance) {
+	id := hash.New(test.FactoryRandomByteSlice())
+	return id, annotation.New(ulid.New().Get(), id, nil, metadataStub.New(kind, value))
+}
+
+// TestInstance_Find tests instance.Find.
+func TestInstance_Find(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	kind := test.FactoryRandomString()
+	name := test.FactoryRandomString()
+	definitions := []Definition{{Name: name, MetadataKind: kind, Path: "$.value.result"}}
+
+	cases := []testCase{
+		{
+			name: "unknown index",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), definitions)
+
+				result, findResult := sut.Find(test.FactoryRandomString(), test.FactoryRandomString())
+
+				assert.Nil(t, result)
+				assert.Equal(t, status.NotFound, findResult)
+			},
+		},
+		{
+			name: "no match",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), definitions)
+
+				result, findResult := sut.Find(name, test.FactoryRandomString())
+
+				assert.Equal(t, []Entry{}, result)
+				assert.Equal(t, status.Success, findResult)
+			},
+		},
+		{
+			name: "created and appended",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), definitions)
+				id, created := newAnnotation(kind, map[string]string{"result": "failure"})
+				_, appended := newAnnotation(kind, map[string]string{"result": "failure"})
+				assert.Equal(t, status.Success, sut.Create(id, created))
+				assert.Equal(t, status.Success, sut.Append(id, appended))
+
+				result, findResult := sut.Find(name, "failure")
+
+				assert.Equal(
+					t,
+					[]Entry{
+						{Identity: id.Printable(), Unique: created.Unique},
+						{Identity: id.Printable(), Unique: appended.Unique},
+					},
+					result,
+				)
+				assert.Equal(t, status.Success, findResult)
+			},
+		},
+		{
+			name: "other kind ignored",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), definitions)
+				id, value := newAnnotation(test.FactoryRandomString(), map[string]string{"result": "failure"})
+				assert.Equal(t, status.Success, sut.Create(id, value))
+
+				result, _ := sut.Find(name, "failure")
+
+				assert.Equal(t, []Entry{}, result)
+			},
+		},
+		{
+			name: "rejected write not indexed",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), definitions)
+				id, value := newAnnotation(kind, map[string]string{"result": "failure"})
+				assert.Equal(t, status.NotFound, sut.Append(id, value))
+
+				result, _ := sut.Find(name, "failure")
+
+				assert.Equal(t, []Entry{}, result)
+			},
+		},
+		{
+			name: "scalar values",
+			test: func(t *testing.T) {
+				sut := New(
+					memory.New(),
+					[]Definition{
+						{Name: "flag", Path: "value[0]"},
+						{Name: "count", Path: "value[1]"},
+					},
+				)
+				id, value := newAnnotation(kind, []interface{}{true, 3})
+				assert.Equal(t, status.Success, sut.Create(id, value))
+
+				flag, _ := sut.Find("flag", "true")
+				count, _ := sut.Find("count", "3")
+
+				assert.Equal(t, []Entry{{Identity: id.Printable(), Unique: value.Unique}}, flag)
+				assert.Equal(t, []Entry{{Identity: id.Printable(), Unique: value.Unique}}, count)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
+
+// TestInstance_Rebuild tests instance.Rebuild.
+func TestInstance_Rebuild(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	kind := test.FactoryRandomString()
+	name := test.FactoryRandomString()
+	definitions := []Definition{{Name: name, MetadataKind: kind, Path: "value"}}
+
+	cases := []testCase{
+		{
+			name: "store cannot walk",
+			test: func(t *testing.T) {
+				sut := New(sdkMemory.New(), definitions)
+
+				assert.Equal(t, status.Unknown, sut.Rebuild())
+			},
+		},
+		{
+			name: "existing data",
+			test: func(t *testing.T) {
+				s := memory.New()
+				id, value := newAnnotation(kind, "x")
+				assert.Equal(t, status.Success, s.Create(id, value))
+				sut := New(s, definitions)
+
+				assert.Equal(t, status.Success, sut.Rebuild())
+
+				result, _ := sut.Find(name, "x")
+				assert.Equal(t, []Entry{{Identity: id.Printable(), Unique: value.Unique}}, result)
+			},
+		},
+		{
+			name: "rebuild is idempotent",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), definitions)
+				id, value := newAnnotation(kind, "x")
+				assert.Equal(t, status.Success, sut.Create(id, value))
+
+				assert.Equal(t, status.Success, sut.Rebuild())
+				assert.Equal(t, status.Success, sut.Rebuild())
+
+				result, _ := sut.Find(name, "x")
+				assert.Equal(t, []Entry{{Identity: id.Printable(), Unique: value.Unique}}, result)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
+
+// TestInstance_Lineage tests the lineage maintained as annotations are written and rebuilt.
+func TestInstance_Lineage(t *testing.T) {
+	prefix := test.FactoryRandomString()
+	parent := url.New(prefix + "/parent")
+	child := url.New(prefix + "/child")
+	other := url.New(test.FactoryRandomString())
+	m := metadataStub.NewNullObject()
+	sut := New(memory.New(), nil)
+	assert.Equal(t, status.Success, sut.Create(parent, annotation.New(ulid.New().Get(), parent, nil, m)))
+	assert.Equal(t, status.Success, sut.Create(other, annotation.New(ulid.New().Get(), other, nil, m)))
+	assert.Equal(t, status.Success, sut.Create(child, annotation.New(ulid.New().Get(), child, parent, m)))
+	assert.Equal(t, status.Success, sut.Append(child, annotation.New(ulid.New().Get(), child, parent, m)))
+
+	for _, rebuild := range []bool{false, true} {
+		if rebuild {
+			assert.Equal(t, status.Success, sut.Rebuild())
+		}
+
+		assert.Equal(t, []string{parent.Printable(), child.Printable()}, sut.Identities(prefix, 10))
+		assert.Equal(t, []string{parent.Printable()}, sut.Identities(prefix, 1))
+
+		node, exists := sut.Node(parent.Printable())
+		assert.True(t, exists)
+		assert.Equal(t, Node{Annotations: 1, Next: []string{child.Printable()}}, node)
+
+		node, exists = sut.Node(child.Printable())
+		assert.True(t, exists)
+		assert.Equal(t, Node{Annotations: 2, Previous: []string{parent.Printable()}}, node)
+
+		_, exists = sut.Node(test.FactoryRandomString())
+		assert.False(t, exists)
+	}
+}
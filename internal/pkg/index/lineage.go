@@ -0,0 +1,131 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package index
+
+import (
+	"strings"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+)
+
+// Node records what the index knows about a stored identity: the number of annotations stored directly against it,
+// the identities it was derived from, and the identities derived from it, in the order they were first recorded.
+type Node struct {
+	Annotations int
+	Previous    []string
+	Next        []string
+}
+
+// Lineage defines the lookups over stored identities that the index maintains.
+type Lineage interface {
+	// Identities returns up to limit stored identities beginning with prefix, in creation order.
+	Identities(prefix string, limit int) []string
+
+	// Node returns what is recorded about id and whether id is stored.
+	Node(id string) (Node, bool)
+}
+
+// lineage is a receiver that encapsulates the identities stored and their derivation.
+type lineage struct {
+	keys  []string
+	nodes map[string]*Node
+}
+
+// newLineage is a factory function that returns an empty lineage.
+func newLineage() lineage {
+	return lineage{
+		nodes: make(map[string]*Node),
+	}
+}
+
+// node returns the node recorded for key, creating it if need be.
+func (l *lineage) node(key string) *Node {
+	n, exists := l.nodes[key]
+	if !exists {
+		n = &Node{}
+		l.nodes[key] = n
+	}
+	return n
+}
+
+// create records that id has been stored.
+func (l *lineage) create(id identity.Contract) {
+	l.keys = append(l.keys, id.Printable())
+	l.node(id.Printable())
+}
+
+// link records m, stored against id, and the derivation it declares.
+func (l *lineage) link(id identity.Contract, m *annotation.Instance) {
+	key := id.Printable()
+	n := l.node(key)
+	n.Annotations++
+	if m == nil || m.PreviousIdentity == nil {
+		return
+	}
+
+	previous := m.PreviousIdentity.Printable()
+	if previous == key || contains(n.Previous, previous) {
+		return
+	}
+	n.Previous = append(n.Previous, previous)
+	p := l.node(previous)
+	p.Next = append(p.Next, key)
+}
+
+// contains returns whether values holds value.
+func contains(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Identities returns up to limit stored identities beginning with prefix, in creation order.
+func (i *instance) Identities(prefix string, limit int) []string {
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	result := make([]string, 0)
+	for key := 0; key < len(i.lineage.keys) && len(result) < limit; key++ {
+		if strings.HasPrefix(i.lineage.keys[key], prefix) {
+			result = append(result, i.lineage.keys[key])
+		}
+	}
+	return result
+}
+
+// Node returns what is recorded about id and whether id is stored.
+func (i *instance) Node(id string) (Node, bool) {
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	n, exists := i.lineage.nodes[id]
+	if !exists {
+		return Node{}, false
+	}
+
+	// an identity named as another's predecessor has a node before it is stored.
+	if n.Annotations == 0 {
+		return Node{}, false
+	}
+	return Node{
+		Annotations: n.Annotations,
+		Previous:    append([]string(nil), n.Previous...),
+		Next:        append([]string(nil), n.Next...),
+	}, true
+}
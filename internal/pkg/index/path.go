@@ -0,0 +1,67 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package index
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// segments splits a JSON path of the form "$.a.b[0].c" (the "$." prefix is optional) into its segments.
+func segments(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.Replace(strings.Replace(path, "[", ".", -1), "]", "", -1)
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, ".")
+}
+
+// evaluate returns the printable scalar value found at path within JSON document data.
+func evaluate(path []string, data []byte) (string, bool) {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return "", false
+	}
+
+	for _, segment := range path {
+		switch node := document.(type) {
+		case map[string]interface{}:
+			value, exists := node[segment]
+			if !exists {
+				return "", false
+			}
+			document = value
+		case []interface{}:
+			position, err := strconv.Atoi(segment)
+			if err != nil || position < 0 || position >= len(node) {
+				return "", false
+			}
+			document = node[position]
+		default:
+			return "", false
+		}
+	}
+
+	switch value := document.(type) {
+	case string:
+		return value, true
+	case bool, float64:
+		result, _ := json.Marshal(value)
+		return string(result), true
+	}
+	return "", false
+}
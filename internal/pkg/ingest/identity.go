@@ -0,0 +1,90 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package ingest
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+)
+
+// Identity check modes.
+const (
+	IdentityCheckOff     = "off"
+	IdentityCheckLenient = "lenient"
+	IdentityCheckStrict  = "strict"
+)
+
+// Mismatch describes an annotation posted under an identity other than its own; Posted is nil if the annotation's
+// identity is missing or of a kind the service cannot decode.
+type Mismatch struct {
+	Path   identity.Contract
+	Posted identity.Contract
+}
+
+// Recorder is called with every mismatch the identity check accepts.
+type Recorder func(mismatch Mismatch)
+
+// validIdentityCheck returns whether mode is a known identity check mode.
+func validIdentityCheck(mode string) bool {
+	switch mode {
+	case IdentityCheckOff, IdentityCheckLenient, IdentityCheckStrict:
+		return true
+	}
+	return false
+}
+
+// sameIdentity returns whether path, which is always a url identity, names posted.  Kinds differ, so identities
+// match if either their printable or their binary forms are equal.
+func sameIdentity(path, posted identity.Contract) bool {
+	if posted == nil {
+		return false
+	}
+	return path.Printable() == posted.Printable() || bytes.Equal(path.Binary(), posted.Binary())
+}
+
+// checkIdentity is a validation stage that compares the identity of value with the identity id it was posted under;
+// mismatches are rejected in strict mode and recorded in lenient mode.
+func (i *instance) checkIdentity(id identity.Contract, value *annotation.Instance) *problem.Instance {
+	if sameIdentity(id, value.CurrentIdentity) {
+		return nil
+	}
+
+	mismatch := Mismatch{Path: id, Posted: value.CurrentIdentity}
+	if i.config.IdentityCheck == IdentityCheckStrict {
+		return problem.ErrIdentityMismatch.WithDetail(mismatch.String())
+	}
+	if i.recorder != nil {
+		i.recorder(mismatch)
+	}
+	return nil
+}
+
+// String explains why m.Posted does not match m.Path.
+func (m Mismatch) String() string {
+	if m.Posted == nil {
+		return fmt.Sprintf("annotation without a recognised identity posted under %q", m.Path.Printable())
+	}
+	return fmt.Sprintf(
+		"annotation identity %q (%s) posted under %q",
+		m.Posted.Printable(),
+		m.Posted.Kind(),
+		m.Path.Printable(),
+	)
+}
@@ -0,0 +1,175 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package ingest
+
+import (
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+	"github.com/project-alvarium/go-store/pkg/schema"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/metadata"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newValidationSUT returns a new system under test using identity check mode and the mismatches it records.
+func newValidationSUT(mode string) (*instance, *[]Mismatch) {
+	config := NewDefaultConfig()
+	config.IdentityCheck = mode
+	sut := newSUT(metadataStub.NewNullObject(), config)
+
+	var recorded []Mismatch
+	sut.SetRecorder(func(mismatch Mismatch) { recorded = append(recorded, mismatch) })
+	return sut, &recorded
+}
+
+// newValue returns an annotation about id.
+func newValue(id identity.Contract) *annotation.Instance {
+	return annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+}
+
+// TestInstance_Validate tests Validate's identity check and metadata validation.
+func TestInstance_Validate(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "hash identity matches printable url identity",
+			test: func(t *testing.T) {
+				id := hash.New(test.FactoryRandomByteSlice())
+				sut, recorded := newValidationSUT(IdentityCheckStrict)
+
+				assert.Nil(t, sut.Validate(url.New(id.Printable()), newValue(id)))
+				assert.Empty(t, *recorded)
+			},
+		},
+		{
+			name: "hash identity matches binary url identity",
+			test: func(t *testing.T) {
+				id := test.FactoryRandomFixedLengthAlphanumericString(16)
+				sut, _ := newValidationSUT(IdentityCheckStrict)
+
+				assert.Nil(t, sut.Validate(url.New(id), newValue(hash.New([]byte(id)))))
+			},
+		},
+		{
+			name: "strict rejects mismatch",
+			test: func(t *testing.T) {
+				path := url.New(test.FactoryRandomFixedLengthAlphanumericString(16))
+				sut, recorded := newValidationSUT(IdentityCheckStrict)
+
+				result := sut.Validate(path, newValue(hash.New(test.FactoryRandomByteSlice())))
+
+				assert.Equal(t, problem.CodeIdentityMismatch, result.Code)
+				assert.Contains(t, result.Detail, path.Printable())
+				assert.Empty(t, *recorded)
+			},
+		},
+		{
+			name: "strict rejects missing identity",
+			test: func(t *testing.T) {
+				sut, _ := newValidationSUT(IdentityCheckStrict)
+				value := newValue(hash.New(test.FactoryRandomByteSlice()))
+				value.CurrentIdentity = nil
+
+				result := sut.Validate(url.New(test.FactoryRandomFixedLengthAlphanumericString(16)), value)
+
+				assert.Equal(t, problem.CodeIdentityMismatch, result.Code)
+			},
+		},
+		{
+			name: "lenient records mismatch",
+			test: func(t *testing.T) {
+				path := url.New(test.FactoryRandomFixedLengthAlphanumericString(16))
+				posted := hash.New(test.FactoryRandomByteSlice())
+				sut, recorded := newValidationSUT(IdentityCheckLenient)
+
+				assert.Nil(t, sut.Validate(path, newValue(posted)))
+				assert.Equal(t, []Mismatch{{Path: path, Posted: posted}}, *recorded)
+			},
+		},
+		{
+			name: "off ignores mismatch",
+			test: func(t *testing.T) {
+				sut, recorded := newValidationSUT(IdentityCheckOff)
+
+				result := sut.Validate(
+					url.New(test.FactoryRandomFixedLengthAlphanumericString(16)),
+					newValue(hash.New(test.FactoryRandomByteSlice())),
+				)
+
+				assert.Nil(t, result)
+				assert.Empty(t, *recorded)
+			},
+		},
+		{
+			name: "invalid metadata",
+			test: func(t *testing.T) {
+				id := hash.New(test.FactoryRandomByteSlice())
+				value := newValue(id)
+				custom := schema.New()
+				custom.Register(
+					value.MetadataKind,
+					func(metadata.Contract) schema.Errors { return schema.Errors{{Field: "a", Message: "is required"}} },
+				)
+				sut, _ := newValidationSUT(IdentityCheckStrict)
+				sut.SetSchema(custom)
+
+				result := sut.Validate(url.New(id.Printable()), value)
+
+				assert.Equal(t, problem.CodeInvalidAnnotation, result.Code)
+				assert.Equal(t, []problem.InvalidParam{{Name: "metadata.a", Reason: "is required"}}, result.InvalidParams)
+			},
+		},
+		{
+			name: "identity checked before metadata",
+			test: func(t *testing.T) {
+				value := newValue(hash.New(test.FactoryRandomByteSlice()))
+				custom := schema.New()
+				custom.Register(value.MetadataKind, func(metadata.Contract) schema.Errors { return schema.Errors{{}} })
+				sut, _ := newValidationSUT(IdentityCheckStrict)
+				sut.SetSchema(custom)
+
+				result := sut.Validate(url.New(test.FactoryRandomFixedLengthAlphanumericString(16)), value)
+
+				assert.Equal(t, problem.CodeIdentityMismatch, result.Code)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
+
+// TestConfig_Validate tests Config.Validate.
+func TestConfig_Validate(t *testing.T) {
+	for _, mode := range []string{IdentityCheckOff, IdentityCheckLenient, IdentityCheckStrict} {
+		assert.Nil(t, Config{IdentityCheck: mode}.Validate())
+	}
+	assert.NotNil(t, Config{IdentityCheck: test.FactoryRandomFixedLengthAlphanumericString(8)}.Validate())
+	assert.NotNil(t, Config{}.Validate())
+}
@@ -0,0 +1,235 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+	"github.com/project-alvarium/go-store/pkg/schema"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+)
+
+const defaultMaxBodySize = 1 << 20
+
+// errTrailing reports a body that continues after the annotation.
+var errTrailing = errors.New("unexpected data after annotation")
+
+// Config bounds the request bodies the service accepts; a MaxBodySize that is not positive disables the bound.
+// IdentityCheck selects how annotations posted under an identity other than their own are treated.
+type Config struct {
+	MaxBodySize   int64  `json:"maxBodySize"`
+	IdentityCheck string `json:"identityCheck"`
+}
+
+// NewDefaultConfig is a factory function that returns the default configuration.
+func NewDefaultConfig() Config {
+	return Config{
+		MaxBodySize:   defaultMaxBodySize,
+		IdentityCheck: IdentityCheckLenient,
+	}
+}
+
+// Validate returns an error if c is not a usable configuration.
+func (c Config) Validate() error {
+	if !validIdentityCheck(c.IdentityCheck) {
+		return fmt.Errorf("unknown identity check %q", c.IdentityCheck)
+	}
+	return nil
+}
+
+// Stage is a validation step applied to an annotation posted under id; it returns the problem that rejects value, if
+// any.
+type Stage func(id identity.Contract, value *annotation.Instance) *problem.Instance
+
+// Kinds reports the metadata kinds the service has been configured to reject.
+type Kinds interface {
+	Disabled(kind string) bool
+}
+
+// Contract defines the decoding of annotations posted to the service.
+type Contract interface {
+	// Decode returns the annotation in r's body and the format the response to r should use, or the problem that
+	// prevented it from being decoded.
+	Decode(w http.ResponseWriter, r *http.Request) (*annotation.Instance, codec.Contract, *problem.Instance)
+
+	// Unmarshal returns the annotation encoded as JSON in data, received by a transport other than HTTP, or the
+	// problem that prevented it from being decoded.
+	Unmarshal(data []byte) (*annotation.Instance, *problem.Instance)
+
+	// Validate returns the first problem the validation stages find with value, posted under id.
+	Validate(id identity.Contract, value *annotation.Instance) *problem.Instance
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	mFactory metadataFactory.Contract
+	iFactory identityFactory.Contract
+	config   Config
+	stages   []Stage
+	recorder Recorder
+	schema   schema.Contract
+	kinds    Kinds
+}
+
+// New is a factory function that returns instance.
+func New(mFactory metadataFactory.Contract, iFactory identityFactory.Contract, config Config) *instance {
+	i := &instance{
+		mFactory: mFactory,
+		iFactory: iFactory,
+		config:   config,
+		schema:   schema.NewDefault(),
+	}
+	if config.IdentityCheck != IdentityCheckOff {
+		i.stages = append(i.stages, i.checkIdentity)
+	}
+	i.stages = append(i.stages, i.checkKind, i.checkSchema)
+	return i
+}
+
+// SetRecorder provides for method injection of the recorder of mismatched identities accepted in lenient mode.
+func (i *instance) SetRecorder(recorder Recorder) {
+	i.recorder = recorder
+}
+
+// Decode returns the annotation in r's body and the format the response to r should use, or the problem that
+// prevented it from being decoded.  JSON bodies are streamed; bodies in binary formats are read and transcoded to
+// JSON first.  Bodies larger than the configured maximum, and bodies that continue after the annotation, are
+// rejected.
+func (i *instance) Decode(
+	w http.ResponseWriter,
+	r *http.Request) (*annotation.Instance, codec.Contract, *problem.Instance) {
+
+	decoder, encoder, ok := codec.Negotiate(r)
+	if !ok {
+		return nil, encoder, problem.ErrUnsupportedMediaType.WithDetail(r.Header.Get(codec.ContentTypeHeader))
+	}
+
+	var body io.Reader = r.Body
+	if i.config.MaxBodySize > 0 {
+		body = http.MaxBytesReader(w, r.Body, i.config.MaxBodySize)
+	}
+
+	if decoder.ContentType() != codec.ContentTypeJSON {
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, encoder, classify(err)
+		}
+		if data, err = decoder.ToJSON(data); err != nil {
+			return nil, encoder, problem.ErrMalformedBody.WithDetail(err.Error())
+		}
+		body = bytes.NewReader(data)
+	}
+
+	value, failure := i.decode(body)
+	return value, encoder, failure
+}
+
+// Unmarshal returns the annotation encoded as JSON in data, or the problem that prevented it from being decoded.
+// Data larger than the configured maximum, and data that continues after the annotation, are rejected.
+func (i *instance) Unmarshal(data []byte) (*annotation.Instance, *problem.Instance) {
+	if i.config.MaxBodySize > 0 && int64(len(data)) > i.config.MaxBodySize {
+		return nil, problem.ErrBodyTooLarge.WithDetail(fmt.Sprintf("annotation exceeds %d bytes", i.config.MaxBodySize))
+	}
+	return i.decode(bytes.NewReader(data))
+}
+
+// decode returns the single annotation encoded as JSON in body, or the problem that prevented it from being decoded.
+func (i *instance) decode(body io.Reader) (*annotation.Instance, *problem.Instance) {
+	var value annotation.Instance
+	value.SetMetadataFactory(i.mFactory)
+	value.SetIdentityFactory(i.iFactory)
+	stream := json.NewDecoder(body)
+	if err := stream.Decode(&value); err != nil {
+		return nil, classify(err)
+	}
+	if _, err := stream.Token(); err != io.EOF {
+		if err == nil {
+			err = errTrailing
+		}
+		return nil, classify(err)
+	}
+	return &value, nil
+}
+
+// SetSchema provides for method injection of the metadata validators; the default is schema.NewDefault().
+func (i *instance) SetSchema(schema schema.Contract) {
+	i.schema = schema
+}
+
+// checkSchema is a validation stage that rejects value if its metadata is invalid, listing the invalid fields.
+func (i *instance) checkSchema(_ identity.Contract, value *annotation.Instance) *problem.Instance {
+	errs := i.schema.Validate(value)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	params := make([]problem.InvalidParam, len(errs))
+	for j := range errs {
+		params[j] = problem.InvalidParam{Name: errs[j].Field, Reason: errs[j].Message}
+	}
+	return problem.ErrInvalidAnnotation.WithDetail(errs.Error()).WithInvalidParams(params)
+}
+
+// SetKinds provides for method injection of the metadata kinds that are rejected; by default none are.
+func (i *instance) SetKinds(kinds Kinds) {
+	i.kinds = kinds
+}
+
+// checkKind is a validation stage that rejects value if its metadata kind is disabled.
+func (i *instance) checkKind(_ identity.Contract, value *annotation.Instance) *problem.Instance {
+	if i.kinds == nil || !i.kinds.Disabled(value.MetadataKind) {
+		return nil
+	}
+
+	reason := "is a disabled metadata kind"
+	return problem.ErrInvalidAnnotation.
+		WithDetail(fmt.Sprintf("%q %s", value.MetadataKind, reason)).
+		WithInvalidParams([]problem.InvalidParam{{Name: "metadataType", Reason: reason}})
+}
+
+// Validate returns the first problem the validation stages find with value, posted under id.
+func (i *instance) Validate(id identity.Contract, value *annotation.Instance) *problem.Instance {
+	for _, stage := range i.stages {
+		if failure := stage(id, value); failure != nil {
+			return failure
+		}
+	}
+	return nil
+}
+
+// classify returns the problem that describes a failure to read or decode a body.
+func classify(err error) *problem.Instance {
+	var tooLarge *http.MaxBytesError
+	var syntax *json.SyntaxError
+	switch {
+	case errors.As(err, &tooLarge):
+		return problem.ErrBodyTooLarge.WithDetail(err.Error())
+	case errors.As(err, &syntax), err == io.EOF, err == io.ErrUnexpectedEOF, err == errTrailing:
+		return problem.ErrMalformedBody.WithDetail(err.Error())
+	}
+	return problem.ErrInvalidAnnotation.WithDetail(err.Error())
+}
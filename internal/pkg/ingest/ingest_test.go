@@ -0,0 +1,385 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package ingest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	metadataStubFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub/factory"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSUT returns a new system under test that decodes annotations carrying m.
+func newSUT(m *metadataStub.Instance, config Config) *instance {
+	return New(
+		metadataFactory.New([]metadataFactory.Contract{metadataStubFactory.New(m)}),
+		identityFactory.New(),
+		config,
+	)
+}
+
+// newRequest returns a request whose body is read from body and whose Content-Type is contentType.
+func newRequest(body io.Reader, contentType string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", body)
+	if contentType != "" {
+		r.Header.Set(codec.ContentTypeHeader, contentType)
+	}
+	return r
+}
+
+// marshal returns value encoded in format.
+func marshal(t *testing.T, format codec.Contract, value interface{}) []byte {
+	result, err := format.Marshal(value)
+	if err != nil {
+		assert.FailNow(t, "Unexpected marshal failure:", err.Error())
+	}
+	return result
+}
+
+// TestInstance_Decode tests Decode.
+func TestInstance_Decode(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T, m *metadataStub.Instance, value *annotation.Instance)
+	}
+
+	cases := []testCase{
+		{
+			name: "JSON",
+			test: func(t *testing.T, m *metadataStub.Instance, value *annotation.Instance) {
+				body := marshal(t, codec.NewJSON(), value)
+				sut := newSUT(m, NewDefaultConfig())
+
+				result, encoder, failure := sut.Decode(httptest.NewRecorder(), newRequest(bytes.NewReader(body), ""))
+
+				assert.Nil(t, failure)
+				assert.Equal(t, codec.ContentTypeJSON, encoder.ContentType())
+				assert.Equal(t, body, marshal(t, codec.NewJSON(), result))
+			},
+		},
+		{
+			name: "MessagePack",
+			test: func(t *testing.T, m *metadataStub.Instance, value *annotation.Instance) {
+				body := marshal(t, codec.NewMsgPack(), value)
+				sut := newSUT(m, NewDefaultConfig())
+				r := newRequest(bytes.NewReader(body), codec.ContentTypeMsgPack)
+
+				result, encoder, failure := sut.Decode(httptest.NewRecorder(), r)
+
+				assert.Nil(t, failure)
+				assert.Equal(t, codec.ContentTypeMsgPack, encoder.ContentType())
+				assert.Equal(t, marshal(t, codec.NewJSON(), value), marshal(t, codec.NewJSON(), result))
+			},
+		},
+		{
+			name: "unknown length",
+			test: func(t *testing.T, m *metadataStub.Instance, value *annotation.Instance) {
+				body := marshal(t, codec.NewJSON(), value)
+				sut := newSUT(m, NewDefaultConfig())
+				r := newRequest(io.MultiReader(bytes.NewReader(body[:1]), bytes.NewReader(body[1:])), "")
+				r.ContentLength = -1
+
+				result, _, failure := sut.Decode(httptest.NewRecorder(), r)
+
+				assert.Nil(t, failure)
+				assert.Equal(t, body, marshal(t, codec.NewJSON(), result))
+			},
+		},
+		{
+			name: "unsupported media type",
+			test: func(t *testing.T, m *metadataStub.Instance, value *annotation.Instance) {
+				body := marshal(t, codec.NewJSON(), value)
+				sut := newSUT(m, NewDefaultConfig())
+
+				_, _, failure := sut.Decode(httptest.NewRecorder(), newRequest(bytes.NewReader(body), "text/plain"))
+
+				assert.Equal(t, problem.CodeUnsupportedMediaType, failure.Code)
+			},
+		},
+		{
+			name: "empty",
+			test: func(t *testing.T, m *metadataStub.Instance, _ *annotation.Instance) {
+				sut := newSUT(m, NewDefaultConfig())
+
+				_, _, failure := sut.Decode(httptest.NewRecorder(), newRequest(strings.NewReader(""), ""))
+
+				assert.Equal(t, problem.CodeMalformedBody, failure.Code)
+			},
+		},
+		{
+			name: "truncated",
+			test: func(t *testing.T, m *metadataStub.Instance, value *annotation.Instance) {
+				body := marshal(t, codec.NewJSON(), value)
+				sut := newSUT(m, NewDefaultConfig())
+				r := newRequest(bytes.NewReader(body[:len(body)-1]), "")
+
+				_, _, failure := sut.Decode(httptest.NewRecorder(), r)
+
+				assert.Equal(t, problem.CodeMalformedBody, failure.Code)
+			},
+		},
+		{
+			name: "trailing value",
+			test: func(t *testing.T, m *metadataStub.Instance, value *annotation.Instance) {
+				body := marshal(t, codec.NewJSON(), value)
+				sut := newSUT(m, NewDefaultConfig())
+				r := newRequest(bytes.NewReader(append(body, body...)), "")
+
+				_, _, failure := sut.Decode(httptest.NewRecorder(), r)
+
+				assert.Equal(t, problem.CodeMalformedBody, failure.Code)
+			},
+		},
+		{
+			name: "trailing garbage",
+			test: func(t *testing.T, m *metadataStub.Instance, value *annotation.Instance) {
+				body := marshal(t, codec.NewJSON(), value)
+				sut := newSUT(m, NewDefaultConfig())
+				r := newRequest(bytes.NewReader(append(body, []byte("garbage")...)), "")
+
+				_, _, failure := sut.Decode(httptest.NewRecorder(), r)
+
+				assert.Equal(t, problem.CodeMalformedBody, failure.Code)
+			},
+		},
+		{
+			name: "trailing whitespace",
+			test: func(t *testing.T, m *metadataStub.Instance, value *annotation.Instance) {
+				body := marshal(t, codec.NewJSON(), value)
+				sut := newSUT(m, NewDefaultConfig())
+				r := newRequest(bytes.NewReader(append(body, []byte(" \n")...)), "")
+
+				_, _, failure := sut.Decode(httptest.NewRecorder(), r)
+
+				assert.Nil(t, failure)
+			},
+		},
+		{
+			name: "invalid annotation",
+			test: func(t *testing.T, m *metadataStub.Instance, _ *annotation.Instance) {
+				sut := newSUT(m, NewDefaultConfig())
+
+				_, _, failure := sut.Decode(httptest.NewRecorder(), newRequest(strings.NewReader(`{"unique": 1}`), ""))
+
+				assert.Equal(t, problem.CodeInvalidAnnotation, failure.Code)
+			},
+		},
+		{
+			name: "oversized",
+			test: func(t *testing.T, m *metadataStub.Instance, value *annotation.Instance) {
+				body := marshal(t, codec.NewJSON(), value)
+				sut := newSUT(m, Config{MaxBodySize: int64(len(body) - 1)})
+
+				_, _, failure := sut.Decode(httptest.NewRecorder(), newRequest(bytes.NewReader(body), ""))
+
+				assert.Equal(t, problem.CodeBodyTooLarge, failure.Code)
+				assert.Equal(t, http.StatusRequestEntityTooLarge, failure.Status)
+			},
+		},
+		{
+			name: "oversized MessagePack",
+			test: func(t *testing.T, m *metadataStub.Instance, value *annotation.Instance) {
+				body := marshal(t, codec.NewMsgPack(), value)
+				sut := newSUT(m, Config{MaxBodySize: int64(len(body) - 1)})
+				r := newRequest(bytes.NewReader(body), codec.ContentTypeMsgPack)
+
+				_, _, failure := sut.Decode(httptest.NewRecorder(), r)
+
+				assert.Equal(t, problem.CodeBodyTooLarge, failure.Code)
+			},
+		},
+		{
+			name: "exactly maximum size",
+			test: func(t *testing.T, m *metadataStub.Instance, value *annotation.Instance) {
+				body := marshal(t, codec.NewJSON(), value)
+				sut := newSUT(m, Config{MaxBodySize: int64(len(body))})
+
+				_, _, failure := sut.Decode(httptest.NewRecorder(), newRequest(bytes.NewReader(body), ""))
+
+				assert.Nil(t, failure)
+			},
+		},
+		{
+			name: "unbounded",
+			test: func(t *testing.T, m *metadataStub.Instance, value *annotation.Instance) {
+				body := marshal(t, codec.NewJSON(), value)
+				sut := newSUT(m, Config{})
+
+				_, _, failure := sut.Decode(httptest.NewRecorder(), newRequest(bytes.NewReader(body), ""))
+
+				assert.Nil(t, failure)
+			},
+		},
+	}
+
+	for i := range cases {
+		m := metadataStub.NewNullObject()
+		value := annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, m)
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				cases[i].test(t, m, value)
+			},
+		)
+	}
+}
+
+// TestInstance_DecodeChunked tests Decode with bodies sent by a client using chunked transfer encoding.
+func TestInstance_DecodeChunked(t *testing.T) {
+	type testCase struct {
+		name         string
+		maxBodySize  func(body []byte) int64
+		expectedCode int
+	}
+
+	cases := []testCase{
+		{
+			name:         "within limit",
+			maxBodySize:  func(body []byte) int64 { return int64(len(body)) },
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "oversized",
+			maxBodySize:  func(body []byte) int64 { return int64(len(body) / 2) },
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				m := metadataStub.NewNullObject()
+				value := annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, m)
+				body := marshal(t, codec.NewJSON(), value)
+				sut := newSUT(m, Config{MaxBodySize: cases[i].maxBodySize(body)})
+
+				var encodings []string
+				server := httptest.NewServer(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							encodings = r.TransferEncoding
+							if _, _, failure := sut.Decode(w, r); failure != nil {
+								problem.Write(w, r, failure)
+							}
+						},
+					),
+				)
+				defer server.Close()
+
+				reader, writer := io.Pipe()
+				go func() {
+					for offset := 0; offset < len(body); offset += 64 {
+						end := offset + 64
+						if end > len(body) {
+							end = len(body)
+						}
+						if _, err := writer.Write(body[offset:end]); err != nil {
+							return
+						}
+					}
+					_ = writer.Close()
+				}()
+				response, err := http.Post(server.URL, codec.ContentTypeJSON, reader)
+				if err != nil {
+					assert.FailNow(t, "Unexpected http.Post failure:", err.Error())
+				}
+				_ = response.Body.Close()
+
+				assert.Equal(t, []string{"chunked"}, encodings)
+				assert.Equal(t, cases[i].expectedCode, response.StatusCode)
+			},
+		)
+	}
+}
+
+// TestInstance_Unmarshal tests Unmarshal.
+func TestInstance_Unmarshal(t *testing.T) {
+	type testCase struct {
+		name         string
+		config       func(body []byte) Config
+		data         func(body []byte) []byte
+		expectedCode string
+	}
+
+	cases := []testCase{
+		{
+			name:   "valid",
+			config: func([]byte) Config { return NewDefaultConfig() },
+			data:   func(body []byte) []byte { return body },
+		},
+		{
+			name:   "unbounded",
+			config: func([]byte) Config { return Config{} },
+			data:   func(body []byte) []byte { return body },
+		},
+		{
+			name:         "oversized",
+			config:       func(body []byte) Config { return Config{MaxBodySize: int64(len(body) - 1)} },
+			data:         func(body []byte) []byte { return body },
+			expectedCode: problem.CodeBodyTooLarge,
+		},
+		{
+			name:         "malformed",
+			config:       func([]byte) Config { return NewDefaultConfig() },
+			data:         func(body []byte) []byte { return body[:len(body)/2] },
+			expectedCode: problem.CodeMalformedBody,
+		},
+		{
+			name:         "trailing data",
+			config:       func([]byte) Config { return NewDefaultConfig() },
+			data:         func(body []byte) []byte { return append(append([]byte{}, body...), body...) },
+			expectedCode: problem.CodeMalformedBody,
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				m := metadataStub.NewNullObject()
+				value := annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, m)
+				body := marshal(t, codec.NewJSON(), value)
+				sut := newSUT(m, cases[i].config(body))
+
+				result, failure := sut.Unmarshal(cases[i].data(body))
+
+				if cases[i].expectedCode == "" {
+					assert.Nil(t, failure)
+					assert.Equal(t, body, marshal(t, codec.NewJSON(), result))
+					return
+				}
+				assert.Nil(t, result)
+				assert.Equal(t, cases[i].expectedCode, failure.Code)
+			},
+		)
+	}
+}
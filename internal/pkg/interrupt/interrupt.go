@@ -28,7 +28,7 @@ func TranslateToCancel(ctx context.Context, cancel context.CancelFunc, wg *sync.
 	go func() {
 		defer wg.Done()
 
-		signalStream := make(chan os.Signal)
+		signalStream := make(chan os.Signal, 1)
 		defer func() {
 			signal.Stop(signalStream)
 			close(signalStream)
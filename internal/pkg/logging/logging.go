@@ -0,0 +1,221 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Level orders entries by severity; entries below the configured level are dropped.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// names are the configured names of the levels, in order.
+var names = []string{"debug", "info", "warn", "error"}
+
+// String returns the level's name.
+func (l Level) String() string {
+	if l < LevelDebug || int(l) >= len(names) {
+		return strconv.Itoa(int(l))
+	}
+	return names[l]
+}
+
+// parse returns the level named name.
+func parse(name string) (Level, bool) {
+	for index := range names {
+		if names[index] == name {
+			return Level(index), true
+		}
+	}
+	return 0, false
+}
+
+// Config selects the lowest Level logged (debug, info, warn or error) and the Format entries are written in (json or
+// text).  Sample maps the names or path templates of high-volume routes to N, logging only one of every N requests
+// to the route that succeed; failed requests are always logged.
+type Config struct {
+	Level  string         `json:"level"`
+	Format string         `json:"format"`
+	Sample map[string]int `json:"sample"`
+}
+
+// NewDefaultConfig returns the default configuration: every request is logged as JSON.
+func NewDefaultConfig() Config {
+	return Config{
+		Level:  "info",
+		Format: FormatJSON,
+		Sample: map[string]int{},
+	}
+}
+
+// Validate returns an error if the configuration is not usable.
+func (c Config) Validate() error {
+	if _, ok := parse(c.Level); !ok {
+		return fmt.Errorf("unknown level %q", c.Level)
+	}
+	if c.Format != FormatJSON && c.Format != FormatText {
+		return fmt.Errorf("unknown format %q", c.Format)
+	}
+	for route, n := range c.Sample {
+		if n < 1 {
+			return fmt.Errorf("sample %s: must be at least 1", route)
+		}
+	}
+	return nil
+}
+
+// Field is a named value in a log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	m       sync.Mutex
+	w       io.Writer
+	level   Level
+	format  string
+	samples map[string]*sample
+	clock   func() time.Time
+}
+
+// New is a factory function that returns instance writing entries allowed by config to w.
+func New(config Config, w io.Writer) (*instance, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	level, _ := parse(config.Level)
+	samples := make(map[string]*sample, len(config.Sample))
+	for route, n := range config.Sample {
+		samples[route] = &sample{every: uint64(n)}
+	}
+	return &instance{
+		w:       w,
+		level:   level,
+		format:  config.Format,
+		samples: samples,
+		clock:   time.Now,
+	}, nil
+}
+
+// SetClock provides for method injection of the clock entries are timed by; the default is time.Now.
+func (i *instance) SetClock(clock func() time.Time) {
+	i.clock = clock
+}
+
+// Enabled returns whether entries at level are logged.
+func (i *instance) Enabled(level Level) bool {
+	return level >= i.level
+}
+
+// Log writes an entry at level with message and fields, if the level is enabled; write failures are dropped.
+func (i *instance) Log(level Level, message string, fields ...Field) {
+	if !i.Enabled(level) {
+		return
+	}
+
+	all := append(
+		[]Field{
+			{Key: "time", Value: i.clock().UTC().Format(time.RFC3339Nano)},
+			{Key: "level", Value: level.String()},
+			{Key: "msg", Value: message},
+		},
+		fields...,
+	)
+	var buffer bytes.Buffer
+	if i.format == FormatText {
+		writeText(&buffer, all)
+	} else {
+		writeJSON(&buffer, all)
+	}
+	buffer.WriteByte('\n')
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	_, _ = i.w.Write(buffer.Bytes())
+}
+
+// writeJSON writes fields to buffer as a JSON object, keeping their order.
+func writeJSON(buffer *bytes.Buffer, fields []Field) {
+	buffer.WriteByte('{')
+	for index, field := range fields {
+		if index > 0 {
+			buffer.WriteByte(',')
+		}
+		key, _ := json.Marshal(field.Key)
+		buffer.Write(key)
+		buffer.WriteByte(':')
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			value, _ = json.Marshal(err.Error())
+		}
+		buffer.Write(value)
+	}
+	buffer.WriteByte('}')
+}
+
+// writeText writes fields to buffer as space-separated key=value pairs, quoting values that need it.
+func writeText(buffer *bytes.Buffer, fields []Field) {
+	for index, field := range fields {
+		if index > 0 {
+			buffer.WriteByte(' ')
+		}
+		buffer.WriteString(field.Key)
+		buffer.WriteByte('=')
+		value := fmt.Sprint(field.Value)
+		if value == "" || strings.ContainsAny(value, " =\"\t\r\n") {
+			value = strconv.Quote(value)
+		}
+		buffer.WriteString(value)
+	}
+}
+
+// sample counts the requests to a sampled route.
+type sample struct {
+	m     sync.Mutex
+	every uint64
+	count uint64
+}
+
+// next returns whether the next request is one of the sample.
+func (s *sample) next() bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.count++
+	return s.count%s.every == 1 || s.every == 1
+}
@@ -0,0 +1,141 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package logging
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// at is the time entries are logged at in tests.
+var at = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// newSUT returns a logger configured by config that writes to the returned buffer at a fixed time.
+func newSUT(t *testing.T, config Config) (*instance, *bytes.Buffer) {
+	var buffer bytes.Buffer
+	sut, err := New(config, &buffer)
+	if err != nil {
+		assert.FailNow(t, "Unexpected New failure:", err.Error())
+	}
+	sut.SetClock(func() time.Time { return at })
+	return sut, &buffer
+}
+
+// TestConfig tests configuration validation.
+func TestConfig(t *testing.T) {
+	type testCase struct {
+		name   string
+		config func() Config
+		valid  bool
+	}
+
+	cases := []testCase{
+		{name: "default", config: NewDefaultConfig, valid: true},
+		{
+			name: "text",
+			config: func() Config {
+				c := NewDefaultConfig()
+				c.Format = FormatText
+				c.Level = "debug"
+				c.Sample["find"] = 10
+				return c
+			},
+			valid: true,
+		},
+		{
+			name: "unknown level",
+			config: func() Config {
+				c := NewDefaultConfig()
+				c.Level = "verbose"
+				return c
+			},
+		},
+		{
+			name: "unknown format",
+			config: func() Config {
+				c := NewDefaultConfig()
+				c.Format = "xml"
+				return c
+			},
+		},
+		{
+			name: "zero sample",
+			config: func() Config {
+				c := NewDefaultConfig()
+				c.Sample["find"] = 0
+				return c
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				err := cases[i].config().Validate()
+
+				assert.Equal(t, cases[i].valid, err == nil)
+			},
+		)
+	}
+}
+
+// TestInstance_Log tests writing entries in each format and dropping those below the configured level.
+func TestInstance_Log(t *testing.T) {
+	type testCase struct {
+		name     string
+		config   Config
+		level    Level
+		expected string
+	}
+
+	fields := []Field{{Key: "status", Value: 201}, {Key: "identity", Value: "a b"}, {Key: "route", Value: ""}}
+	cases := []testCase{
+		{
+			name:   "json",
+			config: Config{Level: "info", Format: FormatJSON},
+			level:  LevelInfo,
+			expected: `{"time":"2020-01-02T03:04:05Z","level":"info","msg":"request","status":201,"identity":"a b",` +
+				`"route":""}` + "\n",
+		},
+		{
+			name:     "text",
+			config:   Config{Level: "info", Format: FormatText},
+			level:    LevelWarn,
+			expected: `time=2020-01-02T03:04:05Z level=warn msg=request status=201 identity="a b" route=""` + "\n",
+		},
+		{
+			name:   "below level",
+			config: Config{Level: "warn", Format: FormatJSON},
+			level:  LevelInfo,
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				sut, buffer := newSUT(t, cases[i].config)
+
+				sut.Log(cases[i].level, "request", fields...)
+
+				assert.Equal(t, cases[i].expected, buffer.String())
+			},
+		)
+	}
+}
@@ -0,0 +1,153 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package logging
+
+import (
+	"context"
+	"net/http"
+
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/recorder"
+	"github.com/project-alvarium/go-store/pkg/http/header"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	RequestIDHeader = header.RequestID
+
+	// maxRequestID is the length of the longest request ID accepted from a client.
+	maxRequestID = 128
+)
+
+// requestIDKey holds a request's ID in its context.
+type requestIDKey struct{}
+
+// subjectKey holds, in a request's context, the cell in which the request's authenticated subject is recorded.
+type subjectKey struct{}
+
+// SetSubject records subject as the authenticated caller of the request ctx belongs to, so that it is logged with the
+// request once it is served.
+func SetSubject(ctx context.Context, subject string) {
+	if cell, ok := ctx.Value(subjectKey{}).(*string); ok {
+		*cell = subject
+	}
+}
+
+// RequestID returns the ID of the request ctx belongs to, or an empty string if it has none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Init installs the logging middleware on muxRouter; it should be installed first, so that every request is given an
+// ID before it is served.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.Use(i.Middleware)
+}
+
+// Middleware gives each request the ID in its X-Request-ID header or, if it has none, a new one, which is returned in
+// the response's header and carried in the request's context; once served, the request's method, route template,
+// identity, authenticated subject (if any), status code, response size and duration are logged, at warn for client
+// errors and error for server errors.
+func (i *instance) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !valid(id) {
+				id = header.NewRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			var subject string
+			ctx := context.WithValue(context.WithValue(r.Context(), requestIDKey{}, id), subjectKey{}, &subject)
+			started := i.clock()
+			recorded := recorder.New(w)
+			next.ServeHTTP(recorded, r.WithContext(ctx))
+			elapsed := i.clock().Sub(started)
+
+			level := LevelInfo
+			switch {
+			case recorded.Code() >= http.StatusInternalServerError:
+				level = LevelError
+			case recorded.Code() >= http.StatusBadRequest:
+				level = LevelWarn
+			}
+			name, template := route(r)
+			if !i.Enabled(level) || (level == LevelInfo && !i.sampled(name, template)) {
+				return
+			}
+			fields := []Field{
+				{Key: "requestId", Value: id},
+				{Key: "method", Value: r.Method},
+				{Key: "route", Value: template},
+				{Key: "identity", Value: identity(r)},
+			}
+			if subject != "" {
+				fields = append(fields, Field{Key: "subject", Value: subject})
+			}
+			fields = append(
+				fields,
+				Field{Key: "status", Value: recorded.Code()},
+				Field{Key: "bytes", Value: recorded.Bytes()},
+				Field{Key: "duration", Value: float64(elapsed.Microseconds()) / 1000},
+			)
+			i.Log(level, "request", fields...)
+		},
+	)
+}
+
+// sampled returns whether a successful request to the route named name with template is logged.
+func (i *instance) sampled(name, template string) bool {
+	if s, exists := i.samples[name]; exists && name != "" {
+		return s.next()
+	}
+	if s, exists := i.samples[template]; exists {
+		return s.next()
+	}
+	return true
+}
+
+// valid returns whether id, sent by a client, may be used as a request ID.
+func valid(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// route returns the name and path template of r's route, or r's path if it has none.
+func route(r *http.Request) (string, string) {
+	current := mux.CurrentRoute(r)
+	if current == nil {
+		return "", r.URL.Path
+	}
+	template, err := current.GetPathTemplate()
+	if err != nil {
+		template = r.URL.Path
+	}
+	return current.GetName(), template
+}
+
+// identity returns the identity r acts on, or an empty string if its route carries none.
+func identity(r *http.Request) string {
+	id, _ := urlIdentity.FromRequest(r)
+	return id
+}
@@ -0,0 +1,215 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// routes is a routable serving a named route that echoes the request ID in its body, an unnamed route that answers
+// with the status code in its path and a route whose caller is authenticated as the subject in its path.
+func routes(muxRouter *mux.Router) {
+	// as the service does, so that identities may contain escaped slashes.
+	muxRouter.UseEncodedPath()
+	muxRouter.HandleFunc(
+		"/find/{identity}",
+		func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte(RequestID(r.Context()))) },
+	).Name("find")
+	muxRouter.HandleFunc(
+		"/status/{code:[0-9]+}",
+		func(w http.ResponseWriter, r *http.Request) {
+			code, _ := strconv.Atoi(mux.Vars(r)["code"])
+			w.WriteHeader(code)
+		},
+	)
+	muxRouter.HandleFunc(
+		"/subject/{subject}",
+		func(w http.ResponseWriter, r *http.Request) { SetSubject(r.Context(), mux.Vars(r)["subject"]) },
+	)
+}
+
+// entries returns the JSON entries logged in output.
+func entries(t *testing.T, output string) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &entry))
+		result = append(result, entry)
+	}
+	return result
+}
+
+// TestMiddleware tests giving requests IDs and logging them.
+func TestMiddleware(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	newRouter := func(t *testing.T, config Config) (*mux.Router, func() []map[string]interface{}, func()) {
+		sut, buffer := newSUT(t, config)
+		cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, []routable.Contract{sut.Init, routes})
+		return muxRouter, func() []map[string]interface{} { return entries(t, buffer.String()) }, func() {
+			cancel()
+			wg.Wait()
+		}
+	}
+
+	cases := []testCase{
+		{
+			name: "request is logged with a new request ID",
+			test: func(t *testing.T) {
+				muxRouter, logged, stop := newRouter(t, NewDefaultConfig())
+				defer stop()
+
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/find/a%2Fb")
+
+				id := response.Header().Get(RequestIDHeader)
+				assert.Len(t, id, 32)
+				assert.Equal(t, id, response.Body.String())
+				assert.Equal(
+					t,
+					[]map[string]interface{}{
+						{
+							"time":      "2020-01-02T03:04:05Z",
+							"level":     "info",
+							"msg":       "request",
+							"requestId": id,
+							"method":    http.MethodGet,
+							"route":     "/find/{identity}",
+							"identity":  "a/b",
+							"status":    float64(http.StatusOK),
+							"bytes":     float64(32),
+							"duration":  float64(0),
+						},
+					},
+					logged(),
+				)
+			},
+		},
+		{
+			name: "authenticated subject is logged",
+			test: func(t *testing.T) {
+				muxRouter, logged, stop := newRouter(t, NewDefaultConfig())
+				defer stop()
+
+				testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPost, "/subject/caller")
+				testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/find/a")
+
+				entries := logged()
+				assert.Len(t, entries, 2)
+				assert.Equal(t, "caller", entries[0]["subject"])
+				assert.NotContains(t, entries[1], "subject")
+			},
+		},
+		{
+			name: "client's request ID is kept",
+			test: func(t *testing.T) {
+				muxRouter, logged, stop := newRouter(t, NewDefaultConfig())
+				defer stop()
+				header := http.Header{}
+				header.Set(RequestIDHeader, "client-id")
+
+				response := testInternal.SendRequestWithHeader(t, muxRouter, http.MethodGet, "/find/a", header, nil)
+
+				assert.Equal(t, "client-id", response.Header().Get(RequestIDHeader))
+				assert.Equal(t, "client-id", response.Body.String())
+				assert.Equal(t, "client-id", logged()[0]["requestId"])
+			},
+		},
+		{
+			name: "unusable request ID is replaced",
+			test: func(t *testing.T) {
+				muxRouter, _, stop := newRouter(t, NewDefaultConfig())
+				defer stop()
+				header := http.Header{}
+				header.Set(RequestIDHeader, strings.Repeat("x", maxRequestID+1))
+
+				response := testInternal.SendRequestWithHeader(t, muxRouter, http.MethodGet, "/find/a", header, nil)
+
+				assert.Len(t, response.Header().Get(RequestIDHeader), 32)
+			},
+		},
+		{
+			name: "failures are logged at warn and error",
+			test: func(t *testing.T) {
+				muxRouter, logged, stop := newRouter(t, NewDefaultConfig())
+				defer stop()
+
+				testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/status/404")
+				testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/status/503")
+
+				entries := logged()
+				assert.Len(t, entries, 2)
+				assert.Equal(t, "warn", entries[0]["level"])
+				assert.Equal(t, "/status/{code:[0-9]+}", entries[0]["route"])
+				assert.Equal(t, "error", entries[1]["level"])
+			},
+		},
+		{
+			name: "level drops successful requests",
+			test: func(t *testing.T) {
+				config := NewDefaultConfig()
+				config.Level = "warn"
+				muxRouter, logged, stop := newRouter(t, config)
+				defer stop()
+
+				testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/find/a")
+				testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/status/400")
+
+				entries := logged()
+				assert.Len(t, entries, 1)
+				assert.Equal(t, float64(http.StatusBadRequest), entries[0]["status"])
+			},
+		},
+		{
+			name: "sampled routes log one of every N successful requests",
+			test: func(t *testing.T) {
+				config := NewDefaultConfig()
+				config.Sample["find"] = 3
+				config.Sample["/status/{code:[0-9]+}"] = 3
+				muxRouter, logged, stop := newRouter(t, config)
+				defer stop()
+
+				for n := 0; n < 7; n++ {
+					testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/find/a")
+				}
+				testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/status/200")
+				testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/status/200")
+				testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/status/500")
+
+				assert.Len(t, logged(), 5)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
@@ -16,31 +16,40 @@ package pkg
 
 import (
 	"context"
+	"crypto/tls"
 	"sync"
 
 	"github.com/project-alvarium/go-store/internal/pkg/interrupt"
 	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	"github.com/project-alvarium/go-store/internal/pkg/runnable"
 	"github.com/project-alvarium/go-store/internal/pkg/server"
 
 	"github.com/gorilla/mux"
 )
 
-// Run is the internal main entry point.
+// Run is the internal main entry point.  Runnables are started with ctx and wg; when serverAddress is nil, the caller
+// is responsible for cancelling ctx and waiting on wg.  The server speaks HTTPS if tlsConfig is not nil.
 func Run(
 	ctx context.Context,
 	cancel context.CancelFunc,
+	wg *sync.WaitGroup,
 	muxRouter *mux.Router,
 	routables []routable.Contract,
-	serverAddress *string) {
+	runnables []runnable.Contract,
+	serverAddress *string,
+	tlsConfig *tls.Config) {
 
 	for key := range routables {
 		routables[key](muxRouter)
 	}
 
+	for key := range runnables {
+		runnables[key](ctx, wg)
+	}
+
 	if serverAddress != nil {
-		var wg sync.WaitGroup
-		interrupt.TranslateToCancel(ctx, cancel, &wg)
-		server.Serve(ctx, muxRouter, &wg, *serverAddress)
+		interrupt.TranslateToCancel(ctx, cancel, wg)
+		server.Serve(ctx, muxRouter, wg, *serverAddress, tlsConfig)
 		wg.Wait()
 	}
 }
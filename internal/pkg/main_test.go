@@ -15,14 +15,21 @@
 package pkg
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/ingest"
+	"github.com/project-alvarium/go-store/internal/pkg/metadata/registry"
 	"github.com/project-alvarium/go-store/internal/pkg/routable"
 	"github.com/project-alvarium/go-store/internal/pkg/routes/append"
 	"github.com/project-alvarium/go-store/internal/pkg/routes/create"
 	"github.com/project-alvarium/go-store/internal/pkg/routes/find"
 	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
 
 	"github.com/project-alvarium/go-sdk/pkg/annotation"
 	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
@@ -32,7 +39,9 @@ import (
 	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
 	assessMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata/factory"
 	pkiMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata/factory"
+	publishMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata"
 	publishMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata/factory"
+	publisherMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/publisher/example/metadata"
 	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
 	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
 	"github.com/project-alvarium/go-sdk/pkg/status"
@@ -42,6 +51,8 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+const identityLength = 32
+
 // TestAcceptance implements multi-route acceptance tests.
 func TestAcceptance(t *testing.T) {
 	type testCase struct {
@@ -84,8 +95,8 @@ func TestAcceptance(t *testing.T) {
 					testInternal.Marshal(t, value),
 				)
 
-				assert.Equal(t, create.CodeSuccess, response.Code)
-				assert.Equal(t, testInternal.Marshal(t, status.Exists), response.Body.Bytes())
+				assert.Equal(t, problem.ErrIdentityExists.Status, response.Code)
+				assert.Equal(t, problem.ContentType, response.Header().Get("Content-Type"))
 			},
 		},
 		{
@@ -102,8 +113,8 @@ func TestAcceptance(t *testing.T) {
 					testInternal.Marshal(t, value),
 				)
 
-				assert.Equal(t, append.CodeSuccess, response.Code)
-				assert.Equal(t, testInternal.Marshal(t, status.NotFound), response.Body.Bytes())
+				assert.Equal(t, problem.ErrIdentityNotFound.Status, response.Code)
+				assert.Equal(t, problem.ContentType, response.Header().Get("Content-Type"))
 			},
 		},
 		{
@@ -196,13 +207,13 @@ func TestAcceptance(t *testing.T) {
 				publishMetadataFactory.NewDefault(),
 			},
 		)
-		iFactory := identityFactory.New()
+		decoder := ingest.New(mFactory, identityFactory.New(), ingest.NewDefaultConfig())
 		cancel, wg, muxRouter := testInternal.NewSUT(
 			Run,
 			[]routable.Contract{
-				append.New(s, mFactory, iFactory).Init,
-				create.New(s, mFactory, iFactory).Init,
-				find.New(s).Init,
+				append.New(s, decoder, false).Init,
+				create.New(s, decoder, false).Init,
+				find.New(s, false).Init,
 			},
 		)
 		t.Run(
@@ -215,3 +226,162 @@ func TestAcceptance(t *testing.T) {
 		)
 	}
 }
+
+// TestMetadataRegistry implements acceptance tests of the metadata kinds the service is configured to accept.
+func TestMetadataRegistry(t *testing.T) {
+	const metadata = `{"z":1,"a":[1.50,"x"],"n":12345678901234567890}`
+
+	// body returns an annotation about id carrying metadata of kind encoded as data.
+	body := func(id *hash.Identity, kind, data string) []byte {
+		return []byte(
+			fmt.Sprintf(
+				`{"unique":%q,"created":"","identityCurrentType":%q,"identityCurrent":%s,`+
+					`"identityPreviousType":%q,"identityPrevious":null,"metadataType":%q,"metadata":%s}`,
+				ulid.New().Get(),
+				hash.Kind,
+				testInternal.Marshal(t, id),
+				hash.Kind,
+				kind,
+				data,
+			),
+		)
+	}
+
+	type testCase struct {
+		name   string
+		config registry.Config
+		test   func(t *testing.T, muxRouter *mux.Router)
+	}
+
+	cases := []testCase{
+		{
+			name:   "Passthrough returns unknown kinds verbatim",
+			config: registry.Config{Passthrough: true},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				id := hash.New(test.FactoryRandomFixedLengthAlphanumericByteSlice(identityLength))
+				posted := body(id, test.FactoryRandomFixedLengthAlphanumericString(identityLength), metadata)
+
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					create.V1Method,
+					create.EscapedV1Route(url.New(id.Printable())),
+					posted,
+				)
+				assert.Equal(t, create.CodeSuccess, response.Code)
+
+				response = testInternal.SendRequestWithoutBody(t, muxRouter, find.V1Method, find.EscapedV1Route(id))
+
+				assert.Equal(t, find.CodeSuccess, response.Code)
+				assert.Equal(t, "["+string(posted)+"]", response.Body.String())
+			},
+		},
+		{
+			name:   "Passthrough in binary formats",
+			config: registry.Config{Passthrough: true},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				id := hash.New(test.FactoryRandomFixedLengthAlphanumericByteSlice(identityLength))
+				posted := body(id, test.FactoryRandomFixedLengthAlphanumericString(identityLength), `{"a":[1.5,"x"]}`)
+				assert.Equal(
+					t,
+					create.CodeSuccess,
+					testInternal.SendRequestWithBody(
+						t,
+						muxRouter,
+						create.V1Method,
+						create.EscapedV1Route(url.New(id.Printable())),
+						posted,
+					).Code,
+				)
+
+				response := testInternal.SendRequestWithHeader(
+					t,
+					muxRouter,
+					find.V1Method,
+					find.EscapedV1Route(id),
+					http.Header{codec.AcceptHeader: []string{codec.ContentTypeCBOR}},
+					[]byte{},
+				)
+
+				assert.Equal(t, find.CodeSuccess, response.Code)
+				result, err := codec.NewCBOR().ToJSON(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.JSONEq(t, "["+string(posted)+"]", string(result))
+			},
+		},
+		{
+			name:   "Unknown kinds are discarded without passthrough",
+			config: registry.NewDefaultConfig(),
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				id := hash.New(test.FactoryRandomFixedLengthAlphanumericByteSlice(identityLength))
+				posted := body(id, test.FactoryRandomFixedLengthAlphanumericString(identityLength), metadata)
+				assert.Equal(
+					t,
+					create.CodeSuccess,
+					testInternal.SendRequestWithBody(
+						t,
+						muxRouter,
+						create.V1Method,
+						create.EscapedV1Route(url.New(id.Printable())),
+						posted,
+					).Code,
+				)
+
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, find.V1Method, find.EscapedV1Route(id))
+
+				assert.Equal(t, find.CodeSuccess, response.Code)
+				assert.True(t, strings.HasSuffix(response.Body.String(), `"metadata":null}]`))
+			},
+		},
+		{
+			name:   "Disabled kinds are rejected",
+			config: registry.Config{Kinds: map[string]bool{publishMetadata.Kind: false}, Passthrough: true},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				id := hash.New(test.FactoryRandomFixedLengthAlphanumericByteSlice(identityLength))
+				value := annotation.New(ulid.New().Get(), id, nil, publishMetadata.New(nil, publisherMetadata.NewSuccess()))
+
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					create.V1Method,
+					create.EscapedV1Route(url.New(id.Printable())),
+					testInternal.Marshal(t, value),
+				)
+
+				assert.Equal(t, problem.ErrInvalidAnnotation.Status, response.Code)
+				result, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(
+					t,
+					[]problem.InvalidParam{{Name: "metadataType", Reason: "is a disabled metadata kind"}},
+					result.InvalidParams,
+				)
+			},
+		},
+	}
+
+	for i := range cases {
+		s := memory.New()
+		mFactory, err := registry.New(cases[i].config)
+		if err != nil {
+			assert.FailNow(t, "Unexpected registry.New failure:", err.Error())
+		}
+		decoder := ingest.New(mFactory, identityFactory.New(), ingest.NewDefaultConfig())
+		decoder.SetKinds(mFactory)
+		cancel, wg, muxRouter := testInternal.NewSUT(
+			Run,
+			[]routable.Contract{
+				create.New(s, decoder, false).Init,
+				find.New(s, false).Init,
+			},
+		)
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				cases[i].test(t, muxRouter)
+				cancel()
+				wg.Wait()
+			},
+		)
+	}
+}
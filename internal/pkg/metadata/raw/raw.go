@@ -0,0 +1,65 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package raw
+
+import (
+	"encoding/json"
+
+	ugorji "github.com/ugorji/go/codec"
+)
+
+// Instance is metadata of a kind the service does not understand, kept as the JSON it arrived as.
+type Instance struct {
+	kind string
+	data json.RawMessage
+}
+
+// New is a factory function that returns an initialized Instance of kind containing data.
+func New(kind string, data json.RawMessage) *Instance {
+	return &Instance{
+		kind: kind,
+		data: append(json.RawMessage(nil), data...),
+	}
+}
+
+// Kind returns the type of concrete implementation.
+func (i *Instance) Kind() string {
+	return i.kind
+}
+
+// MarshalJSON returns the metadata as it arrived; encoding/json removes insignificant whitespace from the result.
+func (i *Instance) MarshalJSON() ([]byte, error) {
+	return i.data, nil
+}
+
+// CodecEncodeSelf encodes the metadata in a binary format; JSON numbers become floating point values.
+func (i *Instance) CodecEncodeSelf(e *ugorji.Encoder) {
+	var value interface{}
+	if err := json.Unmarshal(i.data, &value); err != nil {
+		panic(err)
+	}
+	e.MustEncode(value)
+}
+
+// CodecDecodeSelf decodes metadata encoded in a binary format.
+func (i *Instance) CodecDecodeSelf(d *ugorji.Decoder) {
+	var value interface{}
+	d.MustDecode(&value)
+	data, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	i.data = data
+}
@@ -0,0 +1,66 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package raw
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInstance_Kind tests Kind.
+func TestInstance_Kind(t *testing.T) {
+	kind := test.FactoryRandomString()
+	sut := New(kind, json.RawMessage("{}"))
+
+	assert.Equal(t, kind, sut.Kind())
+}
+
+// TestInstance_MarshalJSON tests that metadata is returned as it arrived.
+func TestInstance_MarshalJSON(t *testing.T) {
+	data := []byte(`{"z":1,"a":[1.50,"x"],"n":12345678901234567890}`)
+	sut := New(test.FactoryRandomString(), data)
+	data[1] = '!'
+
+	result, err := json.Marshal(map[string]interface{}{"metadata": sut})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `{"metadata":{"z":1,"a":[1.50,"x"],"n":12345678901234567890}}`, string(result))
+}
+
+// TestInstance_CodecEncodeSelf tests that metadata survives binary formats.
+func TestInstance_CodecEncodeSelf(t *testing.T) {
+	sut := New(test.FactoryRandomString(), json.RawMessage(`{"a":[1.5,"x"],"b":{"c":true}}`))
+
+	for _, format := range []codec.Contract{codec.NewCBOR(), codec.NewMsgPack()} {
+		t.Run(
+			format.ContentType(),
+			func(t *testing.T) {
+				encoded, err := format.Marshal(map[string]interface{}{"metadata": sut})
+				assert.Nil(t, err)
+
+				result, err := format.ToJSON(encoded)
+
+				assert.Nil(t, err)
+				assert.JSONEq(t, `{"metadata":{"a":[1.5,"x"],"b":{"c":true}}}`, string(result))
+			},
+		)
+	}
+}
@@ -0,0 +1,102 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/project-alvarium/go-store/internal/pkg/metadata/raw"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation/metadata"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	assessMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata"
+	assessMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata/factory"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
+	pkiMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata/factory"
+	publishMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata"
+	publishMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata/factory"
+)
+
+// builtins returns the factories of the metadata kinds the service understands, keyed by kind.
+func builtins() map[string]metadataFactory.Contract {
+	return map[string]metadataFactory.Contract{
+		assessMetadata.Kind:  assessMetadataFactory.NewDefault(),
+		pkiMetadata.Kind:     pkiMetadataFactory.NewDefault(),
+		publishMetadata.Kind: publishMetadataFactory.NewDefault(),
+	}
+}
+
+// Config selects the metadata kinds the service accepts.  Kinds enables or disables each built-in kind; kinds it
+// does not mention are enabled.  Passthrough keeps metadata of kinds the service does not understand as the
+// JSON it arrived as instead of discarding it; it does not apply to disabled kinds.
+type Config struct {
+	Kinds       map[string]bool `json:"kinds"`
+	Passthrough bool            `json:"passthrough"`
+}
+
+// NewDefaultConfig is a factory function that returns the default configuration, which enables every built-in kind.
+func NewDefaultConfig() Config {
+	return Config{
+		Kinds: map[string]bool{},
+	}
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	factories   map[string]metadataFactory.Contract
+	disabled    map[string]bool
+	passthrough bool
+}
+
+// New is a factory function that returns instance, or an error if config names a kind that is not built in.
+func New(config Config) (*instance, error) {
+	available := builtins()
+	i := &instance{
+		factories:   make(map[string]metadataFactory.Contract),
+		disabled:    make(map[string]bool),
+		passthrough: config.Passthrough,
+	}
+	for kind := range config.Kinds {
+		if _, ok := available[kind]; !ok {
+			return nil, fmt.Errorf("unknown metadata kind %q", kind)
+		}
+	}
+	for kind, factory := range available {
+		if enabled, ok := config.Kinds[kind]; ok && !enabled {
+			i.disabled[kind] = true
+			continue
+		}
+		i.factories[kind] = factory
+	}
+	return i, nil
+}
+
+// Create returns a contract implementation based on the provided metadata; it returns nil for disabled kinds, for
+// data that is not valid metadata of an enabled kind, and, unless passthrough is configured, for unknown kinds.
+func (i *instance) Create(kind string, data json.RawMessage) metadata.Contract {
+	if factory, ok := i.factories[kind]; ok {
+		return factory.Create(kind, data)
+	}
+	if i.disabled[kind] || !i.passthrough || len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	return raw.New(kind, data)
+}
+
+// Disabled returns whether kind is a built-in kind that has been disabled.
+func (i *instance) Disabled(kind string) bool {
+	return i.disabled[kind]
+}
@@ -0,0 +1,138 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package registry
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg/metadata/raw"
+
+	assessorMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/assessor/pki/metadata"
+	assessMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
+	publishMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata"
+	publisherMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/publisher/example/metadata"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSUT returns a new system under test.
+func newSUT(t *testing.T, config Config) *instance {
+	sut, err := New(config)
+	if err != nil {
+		assert.FailNow(t, "Unexpected New failure:", err.Error())
+	}
+	return sut
+}
+
+// marshal returns value marshalled to JSON.
+func marshal(t *testing.T, value interface{}) json.RawMessage {
+	result, err := json.Marshal(value)
+	if err != nil {
+		assert.FailNow(t, "Unexpected marshal failure:", err.Error())
+	}
+	return result
+}
+
+// TestNew tests New's handling of configured kinds.
+func TestNew(t *testing.T) {
+	_, err := New(Config{Kinds: map[string]bool{test.FactoryRandomFixedLengthAlphanumericString(16): true}})
+	assert.NotNil(t, err)
+
+	sut := newSUT(t, Config{})
+	for kind := range builtins() {
+		assert.Contains(t, sut.factories, kind)
+		assert.False(t, sut.Disabled(kind))
+	}
+}
+
+// TestInstance_Create tests Create.
+func TestInstance_Create(t *testing.T) {
+	assess := assessMetadata.New(nil, assessorMetadata.NewSuccess(true, []string{test.FactoryRandomString()}))
+	publish := publishMetadata.New(nil, publisherMetadata.NewSuccess())
+	unknown := test.FactoryRandomFixedLengthAlphanumericString(16)
+	data := json.RawMessage(`{"z":1,"a":[1.50,"x"],"n":12345678901234567890}`)
+
+	type testCase struct {
+		name   string
+		config Config
+		test   func(t *testing.T, sut *instance)
+	}
+
+	cases := []testCase{
+		{
+			name:   "default enables built-in kinds",
+			config: NewDefaultConfig(),
+			test: func(t *testing.T, sut *instance) {
+				result := sut.Create(assessMetadata.Kind, marshal(t, assess))
+
+				assert.Equal(t, marshal(t, assess), marshal(t, result))
+			},
+		},
+		{
+			name:   "default discards unknown kinds",
+			config: NewDefaultConfig(),
+			test: func(t *testing.T, sut *instance) {
+				assert.Nil(t, sut.Create(unknown, data))
+			},
+		},
+		{
+			name:   "disabled kind",
+			config: Config{Kinds: map[string]bool{publishMetadata.Kind: false}, Passthrough: true},
+			test: func(t *testing.T, sut *instance) {
+				assert.Nil(t, sut.Create(publishMetadata.Kind, marshal(t, publish)))
+				assert.True(t, sut.Disabled(publishMetadata.Kind))
+				assert.False(t, sut.Disabled(pkiMetadata.Kind))
+				assert.NotNil(t, sut.Create(assessMetadata.Kind, marshal(t, assess)))
+			},
+		},
+		{
+			name:   "passthrough keeps unknown kinds",
+			config: Config{Passthrough: true},
+			test: func(t *testing.T, sut *instance) {
+				result := sut.Create(unknown, data)
+
+				assert.IsType(t, &raw.Instance{}, result)
+				assert.Equal(t, unknown, result.Kind())
+				assert.Equal(t, data, marshal(t, result))
+			},
+		},
+		{
+			name:   "passthrough discards null metadata",
+			config: Config{Passthrough: true},
+			test: func(t *testing.T, sut *instance) {
+				assert.Nil(t, sut.Create(unknown, json.RawMessage("null")))
+			},
+		},
+		{
+			name:   "passthrough does not apply to invalid metadata of known kinds",
+			config: Config{Passthrough: true},
+			test: func(t *testing.T, sut *instance) {
+				assert.Nil(t, sut.Create(publishMetadata.Kind, json.RawMessage(`{"publisherType":1}`)))
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				cases[i].test(t, newSUT(t, cases[i].config))
+			},
+		)
+	}
+}
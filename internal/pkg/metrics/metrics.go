@@ -0,0 +1,182 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/recorder"
+
+	"github.com/gorilla/mux"
+)
+
+// Config enables the metrics; they are served on the service's own listener unless Address names a separate admin
+// listener.
+type Config struct {
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address"`
+}
+
+// Contract defines the metrics abstraction.
+type Contract interface {
+	// Write writes the current value of every metric to w in the Prometheus text exposition format.
+	Write(w io.Writer) error
+}
+
+// invalidName matches the characters that may not appear in a metric name.
+var invalidName = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// routeKey holds, in a request context, the route template a measured request is reported under.
+type routeKey struct{}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	m          sync.Mutex
+	clock      func() time.Time
+	requests   *counter
+	latency    *histogram
+	operations *counter
+	durations  *histogram
+	stores     map[string]*store
+}
+
+// New is a factory function that returns instance.
+func New() *instance {
+	return &instance{
+		clock: time.Now,
+		requests: newCounter(
+			"http_requests_total",
+			"HTTP requests served, by method, route template and status code.",
+			"method", "route", "status",
+		),
+		latency: newHistogram(
+			"http_request_duration_seconds",
+			"Time taken to serve HTTP requests, by method and route template.",
+			"method", "route",
+		),
+		operations: newCounter(
+			"store_operations_total",
+			"Store operations, by tenant, operation and result.",
+			"tenant", "operation", "result",
+		),
+		durations: newHistogram(
+			"store_operation_duration_seconds",
+			"Time taken by store operations, by tenant and operation.",
+			"tenant", "operation",
+		),
+		stores: make(map[string]*store),
+	}
+}
+
+// SetClock provides for method injection of the clock operations are timed by; the default is time.Now.
+func (i *instance) SetClock(clock func() time.Time) {
+	i.clock = clock
+}
+
+// Init installs the metrics middleware on muxRouter.  It may be installed on several routers that serve the same
+// request, which is only counted once, under the route template of the innermost router.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.Use(i.Middleware)
+}
+
+// Middleware counts and times requests by method, route template and status code.
+func (i *instance) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			template := r.URL.Path
+			if route := mux.CurrentRoute(r); route != nil {
+				if t, err := route.GetPathTemplate(); err == nil {
+					template = t
+				}
+			}
+			if reported, ok := r.Context().Value(routeKey{}).(*string); ok {
+				*reported = template
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			started := i.clock()
+			recorded := recorder.New(w)
+			next.ServeHTTP(recorded, r.WithContext(context.WithValue(r.Context(), routeKey{}, &template)))
+			i.requests.add(1, r.Method, template, strconv.Itoa(recorded.Code()))
+			i.latency.observe(i.clock().Sub(started).Seconds(), r.Method, template)
+		},
+	)
+}
+
+// Forget stops reporting the store of tenant.
+func (i *instance) Forget(tenant string) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	delete(i.stores, tenant)
+}
+
+// Write writes the current value of every metric to w in the Prometheus text exposition format; the gauges of the
+// stores' statistics are collected as they are written.
+func (i *instance) Write(w io.Writer) error {
+	return writeFamilies(w, append([]family{i.requests, i.latency, i.operations, i.durations}, i.gauges()...))
+}
+
+// gauges returns a gauge, labelled by tenant, for each statistic reported by a store.
+func (i *instance) gauges() []family {
+	i.m.Lock()
+	tenants := make([]string, 0, len(i.stores))
+	for tenant := range i.stores {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	stores := make([]*store, len(tenants))
+	for index := range tenants {
+		stores[index] = i.stores[tenants[index]]
+	}
+	i.m.Unlock()
+
+	byName := make(map[string]*gauge)
+	for index := range stores {
+		reporter, ok := stores[index].store.(Stats)
+		if !ok {
+			continue
+		}
+		for stat, value := range reporter.Stats() {
+			name := "store_" + invalidName.ReplaceAllString(stat, "_")
+			g, exists := byName[name]
+			if !exists {
+				g = &gauge{name: name, help: "Store statistic " + stat + ", by tenant.", labels: []string{"tenant"}}
+				byName[name] = g
+			}
+			g.samples = append(g.samples, []string{tenants[index]})
+			g.values = append(g.values, value)
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]family, len(names))
+	for index := range names {
+		result[index] = byName[names[index]]
+	}
+	return result
+}
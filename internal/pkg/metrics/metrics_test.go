@@ -0,0 +1,169 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSUT returns metrics whose clock moves forward by step each time it is read.
+func newSUT(step time.Duration) *instance {
+	sut := New()
+	now := time.Now()
+	sut.SetClock(
+		func() time.Time {
+			now = now.Add(step)
+			return now
+		},
+	)
+	return sut
+}
+
+// exposed returns the lines of sut's exposition that begin with prefix.
+func exposed(t *testing.T, sut *instance, prefix string) []string {
+	var buffer bytes.Buffer
+	assert.Nil(t, sut.Write(&buffer))
+
+	result := make([]string, 0)
+	for _, line := range strings.Split(buffer.String(), "\n") {
+		if strings.HasPrefix(line, prefix) {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// TestMiddleware tests counting and timing requests.
+func TestMiddleware(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusCreated) }
+
+	cases := []testCase{
+		{
+			name: "requests are counted by method, route template and status",
+			test: func(t *testing.T) {
+				sut := newSUT(20 * time.Millisecond)
+				cancel, wg, muxRouter := testInternal.NewSUT(
+					pkg.Run,
+					[]routable.Contract{
+						sut.Init,
+						func(muxRouter *mux.Router) {
+							muxRouter.HandleFunc("/v1/identities/{identity}", ok).Methods(http.MethodPut)
+						},
+					},
+				)
+				defer func() {
+					cancel()
+					wg.Wait()
+				}()
+
+				testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/v1/identities/a")
+				testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/v1/identities/b")
+
+				assert.Equal(
+					t,
+					[]string{`http_requests_total{method="PUT",route="/v1/identities/{identity}",status="201"} 2`},
+					exposed(t, sut, "http_requests_total{"),
+				)
+				assert.Equal(
+					t,
+					[]string{
+						`http_request_duration_seconds_bucket{method="PUT",route="/v1/identities/{identity}",le="0.01"} 0`,
+						`http_request_duration_seconds_bucket{method="PUT",route="/v1/identities/{identity}",le="0.025"} 2`,
+					},
+					exposed(t, sut, "http_request_duration_seconds_bucket")[2:4],
+				)
+				assert.Equal(
+					t,
+					[]string{
+						`http_request_duration_seconds_sum{method="PUT",route="/v1/identities/{identity}"} 0.04`,
+						`http_request_duration_seconds_count{method="PUT",route="/v1/identities/{identity}"} 2`,
+					},
+					append(
+						exposed(t, sut, "http_request_duration_seconds_sum"),
+						exposed(t, sut, "http_request_duration_seconds_count")...,
+					),
+				)
+			},
+		},
+		{
+			name: "nested routers count a request once under the innermost route",
+			test: func(t *testing.T) {
+				sut := newSUT(time.Millisecond)
+				inner := mux.NewRouter()
+				sut.Init(inner)
+				inner.HandleFunc("/t/{tenant}/v1/identities/{identity}", ok)
+				cancel, wg, muxRouter := testInternal.NewSUT(
+					pkg.Run,
+					[]routable.Contract{
+						sut.Init,
+						func(muxRouter *mux.Router) { muxRouter.PathPrefix("/t/{tenant}").Handler(inner) },
+					},
+				)
+				defer func() {
+					cancel()
+					wg.Wait()
+				}()
+
+				testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPost, "/t/a/v1/identities/b")
+
+				assert.Equal(
+					t,
+					[]string{`http_requests_total{method="POST",route="/t/{tenant}/v1/identities/{identity}",status="201"} 1`},
+					exposed(t, sut, "http_requests_total{"),
+				)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
+
+// TestWrite tests the exposition format.
+func TestWrite(t *testing.T) {
+	c := newCounter("c_total", "A counter.", "label")
+	c.add(1, `quote " slash \ line`+"\n")
+	c.add(2.5, "b")
+	g := &gauge{name: "g", help: "A gauge.", samples: [][]string{nil}, values: []float64{3}}
+	var buffer bytes.Buffer
+
+	assert.Nil(t, writeFamilies(&buffer, []family{c, g}))
+
+	assert.Equal(
+		t,
+		"# HELP c_total A counter.\n# TYPE c_total counter\n"+
+			`c_total{label="b"} 2.5`+"\n"+
+			`c_total{label="quote \" slash \\ line\n"} 1`+"\n"+
+			"# HELP g A gauge.\n# TYPE g gauge\ng 3\n",
+		buffer.String(),
+	)
+}
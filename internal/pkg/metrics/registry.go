@@ -0,0 +1,225 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// buckets are the upper bounds, in seconds, of the latency histograms' buckets.
+var buckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// family is a named metric whose samples are written in the text exposition format.
+type family interface {
+	write(w *bufio.Writer)
+}
+
+// series holds the values of a family's samples keyed by their label values.
+type series struct {
+	m      sync.Mutex
+	name   string
+	help   string
+	labels []string
+	values map[string][]string
+}
+
+// newSeries returns series for the family name described by help with labels.
+func newSeries(name, help string, labels []string) series {
+	return series{name: name, help: help, labels: labels, values: make(map[string][]string)}
+}
+
+// key returns the key of the sample with values, remembering them; it must be called with the lock held.
+func (s *series) key(values []string) string {
+	key := strings.Join(values, "\xff")
+	if _, exists := s.values[key]; !exists {
+		s.values[key] = append([]string(nil), values...)
+	}
+	return key
+}
+
+// keys returns the keys of the samples, ordered; it must be called with the lock held.
+func (s *series) keys() []string {
+	result := make([]string, 0, len(s.values))
+	for key := range s.values {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// header writes the family's HELP and TYPE lines.
+func header(w *bufio.Writer, name, help, kind string) {
+	_, _ = w.WriteString("# HELP " + name + " " + help + "\n# TYPE " + name + " " + kind + "\n")
+}
+
+// sample writes a sample of name with labels set to values, followed by the extra label name and value pairs, and
+// value.
+func sample(w *bufio.Writer, name string, labels, values []string, extra []string, value float64) {
+	pairs := make([]string, 0, 2*len(labels)+len(extra))
+	for index := range labels {
+		pairs = append(pairs, labels[index], values[index])
+	}
+	pairs = append(pairs, extra...)
+
+	_, _ = w.WriteString(name)
+	if len(pairs) > 0 {
+		_ = w.WriteByte('{')
+		for index := 0; index+1 < len(pairs); index += 2 {
+			if index > 0 {
+				_ = w.WriteByte(',')
+			}
+			_, _ = w.WriteString(pairs[index] + `="` + escape(pairs[index+1]) + `"`)
+		}
+		_ = w.WriteByte('}')
+	}
+	_, _ = w.WriteString(" " + format(value) + "\n")
+}
+
+// escape returns value escaped for use as a label value.
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// format returns value as the exposition format writes it.
+func format(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// counter is a family of monotonically increasing values.
+type counter struct {
+	series
+	counts map[string]float64
+}
+
+// newCounter returns a counter named name described by help with labels.
+func newCounter(name, help string, labels ...string) *counter {
+	return &counter{series: newSeries(name, help, labels), counts: make(map[string]float64)}
+}
+
+// add adds delta to the sample with values.
+func (c *counter) add(delta float64, values ...string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.counts[c.key(values)] += delta
+}
+
+// write writes the counter's samples.
+func (c *counter) write(w *bufio.Writer) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	header(w, c.name, c.help, "counter")
+	for _, key := range c.keys() {
+		sample(w, c.name, c.labels, c.values[key], nil, c.counts[key])
+	}
+}
+
+// observations are the bucket counts, sum and count of a histogram's sample.
+type observations struct {
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+// histogram is a family of distributions of observed values.
+type histogram struct {
+	series
+	observed map[string]*observations
+}
+
+// newHistogram returns a histogram named name described by help with labels.
+func newHistogram(name, help string, labels ...string) *histogram {
+	return &histogram{series: newSeries(name, help, labels), observed: make(map[string]*observations)}
+}
+
+// observe adds value to the sample with values.
+func (h *histogram) observe(value float64, values ...string) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	key := h.key(values)
+	o, exists := h.observed[key]
+	if !exists {
+		o = &observations{buckets: make([]uint64, len(buckets))}
+		h.observed[key] = o
+	}
+	for index := range buckets {
+		if value <= buckets[index] {
+			o.buckets[index]++
+		}
+	}
+	o.sum += value
+	o.count++
+}
+
+// write writes the histogram's samples.
+func (h *histogram) write(w *bufio.Writer) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	header(w, h.name, h.help, "histogram")
+	for _, key := range h.keys() {
+		o := h.observed[key]
+		for index := range buckets {
+			extra := []string{"le", format(buckets[index])}
+			sample(w, h.name+"_bucket", h.labels, h.values[key], extra, float64(o.buckets[index]))
+		}
+		sample(w, h.name+"_bucket", h.labels, h.values[key], []string{"le", "+Inf"}, float64(o.count))
+		sample(w, h.name+"_sum", h.labels, h.values[key], nil, o.sum)
+		sample(w, h.name+"_count", h.labels, h.values[key], nil, float64(o.count))
+	}
+}
+
+// gauge is a family of values collected when written.
+type gauge struct {
+	name    string
+	help    string
+	labels  []string
+	samples [][]string
+	values  []float64
+}
+
+// write writes the gauge's samples.
+func (g *gauge) write(w *bufio.Writer) {
+	header(w, g.name, g.help, "gauge")
+	for index := range g.samples {
+		sample(w, g.name, g.labels, g.samples[index], nil, g.values[index])
+	}
+}
+
+// writeFamilies writes families to w in the text exposition format.
+func writeFamilies(w io.Writer, families []family) error {
+	buffered := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(buffered)
+	}
+	return buffered.Flush()
+}
@@ -0,0 +1,111 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	sdkStore "github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+// results maps store results to the values of the result label.
+var results = map[status.Value]string{
+	status.Success:        "success",
+	status.Exists:         "exists",
+	status.NotFound:       "notFound",
+	status.PublisherError: "publisherError",
+	status.Unknown:        "unknown",
+}
+
+// Stats is implemented by stores that report backend-specific statistics, such as the number of identities and
+// annotations they hold; each is exported as a gauge named store_ followed by the statistic's name.
+type Stats interface {
+	// Stats returns the store's statistics by name.
+	Stats() map[string]float64
+}
+
+// walker is implemented by stores that can be walked to rebuild indexes.
+type walker interface {
+	Walk(fn func(id identity.Contract, annotations []*annotation.Instance))
+}
+
+// store is a receiver that encapsulates required dependencies.
+type store struct {
+	metrics *instance
+	tenant  string
+	store   sdkStore.Contract
+}
+
+// Store returns a decorator of s, the store of tenant (empty for the service's own store), that counts and times its
+// operations by result; s's statistics are reported, if it has any, until tenant is forgotten or given another store.
+// The decorator walks s if s can be walked.
+func (i *instance) Store(tenant string, s sdkStore.Contract) *store {
+	decorator := &store{metrics: i, tenant: tenant, store: s}
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.stores[tenant] = decorator
+	return decorator
+}
+
+// measure counts and times an operation started at the given time with result.
+func (s *store) measure(operation string, started time.Time, result status.Value) status.Value {
+	name, known := results[result]
+	if !known {
+		name = results[status.Unknown]
+	}
+	s.metrics.operations.add(1, s.tenant, operation, name)
+	s.metrics.durations.observe(s.metrics.clock().Sub(started).Seconds(), s.tenant, operation)
+	return result
+}
+
+// FindByIdentity returns annotations and status corresponding to identity.
+func (s *store) FindByIdentity(id identity.Contract) ([]*annotation.Instance, status.Value) {
+	started := s.metrics.clock()
+	annotations, result := s.store.FindByIdentity(id)
+	return annotations, s.measure("find", started, result)
+}
+
+// Create stores annotations corresponding to a new identity and returns status.
+func (s *store) Create(id identity.Contract, m *annotation.Instance) status.Value {
+	started := s.metrics.clock()
+	return s.measure("create", started, s.store.Create(id, m))
+}
+
+// Append stores annotations corresponding to identity and returns status.
+func (s *store) Append(id identity.Contract, m *annotation.Instance) status.Value {
+	started := s.metrics.clock()
+	return s.measure("append", started, s.store.Append(id, m))
+}
+
+// Walk calls fn with each identity and the annotations stored directly against it, if the decorated store can be
+// walked.
+func (s *store) Walk(fn func(id identity.Contract, annotations []*annotation.Instance)) {
+	if w, ok := s.store.(walker); ok {
+		w.Walk(fn)
+	}
+}
+
+// Stats returns the decorated store's statistics, if it reports any.
+func (s *store) Stats() map[string]float64 {
+	if reporter, ok := s.store.(Stats); ok {
+		return reporter.Stats()
+	}
+	return nil
+}
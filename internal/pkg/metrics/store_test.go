@@ -0,0 +1,132 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	sdkMemory "github.com/project-alvarium/go-sdk/pkg/annotation/store/memory"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAnnotation returns a new annotation for id.
+func newAnnotation(id identity.Contract) *annotation.Instance {
+	return annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+}
+
+// TestInstance_Store tests counting store operations and reporting store statistics.
+func TestInstance_Store(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "operations are counted by result",
+			test: func(t *testing.T) {
+				sut := newSUT(time.Millisecond)
+				s := sut.Store("acme", memory.New())
+				id := hash.New(test.FactoryRandomByteSlice())
+
+				assert.Equal(t, status.NotFound, s.Append(id, newAnnotation(id)))
+				assert.Equal(t, status.Success, s.Create(id, newAnnotation(id)))
+				assert.Equal(t, status.Exists, s.Create(id, newAnnotation(id)))
+				assert.Equal(t, status.Success, s.Append(id, newAnnotation(id)))
+				annotations, result := s.FindByIdentity(id)
+
+				assert.Equal(t, status.Success, result)
+				assert.Len(t, annotations, 2)
+				assert.Equal(
+					t,
+					[]string{
+						`store_operations_total{tenant="acme",operation="append",result="notFound"} 1`,
+						`store_operations_total{tenant="acme",operation="append",result="success"} 1`,
+						`store_operations_total{tenant="acme",operation="create",result="exists"} 1`,
+						`store_operations_total{tenant="acme",operation="create",result="success"} 1`,
+						`store_operations_total{tenant="acme",operation="find",result="success"} 1`,
+					},
+					exposed(t, sut, "store_operations_total{"),
+				)
+				assert.Equal(
+					t,
+					[]string{`store_operation_duration_seconds_count{tenant="acme",operation="create"} 2`},
+					exposed(t, sut, `store_operation_duration_seconds_count{tenant="acme",operation="create"}`),
+				)
+			},
+		},
+		{
+			name: "statistics are reported by tenant until forgotten",
+			test: func(t *testing.T) {
+				sut := newSUT(time.Millisecond)
+				shared := sut.Store("", memory.New())
+				tenant := sut.Store("acme", memory.New())
+				sut.Store("other", sdkMemory.New())
+				first := hash.New(test.FactoryRandomByteSlice())
+				second := hash.New(test.FactoryRandomByteSlice())
+				shared.Create(first, newAnnotation(first))
+				shared.Append(first, newAnnotation(first))
+				tenant.Create(second, newAnnotation(second))
+
+				stats := func() []string {
+					return append(exposed(t, sut, "store_annotations{"), exposed(t, sut, "store_identities{")...)
+				}
+
+				assert.Equal(
+					t,
+					[]string{
+						`store_annotations{tenant=""} 2`,
+						`store_annotations{tenant="acme"} 1`,
+						`store_identities{tenant=""} 1`,
+						`store_identities{tenant="acme"} 1`,
+					},
+					stats(),
+				)
+				sut.Forget("acme")
+				assert.Equal(t, []string{`store_annotations{tenant=""} 2`, `store_identities{tenant=""} 1`}, stats())
+			},
+		},
+		{
+			name: "walks the decorated store",
+			test: func(t *testing.T) {
+				sut := newSUT(time.Millisecond)
+				s := sut.Store("", memory.New())
+				id := hash.New(test.FactoryRandomByteSlice())
+				s.Create(id, newAnnotation(id))
+
+				walked := 0
+				s.Walk(func(identity.Contract, []*annotation.Instance) { walked++ })
+				sut.Store("", sdkMemory.New()).Walk(func(identity.Contract, []*annotation.Instance) { walked++ })
+
+				assert.Equal(t, 1, walked)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
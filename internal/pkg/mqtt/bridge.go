@@ -0,0 +1,207 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/audit"
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/ingest"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+const (
+	OperationCreate = "create"
+	OperationAppend = "append"
+)
+
+// Topic maps a topic filter to the store operation applied to the annotations published to it.
+type Topic struct {
+	Filter    string `json:"filter"`
+	Operation string `json:"operation"`
+}
+
+// Config defines the bridge's configuration.  An empty Broker.URL disables the bridge; an empty OutputTopic disables
+// republishing.  Messages carry no principal, so the bridge writes to the default namespace without authentication,
+// authorization or tenant checks; access to the topics must be controlled by the broker.
+type Config struct {
+	Broker      BrokerConfig `json:"broker"`
+	Topics      []Topic      `json:"topics"`
+	OutputTopic string       `json:"outputTopic"`
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	client  Client
+	store   store.Contract
+	notify  notify.Contract
+	decoder ingest.Contract
+	config  Config
+	audit   audit.Contract
+}
+
+// New is a factory function that returns instance; instance writes annotations received from client, decoded and
+// validated by decoder, to store and, when config.OutputTopic is set, republishes every stored annotation reported by
+// notify.
+func New(
+	client Client,
+	store store.Contract,
+	notify notify.Contract,
+	decoder ingest.Contract,
+	config Config) *instance {
+
+	return &instance{
+		client:  client,
+		store:   store,
+		notify:  notify,
+		decoder: decoder,
+		config:  config,
+		audit:   audit.NewDiscard(),
+	}
+}
+
+// SetAudit provides for method injection of the audit log each received annotation is recorded in; by default, they
+// are not recorded.
+func (i *instance) SetAudit(log audit.Contract) {
+	i.audit = log
+}
+
+// Subscribe subscribes to the configured topics.
+func (i *instance) Subscribe() error {
+	for key := range i.config.Topics {
+		topic := i.config.Topics[key]
+		if err := i.client.Subscribe(topic.Filter, i.handler(topic.Operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run starts republishing and is this package's runnable; the configured topics are unsubscribed when ctx is done.
+func (i *instance) Run(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		<-ctx.Done()
+		for key := range i.config.Topics {
+			_ = i.client.Unsubscribe(i.config.Topics[key].Filter)
+		}
+	}()
+
+	if i.config.OutputTopic != "" {
+		i.republish(ctx, wg)
+	}
+}
+
+// handler returns a handler that applies operation to each received annotation that passes validation and records
+// each message in the audit log.  A message is acknowledged once its outcome is final: it is stored, rejected as
+// invalid, or refused because its identity exists or is not found.  A store failure leaves it for the broker to
+// redeliver.
+func (i *instance) handler(operation string) Handler {
+	return func(message Message) {
+		started := time.Now()
+		event := audit.NewEvent(context.Background(), started, operation, message.Topic())
+		result, final := i.apply(operation, message, &event)
+		event.Outcome = audit.OutcomeSuccess
+		event.Latency = audit.Latency(started, time.Now())
+		if result != status.Success {
+			event.Outcome = audit.OutcomeFailure
+		}
+		i.audit.Record(event)
+
+		if final {
+			message.Ack()
+		}
+	}
+}
+
+// apply applies operation to message's annotation if it passes validation, noting in event the identity and Unique of
+// the annotation and why it was rejected, and returns the result and whether redelivering message could not change it.
+func (i *instance) apply(operation string, message Message, event *audit.Event) (status.Value, bool) {
+	value, failure := i.decoder.Unmarshal(message.Payload())
+	if failure != nil {
+		event.Reason = failure.Error()
+		return status.Unknown, true
+	}
+	if value.CurrentIdentity == nil {
+		event.Reason = "annotation carries no identity"
+		return status.Unknown, true
+	}
+
+	id := urlIdentity.New(value.CurrentIdentity.Printable())
+	event.Resource = id.Printable()
+	event.Unique = value.Unique
+	if failure := i.decoder.Validate(id, value); failure != nil {
+		event.Reason = failure.Error()
+		return status.Unknown, true
+	}
+
+	var result status.Value
+	switch operation {
+	case OperationCreate:
+		result = i.store.Create(id, value)
+	case OperationAppend:
+		result = i.store.Append(id, value)
+	default:
+		event.Reason = "unknown operation " + operation
+		return status.Unknown, true
+	}
+	switch result {
+	case status.Success, status.Exists, status.NotFound:
+		return result, true
+	}
+	return result, false
+}
+
+// republish publishes every stored annotation to the output topic until ctx is done.
+func (i *instance) republish(ctx context.Context, wg *sync.WaitGroup) {
+	filter := notify.Filter{Prefix: true}
+	subscription, _ := i.notify.Subscribe(filter, 0)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		var last uint64
+		for {
+			event, ok := subscription.Next(ctx)
+			if ok {
+				last = event.Sequence
+				if body, err := json.Marshal(event); err == nil {
+					_ = i.client.Publish(i.config.OutputTopic, body)
+				}
+				continue
+			}
+
+			subscription.Close()
+			if ctx.Err() != nil {
+				return
+			}
+			var err error
+			if subscription, err = i.notify.Subscribe(filter, last); err != nil {
+				// events written since last are no longer retained; carry on with new events.
+				subscription, _ = i.notify.Subscribe(filter, 0)
+			}
+		}
+	}()
+}
@@ -0,0 +1,340 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/audit"
+	auditStub "github.com/project-alvarium/go-store/internal/pkg/audit/stub"
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/ingest"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	metadataStubFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub/factory"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	createTopic = "devices/+/create"
+	appendTopic = "devices/+/append"
+	outputTopic = "store/annotations"
+)
+
+// newAnnotation returns a new annotation carrying m.
+func newAnnotation(m *metadataStub.Instance) *annotation.Instance {
+	return annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, m)
+}
+
+// failingStore is a store whose writes always fail.
+type failingStore struct {
+	store.Contract
+}
+
+// Create fails.
+func (failingStore) Create(_ identity.Contract, _ *annotation.Instance) status.Value {
+	return status.Unknown
+}
+
+// flakyStore is a store whose first failures creates fail.
+type flakyStore struct {
+	store.Contract
+	failures int
+}
+
+// Create fails until failures is exhausted.
+func (f *flakyStore) Create(id identity.Contract, value *annotation.Instance) status.Value {
+	if f.failures > 0 {
+		f.failures--
+		return status.Unknown
+	}
+	return f.Contract.Create(id, value)
+}
+
+// newSUT returns a running bridge connected to a new broker.
+func newSUT(
+	t *testing.T,
+	s store.Contract,
+	m *metadataStub.Instance,
+	outputTopic string) (*broker, context.CancelFunc, *sync.WaitGroup) {
+
+	b := NewBroker()
+	n := notify.New(s, 0)
+	sut := New(
+		b,
+		n,
+		n,
+		ingest.New(
+			metadataFactory.New([]metadataFactory.Contract{metadataStubFactory.New(m)}),
+			identityFactory.New(),
+			ingest.NewDefaultConfig(),
+		),
+		Config{
+			Topics: []Topic{
+				{Filter: createTopic, Operation: OperationCreate},
+				{Filter: appendTopic, Operation: OperationAppend},
+			},
+			OutputTopic: outputTopic,
+		},
+	)
+	if err := sut.Subscribe(); err != nil {
+		assert.FailNow(t, "Unexpected Subscribe failure:", err.Error())
+	}
+
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	sut.Run(ctx, &wg)
+	return b, cancel, &wg
+}
+
+// TestBridge tests the MQTT bridge.
+func TestBridge(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "acknowledges after store write",
+			test: func(t *testing.T) {
+				s := memory.New()
+				m := metadataStub.NewNullObject()
+				b, cancel, wg := newSUT(t, s, m, "")
+				defer wg.Wait()
+				defer cancel()
+				first := newAnnotation(m)
+				second := annotation.New(ulid.New().Get(), first.CurrentIdentity, nil, m)
+
+				assert.Nil(t, b.Publish("devices/a/create", testInternal.Marshal(t, first)))
+				assert.Nil(t, b.Publish("devices/a/append", testInternal.Marshal(t, second)))
+
+				assert.Equal(t, 0, b.Unacknowledged())
+				annotations, result := s.FindByIdentity(url.New(first.CurrentIdentity.Printable()))
+				assert.Equal(t, status.Success, result)
+				assert.Equal(
+					t,
+					testInternal.Marshal(t, []*annotation.Instance{first, second}),
+					testInternal.Marshal(t, annotations),
+				)
+			},
+		},
+		{
+			name: "identity with a slash is stored under its printable form",
+			test: func(t *testing.T) {
+				s := memory.New()
+				m := metadataStub.NewNullObject()
+				b, cancel, wg := newSUT(t, s, m, "")
+				defer wg.Wait()
+				defer cancel()
+				id := hash.New(append([]byte{0xff, 0xff, 0xff}, test.FactoryRandomByteSlice()...))
+				value := annotation.New(ulid.New().Get(), id, nil, m)
+
+				assert.Nil(t, b.Publish("devices/a/create", testInternal.Marshal(t, value)))
+
+				assert.Equal(t, 0, b.Unacknowledged())
+				annotations, result := s.FindByIdentity(url.New(id.Printable()))
+				assert.Equal(t, status.Success, result)
+				assert.Equal(t, testInternal.Marshal(t, []*annotation.Instance{value}), testInternal.Marshal(t, annotations))
+			},
+		},
+		{
+			name: "unmatched topic is ignored",
+			test: func(t *testing.T) {
+				s := memory.New()
+				m := metadataStub.NewNullObject()
+				b, cancel, wg := newSUT(t, s, m, "")
+				defer wg.Wait()
+				defer cancel()
+				value := newAnnotation(m)
+
+				assert.Nil(t, b.Publish("devices/a/b/create", testInternal.Marshal(t, value)))
+
+				assert.Equal(t, 0, b.Unacknowledged())
+				_, result := s.FindByIdentity(url.New(value.CurrentIdentity.Printable()))
+				assert.Equal(t, status.NotFound, result)
+			},
+		},
+		{
+			name: "rejected writes are acknowledged",
+			test: func(t *testing.T) {
+				s := memory.New()
+				m := metadataStub.NewNullObject()
+				b, cancel, wg := newSUT(t, s, m, "")
+				defer wg.Wait()
+				defer cancel()
+				value := newAnnotation(m)
+				existing := newAnnotation(m)
+				assert.Equal(t, status.Success, s.Create(url.New(existing.CurrentIdentity.Printable()), existing))
+
+				assert.Nil(t, b.Publish("devices/a/append", testInternal.Marshal(t, value)))
+				assert.Nil(t, b.Publish("devices/a/create", []byte(test.FactoryRandomString())))
+				assert.Nil(t, b.Publish("devices/a/create", testInternal.Marshal(t, existing)))
+
+				assert.Equal(t, 0, b.Unacknowledged())
+				_, result := s.FindByIdentity(url.New(value.CurrentIdentity.Printable()))
+				assert.Equal(t, status.NotFound, result)
+			},
+		},
+		{
+			name: "store failure is redelivered",
+			test: func(t *testing.T) {
+				s := &flakyStore{Contract: memory.New(), failures: 1}
+				m := metadataStub.NewNullObject()
+				b, cancel, wg := newSUT(t, s, m, "")
+				defer wg.Wait()
+				defer cancel()
+				value := newAnnotation(m)
+
+				assert.Nil(t, b.Publish("devices/a/create", testInternal.Marshal(t, value)))
+				assert.Equal(t, 1, b.Unacknowledged())
+				b.Redeliver()
+
+				assert.Equal(t, 0, b.Unacknowledged())
+				_, result := s.FindByIdentity(url.New(value.CurrentIdentity.Printable()))
+				assert.Equal(t, status.Success, result)
+			},
+		},
+		{
+			name: "store failure is not acknowledged",
+			test: func(t *testing.T) {
+				m := metadataStub.NewNullObject()
+				b, cancel, wg := newSUT(t, failingStore{memory.New()}, m, "")
+				defer wg.Wait()
+				defer cancel()
+
+				assert.Nil(t, b.Publish("devices/a/create", testInternal.Marshal(t, newAnnotation(m))))
+
+				assert.Equal(t, 1, b.Unacknowledged())
+			},
+		},
+		{
+			name: "republishes stored annotations",
+			test: func(t *testing.T) {
+				s := memory.New()
+				m := metadataStub.NewNullObject()
+				b, cancel, wg := newSUT(t, s, m, outputTopic)
+				value := newAnnotation(m)
+
+				assert.Nil(t, b.Publish("devices/a/create", testInternal.Marshal(t, value)))
+				for start := time.Now(); len(b.Published(outputTopic)) == 0 && time.Since(start) < time.Second*5; {
+					time.Sleep(time.Millisecond * 10)
+				}
+				cancel()
+				wg.Wait()
+
+				published := b.Published(outputTopic)
+				assert.Equal(t, 1, len(published))
+				var event struct {
+					Sequence   uint64          `json:"sequence"`
+					Identity   string          `json:"identity"`
+					Annotation json.RawMessage `json:"annotation"`
+				}
+				assert.Nil(t, json.Unmarshal(published[0].Payload, &event))
+				assert.Equal(t, uint64(1), event.Sequence)
+				assert.Equal(t, value.CurrentIdentity.Printable(), event.Identity)
+				assert.Equal(t, testInternal.Marshal(t, value), []byte(event.Annotation))
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
+
+// TestMatches tests topic filter matching.
+func TestMatches(t *testing.T) {
+	type testCase struct {
+		filter   string
+		topic    string
+		expected bool
+	}
+
+	cases := []testCase{
+		{filter: "a/b", topic: "a/b", expected: true},
+		{filter: "a/b", topic: "a/c", expected: false},
+		{filter: "a/+", topic: "a/b", expected: true},
+		{filter: "a/+", topic: "a/b/c", expected: false},
+		{filter: "a/#", topic: "a/b/c", expected: true},
+		{filter: "#", topic: "a", expected: true},
+		{filter: "a/b/c", topic: "a/b", expected: false},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].filter+" "+cases[i].topic,
+			func(t *testing.T) {
+				assert.Equal(t, cases[i].expected, matches(cases[i].filter, cases[i].topic))
+			},
+		)
+	}
+}
+
+// TestBridge_Audit tests that each received message is recorded in the audit log, whatever its outcome.
+func TestBridge_Audit(t *testing.T) {
+	m := metadataStub.NewNullObject()
+	b := NewBroker()
+	n := notify.New(memory.New(), 0)
+	log := auditStub.New()
+	sut := New(
+		b,
+		n,
+		n,
+		ingest.New(
+			metadataFactory.New([]metadataFactory.Contract{metadataStubFactory.New(m)}),
+			identityFactory.New(),
+			ingest.NewDefaultConfig(),
+		),
+		Config{Topics: []Topic{{Filter: createTopic, Operation: OperationCreate}}},
+	)
+	sut.SetAudit(log)
+	if err := sut.Subscribe(); err != nil {
+		assert.FailNow(t, "Unexpected Subscribe failure:", err.Error())
+	}
+	value := newAnnotation(m)
+
+	assert.Nil(t, b.Publish("devices/a/create", testInternal.Marshal(t, value)))
+	assert.Nil(t, b.Publish("devices/a/create", []byte("{")))
+
+	events := log.Events()
+	if !assert.Len(t, events, 2) {
+		return
+	}
+	assert.Equal(t, OperationCreate, events[0].Action)
+	assert.Equal(t, value.CurrentIdentity.Printable(), events[0].Resource)
+	assert.Equal(t, value.Unique, events[0].Unique)
+	assert.Equal(t, audit.OutcomeSuccess, events[0].Outcome)
+	assert.Equal(t, "devices/a/create", events[1].Resource)
+	assert.Equal(t, audit.OutcomeFailure, events[1].Outcome)
+	assert.NotEmpty(t, events[1].Reason)
+}
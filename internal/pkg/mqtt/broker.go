@@ -0,0 +1,174 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package mqtt
+
+import (
+	"strings"
+	"sync"
+)
+
+// Published is a message published to the broker.
+type Published struct {
+	Topic   string
+	Payload []byte
+}
+
+// message is a receiver that encapsulates a delivered message.
+type message struct {
+	broker  *broker
+	id      int
+	topic   string
+	payload []byte
+}
+
+// Topic returns the topic the message was published to.
+func (m *message) Topic() string {
+	return m.topic
+}
+
+// Payload returns the message's content.
+func (m *message) Payload() []byte {
+	return m.payload
+}
+
+// Ack acknowledges the message.
+func (m *message) Ack() {
+	m.broker.m.Lock()
+	defer m.broker.m.Unlock()
+
+	delete(m.broker.unacknowledged, m.id)
+}
+
+// broker is a receiver that stands in for an MQTT broker and the client connected to it, for tests and for running
+// without an external broker.  Publishes are delivered synchronously to matching subscriptions; QoS 1 messages stay
+// unacknowledged until their handler acks them.
+type broker struct {
+	m              sync.Mutex
+	nextID         int
+	subscriptions  map[string]Handler
+	unacknowledged map[int]*message
+	published      []Published
+}
+
+// NewBroker is a factory function that returns an initialized broker.
+func NewBroker() *broker {
+	return &broker{
+		subscriptions:  make(map[string]Handler),
+		unacknowledged: make(map[int]*message),
+	}
+}
+
+// Subscribe delivers messages published to topics matching filter to handler.
+func (b *broker) Subscribe(filter string, handler Handler) error {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	b.subscriptions[filter] = handler
+	return nil
+}
+
+// Unsubscribe stops delivering messages for filter.
+func (b *broker) Unsubscribe(filter string) error {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	delete(b.subscriptions, filter)
+	return nil
+}
+
+// Publish records payload and delivers it to each subscription matching topic.
+func (b *broker) Publish(topic string, payload []byte) error {
+	b.m.Lock()
+	b.published = append(b.published, Published{Topic: topic, Payload: payload})
+	handlers := make([]Handler, 0)
+	messages := make([]*message, 0)
+	for filter := range b.subscriptions {
+		if !matches(filter, topic) {
+			continue
+		}
+		b.nextID++
+		m := &message{broker: b, id: b.nextID, topic: topic, payload: payload}
+		b.unacknowledged[m.id] = m
+		handlers = append(handlers, b.subscriptions[filter])
+		messages = append(messages, m)
+	}
+	b.m.Unlock()
+
+	for key := range handlers {
+		handlers[key](messages[key])
+	}
+	return nil
+}
+
+// Redeliver delivers every unacknowledged message again, as a broker does when a persistent session resumes.
+func (b *broker) Redeliver() {
+	b.m.Lock()
+	handlers := make([]Handler, 0)
+	messages := make([]*message, 0)
+	for id := range b.unacknowledged {
+		m := b.unacknowledged[id]
+		for filter := range b.subscriptions {
+			if matches(filter, m.topic) {
+				handlers = append(handlers, b.subscriptions[filter])
+				messages = append(messages, m)
+				break
+			}
+		}
+	}
+	b.m.Unlock()
+
+	for key := range handlers {
+		handlers[key](messages[key])
+	}
+}
+
+// Unacknowledged returns the number of delivered messages that have not been acknowledged.
+func (b *broker) Unacknowledged() int {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	return len(b.unacknowledged)
+}
+
+// Published returns the messages published to topic.
+func (b *broker) Published(topic string) []Published {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	results := make([]Published, 0)
+	for key := range b.published {
+		if b.published[key].Topic == topic {
+			results = append(results, b.published[key])
+		}
+	}
+	return results
+}
+
+// matches returns true if topic matches filter, honouring the single-level (+) and multi-level (#) wildcards.
+func matches(filter, topic string) bool {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	for key := range filterLevels {
+		switch {
+		case filterLevels[key] == "#":
+			return true
+		case key >= len(topicLevels):
+			return false
+		case filterLevels[key] != "+" && filterLevels[key] != topicLevels[key]:
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
@@ -0,0 +1,43 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package mqtt
+
+// Message defines a message received from the broker.
+type Message interface {
+	// Topic returns the topic the message was published to.
+	Topic() string
+
+	// Payload returns the message's content.
+	Payload() []byte
+
+	// Ack acknowledges a QoS 1 message; an unacknowledged message is redelivered by the broker.
+	Ack()
+}
+
+// Handler processes a received message.
+type Handler func(message Message)
+
+// Client defines the broker connection abstraction.
+type Client interface {
+	// Subscribe delivers messages published to topics matching filter to handler at QoS 1.  Messages are not
+	// acknowledged automatically.
+	Subscribe(filter string, handler Handler) error
+
+	// Unsubscribe stops delivering messages for filter.
+	Unsubscribe(filter string) error
+
+	// Publish sends payload to topic at QoS 1.
+	Publish(topic string, payload []byte) error
+}
@@ -0,0 +1,96 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package mqtt
+
+import (
+	"errors"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	qos            = 1
+	connectTimeout = time.Second * time.Duration(30)
+	disconnectWait = 250
+)
+
+// BrokerConfig defines how to connect to a broker.  Sessions are persistent so that unacknowledged messages are
+// redelivered after a reconnect.
+type BrokerConfig struct {
+	URL      string `json:"url"`
+	ClientID string `json:"clientId"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// pahoClient is a receiver that adapts a paho client to Client.
+type pahoClient struct {
+	client paho.Client
+}
+
+// Connect is a factory function that returns a Client connected to the broker described by config.
+func Connect(config BrokerConfig) (*pahoClient, error) {
+	options := paho.NewClientOptions().
+		AddBroker(config.URL).
+		SetClientID(config.ClientID).
+		SetUsername(config.Username).
+		SetPassword(config.Password).
+		SetCleanSession(false).
+		SetAutoAckDisabled(true).
+		SetOrderMatters(false)
+
+	client := paho.NewClient(options)
+	if err := wait(client.Connect()); err != nil {
+		return nil, err
+	}
+	return &pahoClient{client: client}, nil
+}
+
+// wait blocks until token completes and returns its error.
+func wait(token paho.Token) error {
+	if !token.WaitTimeout(connectTimeout) {
+		return errors.New("timed out waiting for broker")
+	}
+	return token.Error()
+}
+
+// Subscribe delivers messages published to topics matching filter to handler at QoS 1.
+func (p *pahoClient) Subscribe(filter string, handler Handler) error {
+	return wait(
+		p.client.Subscribe(
+			filter,
+			qos,
+			func(_ paho.Client, message paho.Message) {
+				handler(message)
+			},
+		),
+	)
+}
+
+// Unsubscribe stops delivering messages for filter.
+func (p *pahoClient) Unsubscribe(filter string) error {
+	return wait(p.client.Unsubscribe(filter))
+}
+
+// Publish sends payload to topic at QoS 1.
+func (p *pahoClient) Publish(topic string, payload []byte) error {
+	return wait(p.client.Publish(topic, qos, false, payload))
+}
+
+// Disconnect closes the connection after allowing in-flight work to complete.
+func (p *pahoClient) Disconnect() {
+	p.client.Disconnect(disconnectWait)
+}
@@ -0,0 +1,149 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package notify
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+const (
+	DefaultHistory  = 1024
+	subscriberLimit = 256
+)
+
+// ErrExpired is returned by Subscribe when events after the requested sequence are no longer retained.
+var ErrExpired = errors.New("events after sequence are no longer retained")
+
+// Event describes an annotation successfully written to the store.  Sequence increases by one with every write.
+// Sequences and history are held in memory; they start again from one when the service restarts.
+type Event struct {
+	Sequence   uint64               `json:"sequence"`
+	Identity   string               `json:"identity"`
+	Annotation *annotation.Instance `json:"annotation"`
+}
+
+// Contract defines the notification abstraction.
+type Contract interface {
+	// Subscribe returns a subscription to events matching filter.  Retained events with a sequence greater than
+	// after are replayed first; after of zero subscribes to new events only.  ErrExpired is returned when events
+	// following after have been discarded or after was issued before the service restarted.
+	Subscribe(filter Filter, after uint64) (Subscription, error)
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	m           sync.Mutex
+	store       store.Contract
+	sequence    uint64
+	history     []Event
+	historySize int
+	subscribers map[*subscription]struct{}
+}
+
+// New is a factory function that returns instance; instance decorates store and notifies subscribers of every
+// successful write, retaining the last historySize events for resumption.
+func New(store store.Contract, historySize int) *instance {
+	if historySize <= 0 {
+		historySize = DefaultHistory
+	}
+
+	return &instance{
+		m:           sync.Mutex{},
+		store:       store,
+		history:     make([]Event, 0, historySize),
+		historySize: historySize,
+		subscribers: make(map[*subscription]struct{}),
+	}
+}
+
+// publish records and delivers an event for m stored against id.  Callers must hold the lock.
+func (i *instance) publish(id identity.Contract, m *annotation.Instance) {
+	i.sequence++
+	event := Event{Sequence: i.sequence, Identity: id.Printable(), Annotation: m}
+
+	if len(i.history) == i.historySize {
+		copy(i.history, i.history[1:])
+		i.history = i.history[:len(i.history)-1]
+	}
+	i.history = append(i.history, event)
+
+	for s := range i.subscribers {
+		s.deliver(event)
+	}
+}
+
+// FindByIdentity returns annotations and status corresponding to identity.
+func (i *instance) FindByIdentity(id identity.Contract) ([]*annotation.Instance, status.Value) {
+	return i.store.FindByIdentity(id)
+}
+
+// Create stores annotations corresponding to a new identity, notifies subscribers, and returns status.
+func (i *instance) Create(id identity.Contract, m *annotation.Instance) status.Value {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	result := i.store.Create(id, m)
+	if result == status.Success {
+		i.publish(id, m)
+	}
+	return result
+}
+
+// Append stores annotations corresponding to identity, notifies subscribers, and returns status.
+func (i *instance) Append(id identity.Contract, m *annotation.Instance) status.Value {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	result := i.store.Append(id, m)
+	if result == status.Success {
+		i.publish(id, m)
+	}
+	return result
+}
+
+// Subscribe returns a subscription to events matching filter, replaying retained events after the given sequence.
+func (i *instance) Subscribe(filter Filter, after uint64) (Subscription, error) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	if after > i.sequence || (len(i.history) > 0 && after+1 < i.history[0].Sequence) {
+		return nil, ErrExpired
+	}
+
+	s := newSubscription(filter, subscriberLimit+i.historySize, i.unsubscribe)
+	if after > 0 {
+		for key := range i.history {
+			if i.history[key].Sequence > after {
+				s.deliver(i.history[key])
+			}
+		}
+	}
+	i.subscribers[s] = struct{}{}
+	return s, nil
+}
+
+// unsubscribe stops delivering events to s.
+func (i *instance) unsubscribe(s *subscription) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	delete(i.subscribers, s)
+}
@@ -0,0 +1,261 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAnnotation returns a new annotation.
+func newAnnotation() *annotation.Instance {
+	return annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, metadataStub.NewNullObject())
+}
+
+// subscribe returns a subscription from sut, failing the test if it is refused.
+func subscribe(t *testing.T, sut Contract, filter Filter, after uint64) Subscription {
+	s, err := sut.Subscribe(filter, after)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	return s
+}
+
+// next returns the next event from s, failing the test if none arrives promptly.
+func next(t *testing.T, s Subscription) Event {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	event, ok := s.Next(ctx)
+	if !ok {
+		assert.FailNow(t, "expected event")
+	}
+	return event
+}
+
+// assertNoEvent asserts that s has no pending event.
+func assertNoEvent(t *testing.T, s Subscription) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	_, ok := s.Next(ctx)
+	assert.False(t, ok)
+}
+
+// TestInstance_Subscribe tests instance.Subscribe.
+func TestInstance_Subscribe(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "exact identity",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), 0)
+				id := url.New(test.FactoryRandomString())
+				other := url.New(test.FactoryRandomString())
+				s := subscribe(t, sut, Filter{Identity: id.Printable()}, 0)
+				defer s.Close()
+				created := newAnnotation()
+				appended := newAnnotation()
+
+				assert.Equal(t, status.Success, sut.Create(other, newAnnotation()))
+				assert.Equal(t, status.Success, sut.Create(id, created))
+				assert.Equal(t, status.Success, sut.Append(id, appended))
+
+				assert.Equal(t, Event{Sequence: 2, Identity: id.Printable(), Annotation: created}, next(t, s))
+				assert.Equal(t, Event{Sequence: 3, Identity: id.Printable(), Annotation: appended}, next(t, s))
+				assertNoEvent(t, s)
+			},
+		},
+		{
+			name: "prefix",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), 0)
+				prefix := test.FactoryRandomString()
+				s := subscribe(t, sut, Filter{Identity: prefix, Prefix: true}, 0)
+				defer s.Close()
+				value := newAnnotation()
+
+				assert.Equal(t, status.Success, sut.Create(url.New(test.FactoryRandomString()), newAnnotation()))
+				assert.Equal(t, status.Success, sut.Create(url.New(prefix+"/device"), value))
+
+				assert.Equal(t, Event{Sequence: 2, Identity: prefix + "/device", Annotation: value}, next(t, s))
+				assertNoEvent(t, s)
+			},
+		},
+		{
+			name: "permit",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), 0)
+				permitted := test.FactoryRandomString()
+				s := subscribe(t, sut, Filter{Prefix: true, Permit: func(id string) bool { return id == permitted }}, 0)
+				defer s.Close()
+				value := newAnnotation()
+
+				assert.Equal(t, status.Success, sut.Create(url.New(permitted+"/other"), newAnnotation()))
+				assert.Equal(t, status.Success, sut.Create(url.New(permitted), value))
+
+				assert.Equal(t, Event{Sequence: 2, Identity: permitted, Annotation: value}, next(t, s))
+				assertNoEvent(t, s)
+			},
+		},
+		{
+			name: "failed writes are not published",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), 0)
+				id := url.New(test.FactoryRandomString())
+				s := subscribe(t, sut, Filter{Prefix: true}, 0)
+				defer s.Close()
+
+				assert.Equal(t, status.NotFound, sut.Append(id, newAnnotation()))
+
+				assertNoEvent(t, s)
+			},
+		},
+		{
+			name: "resume after sequence",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), 0)
+				id := url.New(test.FactoryRandomString())
+				first := newAnnotation()
+				second := newAnnotation()
+				third := newAnnotation()
+				assert.Equal(t, status.Success, sut.Create(id, first))
+				assert.Equal(t, status.Success, sut.Append(id, second))
+
+				s := subscribe(t, sut, Filter{Identity: id.Printable()}, 1)
+				defer s.Close()
+				assert.Equal(t, status.Success, sut.Append(id, third))
+
+				assert.Equal(t, Event{Sequence: 2, Identity: id.Printable(), Annotation: second}, next(t, s))
+				assert.Equal(t, Event{Sequence: 3, Identity: id.Printable(), Annotation: third}, next(t, s))
+			},
+		},
+		{
+			name: "history is bounded",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), 2)
+				id := url.New(test.FactoryRandomString())
+				assert.Equal(t, status.Success, sut.Create(id, newAnnotation()))
+				assert.Equal(t, status.Success, sut.Append(id, newAnnotation()))
+				assert.Equal(t, status.Success, sut.Append(id, newAnnotation()))
+
+				s := subscribe(t, sut, Filter{Identity: id.Printable()}, 1)
+				defer s.Close()
+
+				assert.Equal(t, uint64(2), next(t, s).Sequence)
+				assert.Equal(t, uint64(3), next(t, s).Sequence)
+				assertNoEvent(t, s)
+			},
+		},
+		{
+			name: "resume before retained history",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), 1)
+				id := url.New(test.FactoryRandomString())
+				assert.Equal(t, status.Success, sut.Create(id, newAnnotation()))
+				assert.Equal(t, status.Success, sut.Append(id, newAnnotation()))
+				assert.Equal(t, status.Success, sut.Append(id, newAnnotation()))
+
+				s, err := sut.Subscribe(Filter{Identity: id.Printable()}, 1)
+
+				assert.Nil(t, s)
+				assert.Equal(t, ErrExpired, err)
+			},
+		},
+		{
+			name: "resume after restart",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), 0)
+				assert.Equal(t, status.Success, sut.Create(url.New(test.FactoryRandomString()), newAnnotation()))
+
+				s, err := sut.Subscribe(Filter{Prefix: true}, 2)
+
+				assert.Nil(t, s)
+				assert.Equal(t, ErrExpired, err)
+			},
+		},
+		{
+			name: "resume from last retained",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), 1)
+				id := url.New(test.FactoryRandomString())
+				assert.Equal(t, status.Success, sut.Create(id, newAnnotation()))
+				assert.Equal(t, status.Success, sut.Append(id, newAnnotation()))
+
+				s := subscribe(t, sut, Filter{Identity: id.Printable()}, 1)
+				defer s.Close()
+
+				assert.Equal(t, uint64(2), next(t, s).Sequence)
+				assertNoEvent(t, s)
+			},
+		},
+		{
+			name: "slow subscriber is ended",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), 1)
+				id := url.New(test.FactoryRandomString())
+				s := subscribe(t, sut, Filter{Identity: id.Printable()}, 0)
+				defer s.Close()
+
+				assert.Equal(t, status.Success, sut.Create(id, newAnnotation()))
+				for n := 0; n < subscriberLimit+1; n++ {
+					assert.Equal(t, status.Success, sut.Append(id, newAnnotation()))
+				}
+
+				for n := 0; n < subscriberLimit+1; n++ {
+					next(t, s)
+				}
+				_, ok := s.Next(context.Background())
+				assert.False(t, ok)
+			},
+		},
+		{
+			name: "closed subscription",
+			test: func(t *testing.T) {
+				sut := New(memory.New(), 0)
+				id := url.New(test.FactoryRandomString())
+				s := subscribe(t, sut, Filter{Identity: id.Printable()}, 0)
+
+				s.Close()
+				assert.Equal(t, status.Success, sut.Create(id, newAnnotation()))
+
+				_, ok := s.Next(context.Background())
+				assert.False(t, ok)
+				assert.Equal(t, 0, len(sut.subscribers))
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
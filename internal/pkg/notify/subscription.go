@@ -0,0 +1,138 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package notify
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Filter selects the identities a subscription receives events for.  An exact filter matches Identity only; a
+// prefix filter matches every identity that begins with Identity, so an empty prefix matches all identities.  If
+// Permit is not nil, only the identities it permits are matched.
+type Filter struct {
+	Identity string
+	Prefix   bool
+	Permit   func(id string) bool
+}
+
+// matches returns true if filter selects id.
+func (f Filter) matches(id string) bool {
+	if f.Permit != nil && !f.Permit(id) {
+		return false
+	}
+	if f.Prefix {
+		return strings.HasPrefix(id, f.Identity)
+	}
+	return id == f.Identity
+}
+
+// Subscription defines a stream of events.
+type Subscription interface {
+	// Next blocks until an event is available or ctx is done; false means the subscription has ended, either
+	// because it was closed or because the subscriber fell too far behind.
+	Next(ctx context.Context) (Event, bool)
+
+	// Close ends the subscription.
+	Close()
+}
+
+// subscription is a receiver that encapsulates a subscriber's pending events.
+type subscription struct {
+	m       sync.Mutex
+	filter  Filter
+	limit   int
+	queue   []Event
+	signal  chan struct{}
+	ended   bool
+	release func(s *subscription)
+}
+
+// newSubscription is a factory function that returns an initialized subscription.
+func newSubscription(filter Filter, limit int, release func(s *subscription)) *subscription {
+	return &subscription{
+		filter:  filter,
+		limit:   limit,
+		queue:   make([]Event, 0),
+		signal:  make(chan struct{}, 1),
+		release: release,
+	}
+}
+
+// deliver queues event if it matches the subscription's filter; a subscriber whose queue is full is ended so that
+// it can resume from its last event instead of silently missing events.
+func (s *subscription) deliver(event Event) {
+	if !s.filter.matches(event.Identity) {
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.ended {
+		return
+	}
+	if len(s.queue) >= s.limit {
+		s.ended = true
+	} else {
+		s.queue = append(s.queue, event)
+	}
+
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+// Next blocks until an event is available or ctx is done; false means the subscription has ended.
+func (s *subscription) Next(ctx context.Context) (Event, bool) {
+	for {
+		s.m.Lock()
+		if len(s.queue) > 0 {
+			event := s.queue[0]
+			s.queue = s.queue[1:]
+			s.m.Unlock()
+			return event, true
+		}
+		ended := s.ended
+		s.m.Unlock()
+
+		if ended {
+			return Event{}, false
+		}
+
+		select {
+		case <-s.signal:
+		case <-ctx.Done():
+			return Event{}, false
+		}
+	}
+}
+
+// Close ends the subscription.
+func (s *subscription) Close() {
+	s.m.Lock()
+	s.ended = true
+	s.queue = nil
+	s.m.Unlock()
+
+	s.release(s)
+
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,128 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	refPrefix       = "#/components/"
+	ContentTypeJSON = "application/json"
+)
+
+//go:embed openapi.json
+var document []byte
+
+// JSON returns the service's OpenAPI document.
+func JSON() []byte {
+	return document
+}
+
+// Document is the subset of an OpenAPI 3 document used to validate requests and responses.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// PathItem maps lower case http methods to the operations of a path.
+type PathItem map[string]*Operation
+
+// Operation describes a single route.
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Parameters  []*Parameter         `json:"parameters"`
+	RequestBody *RequestBody         `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter describes a path, query or header parameter.
+type Parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody describes a request body.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// MediaType describes the body of a single content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the definitions referenced from elsewhere in the document.
+type Components struct {
+	Parameters map[string]*Parameter `json:"parameters"`
+	Schemas    map[string]*Schema    `json:"schemas"`
+}
+
+// Load returns the service's OpenAPI document.
+func Load() (*Document, error) {
+	return Parse(document)
+}
+
+// Parse returns the OpenAPI document contained in data.
+func Parse(data []byte) (*Document, error) {
+	var result Document
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Operation returns the operation of method on the path template path.
+func (d *Document) Operation(path, method string) (*Operation, bool) {
+	item, exists := d.Paths[path]
+	if !exists {
+		return nil, false
+	}
+	operation, exists := item[strings.ToLower(method)]
+	return operation, exists && operation != nil
+}
+
+// parameter resolves p if it is a reference.
+func (d *Document) parameter(p *Parameter) *Parameter {
+	if p.Ref == "" {
+		return p
+	}
+	if resolved, exists := d.Components.Parameters[strings.TrimPrefix(p.Ref, refPrefix+"parameters/")]; exists {
+		return resolved
+	}
+	return p
+}
+
+// schema resolves s if it is a reference.
+func (d *Document) schema(s *Schema) (*Schema, bool) {
+	if s == nil || s.Ref == "" {
+		return s, true
+	}
+	resolved, exists := d.Components.Schemas[strings.TrimPrefix(s.Ref, refPrefix+"schemas/")]
+	return resolved, exists
+}
@@ -0,0 +1,135 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Schema is the subset of an OpenAPI schema object used to validate values.
+type Schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Nullable   bool               `json:"nullable"`
+	Enum       []interface{}      `json:"enum"`
+	Required   []string           `json:"required"`
+	Properties map[string]*Schema `json:"properties"`
+	Items      *Schema            `json:"items"`
+	AllOf      []*Schema          `json:"allOf"`
+}
+
+// ValidateJSON returns an error if data is not JSON that satisfies s.
+func (d *Document) ValidateJSON(s *Schema, data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid JSON: %v", err)
+	}
+	return d.validate(s, value, "$")
+}
+
+// validate returns an error if value, found at path, does not satisfy s.
+func (d *Document) validate(s *Schema, value interface{}, path string) error {
+	s, exists := d.schema(s)
+	if !exists {
+		return fmt.Errorf("%s: unresolved schema reference", path)
+	}
+	if s == nil {
+		return nil
+	}
+
+	if value == nil {
+		if s.Nullable || (s.Type == "" && len(s.AllOf) == 0) {
+			return nil
+		}
+		return fmt.Errorf("%s: must not be null", path)
+	}
+
+	for key := range s.AllOf {
+		if err := d.validate(s.AllOf[key], value, path); err != nil {
+			return err
+		}
+	}
+
+	if err := validateType(s.Type, value, path); err != nil {
+		return err
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for key := range s.Enum {
+			if fmt.Sprint(s.Enum[key]) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, value, s.Enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key := range s.Required {
+			if _, exists := v[s.Required[key]]; !exists {
+				return fmt.Errorf("%s: %s is required", path, s.Required[key])
+			}
+		}
+		for name := range s.Properties {
+			if property, exists := v[name]; exists {
+				if err := d.validate(s.Properties[name], property, path+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for key := range v {
+				if err := d.validate(s.Items, v[key], fmt.Sprintf("%s[%d]", path, key)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateType returns an error if value, found at path, is not of the JSON schema type kind.
+func validateType(kind string, value interface{}, path string) error {
+	var ok bool
+	switch kind {
+	case "":
+		return nil
+	case "object":
+		_, ok = value.(map[string]interface{})
+	case "array":
+		_, ok = value.([]interface{})
+	case "string":
+		_, ok = value.(string)
+	case "boolean":
+		_, ok = value.(bool)
+	case "number":
+		_, ok = value.(float64)
+	case "integer":
+		var number float64
+		number, ok = value.(float64)
+		ok = ok && number == math.Trunc(number)
+	}
+	if !ok {
+		return fmt.Errorf("%s: must be of type %s", path, kind)
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	CodeInvalidRequest  = http.StatusBadRequest
+	CodeInvalidResponse = http.StatusInternalServerError
+	contentTypeStream   = "text/event-stream"
+	suffixJSON          = "+json"
+	statusUpgrade       = "101"
+	statusDefault       = "default"
+)
+
+// Config enables validation of requests and responses against the document.
+type Config struct {
+	ValidateRequests  bool `json:"validateRequests"`
+	ValidateResponses bool `json:"validateResponses"`
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	document *Document
+	config   Config
+}
+
+// New is a factory function that returns instance.
+func New(document *Document, config Config) *instance {
+	return &instance{
+		document: document,
+		config:   config,
+	}
+}
+
+// Init installs the validation middleware on muxRouter if any validation is enabled.
+func (i *instance) Init(muxRouter *mux.Router) {
+	if i.config.ValidateRequests || i.config.ValidateResponses {
+		muxRouter.Use(i.Middleware)
+	}
+}
+
+// Middleware validates requests to, and responses from, documented operations.  Invalid requests are rejected
+// before reaching next; invalid responses are replaced by an error.  Responses of operations that stream or upgrade
+// the connection are not validated.
+func (i *instance) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			route := mux.CurrentRoute(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			template, err := route.GetPathTemplate()
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			operation, exists := i.document.Operation(template, r.Method)
+			if !exists {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if i.config.ValidateRequests {
+				if err := i.validateRequest(operation, r); err != nil {
+					w.WriteHeader(CodeInvalidRequest)
+					_, _ = w.Write([]byte("invalid request: " + err.Error()))
+					return
+				}
+			}
+
+			if !i.config.ValidateResponses || streams(operation) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			recorded := newRecorder()
+			next.ServeHTTP(recorded, r)
+			if err := i.validateResponse(operation, recorded); err != nil {
+				w.WriteHeader(CodeInvalidResponse)
+				_, _ = w.Write([]byte("invalid response: " + err.Error()))
+				return
+			}
+			recorded.copy(w)
+		},
+	)
+}
+
+// streams returns whether operation can stream its response or upgrade the connection.
+func streams(operation *Operation) bool {
+	for code := range operation.Responses {
+		if code == statusUpgrade {
+			return true
+		}
+		if _, exists := operation.Responses[code].Content[contentTypeStream]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRequest returns an error if r does not satisfy operation; r's body is restored for the next handler.
+func (i *instance) validateRequest(operation *Operation, r *http.Request) error {
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+	for key := range operation.Parameters {
+		p := i.document.parameter(operation.Parameters[key])
+		if !p.Required {
+			continue
+		}
+
+		var present bool
+		switch p.In {
+		case "path":
+			_, present = vars[p.Name]
+		case "query":
+			_, present = query[p.Name]
+		case "header":
+			present = r.Header.Get(p.Name) != ""
+		default:
+			present = true
+		}
+		if !present {
+			return fmt.Errorf("%s parameter %s is required", p.In, p.Name)
+		}
+	}
+
+	if operation.RequestBody == nil {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+
+	if len(body) == 0 {
+		if operation.RequestBody.Required {
+			return errors.New("body is required")
+		}
+		return nil
+	}
+	return i.validateMedia(operation.RequestBody.Content, r.Header.Get(codec.ContentTypeHeader), body)
+}
+
+// validateResponse returns an error if recorded does not satisfy operation.
+func (i *instance) validateResponse(operation *Operation, recorded *recorder) error {
+	response, exists := operation.Responses[strconv.Itoa(recorded.code)]
+	if !exists {
+		if response, exists = operation.Responses[statusDefault]; !exists {
+			return fmt.Errorf("status %d is not documented", recorded.code)
+		}
+	}
+
+	return i.validateMedia(response.Content, recorded.header.Get(codec.ContentTypeHeader), recorded.body.Bytes())
+}
+
+// validateMedia returns an error if body, encoded as contentType, does not satisfy the schema content documents for
+// it; bodies in binary formats are transcoded to JSON first, and bodies in other non-JSON formats are not validated.
+func (i *instance) validateMedia(content map[string]MediaType, contentType string, body []byte) error {
+	mediaType := ContentTypeJSON
+	if contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil
+		}
+		mediaType = parsed
+	}
+	media, exists := content[mediaType]
+	if !exists {
+		return nil
+	}
+
+	format, ok := codec.ForContentType(mediaType)
+	switch {
+	case ok:
+		transcoded, err := format.ToJSON(body)
+		if err != nil {
+			return err
+		}
+		body = transcoded
+	case !strings.HasSuffix(mediaType, suffixJSON):
+		return nil
+	}
+	return i.document.ValidateJSON(media.Schema, body)
+}
+
+// recorder buffers a response so that it can be validated before it is written.
+type recorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+// newRecorder is a factory function that returns recorder.
+func newRecorder() *recorder {
+	return &recorder{
+		header: make(http.Header),
+		code:   http.StatusOK,
+	}
+}
+
+// Header returns the response's header.
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+// Write buffers data.
+func (r *recorder) Write(data []byte) (int, error) {
+	return r.body.Write(data)
+}
+
+// WriteHeader records code.
+func (r *recorder) WriteHeader(code int) {
+	r.code = code
+}
+
+// copy writes the buffered response to w.
+func (r *recorder) copy(w http.ResponseWriter) {
+	for key := range r.header {
+		w.Header()[key] = r.header[key]
+	}
+	w.WriteHeader(r.code)
+	_, _ = w.Write(r.body.Bytes())
+}
@@ -0,0 +1,309 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package openapi
+
+import (
+	"crypto"
+	"net/http"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/ingest"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/create"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/find"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
+	pkiMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata/factory"
+	signerMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/signer/signpkcs1v15/metadata"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubRoute registers a create handler that ignores the request and writes code and body.
+func stubRoute(code int, body string) routable.Contract {
+	return func(muxRouter *mux.Router) {
+		muxRouter.HandleFunc(
+			create.Route("{identity}"),
+			func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(code)
+				_, _ = w.Write([]byte(body))
+			},
+		).Methods(create.Method)
+	}
+}
+
+// newAnnotation returns a pki annotation for a new random identity.
+func newAnnotation() *annotation.Instance {
+	return annotation.New(
+		ulid.New().Get(),
+		hash.New(test.FactoryRandomByteSlice()),
+		nil,
+		pkiMetadata.New(
+			nil,
+			test.FactoryRandomByteSlice(),
+			test.FactoryRandomByteSlice(),
+			test.FactoryRandomByteSlice(),
+			signerMetadata.NewSuccess(crypto.SHA256, test.FactoryRandomString()),
+		),
+	)
+}
+
+// TestMiddleware tests Middleware.
+func TestMiddleware(t *testing.T) {
+	type testCase struct {
+		name      string
+		config    Config
+		routables []routable.Contract
+		test      func(t *testing.T, muxRouter *mux.Router)
+	}
+
+	document, err := Load()
+	if err != nil {
+		assert.FailNow(t, "Unexpected Load failure:", err.Error())
+	}
+	both := Config{ValidateRequests: true, ValidateResponses: true}
+	mFactory := metadataFactory.New([]metadataFactory.Contract{pkiMetadataFactory.NewDefault()})
+	decoder := ingest.New(mFactory, identityFactory.New(), ingest.NewDefaultConfig())
+	s := memory.New()
+
+	cases := []testCase{
+		{
+			name:      "valid request and response",
+			config:    both,
+			routables: []routable.Contract{create.New(s, decoder, false).Init, find.New(s, false).Init},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				value := newAnnotation()
+				id := urlIdentity.New(value.CurrentIdentity.Printable())
+
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					create.Method,
+					create.EscapedRoute(id),
+					testInternal.Marshal(t, value),
+				)
+				assert.Equal(t, create.CodeSuccess, response.Code)
+				assert.Equal(t, "0", response.Body.String())
+
+				response = testInternal.SendRequestWithoutBody(t, muxRouter, find.Method, find.EscapedRoute(id))
+				assert.Equal(t, find.CodeSuccess, response.Code)
+				assert.Equal(t, testInternal.Marshal(t, []*annotation.Instance{value}), response.Body.Bytes())
+			},
+		},
+		{
+			name:      "valid problem response",
+			config:    both,
+			routables: []routable.Contract{create.New(s, decoder, false).Init},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				value := newAnnotation()
+				id := urlIdentity.New(value.CurrentIdentity.Printable())
+				body := testInternal.Marshal(t, value)
+				testInternal.SendRequestWithBody(t, muxRouter, create.Method, create.EscapedRoute(id), body)
+
+				response := testInternal.SendRequestWithBody(t, muxRouter, create.Method, create.EscapedRoute(id), body)
+
+				assert.Equal(t, problem.ErrIdentityExists.Status, response.Code)
+				assert.Equal(t, problem.ContentType, response.Header().Get(codec.ContentTypeHeader))
+			},
+		},
+		{
+			name:      "missing required body",
+			config:    Config{ValidateRequests: true},
+			routables: []routable.Contract{stubRoute(http.StatusOK, "0")},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, create.Method, create.Route("x"))
+
+				assert.Equal(t, CodeInvalidRequest, response.Code)
+				assert.Equal(t, "invalid request: body is required", response.Body.String())
+			},
+		},
+		{
+			name:      "request missing required property",
+			config:    Config{ValidateRequests: true},
+			routables: []routable.Contract{stubRoute(http.StatusOK, "0")},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					create.Method,
+					create.Route("x"),
+					[]byte(`{"unique": "u"}`),
+				)
+
+				assert.Equal(t, CodeInvalidRequest, response.Code)
+				assert.Equal(t, "invalid request: $: created is required", response.Body.String())
+			},
+		},
+		{
+			name:      "valid CBOR request and response",
+			config:    both,
+			routables: []routable.Contract{create.New(s, decoder, false).Init},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				value := newAnnotation()
+				format := codec.NewCBOR()
+				body, err := format.Marshal(value)
+				assert.Nil(t, err)
+
+				response := testInternal.SendRequestWithHeader(
+					t,
+					muxRouter,
+					create.Method,
+					create.EscapedRoute(urlIdentity.New(value.CurrentIdentity.Printable())),
+					http.Header{codec.ContentTypeHeader: []string{codec.ContentTypeCBOR}},
+					body,
+				)
+
+				expected, err := format.Marshal(0)
+				assert.Nil(t, err)
+				assert.Equal(t, create.CodeSuccess, response.Code)
+				assert.Equal(t, expected, response.Body.Bytes())
+			},
+		},
+		{
+			name:      "CBOR request missing required property",
+			config:    Config{ValidateRequests: true},
+			routables: []routable.Contract{stubRoute(http.StatusOK, "0")},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				body, err := codec.NewCBOR().Marshal(map[string]interface{}{"unique": "u"})
+				assert.Nil(t, err)
+
+				response := testInternal.SendRequestWithHeader(
+					t,
+					muxRouter,
+					create.Method,
+					create.Route("x"),
+					http.Header{codec.ContentTypeHeader: []string{codec.ContentTypeCBOR}},
+					body,
+				)
+
+				assert.Equal(t, CodeInvalidRequest, response.Code)
+				assert.Equal(t, "invalid request: $: created is required", response.Body.String())
+			},
+		},
+		{
+			name:      "request property of wrong type",
+			config:    Config{ValidateRequests: true},
+			routables: []routable.Contract{stubRoute(http.StatusOK, "0")},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				value := testInternal.Marshal(t, newAnnotation())
+				value = append(value[:len(value)-1], []byte(`,"identityPrevious": 1}`)...)
+
+				response := testInternal.SendRequestWithBody(t, muxRouter, create.Method, create.Route("x"), value)
+
+				assert.Equal(t, CodeInvalidRequest, response.Code)
+				assert.Equal(
+					t,
+					"invalid request: $.identityPrevious: must be of type object",
+					response.Body.String(),
+				)
+			},
+		},
+		{
+			name:      "request validation disabled",
+			config:    Config{ValidateResponses: true},
+			routables: []routable.Contract{stubRoute(http.StatusOK, "0")},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, create.Method, create.Route("x"))
+
+				assert.Equal(t, http.StatusOK, response.Code)
+			},
+		},
+		{
+			name:      "response outside enum",
+			config:    both,
+			routables: []routable.Contract{stubRoute(http.StatusOK, "7")},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					create.Method,
+					create.Route("x"),
+					testInternal.Marshal(t, newAnnotation()),
+				)
+
+				assert.Equal(t, CodeInvalidResponse, response.Code)
+				assert.Equal(t, "invalid response: $: 7 is not one of [0 1 2 3 4]", response.Body.String())
+			},
+		},
+		{
+			name:      "undocumented response status",
+			config:    Config{ValidateResponses: true},
+			routables: []routable.Contract{stubRoute(http.StatusTeapot, "")},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, create.Method, create.Route("x"))
+
+				assert.Equal(t, CodeInvalidResponse, response.Code)
+				assert.Equal(t, "invalid response: status 418 is not documented", response.Body.String())
+			},
+		},
+		{
+			name:      "response validation disabled",
+			config:    Config{ValidateRequests: true},
+			routables: []routable.Contract{stubRoute(http.StatusTeapot, "")},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					create.Method,
+					create.Route("x"),
+					testInternal.Marshal(t, newAnnotation()),
+				)
+
+				assert.Equal(t, http.StatusTeapot, response.Code)
+			},
+		},
+		{
+			name:      "undocumented route",
+			config:    both,
+			routables: []routable.Contract{stubRoute(http.StatusTeapot, "")},
+			test: func(t *testing.T, muxRouter *mux.Router) {
+				muxRouter.HandleFunc(
+					"/undocumented",
+					func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusTeapot) },
+				)
+
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/undocumented")
+
+				assert.Equal(t, http.StatusTeapot, response.Code)
+			},
+		},
+	}
+
+	for i := range cases {
+		routables := append(cases[i].routables, New(document, cases[i].config).Init)
+		cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, routables)
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				cases[i].test(t, muxRouter)
+				cancel()
+				wg.Wait()
+			},
+		)
+	}
+}
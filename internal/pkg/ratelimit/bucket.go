@@ -0,0 +1,74 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// Limit allows Rate requests per second on average, in bursts of up to Burst requests; a zero Burst allows bursts of
+// one second's worth of requests, and a zero Rate places no limit.
+type Limit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// capacity returns the number of tokens a full bucket holds.
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return math.Max(1, math.Ceil(l.Rate))
+}
+
+// bucket is a token bucket; it holds tokens as of last.
+type bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+// newBucket returns a full bucket for limit.
+func newBucket(limit Limit, now time.Time) *bucket {
+	return &bucket{
+		limit:  limit,
+		tokens: limit.capacity(),
+		last:   now,
+	}
+}
+
+// refill adds the tokens earned since the bucket was last refilled.
+func (b *bucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens = math.Min(b.limit.capacity(), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+		b.last = now
+	}
+}
+
+// wait returns how long until the bucket, refilled as of now, holds a token; it is zero if it holds one already.
+func (b *bucket) wait(now time.Time) time.Duration {
+	b.refill(now)
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+}
+
+// full returns whether the bucket, refilled as of now, is full, and so no different from a new bucket.
+func (b *bucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.limit.capacity()
+}
@@ -0,0 +1,300 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/pkg/http/header"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	RetryAfterHeader = header.RetryAfter
+
+	// sweepInterval is how often buckets that have refilled are discarded.
+	sweepInterval = time.Minute
+)
+
+// Config limits the rate at which each client, identified by its principal's subject or, if it is not
+// authenticated, by its address, makes requests.  Every request is charged to the client's Principal limit, which
+// Principals overrides by subject; requests to routes named in Routes and on identities beneath a prefix in Prefixes
+// are also charged to the client's limit for that route and that prefix.  MaxInFlight, if not zero, limits the number
+// of requests served at once, other than streams; requests beyond it are told to retry after RetryAfter seconds.
+type Config struct {
+	Principal   Limit            `json:"principal"`
+	Principals  map[string]Limit `json:"principals"`
+	Routes      map[string]Limit `json:"routes"`
+	Prefixes    map[string]Limit `json:"prefixes"`
+	MaxInFlight int              `json:"maxInFlight"`
+	RetryAfter  int              `json:"retryAfter"`
+}
+
+// NewDefaultConfig returns the default configuration: requests are not limited.
+func NewDefaultConfig() Config {
+	return Config{
+		Principals: map[string]Limit{},
+		Routes:     map[string]Limit{},
+		Prefixes:   map[string]Limit{},
+		RetryAfter: 1,
+	}
+}
+
+// Enabled returns whether the configuration limits any requests.
+func (c Config) Enabled() bool {
+	return c.Principal.Rate > 0 || len(c.Principals) > 0 || len(c.Routes) > 0 || len(c.Prefixes) > 0 ||
+		c.MaxInFlight > 0
+}
+
+// Validate returns an error if the configuration is not usable.
+func (c Config) Validate() error {
+	if c.MaxInFlight < 0 || c.RetryAfter < 0 {
+		return errors.New("maxInFlight and retryAfter must not be negative")
+	}
+	limits := map[string]Limit{"principal": c.Principal}
+	for subject, limit := range c.Principals {
+		limits["principal "+subject] = limit
+	}
+	for route, limit := range c.Routes {
+		limits["route "+route] = limit
+	}
+	for prefix, limit := range c.Prefixes {
+		limits["prefix "+prefix] = limit
+	}
+	for name, limit := range limits {
+		if limit.Rate < 0 || limit.Burst < 0 {
+			return fmt.Errorf("%s: rate and burst must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// key identifies a client's bucket for a kind of limit.
+type key struct {
+	client string
+	kind   string
+	name   string
+}
+
+// charge is a bucket a request is charged to and the limit that bucket enforces.
+type charge struct {
+	key   key
+	limit Limit
+}
+
+// admittedKey marks, in a request context, a request already admitted by the instance.
+type admittedKey struct{}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	config   Config
+	streams  []string
+	clock    func() time.Time
+	inFlight chan struct{}
+	m        sync.Mutex
+	buckets  map[key]*bucket
+	swept    time.Time
+}
+
+// New is a factory function that returns instance enforcing config; requests to routes named in streams are not
+// counted against MaxInFlight, since they last as long as the client stays connected.
+func New(config Config, streams []string) (*instance, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	i := &instance{
+		config:  config,
+		streams: streams,
+		clock:   time.Now,
+		buckets: make(map[key]*bucket),
+	}
+	if config.MaxInFlight > 0 {
+		i.inFlight = make(chan struct{}, config.MaxInFlight)
+	}
+	return i, nil
+}
+
+// SetClock provides for method injection of the clock buckets are refilled by; the default is time.Now.
+func (i *instance) SetClock(clock func() time.Time) {
+	i.clock = clock
+}
+
+// Init installs the rate limiting middleware on muxRouter if the configuration limits any requests; it must be
+// installed after the authentication middleware.  It may be installed on several routers that serve the same
+// request, which is only admitted once but is charged to the route and identity of each router that names them.
+func (i *instance) Init(muxRouter *mux.Router) {
+	if i.config.Enabled() {
+		muxRouter.Use(i.Middleware)
+	}
+}
+
+// Middleware rejects requests whose client has exhausted one of the limits the request is charged to, or that
+// arrive while MaxInFlight requests are being served, with a Retry-After header saying when to try again.
+func (i *instance) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			admitted, _ := r.Context().Value(admittedKey{}).(bool)
+			if !admitted && i.inFlight != nil && !i.streaming(r) {
+				select {
+				case i.inFlight <- struct{}{}:
+					defer func() { <-i.inFlight }()
+				default:
+					reject(w, r, problem.ErrOverloaded, time.Duration(i.config.RetryAfter)*time.Second)
+					return
+				}
+			}
+
+			if wait := i.take(i.charges(r, !admitted)); wait > 0 {
+				reject(w, r, problem.ErrRateLimited, wait)
+				return
+			}
+
+			if !admitted {
+				r = r.WithContext(context.WithValue(r.Context(), admittedKey{}, true))
+			}
+			next.ServeHTTP(w, r)
+		},
+	)
+}
+
+// reject writes p with a Retry-After header of wait, rounded up to whole seconds.
+func reject(w http.ResponseWriter, r *http.Request, p *problem.Instance, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set(RetryAfterHeader, strconv.Itoa(seconds))
+	problem.Write(w, r, p.WithDetail("retry after "+strconv.Itoa(seconds)+"s"))
+}
+
+// streaming returns whether r is to a route named in streams.
+func (i *instance) streaming(r *http.Request) bool {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return false
+	}
+	for _, name := range i.streams {
+		if route.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// client returns the identity of r's client.
+func client(r *http.Request) string {
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		return "subject:" + principal.Subject
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "address:" + host
+}
+
+// charges returns the buckets r is charged to; the principal's is included if principal is set.
+func (i *instance) charges(r *http.Request, principal bool) []charge {
+	c := client(r)
+	var result []charge
+	if principal {
+		limit := i.config.Principal
+		if p, ok := auth.FromContext(r.Context()); ok {
+			if override, exists := i.config.Principals[p.Subject]; exists {
+				limit = override
+			}
+		}
+		result = append(result, charge{key: key{client: c, kind: "principal"}, limit: limit})
+	}
+
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return result
+	}
+	if limit, exists := i.config.Routes[route.GetName()]; exists {
+		result = append(result, charge{key: key{client: c, kind: "route", name: route.GetName()}, limit: limit})
+	}
+	id, exists := urlIdentity.FromRequest(r)
+	if !exists {
+		return result
+	}
+	for prefix, limit := range i.config.Prefixes {
+		if strings.HasPrefix(id, prefix) {
+			result = append(result, charge{key: key{client: c, kind: "prefix", name: prefix}, limit: limit})
+		}
+	}
+	return result
+}
+
+// take removes a token from the bucket of each charge and returns zero if every bucket held one; otherwise it takes
+// none and returns how long until they all will.
+func (i *instance) take(charges []charge) time.Duration {
+	now := i.clock()
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.sweep(now)
+	var wait time.Duration
+	buckets := make([]*bucket, 0, len(charges))
+	for _, c := range charges {
+		if c.limit.Rate <= 0 {
+			continue
+		}
+		b, exists := i.buckets[c.key]
+		if !exists || b.limit != c.limit {
+			b = newBucket(c.limit, now)
+			i.buckets[c.key] = b
+		}
+		if w := b.wait(now); w > wait {
+			wait = w
+		}
+		buckets = append(buckets, b)
+	}
+	if wait > 0 {
+		return wait
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return 0
+}
+
+// sweep discards buckets that have refilled, at most once every sweepInterval; it must be called with the lock held.
+func (i *instance) sweep(now time.Time) {
+	if now.Sub(i.swept) < sweepInterval {
+		return
+	}
+	i.swept = now
+	for k, b := range i.buckets {
+		if b.full(now) {
+			delete(i.buckets, k)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package ratelimit
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	routeAppend = "append"
+	routeFind   = "find"
+	routeStream = "stream"
+	subject     = "X-Subject"
+)
+
+// clock is a clock that only moves when told to.
+type clock struct {
+	now time.Time
+}
+
+// Now returns the clock's time.
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+// authenticate is a routable that authenticates requests as the subject in their X-Subject header, if any.
+func authenticate(muxRouter *mux.Router) {
+	muxRouter.Use(
+		func(next http.Handler) http.Handler {
+			return http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					if s := r.Header.Get(subject); s != "" {
+						r = r.WithContext(auth.NewContext(r.Context(), &auth.Principal{Subject: s}))
+					}
+					next.ServeHTTP(w, r)
+				},
+			)
+		},
+	)
+}
+
+// routes returns a routable serving a route of each kind; requests to the stream route wait for release.
+func routes(release chan struct{}) routable.Contract {
+	return func(muxRouter *mux.Router) {
+		// as the service does, so that identities may contain escaped slashes.
+		muxRouter.UseEncodedPath()
+		ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+		muxRouter.HandleFunc("/append/{identity}", ok).Name(routeAppend)
+		muxRouter.HandleFunc("/find/{identity}", ok).Name(routeFind)
+		muxRouter.HandleFunc(
+			"/stream",
+			func(w http.ResponseWriter, r *http.Request) {
+				<-release
+				w.WriteHeader(http.StatusOK)
+			},
+		).Name(routeStream)
+		muxRouter.HandleFunc(
+			"/slow",
+			func(w http.ResponseWriter, r *http.Request) {
+				<-release
+				w.WriteHeader(http.StatusOK)
+			},
+		)
+	}
+}
+
+// newSUT returns a router enforcing config with a clock that only moves when told to; routables are installed after
+// the rate limiting middleware.
+func newSUT(t *testing.T, config Config, routables ...routable.Contract) (*mux.Router, *clock, func()) {
+	sut, err := New(config, []string{routeStream})
+	if err != nil {
+		assert.FailNow(t, "Unexpected New failure:", err.Error())
+	}
+	c := &clock{now: time.Now()}
+	sut.SetClock(c.Now)
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		append([]routable.Contract{authenticate, sut.Init}, routables...),
+	)
+	return muxRouter, c, func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// send returns the status code and Retry-After header of a GET request to url from the client named s.
+func send(t *testing.T, muxRouter *mux.Router, url, s string) (int, string) {
+	header := http.Header{}
+	if s != "" {
+		header.Set(subject, s)
+	}
+	response := testInternal.SendRequestWithHeader(t, muxRouter, http.MethodGet, url, header, nil)
+	return response.Code, response.Header().Get(RetryAfterHeader)
+}
+
+// TestConfig tests configuration validation.
+func TestConfig(t *testing.T) {
+	assert.False(t, NewDefaultConfig().Enabled())
+	assert.Nil(t, NewDefaultConfig().Validate())
+	assert.True(t, Config{MaxInFlight: 1}.Enabled())
+	assert.True(t, Config{Routes: map[string]Limit{routeAppend: {Rate: 1}}}.Enabled())
+	assert.NotNil(t, Config{MaxInFlight: -1}.Validate())
+	assert.NotNil(t, Config{Prefixes: map[string]Limit{"a/": {Rate: -1}}}.Validate())
+	assert.NotNil(t, Config{Principal: Limit{Rate: 1, Burst: -1}}.Validate())
+}
+
+// TestMiddleware tests rate limiting and admission control.
+func TestMiddleware(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "principal limit",
+			test: func(t *testing.T) {
+				muxRouter, c, stop := newSUT(t, Config{Principal: Limit{Rate: 1, Burst: 2}}, routes(nil))
+				defer stop()
+
+				first, _ := send(t, muxRouter, "/find/a", "gateway")
+				second, _ := send(t, muxRouter, "/find/a", "gateway")
+				limited, retryAfter := send(t, muxRouter, "/find/a", "gateway")
+				other, _ := send(t, muxRouter, "/find/a", "other")
+				c.now = c.now.Add(time.Second)
+				refilled, _ := send(t, muxRouter, "/find/a", "gateway")
+
+				assert.Equal(t, http.StatusOK, first)
+				assert.Equal(t, http.StatusOK, second)
+				assert.Equal(t, http.StatusTooManyRequests, limited)
+				assert.Equal(t, "1", retryAfter)
+				assert.Equal(t, http.StatusOK, other)
+				assert.Equal(t, http.StatusOK, refilled)
+			},
+		},
+		{
+			name: "principal override",
+			test: func(t *testing.T) {
+				muxRouter, _, stop := newSUT(
+					t,
+					Config{
+						Principal:  Limit{Rate: 1},
+						Principals: map[string]Limit{"trusted": {Rate: 0.1, Burst: 3}},
+					},
+					routes(nil),
+				)
+				defer stop()
+
+				codes := make([]int, 4)
+				for key := range codes {
+					codes[key], _ = send(t, muxRouter, "/find/a", "trusted")
+				}
+				_, retryAfter := send(t, muxRouter, "/find/a", "trusted")
+
+				assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+				assert.Equal(t, "10", retryAfter)
+			},
+		},
+		{
+			name: "route limit",
+			test: func(t *testing.T) {
+				muxRouter, _, stop := newSUT(t, Config{Routes: map[string]Limit{routeAppend: {Rate: 1}}}, routes(nil))
+				defer stop()
+
+				appended, _ := send(t, muxRouter, "/append/a", "gateway")
+				limited, _ := send(t, muxRouter, "/append/b", "gateway")
+				found, _ := send(t, muxRouter, "/find/a", "gateway")
+				other, _ := send(t, muxRouter, "/append/a", "other")
+
+				assert.Equal(t, http.StatusOK, appended)
+				assert.Equal(t, http.StatusTooManyRequests, limited)
+				assert.Equal(t, http.StatusOK, found)
+				assert.Equal(t, http.StatusOK, other)
+			},
+		},
+		{
+			name: "prefix limit",
+			test: func(t *testing.T) {
+				muxRouter, _, stop := newSUT(
+					t,
+					Config{Prefixes: map[string]Limit{"plant-3/": {Rate: 1}}},
+					routes(nil),
+				)
+				defer stop()
+
+				first, _ := send(t, muxRouter, "/append/plant-3%2Fpump", "gateway")
+				limited, _ := send(t, muxRouter, "/find/plant-3%2Fvalve", "gateway")
+				outside, _ := send(t, muxRouter, "/append/plant-4%2Fpump", "gateway")
+
+				assert.Equal(t, http.StatusOK, first)
+				assert.Equal(t, http.StatusTooManyRequests, limited)
+				assert.Equal(t, http.StatusOK, outside)
+			},
+		},
+		{
+			name: "unauthenticated clients limited by address",
+			test: func(t *testing.T) {
+				muxRouter, _, stop := newSUT(t, Config{Principal: Limit{Rate: 1}}, routes(nil))
+				defer stop()
+
+				first, _ := send(t, muxRouter, "/find/a", "")
+				limited, _ := send(t, muxRouter, "/find/a", "")
+
+				assert.Equal(t, http.StatusOK, first)
+				assert.Equal(t, http.StatusTooManyRequests, limited)
+			},
+		},
+		{
+			name: "admitted once across routers",
+			test: func(t *testing.T) {
+				sut, err := New(Config{Principal: Limit{Rate: 1}}, nil)
+				if err != nil {
+					assert.FailNow(t, "Unexpected New failure:", err.Error())
+				}
+				muxRouter, _, stop := newSUT(t, Config{}, sut.Init, sut.Init, routes(nil))
+				defer stop()
+
+				first, _ := send(t, muxRouter, "/find/a", "gateway")
+				limited, _ := send(t, muxRouter, "/find/a", "gateway")
+
+				assert.Equal(t, http.StatusOK, first)
+				assert.Equal(t, http.StatusTooManyRequests, limited)
+			},
+		},
+		{
+			name: "in flight limit",
+			test: func(t *testing.T) {
+				release := make(chan struct{})
+				muxRouter, _, stop := newSUT(t, Config{MaxInFlight: 1, RetryAfter: 2}, routes(release))
+				defer stop()
+
+				var wg sync.WaitGroup
+				served := make([]int, 2)
+				for key, url := range []string{"/slow", "/stream"} {
+					wg.Add(1)
+					go func(key int, url string) {
+						defer wg.Done()
+						served[key], _ = send(t, muxRouter, url, "gateway")
+					}(key, url)
+				}
+				assert.Eventually(
+					t,
+					func() bool {
+						code, _ := send(t, muxRouter, "/find/a", "other")
+						return code == http.StatusTooManyRequests
+					},
+					time.Second,
+					time.Millisecond,
+				)
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/find/a")
+				close(release)
+				wg.Wait()
+				admitted, _ := send(t, muxRouter, "/find/a", "other")
+
+				failure, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeOverloaded, failure.Code)
+				assert.Equal(t, "2", response.Header().Get(RetryAfterHeader))
+				assert.Equal(t, http.StatusOK, admitted)
+				assert.Equal(t, []int{http.StatusOK, http.StatusOK}, served)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
@@ -0,0 +1,84 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package recorder
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// instance is a receiver that passes a response through and records its status code and size; it supports streaming
+// responses and upgraded connections when the response it wraps does.
+type instance struct {
+	http.ResponseWriter
+	code  int
+	bytes int
+}
+
+// New is a factory function that returns instance wrapping w; the status code is http.StatusOK until another is
+// written.
+func New(w http.ResponseWriter) *instance {
+	return &instance{
+		ResponseWriter: w,
+		code:           http.StatusOK,
+	}
+}
+
+// Code returns the status code recorded.
+func (r *instance) Code() int {
+	return r.code
+}
+
+// Bytes returns the size of the body written.
+func (r *instance) Bytes() int {
+	return r.bytes
+}
+
+// WriteHeader records code and writes it.
+func (r *instance) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write counts and writes body.
+func (r *instance) Write(body []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(body)
+	r.bytes += n
+	return n, err
+}
+
+// Flush flushes the response, if it can be.
+func (r *instance) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack takes over the connection, if it can be; the status code of an upgraded connection is recorded as 101.
+func (r *instance) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+	r.code = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the response r wraps.
+func (r *instance) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
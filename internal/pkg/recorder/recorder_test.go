@@ -0,0 +1,81 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package recorder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInstance tests recording responses.
+func TestInstance(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T, w *httptest.ResponseRecorder, sut *instance)
+	}
+
+	cases := []testCase{
+		{
+			name: "defaults to OK",
+			test: func(t *testing.T, w *httptest.ResponseRecorder, sut *instance) {
+				_, _ = sut.Write([]byte("body"))
+
+				assert.Equal(t, http.StatusOK, sut.Code())
+				assert.Equal(t, 4, sut.Bytes())
+				assert.Equal(t, "body", w.Body.String())
+			},
+		},
+		{
+			name: "records status code",
+			test: func(t *testing.T, w *httptest.ResponseRecorder, sut *instance) {
+				sut.WriteHeader(http.StatusNotFound)
+
+				assert.Equal(t, http.StatusNotFound, sut.Code())
+				assert.Equal(t, http.StatusNotFound, w.Code)
+				assert.Equal(t, 0, sut.Bytes())
+			},
+		},
+		{
+			name: "flushes",
+			test: func(t *testing.T, w *httptest.ResponseRecorder, sut *instance) {
+				assert.Nil(t, http.NewResponseController(sut).Flush())
+
+				assert.True(t, w.Flushed)
+			},
+		},
+		{
+			name: "hijack unsupported",
+			test: func(t *testing.T, _ *httptest.ResponseRecorder, sut *instance) {
+				_, _, err := sut.Hijack()
+
+				assert.NotNil(t, err)
+				assert.Equal(t, http.StatusOK, sut.Code())
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				w := httptest.NewRecorder()
+				cases[i].test(t, w, New(w))
+			},
+		)
+	}
+}
@@ -15,29 +15,34 @@
 package append
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 
+	"github.com/project-alvarium/go-store/internal/pkg/audit"
+	"github.com/project-alvarium/go-store/internal/pkg/author"
+	"github.com/project-alvarium/go-store/internal/pkg/deprecation"
 	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/ingest"
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
 
-	"github.com/project-alvarium/go-sdk/pkg/annotation"
-	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
 	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
 	"github.com/project-alvarium/go-sdk/pkg/identity"
-	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/status"
 
 	"github.com/gorilla/mux"
 )
 
 const (
-	identityParam      = "identity"
-	Method             = http.MethodPut
-	codeBodyReadFailed = http.StatusBadRequest
-	codeMarshalFailed  = http.StatusBadRequest
-	CodeSuccess        = http.StatusOK
+	identityParam     = "identity"
+	Name              = "append"
+	Method            = http.MethodPut
+	V1Method          = http.MethodPost
+	CodeSuccess       = http.StatusCreated
+	CodeLegacySuccess = http.StatusOK
+	CodeLegacyFailure = http.StatusBadRequest
 )
 
 // Route creates a url.
@@ -50,51 +55,109 @@ func EscapedRoute(id identity.Contract) string {
 	return Route(url.PathEscape(id.Printable()))
 }
 
+// V1Route creates the version 1 url, which adds an annotation to an identity.
+func V1Route(id string) string {
+	return fmt.Sprintf("/v1/identities/%s/annotations", id)
+}
+
+// EscapedV1Route creates the version 1 url for client.
+func EscapedV1Route(id identity.Contract) string {
+	return V1Route(url.PathEscape(id.Printable()))
+}
+
 // instance is a receiver that encapsulates required dependencies.
 type instance struct {
-	store    store.Contract
-	mFactory metadataFactory.Contract
-	iFactory identityFactory.Contract
+	store   store.Contract
+	decoder ingest.Contract
+	authors author.Contract
+	legacy  bool
 }
 
-// New is a factory function that returns instance.
-func New(store store.Contract, mFactory metadataFactory.Contract, iFactory identityFactory.Contract) *instance {
+// New is a factory function that returns instance; legacy selects the original responses, which report every
+// store result with CodeLegacySuccess and every failure with CodeLegacyFailure and an empty body.
+func New(store store.Contract, decoder ingest.Contract, legacy bool) *instance {
 	return &instance{
-		store:    store,
-		mFactory: mFactory,
-		iFactory: iFactory,
+		store:   store,
+		decoder: decoder,
+		authors: author.NewDiscard(),
+		legacy:  legacy,
 	}
 }
 
-// Init adds package's route to muxRouter.
+// SetAuthors provides for method injection of the record each stored annotation's author is noted in; by default,
+// authors are not noted.
+func (i *instance) SetAuthors(authors author.Contract) {
+	i.authors = authors
+}
+
+// Init adds package's routes to muxRouter; the original route is kept as a deprecated alias of the version 1 route.
+// Both are named Name, which authorization policies refer to.
 func (i *instance) Init(muxRouter *mux.Router) {
-	muxRouter.HandleFunc(Route("{"+identityParam+"}"), i.handle).Methods(Method)
+	muxRouter.HandleFunc(V1Route("{"+identityParam+"}"), i.handle).Methods(V1Method).Name(Name)
+	muxRouter.HandleFunc(Route("{"+identityParam+"}"), deprecation.Handler(i.handle, successor)).
+		Methods(Method).
+		Name(Name)
+}
+
+// successor returns the version 1 url that replaces r.
+func successor(r *http.Request) string {
+	return EscapedV1Route(urlIdentity.New(urlIdentity.Unescape(mux.Vars(r)[identityParam])))
 }
 
 // handle implements package's functionality.
 func (i *instance) handle(w http.ResponseWriter, r *http.Request) {
-	id := urlIdentity.New(mux.Vars(r)[identityParam])
+	id := urlIdentity.New(urlIdentity.Unescape(mux.Vars(r)[identityParam]))
 
-	body := make([]byte, r.ContentLength)
-	if _, err := r.Body.Read(body); err != nil && err != io.EOF {
-		w.WriteHeader(codeBodyReadFailed)
+	value, encoder, failure := i.decoder.Decode(w, r)
+	if failure == nil {
+		failure = i.decoder.Validate(id, value)
+	}
+	if failure != nil {
+		i.fail(w, r, failure)
 		return
 	}
+	audit.SetUnique(r.Context(), value.Unique)
 
-	var value annotation.Instance
-	value.SetMetadataFactory(i.mFactory)
-	value.SetIdentityFactory(i.iFactory)
-	if err := json.Unmarshal(body, &value); err != nil {
-		w.WriteHeader(codeMarshalFailed)
-		return
+	result := i.store.Append(id, value)
+	if result == status.Success {
+		i.authors.Record(r.Context(), value)
+	}
+	if !i.legacy {
+		switch result {
+		case status.Success:
+		case status.NotFound:
+			i.fail(w, r, problem.ErrIdentityNotFound.WithDetail(id.Printable()))
+			return
+		default:
+			i.fail(w, r, problem.ErrInternal.WithDetail("store returned status "+strconv.Itoa(int(result))))
+			return
+		}
 	}
 
-	resultInBytes, err := json.Marshal(i.store.Append(id, &value))
+	resultInBytes, err := encoder.Marshal(result)
 	if err != nil {
-		w.WriteHeader(codeMarshalFailed)
+		i.fail(w, r, problem.ErrInternal.WithDetail(err.Error()))
 		return
 	}
 
-	w.WriteHeader(CodeSuccess)
+	w.Header().Set(codec.ContentTypeHeader, encoder.ContentType())
+	w.WriteHeader(i.successCode())
 	_, _ = w.Write(resultInBytes)
 }
+
+// successCode returns the status code of a successful write.
+func (i *instance) successCode() int {
+	if i.legacy {
+		return CodeLegacySuccess
+	}
+	return CodeSuccess
+}
+
+// fail reports p, or CodeLegacyFailure if the instance reports legacy responses.
+func (i *instance) fail(w http.ResponseWriter, r *http.Request, p *problem.Instance) {
+	if i.legacy {
+		w.WriteHeader(CodeLegacyFailure)
+		return
+	}
+	problem.Write(w, r, p)
+}
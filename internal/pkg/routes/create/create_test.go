@@ -15,12 +15,20 @@
 package create
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	authStub "github.com/project-alvarium/go-store/internal/pkg/auth/stub"
+	"github.com/project-alvarium/go-store/internal/pkg/author"
+	"github.com/project-alvarium/go-store/internal/pkg/deprecation"
 	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/ingest"
 	"github.com/project-alvarium/go-store/internal/pkg/routable"
 	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
 
 	"github.com/project-alvarium/go-sdk/pkg/annotation"
 	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
@@ -29,6 +37,7 @@ import (
 	"github.com/project-alvarium/go-sdk/pkg/annotation/store/memory"
 	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
 	assessMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata/factory"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
 	pkiMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata/factory"
 	publishMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata/factory"
 	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
@@ -40,18 +49,34 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+const identityLength = 32
+
+// randomBytes returns random bytes for a hash identity; unlike FactoryRandomByteSlice it is never empty, as an empty
+// printable form matches no route.
+func randomBytes() []byte {
+	return append(test.FactoryRandomByteSlice(), test.FactoryRandomFixedLengthAlphanumericByteSlice(1)...)
+}
+
+// newSlashedIdentity returns a random hash identity whose printable form begins with slashes, as the base64 form of
+// many real hashes contains them.
+func newSlashedIdentity() *hash.Identity {
+	return hash.New(append([]byte{0xff, 0xff, 0xff}, randomBytes()...))
+}
+
 // TestCreate tests create route.
 func TestCreate(t *testing.T) {
 	type testCase struct {
-		name string
-		test func(t *testing.T, muxRouter *mux.Router, store store.Contract)
+		name   string
+		legacy bool
+		strict bool
+		test   func(t *testing.T, muxRouter *mux.Router, store store.Contract)
 	}
 
 	cases := []testCase{
 		{
 			name: "Success (does not exist)",
 			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
-				id := hash.New(test.FactoryRandomByteSlice())
+				id := hash.New(randomBytes())
 				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
 
 				response := testInternal.SendRequestWithBody(
@@ -64,12 +89,13 @@ func TestCreate(t *testing.T) {
 
 				assert.Equal(t, CodeSuccess, response.Code)
 				assert.Equal(t, testInternal.Marshal(t, status.Success), response.Body.Bytes())
+				assert.Equal(t, EscapedV1Route(url.New(id.Printable())), response.Header().Get(locationHeader))
 			},
 		},
 		{
-			name: "Success (exists)",
+			name: "Exists",
 			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
-				id := hash.New(test.FactoryRandomByteSlice())
+				id := hash.New(randomBytes())
 				idContract := url.New(id.Printable())
 				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
 				assert.Equal(t, status.Success, store.Create(idContract, value))
@@ -82,10 +108,313 @@ func TestCreate(t *testing.T) {
 					testInternal.Marshal(t, value),
 				)
 
-				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(t, problem.ErrIdentityExists.Status, response.Code)
+				assert.Equal(t, problem.ContentType, response.Header().Get(codec.ContentTypeHeader))
+				result, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeIdentityExists, result.Code)
+				assert.Equal(t, EscapedRoute(idContract), result.Instance)
+			},
+		},
+		{
+			name:   "Legacy (exists)",
+			legacy: true,
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				id := hash.New(randomBytes())
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+				assert.Equal(t, status.Success, store.Create(idContract, value))
+
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					Method,
+					EscapedRoute(idContract),
+					testInternal.Marshal(t, value),
+				)
+
+				assert.Equal(t, CodeLegacySuccess, response.Code)
 				assert.Equal(t, testInternal.Marshal(t, status.Exists), response.Body.Bytes())
 			},
 		},
+		{
+			name: "Malformed body",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					Method,
+					EscapedRoute(url.New(test.FactoryRandomFixedLengthAlphanumericString(identityLength))),
+					[]byte("{"),
+				)
+
+				assert.Equal(t, problem.ErrMalformedBody.Status, response.Code)
+				result, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeMalformedBody, result.Code)
+			},
+		},
+		{
+			name:   "Identity mismatch (strict)",
+			strict: true,
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				m := metadataStub.NewNullObject()
+				id := hash.New(randomBytes())
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), hash.New(randomBytes()), nil, m)
+
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					V1Method,
+					EscapedV1Route(idContract),
+					testInternal.Marshal(t, value),
+				)
+
+				assert.Equal(t, problem.ErrIdentityMismatch.Status, response.Code)
+				result, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeIdentityMismatch, result.Code)
+				_, found := store.FindByIdentity(idContract)
+				assert.Equal(t, status.NotFound, found)
+			},
+		},
+		{
+			name:   "Identity with a slash (strict)",
+			strict: true,
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				id := newSlashedIdentity()
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					V1Method,
+					EscapedV1Route(idContract),
+					testInternal.Marshal(t, value),
+				)
+
+				assert.Equal(t, CodeSuccess, response.Code)
+				_, found := store.FindByIdentity(idContract)
+				assert.Equal(t, status.Success, found)
+			},
+		},
+		{
+			name: "Identity mismatch (lenient)",
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				m := metadataStub.NewNullObject()
+				id := hash.New(randomBytes())
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), hash.New(randomBytes()), nil, m)
+
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					V1Method,
+					EscapedV1Route(idContract),
+					testInternal.Marshal(t, value),
+				)
+
+				assert.Equal(t, CodeSuccess, response.Code)
+			},
+		},
+		{
+			name: "Invalid metadata",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				id := hash.New(randomBytes())
+				value := annotation.New(ulid.New().Get(), id, nil, &pkiMetadata.Instance{})
+
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					V1Method,
+					EscapedV1Route(url.New(id.Printable())),
+					testInternal.Marshal(t, value),
+				)
+
+				assert.Equal(t, problem.ErrInvalidAnnotation.Status, response.Code)
+				result, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeInvalidAnnotation, result.Code)
+				assert.Contains(t, result.InvalidParams, problem.InvalidParam{Name: "metadata.publicKey", Reason: "is required"})
+			},
+		},
+		{
+			name: "Invalid annotation",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					Method,
+					EscapedRoute(url.New(test.FactoryRandomFixedLengthAlphanumericString(identityLength))),
+					[]byte(`{"unique": 1}`),
+				)
+
+				assert.Equal(t, problem.ErrInvalidAnnotation.Status, response.Code)
+				result, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeInvalidAnnotation, result.Code)
+			},
+		},
+		{
+			name:   "Legacy (malformed body)",
+			legacy: true,
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					Method,
+					EscapedRoute(url.New(test.FactoryRandomFixedLengthAlphanumericString(identityLength))),
+					[]byte("{"),
+				)
+
+				assert.Equal(t, CodeLegacyFailure, response.Code)
+				assert.Empty(t, response.Body.Bytes())
+			},
+		},
+		{
+			name: "Version 1",
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				id := hash.New(randomBytes())
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					V1Method,
+					EscapedV1Route(idContract),
+					testInternal.Marshal(t, value),
+				)
+
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(t, testInternal.Marshal(t, status.Success), response.Body.Bytes())
+				assert.Empty(t, response.Header().Get(deprecation.Header))
+				_, result := store.FindByIdentity(idContract)
+				assert.Equal(t, status.Success, result)
+			},
+		},
+		{
+			name: "Deprecated alias",
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				id := hash.New(randomBytes())
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					Method,
+					EscapedRoute(idContract),
+					testInternal.Marshal(t, value),
+				)
+
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(t, "true", response.Header().Get(deprecation.Header))
+				assert.Equal(
+					t,
+					"<"+EscapedV1Route(idContract)+">; rel=\"successor-version\"",
+					response.Header().Get(deprecation.LinkHeader),
+				)
+			},
+		},
+		{
+			name: "Deprecated alias (identity with a slash)",
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				id := newSlashedIdentity()
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					Method,
+					EscapedRoute(idContract),
+					testInternal.Marshal(t, value),
+				)
+
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(
+					t,
+					"<"+EscapedV1Route(idContract)+">; rel=\"successor-version\"",
+					response.Header().Get(deprecation.LinkHeader),
+				)
+				assert.Equal(t, EscapedV1Route(idContract), response.Header().Get(locationHeader))
+				assert.NotContains(t, response.Header().Get(deprecation.LinkHeader), "%252F")
+				assert.NotContains(t, response.Header().Get(locationHeader), "%252F")
+			},
+		},
+		{
+			name: "CBOR",
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				id := hash.New(randomBytes())
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+				format := codec.NewCBOR()
+				body, err := format.Marshal(value)
+				assert.Nil(t, err)
+
+				response := testInternal.SendRequestWithHeader(
+					t,
+					muxRouter,
+					V1Method,
+					EscapedV1Route(idContract),
+					http.Header{codec.ContentTypeHeader: []string{codec.ContentTypeCBOR}},
+					body,
+				)
+
+				expected, err := format.Marshal(status.Success)
+				assert.Nil(t, err)
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(t, codec.ContentTypeCBOR, response.Header().Get(codec.ContentTypeHeader))
+				assert.Equal(t, expected, response.Body.Bytes())
+			},
+		},
+		{
+			name: "MessagePack response to JSON request",
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				id := hash.New(randomBytes())
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+
+				response := testInternal.SendRequestWithHeader(
+					t,
+					muxRouter,
+					V1Method,
+					EscapedV1Route(idContract),
+					http.Header{codec.AcceptHeader: []string{codec.ContentTypeMsgPack}},
+					testInternal.Marshal(t, value),
+				)
+
+				expected, err := codec.NewMsgPack().Marshal(status.Success)
+				assert.Nil(t, err)
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(t, codec.ContentTypeMsgPack, response.Header().Get(codec.ContentTypeHeader))
+				assert.Equal(t, expected, response.Body.Bytes())
+			},
+		},
+		{
+			name: "Unsupported content type",
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				id := hash.New(randomBytes())
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+
+				response := testInternal.SendRequestWithHeader(
+					t,
+					muxRouter,
+					V1Method,
+					EscapedV1Route(idContract),
+					http.Header{codec.ContentTypeHeader: []string{"text/plain"}},
+					testInternal.Marshal(t, value),
+				)
+
+				assert.Equal(t, problem.ErrUnsupportedMediaType.Status, response.Code)
+				_, result := store.FindByIdentity(idContract)
+				assert.NotEqual(t, status.Success, result)
+			},
+		},
 	}
 
 	for i := range cases {
@@ -97,8 +426,15 @@ func TestCreate(t *testing.T) {
 				publishMetadataFactory.NewDefault(),
 			},
 		)
-		iFactory := identityFactory.New()
-		cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, []routable.Contract{New(s, mFactory, iFactory).Init})
+		config := ingest.NewDefaultConfig()
+		if cases[i].strict {
+			config.IdentityCheck = ingest.IdentityCheckStrict
+		}
+		decoder := ingest.New(mFactory, identityFactory.New(), config)
+		cancel, wg, muxRouter := testInternal.NewSUT(
+			pkg.Run,
+			[]routable.Contract{New(s, decoder, cases[i].legacy).Init},
+		)
 		t.Run(
 			cases[i].name,
 			func(t *testing.T) {
@@ -109,3 +445,52 @@ func TestCreate(t *testing.T) {
 		)
 	}
 }
+
+// TestCreate_Author tests that the principal that creates an identity is noted as its annotation's author.
+func TestCreate_Author(t *testing.T) {
+	s := memory.New()
+	decoder := ingest.New(
+		metadataFactory.New([]metadataFactory.Contract{publishMetadataFactory.NewDefault()}),
+		identityFactory.New(),
+		ingest.NewDefaultConfig(),
+	)
+	authors := author.New()
+	sut := New(s, decoder, false)
+	sut.SetAuthors(authors)
+	principal := &auth.Principal{Subject: test.FactoryRandomString(), Method: "jwt"}
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{authStub.Authenticated(principal), sut.Init},
+	)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	id := hash.New(randomBytes())
+	uniques := ulid.New()
+	value := annotation.New(uniques.Get(), id, nil, metadataStub.NewNullObject())
+	rejected := annotation.New(uniques.Get(), id, nil, metadataStub.NewNullObject())
+
+	created := testInternal.SendRequestWithBody(
+		t,
+		muxRouter,
+		V1Method,
+		EscapedV1Route(url.New(id.Printable())),
+		testInternal.Marshal(t, value),
+	)
+	exists := testInternal.SendRequestWithBody(
+		t,
+		muxRouter,
+		V1Method,
+		EscapedV1Route(url.New(id.Printable())),
+		testInternal.Marshal(t, rejected),
+	)
+
+	assert.Equal(t, CodeSuccess, created.Code)
+	assert.Equal(t, problem.ErrIdentityExists.Status, exists.Code)
+	a, ok := authors.Find(value)
+	assert.True(t, ok)
+	assert.Equal(t, author.Author{Subject: principal.Subject, Method: "jwt"}, a)
+	_, ok = authors.Find(rejected)
+	assert.False(t, ok)
+}
@@ -15,12 +15,15 @@
 package find
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 
+	"github.com/project-alvarium/go-store/internal/pkg/deprecation"
 	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
 
 	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
 	"github.com/project-alvarium/go-sdk/pkg/identity"
@@ -31,9 +34,11 @@ import (
 
 const (
 	identityParam        = "identity"
+	Name                 = "find"
 	Method               = http.MethodGet
-	CodeIdentityNotFound = http.StatusBadRequest
-	codeMarshalFailed    = http.StatusBadRequest
+	V1Method             = http.MethodGet
+	CodeIdentityNotFound = http.StatusNotFound
+	CodeLegacyFailure    = http.StatusBadRequest
 	CodeSuccess          = http.StatusOK
 )
 
@@ -47,37 +52,76 @@ func EscapedRoute(id identity.Contract) string {
 	return Route(url.PathEscape(id.Printable()))
 }
 
+// V1Route creates the version 1 url, which lists an identity's annotations.
+func V1Route(id string) string {
+	return fmt.Sprintf("/v1/identities/%s/annotations", id)
+}
+
+// EscapedV1Route creates the version 1 url for client.
+func EscapedV1Route(id identity.Contract) string {
+	return V1Route(url.PathEscape(id.Printable()))
+}
+
 // instance is a receiver that encapsulates required dependencies.
 type instance struct {
-	store store.Contract
+	store  store.Contract
+	legacy bool
 }
 
-// New is a factory function that returns instance.
-func New(store store.Contract) *instance {
+// New is a factory function that returns instance; legacy selects the original responses, which report every
+// failure with CodeLegacyFailure and an empty body.
+func New(store store.Contract, legacy bool) *instance {
 	return &instance{
-		store: store,
+		store:  store,
+		legacy: legacy,
 	}
 }
 
-// Init adds package's route to muxRouter.
+// Init adds package's routes to muxRouter; the original route is kept as a deprecated alias of the version 1 route.
+// Both are named Name, which authorization policies refer to.
 func (i *instance) Init(muxRouter *mux.Router) {
-	muxRouter.HandleFunc(Route("{"+identityParam+"}"), i.handle).Methods(Method)
+	muxRouter.HandleFunc(V1Route("{"+identityParam+"}"), i.handle).Methods(V1Method).Name(Name)
+	muxRouter.HandleFunc(Route("{"+identityParam+"}"), deprecation.Handler(i.handle, successor)).
+		Methods(Method).
+		Name(Name)
+}
+
+// successor returns the version 1 url that replaces r.
+func successor(r *http.Request) string {
+	return EscapedV1Route(urlIdentity.New(urlIdentity.Unescape(mux.Vars(r)[identityParam])))
 }
 
 // handle implements package's functionality.
 func (i *instance) handle(w http.ResponseWriter, r *http.Request) {
-	value, result := i.store.FindByIdentity(urlIdentity.New(mux.Vars(r)[identityParam]))
-	if result != status.Success {
-		w.WriteHeader(CodeIdentityNotFound)
+	id := urlIdentity.New(urlIdentity.Unescape(mux.Vars(r)[identityParam]))
+	value, result := i.store.FindByIdentity(id)
+	switch result {
+	case status.Success:
+	case status.NotFound:
+		i.fail(w, r, problem.ErrIdentityNotFound.WithDetail(id.Printable()))
+		return
+	default:
+		i.fail(w, r, problem.ErrInternal.WithDetail("store returned status "+strconv.Itoa(int(result))))
 		return
 	}
 
-	body, err := json.Marshal(value)
+	_, encoder, _ := codec.Negotiate(r)
+	body, err := encoder.Marshal(value)
 	if err != nil {
-		w.WriteHeader(codeMarshalFailed)
+		i.fail(w, r, problem.ErrInternal.WithDetail(err.Error()))
 		return
 	}
 
+	w.Header().Set(codec.ContentTypeHeader, encoder.ContentType())
 	w.WriteHeader(CodeSuccess)
 	_, _ = w.Write(body)
 }
+
+// fail reports p, or CodeLegacyFailure if the instance reports legacy responses.
+func (i *instance) fail(w http.ResponseWriter, r *http.Request, p *problem.Instance) {
+	if i.legacy {
+		w.WriteHeader(CodeLegacyFailure)
+		return
+	}
+	problem.Write(w, r, p)
+}
@@ -15,12 +15,16 @@
 package find
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/deprecation"
 	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
 	"github.com/project-alvarium/go-store/internal/pkg/routable"
 	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
 
 	"github.com/project-alvarium/go-sdk/pkg/annotation"
 	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
@@ -37,8 +41,9 @@ import (
 // TestFind tests find route.
 func TestFind(t *testing.T) {
 	type testCase struct {
-		name string
-		test func(t *testing.T, muxRouter *mux.Router, store store.Contract)
+		name   string
+		legacy bool
+		test   func(t *testing.T, muxRouter *mux.Router, store store.Contract)
 	}
 
 	cases := []testCase{
@@ -48,6 +53,19 @@ func TestFind(t *testing.T) {
 				response := testInternal.SendRequestWithoutBody(t, muxRouter, Method, Route(test.FactoryRandomString()))
 
 				assert.Equal(t, CodeIdentityNotFound, response.Code)
+				assert.Equal(t, problem.ContentType, response.Header().Get(codec.ContentTypeHeader))
+				result, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeIdentityNotFound, result.Code)
+			},
+		},
+		{
+			name:   "Legacy (identity not found)",
+			legacy: true,
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, Method, Route(test.FactoryRandomString()))
+
+				assert.Equal(t, CodeLegacyFailure, response.Code)
 				assert.Nil(t, response.Body.Bytes())
 			},
 		},
@@ -65,11 +83,87 @@ func TestFind(t *testing.T) {
 				assert.Equal(t, testInternal.Marshal(t, []*annotation.Instance{value}), response.Body.Bytes())
 			},
 		},
+		{
+			name: "Version 1",
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				id := hash.New(test.FactoryRandomByteSlice())
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+				store.Create(idContract, value)
+
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, V1Method, EscapedV1Route(idContract))
+
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(t, testInternal.Marshal(t, []*annotation.Instance{value}), response.Body.Bytes())
+				assert.Empty(t, response.Header().Get(deprecation.Header))
+			},
+		},
+		{
+			name: "Deprecated alias",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				idContract := url.New(test.FactoryRandomString())
+
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, Method, EscapedRoute(idContract))
+
+				assert.Equal(t, CodeIdentityNotFound, response.Code)
+				assert.Equal(t, "true", response.Header().Get(deprecation.Header))
+				assert.Equal(
+					t,
+					"<"+EscapedV1Route(idContract)+">; rel=\"successor-version\"",
+					response.Header().Get(deprecation.LinkHeader),
+				)
+			},
+		},
+		{
+			name: "Deprecated alias (identity with a slash)",
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				id := hash.New(append([]byte{0xff, 0xff, 0xff}, test.FactoryRandomByteSlice()...))
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+				store.Create(idContract, value)
+
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, Method, EscapedRoute(idContract))
+
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(t, testInternal.Marshal(t, []*annotation.Instance{value}), response.Body.Bytes())
+				assert.Equal(
+					t,
+					"<"+EscapedV1Route(idContract)+">; rel=\"successor-version\"",
+					response.Header().Get(deprecation.LinkHeader),
+				)
+				assert.NotContains(t, response.Header().Get(deprecation.LinkHeader), "%252F")
+			},
+		},
+		{
+			name: "MessagePack",
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				id := hash.New(test.FactoryRandomByteSlice())
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+				store.Create(idContract, value)
+
+				response := testInternal.SendRequestWithHeader(
+					t,
+					muxRouter,
+					V1Method,
+					EscapedV1Route(idContract),
+					http.Header{codec.AcceptHeader: []string{codec.ContentTypeMsgPack}},
+					[]byte{},
+				)
+
+				format := codec.NewMsgPack()
+				expected, err := format.Marshal([]*annotation.Instance{value})
+				assert.Nil(t, err)
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(t, codec.ContentTypeMsgPack, response.Header().Get(codec.ContentTypeHeader))
+				assert.Equal(t, expected, response.Body.Bytes())
+			},
+		},
 	}
 
 	for i := range cases {
 		s := memory.New()
-		cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, []routable.Contract{New(s).Init})
+		cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, []routable.Contract{New(s, cases[i].legacy).Init})
 		t.Run(
 			cases[i].name,
 			func(t *testing.T) {
@@ -0,0 +1,133 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/authz"
+	"github.com/project-alvarium/go-store/internal/pkg/graph"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	queryParam         = "query"
+	operationNameParam = "operationName"
+	variablesParam     = "variables"
+	Name               = "graphql"
+	Method             = http.MethodPost
+	GetMethod          = http.MethodGet
+	CodeInvalidRequest = http.StatusBadRequest
+	CodeSuccess        = http.StatusOK
+)
+
+// Route creates a url.
+func Route() string {
+	return "/graphql"
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	graph       graph.Contract
+	maxBodySize int64
+	authorizer  authz.Contract
+}
+
+// New is a factory function that returns instance; request bodies larger than maxBodySize are rejected unless it is
+// not positive.
+func New(graph graph.Contract, maxBodySize int64) *instance {
+	return &instance{
+		graph:       graph,
+		maxBodySize: maxBodySize,
+		authorizer:  authz.NewDefault(),
+	}
+}
+
+// SetAuthorizer provides for method injection of the authorizer that decides which identities a principal's queries
+// see; by default, queries see the identities the principal's prefixes permit.
+func (i *instance) SetAuthorizer(authorizer authz.Contract) {
+	i.authorizer = authorizer
+}
+
+// Init adds package's routes to muxRouter.  Both are named Name, which authorization refers to.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.HandleFunc(Route(), i.handle).Methods(Method).Name(Name)
+	muxRouter.HandleFunc(Route(), i.handleGet).Methods(GetMethod).Name(Name)
+}
+
+// execute runs request, seeing only the identities the principal may use Name on, and writes its result; query
+// errors are reported within the result.
+func (i *instance) execute(w http.ResponseWriter, r *http.Request, request graph.Request) {
+	if request.Query == "" {
+		problem.Write(w, r, problem.ErrMalformedBody.WithDetail("query is required"))
+		return
+	}
+
+	principal, _ := auth.FromContext(r.Context())
+	ctx := graph.NewContext(r.Context(), func(id string) bool { return i.authorizer.Allows(principal, Name, id) })
+	body, err := json.Marshal(i.graph.Execute(ctx, request))
+	if err != nil {
+		problem.Write(w, r, problem.ErrInternal.WithDetail(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(CodeSuccess)
+	_, _ = w.Write(body)
+}
+
+// handle implements package's functionality for a request carried in a JSON body.
+func (i *instance) handle(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader = r.Body
+	if i.maxBodySize > 0 {
+		body = http.MaxBytesReader(w, r.Body, i.maxBodySize)
+	}
+
+	var request graph.Request
+	if err := json.NewDecoder(body).Decode(&request); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.Write(w, r, problem.ErrBodyTooLarge.WithDetail(err.Error()))
+			return
+		}
+		problem.Write(w, r, problem.ErrMalformedBody.WithDetail(err.Error()))
+		return
+	}
+
+	i.execute(w, r, request)
+}
+
+// handleGet implements package's functionality for a request carried in query parameters.
+func (i *instance) handleGet(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	request := graph.Request{
+		Query:         values.Get(queryParam),
+		OperationName: values.Get(operationNameParam),
+	}
+	if variables := values.Get(variablesParam); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+			problem.Write(w, r, problem.ErrMalformedBody.WithDetail("invalid variables: "+err.Error()))
+			return
+		}
+	}
+
+	i.execute(w, r, request)
+}
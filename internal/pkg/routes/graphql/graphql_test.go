@@ -0,0 +1,569 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package graphql
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	authStub "github.com/project-alvarium/go-store/internal/pkg/auth/stub"
+	"github.com/project-alvarium/go-store/internal/pkg/author"
+	"github.com/project-alvarium/go-store/internal/pkg/graph"
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/index"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	assessorMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/assessor/pki/metadata"
+	assessMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
+	signerMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/signer/signpkcs1v15/metadata"
+	publishMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata"
+	publisherMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/publisher/example/metadata"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+const maxBodySize = 1 << 12
+
+// assertProblem asserts that body is a problem document with the given code.
+func assertProblem(t *testing.T, code string, body []byte) {
+	failure, err := problem.Decode(body)
+	if err != nil {
+		assert.FailNow(t, "Unexpected problem.Decode failure:", err.Error())
+	}
+	assert.Equal(t, code, failure.Code)
+}
+
+// fixture records the data stored by seed.
+type fixture struct {
+	parent    string
+	child     string
+	publicKey []byte
+}
+
+// seed stores a parent identity with pki and assess annotations and a child identity derived from it with a
+// publish annotation.
+func seed(t *testing.T, s store.Contract) fixture {
+	parent := hash.New(test.FactoryRandomByteSlice())
+	child := hash.New(test.FactoryRandomByteSlice())
+	publicKey := test.FactoryRandomByteSlice()
+
+	write := func(fn func(identity.Contract, *annotation.Instance) status.Value, id identity.Contract,
+		m *annotation.Instance) {
+		if result := fn(urlIdentity.New(id.Printable()), m); result != status.Success {
+			assert.FailNow(t, "Unexpected store failure:", result)
+		}
+	}
+
+	write(
+		s.Create,
+		parent,
+		annotation.New(
+			ulid.New().Get(),
+			parent,
+			nil,
+			pkiMetadata.New(
+				nil,
+				test.FactoryRandomByteSlice(),
+				test.FactoryRandomByteSlice(),
+				publicKey,
+				signerMetadata.NewSuccess(crypto.SHA256, test.FactoryRandomString()),
+			),
+		),
+	)
+	write(
+		s.Append,
+		parent,
+		annotation.New(
+			ulid.New().Get(),
+			parent,
+			nil,
+			assessMetadata.New(nil, assessorMetadata.NewSuccess(true, []string{test.FactoryRandomString()})),
+		),
+	)
+	write(
+		s.Create,
+		child,
+		annotation.New(ulid.New().Get(), child, parent, publishMetadata.New(nil, publisherMetadata.NewSuccess())),
+	)
+
+	return fixture{parent: parent.Printable(), child: child.Printable(), publicKey: publicKey}
+}
+
+// response defines a GraphQL response whose data has not yet been decoded.
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// execute posts query with variables, decodes the response's data into data, and returns its error messages.
+func execute(t *testing.T, muxRouter *mux.Router, query string, variables map[string]interface{},
+	data interface{}) []string {
+	result := testInternal.SendRequestWithBody(
+		t,
+		muxRouter,
+		Method,
+		Route(),
+		testInternal.Marshal(t, graph.Request{Query: query, Variables: variables}),
+	)
+	assert.Equal(t, CodeSuccess, result.Code)
+
+	var value response
+	if err := json.Unmarshal(result.Body.Bytes(), &value); err != nil {
+		assert.FailNow(t, "Unexpected json.Unmarshal failure:", err.Error())
+	}
+	if data != nil && len(value.Data) > 0 {
+		assert.Nil(t, json.Unmarshal(value.Data, data))
+	}
+
+	messages := make([]string, 0, len(value.Errors))
+	for i := range value.Errors {
+		messages = append(messages, value.Errors[i].Message)
+	}
+	return messages
+}
+
+// TestGraphQL tests the graphql routes.
+func TestGraphQL(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T, muxRouter *mux.Router, store store.Contract)
+	}
+
+	type annotationResult struct {
+		TypeName     string `json:"__typename"`
+		MetadataType string `json:"metadataType"`
+		SignerType   string `json:"signerType"`
+		PublicKey    string `json:"publicKey"`
+		AssessorType string `json:"assessorType"`
+		Result       string `json:"result"`
+	}
+
+	cases := []testCase{
+		{
+			name: "missing query",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				result := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					Method,
+					Route(),
+					testInternal.Marshal(t, graph.Request{}),
+				)
+
+				assert.Equal(t, CodeInvalidRequest, result.Code)
+				assertProblem(t, problem.CodeMalformedBody, result.Body.Bytes())
+			},
+		},
+		{
+			name: "malformed body",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				result := testInternal.SendRequestWithBody(t, muxRouter, Method, Route(), []byte("{"))
+
+				assert.Equal(t, http.StatusBadRequest, result.Code)
+				assertProblem(t, problem.CodeMalformedBody, result.Body.Bytes())
+			},
+		},
+		{
+			name: "body too large",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				query := "{ identities { id } }" + strings.Repeat(" ", maxBodySize)
+				result := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					Method,
+					Route(),
+					testInternal.Marshal(t, graph.Request{Query: query}),
+				)
+
+				assert.Equal(t, http.StatusRequestEntityTooLarge, result.Code)
+				assertProblem(t, problem.CodeBodyTooLarge, result.Body.Bytes())
+			},
+		},
+		{
+			name: "invalid query",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				messages := execute(t, muxRouter, "{ identity(id: \"x\") { unknown } }", nil, nil)
+
+				assert.Len(t, messages, 1)
+			},
+		},
+		{
+			name: "identity not found",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				var data struct {
+					Identity *struct{} `json:"identity"`
+				}
+				messages := execute(
+					t,
+					muxRouter,
+					"{ identity(id: \""+test.FactoryRandomString()+"\") { id } }",
+					nil,
+					&data,
+				)
+
+				assert.Empty(t, messages)
+				assert.Nil(t, data.Identity)
+			},
+		},
+		{
+			name: "annotations typed per metadata kind",
+			test: func(t *testing.T, muxRouter *mux.Router, s store.Contract) {
+				f := seed(t, s)
+
+				var data struct {
+					Identity struct {
+						ID          string             `json:"id"`
+						Annotations []annotationResult `json:"annotations"`
+					} `json:"identity"`
+				}
+				messages := execute(
+					t,
+					muxRouter,
+					`query($id: String!) {
+						identity(id: $id) {
+							id
+							annotations {
+								__typename
+								metadataType
+								... on PkiAnnotation { signerType publicKey result }
+								... on AssessAnnotation { assessorType result }
+							}
+						}
+					}`,
+					map[string]interface{}{"id": f.parent},
+					&data,
+				)
+
+				assert.Empty(t, messages)
+				assert.Equal(t, f.parent, data.Identity.ID)
+				assert.Equal(
+					t,
+					[]annotationResult{
+						{
+							TypeName:     "PkiAnnotation",
+							MetadataType: pkiMetadata.Kind,
+							SignerType:   signerMetadata.Kind,
+							PublicKey:    base64.StdEncoding.EncodeToString(f.publicKey),
+							Result:       signerMetadata.SuccessResult,
+						},
+						{
+							TypeName:     "AssessAnnotation",
+							MetadataType: assessMetadata.Kind,
+							AssessorType: assessorMetadata.Kind,
+							Result:       assessorMetadata.SuccessResult,
+						},
+					},
+					data.Identity.Annotations,
+				)
+			},
+		},
+		{
+			name: "annotations filtered by kind",
+			test: func(t *testing.T, muxRouter *mux.Router, s store.Contract) {
+				f := seed(t, s)
+
+				var data struct {
+					Identity struct {
+						Annotations []annotationResult `json:"annotations"`
+					} `json:"identity"`
+				}
+				messages := execute(
+					t,
+					muxRouter,
+					"query($id: String!, $kind: String) { identity(id: $id) { annotations(kind: $kind) { __typename } } }",
+					map[string]interface{}{"id": f.parent, "kind": assessMetadata.Kind},
+					&data,
+				)
+
+				assert.Empty(t, messages)
+				assert.Equal(t, []annotationResult{{TypeName: "AssessAnnotation"}}, data.Identity.Annotations)
+			},
+		},
+		{
+			name: "identities filtered by prefix and first",
+			test: func(t *testing.T, muxRouter *mux.Router, s store.Contract) {
+				f := seed(t, s)
+
+				type identityResult struct {
+					ID string `json:"id"`
+				}
+				var data struct {
+					All    []identityResult `json:"all"`
+					First  []identityResult `json:"first"`
+					Prefix []identityResult `json:"prefix"`
+				}
+				messages := execute(
+					t,
+					muxRouter,
+					`query($prefix: String) {
+						all: identities { id }
+						first: identities(first: 1) { id }
+						prefix: identities(prefix: $prefix) { id }
+					}`,
+					map[string]interface{}{"prefix": f.child},
+					&data,
+				)
+
+				assert.Empty(t, messages)
+				assert.Equal(t, []identityResult{{ID: f.parent}, {ID: f.child}}, data.All)
+				assert.Equal(t, []identityResult{{ID: f.parent}}, data.First)
+				assert.Equal(t, []identityResult{{ID: f.child}}, data.Prefix)
+			},
+		},
+		{
+			name: "lineage",
+			test: func(t *testing.T, muxRouter *mux.Router, s store.Contract) {
+				f := seed(t, s)
+
+				var data struct {
+					Identity struct {
+						Next []struct {
+							ID          string             `json:"id"`
+							Annotations []annotationResult `json:"annotations"`
+							Previous    []struct {
+								ID string `json:"id"`
+							} `json:"previous"`
+						} `json:"next"`
+					} `json:"identity"`
+				}
+				messages := execute(
+					t,
+					muxRouter,
+					`query($id: String!) {
+						identity(id: $id) { next(first: 2) { id annotations(first: 5) { __typename } previous(first: 1) { id } } }
+					}`,
+					map[string]interface{}{"id": f.parent},
+					&data,
+				)
+
+				assert.Empty(t, messages)
+				if assert.Len(t, data.Identity.Next, 1) {
+					assert.Equal(t, f.child, data.Identity.Next[0].ID)
+					assert.Equal(t, []annotationResult{{TypeName: "PublishAnnotation"}}, data.Identity.Next[0].Annotations)
+					if assert.Len(t, data.Identity.Next[0].Previous, 1) {
+						assert.Equal(t, f.parent, data.Identity.Next[0].Previous[0].ID)
+					}
+				}
+			},
+		},
+		{
+			name: "depth limit",
+			test: func(t *testing.T, muxRouter *mux.Router, s store.Contract) {
+				f := seed(t, s)
+
+				var data map[string]interface{}
+				messages := execute(
+					t,
+					muxRouter,
+					`query($id: String!) { identity(id: $id) { ...hop } }
+					fragment hop on Identity { next { next { next { id } } } }`,
+					map[string]interface{}{"id": f.parent},
+					&data,
+				)
+
+				assert.Equal(t, []string{"query depth exceeds limit 4"}, messages)
+				assert.Nil(t, data)
+			},
+		},
+		{
+			name: "complexity limit",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				messages := execute(t, muxRouter, "{ identities { annotations { unique } } }", nil, nil)
+
+				assert.Equal(t, []string{"query complexity exceeds limit 1000"}, messages)
+			},
+		},
+		{
+			name: "complexity bounded by first",
+			test: func(t *testing.T, muxRouter *mux.Router, s store.Contract) {
+				seed(t, s)
+
+				var data struct {
+					Identities []struct {
+						Annotations []struct {
+							Unique string `json:"unique"`
+						} `json:"annotations"`
+					} `json:"identities"`
+				}
+				messages := execute(
+					t,
+					muxRouter,
+					"query($first: Int) { identities(first: $first) { annotations(first: 1) { unique } } }",
+					map[string]interface{}{"first": 2},
+					&data,
+				)
+
+				assert.Empty(t, messages)
+				assert.Len(t, data.Identities, 2)
+			},
+		},
+		{
+			name: "get",
+			test: func(t *testing.T, muxRouter *mux.Router, s store.Contract) {
+				f := seed(t, s)
+
+				values := url.Values{}
+				values.Set(queryParam, "query($id: String!) { identity(id: $id) { id } }")
+				values.Set(variablesParam, string(testInternal.Marshal(t, map[string]string{"id": f.child})))
+				result := testInternal.SendRequestWithoutBody(t, muxRouter, GetMethod, Route()+"?"+values.Encode())
+
+				assert.Equal(t, CodeSuccess, result.Code)
+				assert.JSONEq(t, `{"data":{"identity":{"id":"`+f.child+`"}}}`, result.Body.String())
+			},
+		},
+		{
+			name: "get with invalid variables",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				values := url.Values{}
+				values.Set(queryParam, "{ identities { id } }")
+				values.Set(variablesParam, "{")
+				result := testInternal.SendRequestWithoutBody(t, muxRouter, GetMethod, Route()+"?"+values.Encode())
+
+				assert.Equal(t, http.StatusBadRequest, result.Code)
+				assertProblem(t, problem.CodeMalformedBody, result.Body.Bytes())
+			},
+		},
+	}
+
+	for i := range cases {
+		s := index.New(memory.New(), nil)
+		g, err := graph.New(s, graph.Limits{MaxDepth: 4, MaxComplexity: 1000})
+		if err != nil {
+			assert.FailNow(t, "Unexpected graph.New failure:", err.Error())
+		}
+		cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, []routable.Contract{New(g, maxBodySize).Init})
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				cases[i].test(t, muxRouter, s)
+				cancel()
+				wg.Wait()
+			},
+		)
+	}
+}
+
+// TestGraphQL_Authorization tests that queries only see the identities the principal's prefixes permit.
+func TestGraphQL_Authorization(t *testing.T) {
+	s := index.New(memory.New(), nil)
+	f := seed(t, s)
+	g, err := graph.New(s, graph.Limits{MaxDepth: 4, MaxComplexity: 1000})
+	if err != nil {
+		assert.FailNow(t, "Unexpected graph.New failure:", err.Error())
+	}
+	principal := &auth.Principal{Subject: test.FactoryRandomString(), Prefixes: []string{f.child}}
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{authStub.Authenticated(principal), New(g, maxBodySize).Init},
+	)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	type identityResult struct {
+		ID string `json:"id"`
+	}
+	var data struct {
+		Parent     *identityResult  `json:"parent"`
+		Identities []identityResult `json:"identities"`
+	}
+	messages := execute(
+		t,
+		muxRouter,
+		`query($id: String!) {
+			parent: identity(id: $id) { id }
+			identities { id }
+		}`,
+		map[string]interface{}{"id": f.parent},
+		&data,
+	)
+
+	assert.Empty(t, messages)
+	assert.Nil(t, data.Parent)
+	assert.Equal(t, []identityResult{{ID: f.child}}, data.Identities)
+}
+
+// TestGraphQL_Author tests that annotations report the principal recorded as their author.
+func TestGraphQL_Author(t *testing.T) {
+	s := index.New(memory.New(), nil)
+	id := hash.New(append(test.FactoryRandomByteSlice(), test.FactoryRandomFixedLengthAlphanumericByteSlice(1)...))
+	uniques := ulid.New()
+	authored := annotation.New(uniques.Get(), id, nil, publishMetadata.New(nil, publisherMetadata.NewSuccess()))
+	anonymous := annotation.New(uniques.Get(), id, nil, publishMetadata.New(nil, publisherMetadata.NewSuccess()))
+	assert.Equal(t, status.Success, s.Create(urlIdentity.New(id.Printable()), authored))
+	assert.Equal(t, status.Success, s.Append(urlIdentity.New(id.Printable()), anonymous))
+	principal := &auth.Principal{Subject: test.FactoryRandomString(), Method: "jwt"}
+	authors := author.New()
+	authors.Record(auth.NewContext(context.Background(), principal), authored)
+	g, err := graph.New(s, graph.Limits{MaxDepth: 4, MaxComplexity: 1000})
+	if err != nil {
+		assert.FailNow(t, "Unexpected graph.New failure:", err.Error())
+	}
+	g.SetAuthors(authors)
+	cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, []routable.Contract{New(g, maxBodySize).Init})
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	type authorResult struct {
+		Subject string  `json:"subject"`
+		Method  string  `json:"method"`
+		Tenant  *string `json:"tenant"`
+	}
+	var data struct {
+		Identity struct {
+			Annotations []struct {
+				Author *authorResult `json:"author"`
+			} `json:"annotations"`
+		} `json:"identity"`
+	}
+	messages := execute(
+		t,
+		muxRouter,
+		`query($id: String!) { identity(id: $id) { annotations { author { subject method tenant } } } }`,
+		map[string]interface{}{"id": id.Printable()},
+		&data,
+	)
+
+	assert.Empty(t, messages)
+	if assert.Len(t, data.Identity.Annotations, 2) {
+		assert.Equal(t, &authorResult{Subject: principal.Subject, Method: "jwt"}, data.Identity.Annotations[0].Author)
+		assert.Nil(t, data.Identity.Annotations[1].Author)
+	}
+}
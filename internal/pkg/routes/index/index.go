@@ -0,0 +1,137 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/authz"
+	"github.com/project-alvarium/go-store/internal/pkg/index"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/project-alvarium/go-sdk/pkg/status"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	nameParam          = "name"
+	valueParam         = "value"
+	Name               = "index"
+	Method             = http.MethodGet
+	RebuildMethod      = http.MethodPut
+	CodeIndexNotFound  = http.StatusNotFound
+	CodeValueMissing   = http.StatusBadRequest
+	CodeRebuildFailed  = http.StatusInternalServerError
+	CodeSuccess        = http.StatusOK
+	CodeRebuildSuccess = http.StatusOK
+)
+
+// Route creates a url.
+func Route(name string) string {
+	return fmt.Sprintf("/index/%s", name)
+}
+
+// EscapedRoute creates a url for client.
+func EscapedRoute(name, value string) string {
+	return Route(url.PathEscape(name)) + "?" + url.Values{valueParam: []string{value}}.Encode()
+}
+
+// RebuildRoute creates the index rebuild url.
+func RebuildRoute() string {
+	return "/rebuildIndexes"
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	index      index.Contract
+	authorizer authz.Contract
+}
+
+// New is a factory function that returns instance.
+func New(index index.Contract) *instance {
+	return &instance{
+		index:      index,
+		authorizer: authz.NewDefault(),
+	}
+}
+
+// SetAuthorizer provides for method injection of the authorizer that decides which entries a principal may see; by
+// default, principals see the entries their prefixes permit.
+func (i *instance) SetAuthorizer(authorizer authz.Contract) {
+	i.authorizer = authorizer
+}
+
+// Init adds package's routes to muxRouter; the lookup route is named Name, which authorization refers to.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.HandleFunc(Route("{"+nameParam+"}"), i.handle).Methods(Method).Name(Name)
+	muxRouter.HandleFunc(RebuildRoute(), i.handleRebuild).Methods(RebuildMethod)
+}
+
+// handle implements package's lookup functionality; entries whose identities the principal may not use Name on are
+// left out.
+func (i *instance) handle(w http.ResponseWriter, r *http.Request) {
+	values, exists := r.URL.Query()[valueParam]
+	if !exists || len(values) == 0 {
+		problem.Write(w, r, problem.ErrInvalidQuery.WithDetail(valueParam+" is required"))
+		return
+	}
+
+	name := mux.Vars(r)[nameParam]
+	entries, result := i.index.Find(name, values[0])
+	if result != status.Success {
+		problem.Write(w, r, problem.ErrIndexNotFound.WithDetail(name))
+		return
+	}
+
+	principal, _ := auth.FromContext(r.Context())
+	permitted := make([]index.Entry, 0, len(entries))
+	for key := range entries {
+		if i.authorizer.Allows(principal, Name, entries[key].Identity) {
+			permitted = append(permitted, entries[key])
+		}
+	}
+
+	body, err := json.Marshal(permitted)
+	if err != nil {
+		problem.Write(w, r, problem.ErrInternal.WithDetail(err.Error()))
+		return
+	}
+
+	w.WriteHeader(CodeSuccess)
+	_, _ = w.Write(body)
+}
+
+// handleRebuild implements package's rebuild functionality.
+func (i *instance) handleRebuild(w http.ResponseWriter, r *http.Request) {
+	result := i.index.Rebuild()
+	if result != status.Success {
+		problem.Write(w, r, problem.ErrInternal.WithDetail("the store cannot be walked"))
+		return
+	}
+
+	resultInBytes, err := json.Marshal(result)
+	if err != nil {
+		problem.Write(w, r, problem.ErrInternal.WithDetail(err.Error()))
+		return
+	}
+
+	w.WriteHeader(CodeRebuildSuccess)
+	_, _ = w.Write(resultInBytes)
+}
@@ -0,0 +1,167 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package index
+
+import (
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	authStub "github.com/project-alvarium/go-store/internal/pkg/auth/stub"
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/index"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIndex tests index routes.
+func TestIndex(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T, muxRouter *mux.Router, underlying store.Contract, indexed store.Contract)
+	}
+
+	kind := test.FactoryRandomString()
+	name := test.FactoryRandomString()
+
+	cases := []testCase{
+		{
+			name: "Index not found",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract, _ store.Contract) {
+				response := testInternal.SendRequestWithoutBody(
+					t,
+					muxRouter,
+					Method,
+					EscapedRoute(test.FactoryRandomString(), test.FactoryRandomString()),
+				)
+
+				assert.Equal(t, CodeIndexNotFound, response.Code)
+				failure, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeIndexNotFound, failure.Code)
+			},
+		},
+		{
+			name: "Value missing",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract, _ store.Contract) {
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, Method, Route(name))
+
+				assert.Equal(t, CodeValueMissing, response.Code)
+				failure, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeInvalidQuery, failure.Code)
+			},
+		},
+		{
+			name: "Success",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract, indexed store.Contract) {
+				id := hash.New(test.FactoryRandomByteSlice())
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.New(kind, "failure/one"))
+				assert.Equal(t, status.Success, indexed.Create(idContract, value))
+
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, Method, EscapedRoute(name, "failure/one"))
+
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(
+					t,
+					testInternal.Marshal(t, []index.Entry{{Identity: idContract.Printable(), Unique: value.Unique}}),
+					response.Body.Bytes(),
+				)
+			},
+		},
+		{
+			name: "Rebuild",
+			test: func(t *testing.T, muxRouter *mux.Router, underlying store.Contract, _ store.Contract) {
+				id := hash.New(test.FactoryRandomByteSlice())
+				idContract := url.New(id.Printable())
+				value := annotation.New(ulid.New().Get(), id, nil, metadataStub.New(kind, "x"))
+				assert.Equal(t, status.Success, underlying.Create(idContract, value))
+
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, RebuildMethod, RebuildRoute())
+
+				assert.Equal(t, CodeRebuildSuccess, response.Code)
+				assert.Equal(t, testInternal.Marshal(t, status.Success), response.Body.Bytes())
+
+				response = testInternal.SendRequestWithoutBody(t, muxRouter, Method, EscapedRoute(name, "x"))
+
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(
+					t,
+					testInternal.Marshal(t, []index.Entry{{Identity: idContract.Printable(), Unique: value.Unique}}),
+					response.Body.Bytes(),
+				)
+			},
+		},
+	}
+
+	for i := range cases {
+		s := memory.New()
+		x := index.New(s, []index.Definition{{Name: name, MetadataKind: kind, Path: "value"}})
+		cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, []routable.Contract{New(x).Init})
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				cases[i].test(t, muxRouter, s, x)
+				cancel()
+				wg.Wait()
+			},
+		)
+	}
+}
+
+// TestIndex_Authorization tests that lookups leave out entries whose identities the principal may not use.
+func TestIndex_Authorization(t *testing.T) {
+	kind := test.FactoryRandomString()
+	name := test.FactoryRandomString()
+	prefix := test.FactoryRandomFixedLengthAlphanumericString(16)
+	x := index.New(memory.New(), []index.Definition{{Name: name, MetadataKind: kind, Path: "value"}})
+	principal := &auth.Principal{Subject: test.FactoryRandomString(), Prefixes: []string{prefix}}
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{authStub.Authenticated(principal), New(x).Init},
+	)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	permitted := url.New(prefix + test.FactoryRandomString())
+	value := annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, metadataStub.New(kind, "v"))
+	assert.Equal(t, status.Success, x.Create(permitted, value))
+	other := annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, metadataStub.New(kind, "v"))
+	assert.Equal(t, status.Success, x.Create(url.New(test.FactoryRandomFixedLengthAlphanumericString(16)+prefix), other))
+
+	response := testInternal.SendRequestWithoutBody(t, muxRouter, Method, EscapedRoute(name, "v"))
+
+	assert.Equal(t, CodeSuccess, response.Code)
+	assert.Equal(
+		t,
+		testInternal.Marshal(t, []index.Entry{{Identity: permitted.Printable(), Unique: value.Unique}}),
+		response.Body.Bytes(),
+	)
+}
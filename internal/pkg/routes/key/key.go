@@ -0,0 +1,136 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package key
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/auth/apikey"
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	idParam      = "id"
+	Method       = http.MethodGet
+	CreateMethod = http.MethodPost
+	RevokeMethod = http.MethodDelete
+	CodeSuccess  = http.StatusOK
+	CodeCreated  = http.StatusCreated
+	CodeRevoked  = http.StatusNoContent
+)
+
+// Request is the body of a request to create a key; Tenant, if not empty, binds the key to a tenant.
+type Request struct {
+	Name   string       `json:"name"`
+	Scopes []auth.Scope `json:"scopes"`
+	Tenant string       `json:"tenant,omitempty"`
+}
+
+// Route creates a url.
+func Route() string {
+	return "/v1/keys"
+}
+
+// RevokeRoute creates the url of the key with the given ID.
+func RevokeRoute(id string) string {
+	return fmt.Sprintf("%s/%s", Route(), id)
+}
+
+// EscapedRevokeRoute creates the url of the key with the given ID for client.
+func EscapedRevokeRoute(id string) string {
+	return RevokeRoute(url.PathEscape(id))
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	keys apikey.Contract
+}
+
+// New is a factory function that returns instance.
+func New(keys apikey.Contract) *instance {
+	return &instance{
+		keys: keys,
+	}
+}
+
+// Init adds package's routes to muxRouter.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.HandleFunc(Route(), i.handle).Methods(Method)
+	muxRouter.HandleFunc(Route(), i.handleCreate).Methods(CreateMethod)
+	muxRouter.HandleFunc(RevokeRoute("{"+idParam+"}"), i.handleRevoke).Methods(RevokeMethod)
+}
+
+// write marshals value and writes it as the response body with code.
+func write(w http.ResponseWriter, r *http.Request, code int, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		problem.Write(w, r, problem.ErrInternal.WithDetail(err.Error()))
+		return
+	}
+
+	w.Header().Set(codec.ContentTypeHeader, codec.ContentTypeJSON)
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+}
+
+// handle returns the keys, without their secrets.
+func (i *instance) handle(w http.ResponseWriter, r *http.Request) {
+	write(w, r, CodeSuccess, i.keys.List())
+}
+
+// handleCreate creates the key described by the request body and returns it with its secret.
+func (i *instance) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var request Request
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		problem.Write(w, r, problem.ErrMalformedBody.WithDetail(err.Error()))
+		return
+	}
+
+	issued, err := i.keys.Create(request.Name, request.Scopes, request.Tenant)
+	switch {
+	case errors.Is(err, apikey.ErrInvalidScopes):
+		problem.Write(w, r, problem.ErrInvalidKey.WithDetail(err.Error()))
+		return
+	case err != nil:
+		problem.Write(w, r, problem.ErrInternal.WithDetail(err.Error()))
+		return
+	}
+
+	write(w, r, CodeCreated, issued)
+}
+
+// handleRevoke revokes a key.
+func (i *instance) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)[idParam]
+	revoked, err := i.keys.Revoke(id)
+	switch {
+	case err != nil:
+		problem.Write(w, r, problem.ErrInternal.WithDetail(err.Error()))
+		return
+	case !revoked:
+		problem.Write(w, r, problem.ErrKeyNotFound.WithDetail(id))
+		return
+	}
+
+	w.WriteHeader(CodeRevoked)
+}
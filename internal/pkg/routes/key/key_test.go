@@ -0,0 +1,146 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package key
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/auth/apikey"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// unmarshal decodes body into v.
+func unmarshal(t *testing.T, body []byte, v interface{}) {
+	if err := json.Unmarshal(body, v); err != nil {
+		assert.FailNow(t, "Unexpected unmarshal failure:", err.Error())
+	}
+}
+
+// assertProblem asserts that response carries a problem document with code.
+func assertProblem(t *testing.T, code string, body []byte) {
+	failure, err := problem.Decode(body)
+	assert.Nil(t, err)
+	assert.Equal(t, code, failure.Code)
+}
+
+// TestKey tests key management routes.
+func TestKey(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T, muxRouter *mux.Router, keys apikey.Contract)
+	}
+
+	cases := []testCase{
+		{
+			name: "Create malformed request",
+			test: func(t *testing.T, muxRouter *mux.Router, _ apikey.Contract) {
+				response := testInternal.SendRequestWithBody(t, muxRouter, CreateMethod, Route(), []byte("{"))
+
+				assert.Equal(t, http.StatusBadRequest, response.Code)
+				assertProblem(t, problem.CodeMalformedBody, response.Body.Bytes())
+			},
+		},
+		{
+			name: "Create invalid scopes",
+			test: func(t *testing.T, muxRouter *mux.Router, keys apikey.Contract) {
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					CreateMethod,
+					Route(),
+					testInternal.Marshal(t, Request{Name: test.FactoryRandomString(), Scopes: []auth.Scope{"unknown"}}),
+				)
+
+				assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+				assertProblem(t, problem.CodeInvalidKey, response.Body.Bytes())
+				assert.Empty(t, keys.List())
+			},
+		},
+		{
+			name: "Create list revoke",
+			test: func(t *testing.T, muxRouter *mux.Router, keys apikey.Contract) {
+				request := Request{Name: test.FactoryRandomString(), Scopes: []auth.Scope{auth.ScopeRead}}
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					CreateMethod,
+					Route(),
+					testInternal.Marshal(t, request),
+				)
+				assert.Equal(t, CodeCreated, response.Code)
+				var issued apikey.Issued
+				unmarshal(t, response.Body.Bytes(), &issued)
+				assert.Equal(t, request.Name, issued.Name)
+				assert.Equal(t, request.Scopes, issued.Scopes)
+				assert.NotEmpty(t, issued.Secret)
+				assert.Empty(t, issued.Hash)
+
+				response = testInternal.SendRequestWithoutBody(t, muxRouter, Method, Route())
+				assert.Equal(t, CodeSuccess, response.Code)
+				var listed []apikey.Key
+				unmarshal(t, response.Body.Bytes(), &listed)
+				assert.Equal(t, keys.List(), listed)
+				assert.Len(t, listed, 1)
+				assert.NotContains(t, response.Body.String(), issued.Secret)
+
+				response = testInternal.SendRequestWithoutBody(t, muxRouter, RevokeMethod, EscapedRevokeRoute(issued.ID))
+				assert.Equal(t, CodeRevoked, response.Code)
+				assert.Empty(t, response.Body.Bytes())
+				assert.Empty(t, keys.List())
+			},
+		},
+		{
+			name: "Revoke unknown key",
+			test: func(t *testing.T, muxRouter *mux.Router, _ apikey.Contract) {
+				response := testInternal.SendRequestWithoutBody(
+					t,
+					muxRouter,
+					RevokeMethod,
+					EscapedRevokeRoute(test.FactoryRandomFixedLengthAlphanumericString(16)),
+				)
+
+				assert.Equal(t, http.StatusNotFound, response.Code)
+				assertProblem(t, problem.CodeKeyNotFound, response.Body.Bytes())
+			},
+		},
+	}
+
+	for i := range cases {
+		keys, err := apikey.New("")
+		if err != nil {
+			assert.FailNow(t, "Unexpected apikey.New failure:", err.Error())
+		}
+		cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, []routable.Contract{New(keys).Init})
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				cases[i].test(t, muxRouter, keys)
+				cancel()
+				wg.Wait()
+			},
+		)
+	}
+}
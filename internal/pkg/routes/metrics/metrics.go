@@ -0,0 +1,66 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/project-alvarium/go-store/internal/pkg/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	Method      = http.MethodGet
+	CodeSuccess = http.StatusOK
+	CodeFailed  = http.StatusInternalServerError
+)
+
+// Route creates a url.
+func Route() string {
+	return "/metrics"
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	metrics metrics.Contract
+}
+
+// New is a factory function that returns instance.
+func New(metrics metrics.Contract) *instance {
+	return &instance{
+		metrics: metrics,
+	}
+}
+
+// Init adds package's route to muxRouter.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.HandleFunc(Route(), i.handle).Methods(Method)
+}
+
+// handle implements package's functionality.
+func (i *instance) handle(w http.ResponseWriter, _ *http.Request) {
+	var buffer bytes.Buffer
+	if err := i.metrics.Write(&buffer); err != nil {
+		w.WriteHeader(CodeFailed)
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+	w.WriteHeader(CodeSuccess)
+	_, _ = w.Write(buffer.Bytes())
+}
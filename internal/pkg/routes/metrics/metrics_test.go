@@ -0,0 +1,86 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package metrics
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/metrics"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failing is metrics that cannot be written.
+type failing struct{}
+
+// Write returns an error.
+func (failing) Write(io.Writer) error {
+	return errors.New("unavailable")
+}
+
+// TestMetrics tests the metrics route.
+func TestMetrics(t *testing.T) {
+	type testCase struct {
+		name    string
+		metrics metrics.Contract
+		code    int
+		body    string
+	}
+
+	cases := []testCase{
+		{
+			name:    "success",
+			metrics: metrics.New(),
+			code:    CodeSuccess,
+			body:    "# TYPE http_requests_total counter",
+		},
+		{
+			name:    "failure",
+			metrics: failing{},
+			code:    CodeFailed,
+			body:    "unavailable",
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				cancel, wg, muxRouter := testInternal.NewSUT(
+					pkg.Run,
+					[]routable.Contract{New(cases[i].metrics).Init},
+				)
+				defer func() {
+					cancel()
+					wg.Wait()
+				}()
+
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, Method, Route())
+
+				assert.Equal(t, cases[i].code, response.Code)
+				assert.True(t, strings.Contains(response.Body.String(), cases[i].body))
+				if cases[i].code == CodeSuccess {
+					assert.Equal(t, metrics.ContentType, response.Header().Get("Content-Type"))
+				}
+			},
+		)
+	}
+}
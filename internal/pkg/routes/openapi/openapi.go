@@ -0,0 +1,55 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	Method      = http.MethodGet
+	CodeSuccess = http.StatusOK
+)
+
+// Route creates a url.
+func Route() string {
+	return "/openapi.json"
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	document []byte
+}
+
+// New is a factory function that returns instance; document is the OpenAPI document served.
+func New(document []byte) *instance {
+	return &instance{
+		document: document,
+	}
+}
+
+// Init adds package's route to muxRouter.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.HandleFunc(Route(), i.handle).Methods(Method)
+}
+
+// handle implements package's functionality.
+func (i *instance) handle(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(CodeSuccess)
+	_, _ = w.Write(i.document)
+}
@@ -0,0 +1,43 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package openapi
+
+import (
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/openapi"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOpenAPI tests the openapi route.
+func TestOpenAPI(t *testing.T) {
+	cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, []routable.Contract{New(openapi.JSON()).Init})
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	response := testInternal.SendRequestWithoutBody(t, muxRouter, Method, Route())
+
+	assert.Equal(t, CodeSuccess, response.Code)
+	assert.Equal(t, openapi.JSON(), response.Body.Bytes())
+	document, err := openapi.Parse(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, "3.0.3", document.OpenAPI)
+}
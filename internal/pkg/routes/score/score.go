@@ -0,0 +1,152 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package score
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/score"
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	identityParam        = "identity"
+	Name                 = "score"
+	Method               = http.MethodGet
+	PolicyMethod         = http.MethodGet
+	SwapPolicyMethod     = http.MethodPut
+	CodeIdentityNotFound = http.StatusNotFound
+	CodeMalformedPolicy  = http.StatusBadRequest
+	CodeInvalidPolicy    = http.StatusUnprocessableEntity
+	CodeSuccess          = http.StatusOK
+)
+
+// errMalformedPolicy and errInvalidPolicy report rejected policies with the route's status codes.
+var (
+	errMalformedPolicy = problem.New(CodeMalformedPolicy, problem.CodeMalformedBody, problem.ErrMalformedBody.Title)
+	errInvalidPolicy   = problem.New(CodeInvalidPolicy, problem.CodeInvalidPolicy, problem.ErrInvalidPolicy.Title)
+)
+
+// Route creates a url.
+func Route(id string) string {
+	return fmt.Sprintf("/score/%s", id)
+}
+
+// EscapedRoute creates a url for client.
+func EscapedRoute(id identity.Contract) string {
+	return Route(url.PathEscape(id.Printable()))
+}
+
+// PolicyRoute creates the policy url.
+func PolicyRoute() string {
+	return "/scorePolicy"
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	score       score.Contract
+	maxBodySize int64
+}
+
+// New is a factory function that returns instance; policies larger than maxBodySize bytes are rejected, and zero
+// accepts any size.
+func New(score score.Contract, maxBodySize int64) *instance {
+	return &instance{
+		score:       score,
+		maxBodySize: maxBodySize,
+	}
+}
+
+// Init adds package's routes to muxRouter.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.HandleFunc(Route("{"+identityParam+"}"), i.handle).Methods(Method).Name(Name)
+	muxRouter.HandleFunc(PolicyRoute(), i.handlePolicy).Methods(PolicyMethod)
+	muxRouter.HandleFunc(PolicyRoute(), i.handleSwapPolicy).Methods(SwapPolicyMethod)
+}
+
+// write marshals value and writes it as the response body.
+func write(w http.ResponseWriter, r *http.Request, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		problem.Write(w, r, problem.ErrInternal.WithDetail(err.Error()))
+		return
+	}
+
+	w.Header().Set(codec.ContentTypeHeader, codec.ContentTypeJSON)
+	w.WriteHeader(CodeSuccess)
+	_, _ = w.Write(body)
+}
+
+// handle implements package's scoring functionality.
+func (i *instance) handle(w http.ResponseWriter, r *http.Request) {
+	id := urlIdentity.Unescape(mux.Vars(r)[identityParam])
+	value, result := i.score.Score(urlIdentity.New(id))
+	switch result {
+	case status.Success:
+	case status.NotFound:
+		problem.Write(w, r, problem.ErrIdentityNotFound.WithDetail(id))
+		return
+	default:
+		problem.Write(w, r, problem.ErrInternal.WithDetail("store returned status "+strconv.Itoa(int(result))))
+		return
+	}
+
+	write(w, r, value)
+}
+
+// handlePolicy returns the current policy.
+func (i *instance) handlePolicy(w http.ResponseWriter, r *http.Request) {
+	write(w, r, i.score.Policy())
+}
+
+// handleSwapPolicy installs the policy contained in the request body.
+func (i *instance) handleSwapPolicy(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader = r.Body
+	if i.maxBodySize > 0 {
+		body = http.MaxBytesReader(w, r.Body, i.maxBodySize)
+	}
+
+	var policy score.Policy
+	if err := json.NewDecoder(body).Decode(&policy); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.Write(w, r, problem.ErrBodyTooLarge.WithDetail(err.Error()))
+			return
+		}
+		problem.Write(w, r, errMalformedPolicy.WithDetail(err.Error()))
+		return
+	}
+
+	installed, err := i.score.Swap(policy)
+	if err != nil {
+		problem.Write(w, r, errInvalidPolicy.WithDetail(err.Error()))
+		return
+	}
+
+	write(w, r, installed)
+}
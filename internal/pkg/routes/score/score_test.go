@@ -0,0 +1,218 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package score
+
+import (
+	"crypto"
+	"encoding/json"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	"github.com/project-alvarium/go-store/internal/pkg/score"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
+	signerMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/signer/signpkcs1v15/metadata"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+const maxBodySize = 1 << 12
+
+// failingStore is a store whose lookups always fail.
+type failingStore struct {
+	store.Contract
+}
+
+// FindByIdentity fails.
+func (failingStore) FindByIdentity(_ identity.Contract) ([]*annotation.Instance, status.Value) {
+	return nil, status.Unknown
+}
+
+// TestScore tests score routes.
+func TestScore(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T, muxRouter *mux.Router, store store.Contract)
+	}
+
+	policy := score.Policy{
+		Name:  test.FactoryRandomString(),
+		Rules: []score.Rule{{MetadataKind: pkiMetadata.Kind, Weight: 1}},
+	}
+
+	cases := []testCase{
+		{
+			name: "Identity not found",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, Method, Route(test.FactoryRandomString()))
+
+				assert.Equal(t, CodeIdentityNotFound, response.Code)
+				failure, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeIdentityNotFound, failure.Code)
+			},
+		},
+		{
+			name: "Current policy",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, PolicyMethod, PolicyRoute())
+
+				expected := *score.NewDefaultPolicy()
+				expected.Version = 1
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(t, codec.ContentTypeJSON, response.Header().Get(codec.ContentTypeHeader))
+				assert.Equal(t, testInternal.Marshal(t, expected), response.Body.Bytes())
+			},
+		},
+		{
+			name: "Invalid policy",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					SwapPolicyMethod,
+					PolicyRoute(),
+					testInternal.Marshal(t, score.Policy{}),
+				)
+
+				assert.Equal(t, CodeInvalidPolicy, response.Code)
+				failure, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeInvalidPolicy, failure.Code)
+			},
+		},
+		{
+			name: "Malformed policy",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				response := testInternal.SendRequestWithBody(t, muxRouter, SwapPolicyMethod, PolicyRoute(), []byte("{"))
+
+				assert.Equal(t, CodeMalformedPolicy, response.Code)
+				failure, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeMalformedBody, failure.Code)
+			},
+		},
+		{
+			name: "Policy too large",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				large := policy
+				large.Name = string(test.FactoryRandomFixedLengthAlphanumericByteSlice(maxBodySize))
+
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					SwapPolicyMethod,
+					PolicyRoute(),
+					testInternal.Marshal(t, large),
+				)
+
+				assert.Equal(t, problem.ErrBodyTooLarge.Status, response.Code)
+				failure, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeBodyTooLarge, failure.Code)
+			},
+		},
+		{
+			name: "Swap policy and score",
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					SwapPolicyMethod,
+					PolicyRoute(),
+					testInternal.Marshal(t, policy),
+				)
+				expected := policy
+				expected.Version = 2
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(t, testInternal.Marshal(t, expected), response.Body.Bytes())
+
+				id := hash.New(test.FactoryRandomByteSlice())
+				idContract := url.New(id.Printable())
+				value := annotation.New(
+					ulid.New().Get(),
+					id,
+					nil,
+					pkiMetadata.New(
+						nil,
+						test.FactoryRandomByteSlice(),
+						test.FactoryRandomByteSlice(),
+						test.FactoryRandomByteSlice(),
+						signerMetadata.NewSuccess(crypto.SHA256, test.FactoryRandomString()),
+					),
+				)
+				assert.Equal(t, status.Success, store.Create(idContract, value))
+
+				response = testInternal.SendRequestWithoutBody(t, muxRouter, Method, EscapedRoute(idContract))
+
+				var result struct {
+					Policy score.PolicyReference `json:"policy"`
+					Score  float64               `json:"score"`
+				}
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &result))
+				assert.Equal(t, score.PolicyReference{Name: policy.Name, Version: 2}, result.Policy)
+				assert.Equal(t, float64(1), result.Score)
+			},
+		},
+	}
+
+	for i := range cases {
+		s := memory.New()
+		cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, []routable.Contract{New(score.New(s), maxBodySize).Init})
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				cases[i].test(t, muxRouter, s)
+				cancel()
+				wg.Wait()
+			},
+		)
+	}
+}
+
+// TestScore_StoreFailure tests that a failed store lookup is reported as an internal error rather than as a missing
+// identity.
+func TestScore_StoreFailure(t *testing.T) {
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{New(score.New(failingStore{memory.New()}), maxBodySize).Init},
+	)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	response := testInternal.SendRequestWithoutBody(t, muxRouter, Method, Route(test.FactoryRandomString()))
+
+	assert.Equal(t, problem.ErrInternal.Status, response.Code)
+	failure, err := problem.Decode(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, problem.CodeInternal, failure.Code)
+}
@@ -0,0 +1,335 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package socket
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/audit"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+	appendRoute "github.com/project-alvarium/go-store/internal/pkg/routes/append"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/create"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/find"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/subscribe"
+
+	"github.com/project-alvarium/go-sdk/pkg/status"
+
+	"github.com/gorilla/websocket"
+)
+
+// connection is a receiver that encapsulates the state of a single client connection; principal is nil if
+// authentication is disabled.
+type connection struct {
+	route         *instance
+	conn          *websocket.Conn
+	principal     *auth.Principal
+	send          chan Response
+	window        chan struct{}
+	m             sync.Mutex
+	subscriptions map[string]notify.Subscription
+	wg            sync.WaitGroup
+}
+
+// newConnection is a factory function that returns an initialized connection.
+func newConnection(route *instance, conn *websocket.Conn, principal *auth.Principal) *connection {
+	return &connection{
+		route:         route,
+		conn:          conn,
+		principal:     principal,
+		send:          make(chan Response, sendQueue),
+		window:        make(chan struct{}, Window),
+		subscriptions: make(map[string]notify.Subscription),
+	}
+}
+
+// serve reads requests until the connection fails or ctx is done, then releases the connection's resources.
+func (c *connection) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		c.write(ctx)
+	}()
+	go func() {
+		// unblocks the reader when the service stops.
+		<-ctx.Done()
+		_ = c.conn.Close()
+	}()
+
+	c.read(ctx)
+
+	cancel()
+	c.wg.Wait()
+	<-writerDone
+	c.m.Lock()
+	for id := range c.subscriptions {
+		c.subscriptions[id].Close()
+	}
+	c.m.Unlock()
+}
+
+// read dispatches incoming requests, applying backpressure once the connection's window is full.
+func (c *connection) read(ctx context.Context) {
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(PongWait))
+	c.conn.SetPongHandler(
+		func(string) error {
+			return c.conn.SetReadDeadline(time.Now().Add(PongWait))
+		},
+	)
+
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = c.conn.SetReadDeadline(time.Now().Add(PongWait))
+
+		var request Request
+		if err := json.Unmarshal(message, &request); err != nil {
+			c.reply(ctx, Response{Kind: KindError, Status: status.Unknown, Error: err.Error()})
+			continue
+		}
+
+		select {
+		case c.window <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			defer func() { <-c.window }()
+
+			if response := c.handle(ctx, request); response != nil {
+				c.reply(ctx, *response)
+			}
+		}()
+	}
+}
+
+// write sends queued responses and keepalive pings until ctx is done or a write fails.
+func (c *connection) write(ctx context.Context) {
+	ticker := time.NewTicker(PingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case response := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteJSON(response); err != nil {
+				_ = c.conn.Close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				_ = c.conn.Close()
+				return
+			}
+		case <-ctx.Done():
+			_ = c.conn.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, ""),
+				time.Now().Add(writeWait),
+			)
+			return
+		}
+	}
+}
+
+// reply queues response, waiting for room unless ctx is done.
+func (c *connection) reply(ctx context.Context, response Response) {
+	select {
+	case c.send <- response:
+	case <-ctx.Done():
+	}
+}
+
+// result returns a result response for request.
+func result(request Request, value status.Value) *Response {
+	return &Response{ID: request.ID, Kind: KindResult, Status: value}
+}
+
+// failure returns an error response for request.
+func failure(request Request, message string) *Response {
+	return &Response{ID: request.ID, Kind: KindError, Status: status.Unknown, Error: message}
+}
+
+// operations maps each operation acting on a single identity to the route whose authorization it shares.
+var operations = map[string]string{
+	OperationCreate: create.Name,
+	OperationAppend: appendRoute.Name,
+	OperationFind:   find.Name,
+}
+
+// allows returns whether the connection's principal may use route on id.
+func (c *connection) allows(route, id string) bool {
+	return c.route.authorizer.Allows(c.principal, route, id)
+}
+
+// handle performs request and returns its response; nil means the response has already been queued.
+func (c *connection) handle(ctx context.Context, request Request) *Response {
+	if route, exists := operations[request.Operation]; exists && !c.allows(route, request.Identity) {
+		response := failure(request, request.Operation+" is not allowed on "+request.Identity)
+		c.record(ctx, time.Now(), request, "", response)
+		return response
+	}
+
+	switch request.Operation {
+	case OperationCreate, OperationAppend:
+		started := time.Now()
+		response, unique := c.store(ctx, request)
+		c.record(ctx, started, request, unique, response)
+		return response
+	case OperationFind:
+		annotations, value := c.route.store.FindByIdentity(urlIdentity.New(request.Identity))
+		response := result(request, value)
+		if value == status.Success {
+			response.Annotations = annotations
+		}
+		return response
+	case OperationSubscribe:
+		return c.subscribe(ctx, request)
+	case OperationUnsubscribe:
+		c.m.Lock()
+		subscription, exists := c.subscriptions[request.Subscription]
+		delete(c.subscriptions, request.Subscription)
+		c.m.Unlock()
+		if !exists {
+			return result(request, status.NotFound)
+		}
+		subscription.Close()
+		return result(request, status.Success)
+	}
+	return failure(request, "unknown operation "+request.Operation)
+}
+
+// store performs a create or append request, noting the connection's principal as the author of the annotation it
+// stores, and returns its response and the Unique of the annotation it carries.
+func (c *connection) store(ctx context.Context, request Request) (*Response, string) {
+	if c.principal != nil && !c.principal.Allows(auth.ScopeWrite) {
+		return failure(request, "requires the "+string(auth.ScopeWrite)+" scope"), ""
+	}
+
+	id := urlIdentity.New(request.Identity)
+	value, rejected := c.route.decoder.Unmarshal(request.Annotation)
+	if rejected == nil {
+		rejected = c.route.decoder.Validate(id, value)
+	}
+	if rejected != nil {
+		return failure(request, rejected.Error()), ""
+	}
+
+	var stored status.Value
+	if request.Operation == OperationCreate {
+		stored = c.route.store.Create(id, value)
+	} else {
+		stored = c.route.store.Append(id, value)
+	}
+	if stored == status.Success {
+		c.route.authors.Record(ctx, value)
+	}
+	return result(request, stored), value.Unique
+}
+
+// record records a create or append request, started at started and answered with response, in the audit log; other
+// requests are not recorded.
+func (c *connection) record(
+	ctx context.Context,
+	started time.Time,
+	request Request,
+	unique string,
+	response *Response) {
+
+	if request.Operation != OperationCreate && request.Operation != OperationAppend {
+		return
+	}
+
+	event := audit.NewEvent(ctx, started, operations[request.Operation], request.Identity)
+	event.Address = audit.Host(c.conn.RemoteAddr().String())
+	event.Unique = unique
+	event.Outcome = audit.OutcomeSuccess
+	event.Latency = audit.Latency(started, time.Now())
+	if response.Kind != KindResult || response.Status != status.Success {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = response.Error
+	}
+	c.route.audit.Record(event)
+}
+
+// subscribe starts delivering events matching request to the client after queueing the acknowledgement; a prefix
+// subscription only delivers events on the identities the principal may subscribe to.  A subscription whose events cannot be queued is ended
+// so that the client can resubscribe from the last sequence it received.
+func (c *connection) subscribe(ctx context.Context, request Request) *Response {
+	if !request.Prefix && !c.allows(subscribe.Name, request.Identity) {
+		return failure(request, request.Operation+" is not allowed on "+request.Identity)
+	}
+
+	c.m.Lock()
+	if _, exists := c.subscriptions[request.ID]; exists {
+		c.m.Unlock()
+		return result(request, status.Exists)
+	}
+	filter := notify.Filter{
+		Identity: request.Identity,
+		Prefix:   request.Prefix,
+		Permit:   func(id string) bool { return c.allows(subscribe.Name, id) },
+	}
+	subscription, err := c.route.notify.Subscribe(filter, request.After)
+	if err != nil {
+		c.m.Unlock()
+		return &Response{ID: request.ID, Kind: KindExpired, Status: status.Unknown, Error: err.Error()}
+	}
+	c.subscriptions[request.ID] = subscription
+	c.m.Unlock()
+
+	c.reply(ctx, *result(request, status.Success))
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		last := request.After
+		for queued := true; queued; {
+			event, ok := subscription.Next(ctx)
+			if !ok {
+				break
+			}
+
+			select {
+			case c.send <- Response{ID: request.ID, Kind: KindEvent, Status: status.Success, Event: &event}:
+				last = event.Sequence
+			default:
+				queued = false
+			}
+		}
+
+		// the subscription ID is released before the client learns it may resubscribe.
+		c.m.Lock()
+		if c.subscriptions[request.ID] == subscription {
+			delete(c.subscriptions, request.ID)
+		}
+		c.m.Unlock()
+		subscription.Close()
+		c.reply(ctx, Response{ID: request.ID, Kind: KindEnded, Status: status.Success, Sequence: last})
+	}()
+
+	return nil
+}
@@ -0,0 +1,65 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package socket
+
+import (
+	"encoding/json"
+
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+const (
+	OperationCreate      = "create"
+	OperationAppend      = "append"
+	OperationFind        = "find"
+	OperationSubscribe   = "subscribe"
+	OperationUnsubscribe = "unsubscribe"
+
+	KindResult  = "result"
+	KindEvent   = "event"
+	KindEnded   = "ended"
+	KindExpired = "expired"
+	KindError   = "error"
+)
+
+// Request is a message sent by a client.  ID is chosen by the client and correlates the request with its
+// responses; a subscribe request's ID also identifies the subscription in later event, ended, and unsubscribe
+// messages.
+type Request struct {
+	ID           string          `json:"id"`
+	Operation    string          `json:"operation"`
+	Identity     string          `json:"identity,omitempty"`
+	Prefix       bool            `json:"prefix,omitempty"`
+	After        uint64          `json:"after,omitempty"`
+	Subscription string          `json:"subscription,omitempty"`
+	Annotation   json.RawMessage `json:"annotation,omitempty"`
+}
+
+// Response is a message sent by the server.  Kind is result for the outcome of a request, event for an annotation
+// delivered to a subscription, ended when a subscription stops (Sequence is then the last event delivered), expired
+// when a subscribe request's After precedes the retained events (the client has missed events and may subscribe
+// again from new ones), and error when a request could not be processed.
+type Response struct {
+	ID          string                 `json:"id"`
+	Kind        string                 `json:"kind"`
+	Status      status.Value           `json:"status"`
+	Annotations []*annotation.Instance `json:"annotations,omitempty"`
+	Event       *notify.Event          `json:"event,omitempty"`
+	Sequence    uint64                 `json:"sequence,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+}
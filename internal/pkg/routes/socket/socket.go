@@ -0,0 +1,116 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package socket
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/audit"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/author"
+	"github.com/project-alvarium/go-store/internal/pkg/authz"
+	"github.com/project-alvarium/go-store/internal/pkg/ingest"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	Name   = "socket"
+	Method = http.MethodGet
+
+	// Window is the number of requests a connection may have in flight; the server stops reading from a connection
+	// whose window is full.
+	Window = 16
+
+	// PingPeriod is how often keepalive pings are sent; a peer that has not answered within PongWait is disconnected.
+	PingPeriod = time.Second * time.Duration(20)
+	PongWait   = time.Second * time.Duration(45)
+
+	writeWait      = time.Second * time.Duration(10)
+	sendQueue      = 256
+	maxMessageSize = 1 << 20
+)
+
+// Route creates a url.
+func Route() string {
+	return "/socket"
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	store      store.Contract
+	notify     notify.Contract
+	decoder    ingest.Contract
+	authorizer authz.Contract
+	audit      audit.Contract
+	authors    author.Contract
+	upgrader   websocket.Upgrader
+}
+
+// New is a factory function that returns instance; annotations are decoded and validated by decoder, as they are
+// when posted over HTTP.
+func New(store store.Contract, notify notify.Contract, decoder ingest.Contract) *instance {
+	return &instance{
+		store:      store,
+		notify:     notify,
+		decoder:    decoder,
+		authorizer: authz.NewDefault(),
+		audit:      audit.NewDiscard(),
+		authors:    author.NewDiscard(),
+		upgrader: websocket.Upgrader{
+			HandshakeTimeout: writeWait,
+		},
+	}
+}
+
+// SetAuthorizer provides for method injection of the authorizer that decides each request's identity; by default,
+// requests may use the identities the principal's prefixes permit.
+func (i *instance) SetAuthorizer(authorizer authz.Contract) {
+	i.authorizer = authorizer
+}
+
+// SetAudit provides for method injection of the audit log each create and append request is recorded in; by default,
+// they are not recorded.
+func (i *instance) SetAudit(log audit.Contract) {
+	i.audit = log
+}
+
+// SetAuthors provides for method injection of the record each stored annotation's author is noted in; by default,
+// authors are not noted.
+func (i *instance) SetAuthors(authors author.Contract) {
+	i.authors = authors
+}
+
+// Init adds package's route to muxRouter; it is named Name, which admission control refers to.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.HandleFunc(Route(), i.handle).Methods(Method).Name(Name)
+}
+
+// handle upgrades the request and serves the connection until the client disconnects or the service stops.  The
+// upgrade only requires the read scope, so the connection checks each request against the principal that opened it.
+func (i *instance) handle(w http.ResponseWriter, r *http.Request) {
+	conn, err := i.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	principal, _ := auth.FromContext(r.Context())
+	newConnection(i, conn, principal).serve(r.Context())
+}
@@ -0,0 +1,452 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package socket
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/audit"
+	auditStub "github.com/project-alvarium/go-store/internal/pkg/audit/stub"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	authStub "github.com/project-alvarium/go-store/internal/pkg/auth/stub"
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/ingest"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/create"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	metadataStubFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub/factory"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAnnotation returns a new annotation carrying m.
+func newAnnotation(m *metadataStub.Instance) *annotation.Instance {
+	return annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, m)
+}
+
+// dial opens a connection to server.
+func dial(t *testing.T, server *httptest.Server) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+Route(), nil)
+	if err != nil {
+		assert.FailNow(t, "Unexpected Dial failure:", err.Error())
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	return conn
+}
+
+// received is a server message whose annotations are left undecoded.
+type received struct {
+	ID          string          `json:"id"`
+	Kind        string          `json:"kind"`
+	Status      status.Value    `json:"status"`
+	Annotations json.RawMessage `json:"annotations"`
+	Event       *struct {
+		Sequence   uint64          `json:"sequence"`
+		Identity   string          `json:"identity"`
+		Annotation json.RawMessage `json:"annotation"`
+	} `json:"event"`
+	Sequence uint64 `json:"sequence"`
+	Error    string `json:"error"`
+}
+
+// exchange sends request over conn and returns the next message received.
+func exchange(t *testing.T, conn *websocket.Conn, request interface{}) received {
+	if err := conn.WriteJSON(request); err != nil {
+		assert.FailNow(t, "Unexpected WriteJSON failure:", err.Error())
+	}
+	return receive(t, conn)
+}
+
+// receive returns the next message received over conn.
+func receive(t *testing.T, conn *websocket.Conn) received {
+	var response received
+	if err := conn.ReadJSON(&response); err != nil {
+		assert.FailNow(t, "Unexpected ReadJSON failure:", err.Error())
+	}
+	return response
+}
+
+// TestSocket tests socket route.
+func TestSocket(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T, conn *websocket.Conn, m *metadataStub.Instance)
+	}
+
+	cases := []testCase{
+		{
+			name: "create append find",
+			test: func(t *testing.T, conn *websocket.Conn, m *metadataStub.Instance) {
+				id := url.New(test.FactoryRandomString())
+				first := newAnnotation(m)
+				second := newAnnotation(m)
+
+				create := Request{
+					ID:         ulid.New().Get(),
+					Operation:  OperationCreate,
+					Identity:   id.Printable(),
+					Annotation: testInternal.Marshal(t, first),
+				}
+				assert.Equal(t, received{ID: create.ID, Kind: KindResult, Status: status.Success}, exchange(t, conn, create))
+				create.ID = ulid.New().Get()
+				assert.Equal(t, received{ID: create.ID, Kind: KindResult, Status: status.Exists}, exchange(t, conn, create))
+
+				append := Request{
+					ID:         ulid.New().Get(),
+					Operation:  OperationAppend,
+					Identity:   id.Printable(),
+					Annotation: testInternal.Marshal(t, second),
+				}
+				assert.Equal(t, received{ID: append.ID, Kind: KindResult, Status: status.Success}, exchange(t, conn, append))
+
+				find := Request{ID: ulid.New().Get(), Operation: OperationFind, Identity: id.Printable()}
+				response := exchange(t, conn, find)
+				assert.Equal(t, find.ID, response.ID)
+				assert.Equal(t, status.Success, response.Status)
+				assert.Equal(
+					t,
+					testInternal.Marshal(t, []*annotation.Instance{first, second}),
+					[]byte(response.Annotations),
+				)
+			},
+		},
+		{
+			name: "find not found",
+			test: func(t *testing.T, conn *websocket.Conn, m *metadataStub.Instance) {
+				find := Request{ID: ulid.New().Get(), Operation: OperationFind, Identity: test.FactoryRandomString()}
+
+				assert.Equal(t, received{ID: find.ID, Kind: KindResult, Status: status.NotFound}, exchange(t, conn, find))
+			},
+		},
+		{
+			name: "subscribe and unsubscribe",
+			test: func(t *testing.T, conn *websocket.Conn, m *metadataStub.Instance) {
+				prefix := test.FactoryRandomString()
+				id := url.New(prefix + "/" + test.FactoryRandomString())
+				value := newAnnotation(m)
+				subscribe := Request{ID: ulid.New().Get(), Operation: OperationSubscribe, Identity: prefix, Prefix: true}
+				assert.Equal(t, received{ID: subscribe.ID, Kind: KindResult, Status: status.Success}, exchange(t, conn, subscribe))
+				assert.Equal(t, received{ID: subscribe.ID, Kind: KindResult, Status: status.Exists}, exchange(t, conn, subscribe))
+
+				create := Request{
+					ID:         ulid.New().Get(),
+					Operation:  OperationCreate,
+					Identity:   id.Printable(),
+					Annotation: testInternal.Marshal(t, value),
+				}
+				if err := conn.WriteJSON(create); err != nil {
+					assert.FailNow(t, "Unexpected WriteJSON failure:", err.Error())
+				}
+				responses := map[string]received{}
+				for len(responses) < 2 {
+					response := receive(t, conn)
+					responses[response.Kind] = response
+				}
+				assert.Equal(t, received{ID: create.ID, Kind: KindResult, Status: status.Success}, responses[KindResult])
+				assert.Equal(t, subscribe.ID, responses[KindEvent].ID)
+				assert.Equal(t, uint64(1), responses[KindEvent].Event.Sequence)
+				assert.Equal(t, id.Printable(), responses[KindEvent].Event.Identity)
+				assert.Equal(
+					t,
+					testInternal.Marshal(t, value),
+					[]byte(responses[KindEvent].Event.Annotation),
+				)
+
+				unsubscribe := Request{ID: ulid.New().Get(), Operation: OperationUnsubscribe, Subscription: subscribe.ID}
+				responses = map[string]received{}
+				if err := conn.WriteJSON(unsubscribe); err != nil {
+					assert.FailNow(t, "Unexpected WriteJSON failure:", err.Error())
+				}
+				for len(responses) < 2 {
+					response := receive(t, conn)
+					responses[response.Kind] = response
+				}
+				assert.Equal(t, received{ID: unsubscribe.ID, Kind: KindResult, Status: status.Success}, responses[KindResult])
+				assert.Equal(t, received{ID: subscribe.ID, Kind: KindEnded, Status: status.Success, Sequence: 1}, responses[KindEnded])
+			},
+		},
+		{
+			name: "unsubscribe unknown",
+			test: func(t *testing.T, conn *websocket.Conn, m *metadataStub.Instance) {
+				unsubscribe := Request{ID: ulid.New().Get(), Operation: OperationUnsubscribe, Subscription: test.FactoryRandomString()}
+
+				assert.Equal(t, received{ID: unsubscribe.ID, Kind: KindResult, Status: status.NotFound}, exchange(t, conn, unsubscribe))
+			},
+		},
+		{
+			name: "malformed request",
+			test: func(t *testing.T, conn *websocket.Conn, m *metadataStub.Instance) {
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(test.FactoryRandomString())); err != nil {
+					assert.FailNow(t, "Unexpected WriteMessage failure:", err.Error())
+				}
+
+				response := receive(t, conn)
+
+				assert.Equal(t, KindError, response.Kind)
+				assert.NotEmpty(t, response.Error)
+			},
+		},
+		{
+			name: "malformed annotation",
+			test: func(t *testing.T, conn *websocket.Conn, m *metadataStub.Instance) {
+				create := Request{
+					ID:         ulid.New().Get(),
+					Operation:  OperationCreate,
+					Identity:   test.FactoryRandomString(),
+					Annotation: json.RawMessage(`"` + test.FactoryRandomString() + `"`),
+				}
+
+				response := exchange(t, conn, create)
+
+				assert.Equal(t, create.ID, response.ID)
+				assert.Equal(t, KindError, response.Kind)
+			},
+		},
+		{
+			name: "unknown operation",
+			test: func(t *testing.T, conn *websocket.Conn, m *metadataStub.Instance) {
+				request := Request{ID: ulid.New().Get(), Operation: test.FactoryRandomString()}
+
+				response := exchange(t, conn, request)
+
+				assert.Equal(t, request.ID, response.ID)
+				assert.Equal(t, KindError, response.Kind)
+				assert.Equal(t, "unknown operation "+request.Operation, response.Error)
+			},
+		},
+	}
+
+	for i := range cases {
+		s := notify.New(memory.New(), 0)
+		m := metadataStub.NewNullObject()
+		mFactory := metadataFactory.New([]metadataFactory.Contract{metadataStubFactory.New(m)})
+		cancel, wg, muxRouter := testInternal.NewSUT(
+			pkg.Run,
+			[]routable.Contract{
+				New(s, s, ingest.New(mFactory, identityFactory.New(), ingest.NewDefaultConfig())).Init,
+			},
+		)
+		server := httptest.NewServer(muxRouter)
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				conn := dial(t, server)
+				cases[i].test(t, conn, m)
+				_ = conn.Close()
+				server.Close()
+				cancel()
+				wg.Wait()
+			},
+		)
+	}
+}
+
+// TestSocket_Validation tests that annotations are validated as they are when posted over HTTP.
+func TestSocket_Validation(t *testing.T) {
+	s := notify.New(memory.New(), 0)
+	m := metadataStub.NewNullObject()
+	mFactory := metadataFactory.New([]metadataFactory.Contract{metadataStubFactory.New(m)})
+	config := ingest.NewDefaultConfig()
+	config.IdentityCheck = ingest.IdentityCheckStrict
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{New(s, s, ingest.New(mFactory, identityFactory.New(), config)).Init},
+	)
+	server := httptest.NewServer(muxRouter)
+	conn := dial(t, server)
+	defer func() {
+		_ = conn.Close()
+		server.Close()
+		cancel()
+		wg.Wait()
+	}()
+
+	for _, operation := range []string{OperationCreate, OperationAppend} {
+		id := url.New(test.FactoryRandomString())
+		request := Request{
+			ID:         ulid.New().Get(),
+			Operation:  operation,
+			Identity:   id.Printable(),
+			Annotation: testInternal.Marshal(t, newAnnotation(m)),
+		}
+
+		response := exchange(t, conn, request)
+
+		assert.Equal(t, KindError, response.Kind)
+		assert.Contains(t, response.Error, id.Printable())
+		_, result := s.FindByIdentity(id)
+		assert.Equal(t, status.NotFound, result)
+	}
+}
+
+// TestSocket_Scope tests that writes require the write scope of the principal that opened the connection.
+func TestSocket_Scope(t *testing.T) {
+	s := notify.New(memory.New(), 0)
+	m := metadataStub.NewNullObject()
+	mFactory := metadataFactory.New([]metadataFactory.Contract{metadataStubFactory.New(m)})
+	reader := &auth.Principal{Subject: test.FactoryRandomString(), Scopes: []auth.Scope{auth.ScopeRead}}
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{
+			authStub.Authenticated(reader),
+			New(s, s, ingest.New(mFactory, identityFactory.New(), ingest.NewDefaultConfig())).Init,
+		},
+	)
+	server := httptest.NewServer(muxRouter)
+	conn := dial(t, server)
+	defer func() {
+		_ = conn.Close()
+		server.Close()
+		cancel()
+		wg.Wait()
+	}()
+
+	id := url.New(test.FactoryRandomString())
+	for _, operation := range []string{OperationCreate, OperationAppend} {
+		request := Request{
+			ID:         ulid.New().Get(),
+			Operation:  operation,
+			Identity:   id.Printable(),
+			Annotation: testInternal.Marshal(t, newAnnotation(m)),
+		}
+
+		response := exchange(t, conn, request)
+
+		assert.Equal(t, KindError, response.Kind)
+		assert.Equal(t, "requires the write scope", response.Error)
+	}
+
+	find := Request{ID: ulid.New().Get(), Operation: OperationFind, Identity: id.Printable()}
+	assert.Equal(t, received{ID: find.ID, Kind: KindResult, Status: status.NotFound}, exchange(t, conn, find))
+}
+
+// TestSocket_Authorization tests that each request is limited to the identities the principal's prefixes permit.
+func TestSocket_Authorization(t *testing.T) {
+	s := notify.New(memory.New(), 0)
+	m := metadataStub.NewNullObject()
+	mFactory := metadataFactory.New([]metadataFactory.Contract{metadataStubFactory.New(m)})
+	prefix := test.FactoryRandomFixedLengthAlphanumericString(16)
+	principal := &auth.Principal{
+		Subject:  test.FactoryRandomString(),
+		Scopes:   []auth.Scope{auth.ScopeRead, auth.ScopeWrite},
+		Prefixes: []string{prefix},
+	}
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{
+			authStub.Authenticated(principal),
+			New(s, s, ingest.New(mFactory, identityFactory.New(), ingest.NewDefaultConfig())).Init,
+		},
+	)
+	server := httptest.NewServer(muxRouter)
+	conn := dial(t, server)
+	defer func() {
+		_ = conn.Close()
+		server.Close()
+		cancel()
+		wg.Wait()
+	}()
+
+	outside := url.New(test.FactoryRandomFixedLengthAlphanumericString(16) + prefix)
+	for _, operation := range []string{OperationCreate, OperationAppend, OperationFind, OperationSubscribe} {
+		request := Request{
+			ID:         ulid.New().Get(),
+			Operation:  operation,
+			Identity:   outside.Printable(),
+			Annotation: testInternal.Marshal(t, newAnnotation(m)),
+		}
+
+		response := exchange(t, conn, request)
+
+		assert.Equal(t, KindError, response.Kind)
+		assert.Equal(t, operation+" is not allowed on "+outside.Printable(), response.Error)
+	}
+
+	subscription := Request{ID: ulid.New().Get(), Operation: OperationSubscribe, Prefix: true}
+	assert.Equal(t, KindResult, exchange(t, conn, subscription).Kind)
+	permitted := url.New(prefix + test.FactoryRandomString())
+	assert.Equal(t, status.Success, s.Create(outside, newAnnotation(m)))
+	assert.Equal(t, status.Success, s.Create(permitted, newAnnotation(m)))
+
+	response := receive(t, conn)
+
+	assert.Equal(t, KindEvent, response.Kind)
+	assert.Equal(t, permitted.Printable(), response.Event.Identity)
+}
+
+// TestSocket_Audit tests that each create and append request is recorded in the audit log, whatever its outcome.
+func TestSocket_Audit(t *testing.T) {
+	s := notify.New(memory.New(), 0)
+	m := metadataStub.NewNullObject()
+	mFactory := metadataFactory.New([]metadataFactory.Contract{metadataStubFactory.New(m)})
+	principal := &auth.Principal{Subject: test.FactoryRandomString(), Scopes: []auth.Scope{auth.ScopeWrite}}
+	log := auditStub.New()
+	sut := New(s, s, ingest.New(mFactory, identityFactory.New(), ingest.NewDefaultConfig()))
+	sut.SetAudit(log)
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{authStub.Authenticated(principal), sut.Init},
+	)
+	server := httptest.NewServer(muxRouter)
+	conn := dial(t, server)
+	defer func() {
+		_ = conn.Close()
+		server.Close()
+		cancel()
+		wg.Wait()
+	}()
+
+	id := url.New(test.FactoryRandomString())
+	value := newAnnotation(m)
+	for _, operation := range []string{OperationCreate, OperationCreate, OperationFind} {
+		request := Request{
+			ID:         ulid.New().Get(),
+			Operation:  operation,
+			Identity:   id.Printable(),
+			Annotation: testInternal.Marshal(t, value),
+		}
+		_ = exchange(t, conn, request)
+	}
+
+	events := log.Events()
+	if !assert.Len(t, events, 2) {
+		return
+	}
+	for key, outcome := range []string{audit.OutcomeSuccess, audit.OutcomeFailure} {
+		assert.Equal(t, principal.Subject, events[key].Subject)
+		assert.Equal(t, "127.0.0.1", events[key].Address)
+		assert.Equal(t, create.Name, events[key].Action)
+		assert.Equal(t, id.Printable(), events[key].Resource)
+		assert.Equal(t, value.Unique, events[key].Unique)
+		assert.Equal(t, outcome, events[key].Outcome)
+	}
+}
@@ -0,0 +1,170 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package subscribe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/authz"
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	identityParam            = "identity"
+	prefixParam              = "prefix"
+	Name                     = "subscribe"
+	LastEventIDHeader        = "Last-Event-ID"
+	ContentType              = "text/event-stream"
+	EventName                = "annotation"
+	Method                   = http.MethodGet
+	CodeInvalidLastEventID   = http.StatusBadRequest
+	CodeStreamingUnsupported = http.StatusInternalServerError
+	CodeSuccess              = http.StatusOK
+	keepAlive                = time.Second * time.Duration(15)
+	retry                    = time.Second
+)
+
+// Route creates a url.
+func Route(id string) string {
+	return fmt.Sprintf("/subscribe/%s", id)
+}
+
+// EscapedRoute creates a url for client.
+func EscapedRoute(id identity.Contract) string {
+	return Route(url.PathEscape(id.Printable()))
+}
+
+// PrefixRoute creates the url of the identity prefix variant; an empty prefix subscribes to every identity.
+func PrefixRoute() string {
+	return "/subscribe"
+}
+
+// EscapedPrefixRoute creates a url for client.
+func EscapedPrefixRoute(prefix string) string {
+	return PrefixRoute() + "?" + url.Values{prefixParam: []string{prefix}}.Encode()
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	notify     notify.Contract
+	authorizer authz.Contract
+}
+
+// New is a factory function that returns instance.
+func New(notify notify.Contract) *instance {
+	return &instance{
+		notify:     notify,
+		authorizer: authz.NewDefault(),
+	}
+}
+
+// SetAuthorizer provides for method injection of the authorizer that decides which identities' events a principal
+// receives; by default, principals receive the events of the identities their prefixes permit.
+func (i *instance) SetAuthorizer(authorizer authz.Contract) {
+	i.authorizer = authorizer
+}
+
+// Init adds package's routes to muxRouter.  Both are named Name, which admission control and authorization refer to.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.HandleFunc(Route("{"+identityParam+"}"), i.handleIdentity).Methods(Method).Name(Name)
+	muxRouter.HandleFunc(PrefixRoute(), i.handlePrefix).Methods(Method).Name(Name)
+}
+
+// handleIdentity streams events for a single identity.
+func (i *instance) handleIdentity(w http.ResponseWriter, r *http.Request) {
+	i.stream(w, r, notify.Filter{Identity: urlIdentity.Unescape(mux.Vars(r)[identityParam])})
+}
+
+// handlePrefix streams events for every identity beginning with the prefix query parameter.
+func (i *instance) handlePrefix(w http.ResponseWriter, r *http.Request) {
+	i.stream(w, r, notify.Filter{Identity: r.URL.Query().Get(prefixParam), Prefix: true})
+}
+
+// stream writes events matching filter, for identities the principal may use Name on, as server-sent events until the
+// client disconnects or the service stops.
+func (i *instance) stream(w http.ResponseWriter, r *http.Request, filter notify.Filter) {
+	principal, _ := auth.FromContext(r.Context())
+	filter.Permit = func(id string) bool { return i.authorizer.Allows(principal, Name, id) }
+
+	var after uint64
+	if lastEventID := r.Header.Get(LastEventIDHeader); lastEventID != "" {
+		var err error
+		if after, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			w.WriteHeader(CodeInvalidLastEventID)
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(CodeStreamingUnsupported)
+		return
+	}
+
+	// streams outlive the server's write timeout.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	subscription, err := i.notify.Subscribe(filter, after)
+	if err != nil {
+		// the client must start again from new events and reconcile what it missed by reading the store.
+		problem.Write(w, r, problem.ErrEventsExpired.WithDetail(LastEventIDHeader+" precedes the retained events"))
+		return
+	}
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(CodeSuccess)
+	_, _ = fmt.Fprintf(w, "retry: %d\n\n", retry.Milliseconds())
+	flusher.Flush()
+
+	for {
+		ctx, cancel := context.WithTimeout(r.Context(), keepAlive)
+		event, ok := subscription.Next(ctx)
+		timedOut := ctx.Err() == context.DeadlineExceeded
+		cancel()
+
+		switch {
+		case ok:
+			data, err := json.Marshal(event)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, EventName, data); err != nil {
+				return
+			}
+		case timedOut && r.Context().Err() == nil:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		default:
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,226 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package subscribe
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	authStub "github.com/project-alvarium/go-store/internal/pkg/auth/stub"
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAnnotation returns a new annotation.
+func newAnnotation() *annotation.Instance {
+	return annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, metadataStub.NewNullObject())
+}
+
+// open starts a stream against server and returns a reader positioned after the initial retry hint.
+func open(t *testing.T, ctx context.Context, server *httptest.Server, path, lastEventID string) *bufio.Reader {
+	request, err := http.NewRequestWithContext(ctx, Method, server.URL+path, nil)
+	if err != nil {
+		assert.FailNow(t, "Unexpected http.NewRequest failure:", err.Error())
+	}
+	if lastEventID != "" {
+		request.Header.Set(LastEventIDHeader, lastEventID)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		assert.FailNow(t, "Unexpected http.Do failure:", err.Error())
+	}
+	assert.Equal(t, CodeSuccess, response.StatusCode)
+	assert.Equal(t, ContentType, response.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(response.Body)
+	assert.Equal(t, "retry: 1000\n\n", readEvent(t, reader))
+	return reader
+}
+
+// readEvent returns the next blank-line terminated event from reader.
+func readEvent(t *testing.T, reader *bufio.Reader) string {
+	var event strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			assert.FailNow(t, "Unexpected ReadString failure:", err.Error())
+		}
+		event.WriteString(line)
+		if line == "\n" {
+			return event.String()
+		}
+	}
+}
+
+// expectedEvent returns the wire representation of event.
+func expectedEvent(t *testing.T, event notify.Event) string {
+	return "id: " + strings.TrimSpace(string(testInternal.Marshal(t, event.Sequence))) +
+		"\nevent: " + EventName +
+		"\ndata: " + string(testInternal.Marshal(t, event)) + "\n\n"
+}
+
+// TestSubscribe tests subscribe routes.
+func TestSubscribe(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T, server *httptest.Server, muxRouter *mux.Router, store store.Contract)
+	}
+
+	cases := []testCase{
+		{
+			name: "Invalid Last-Event-ID",
+			test: func(t *testing.T, _ *httptest.Server, muxRouter *mux.Router, _ store.Contract) {
+				request := httptest.NewRequest(Method, Route(test.FactoryRandomFixedLengthAlphanumericString(16)), nil)
+				request.Header.Set(LastEventIDHeader, test.FactoryRandomString())
+				response := httptest.NewRecorder()
+
+				muxRouter.ServeHTTP(response, request)
+
+				assert.Equal(t, CodeInvalidLastEventID, response.Code)
+			},
+		},
+		{
+			name: "Last-Event-ID not retained",
+			test: func(t *testing.T, _ *httptest.Server, muxRouter *mux.Router, _ store.Contract) {
+				request := httptest.NewRequest(Method, Route(test.FactoryRandomFixedLengthAlphanumericString(16)), nil)
+				request.Header.Set(LastEventIDHeader, "1")
+				response := httptest.NewRecorder()
+
+				muxRouter.ServeHTTP(response, request)
+
+				assert.Equal(t, http.StatusGone, response.Code)
+				failure, err := problem.Decode(response.Body.Bytes())
+				assert.Nil(t, err)
+				assert.Equal(t, problem.CodeEventsExpired, failure.Code)
+			},
+		},
+		{
+			name: "Identity",
+			test: func(t *testing.T, server *httptest.Server, _ *mux.Router, store store.Contract) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+				defer cancel()
+				id := url.New(test.FactoryRandomString())
+				reader := open(t, ctx, server, EscapedRoute(id), "")
+				value := newAnnotation()
+
+				assert.Equal(t, status.Success, store.Create(url.New(test.FactoryRandomString()), newAnnotation()))
+				assert.Equal(t, status.Success, store.Create(id, value))
+
+				assert.Equal(
+					t,
+					expectedEvent(t, notify.Event{Sequence: 2, Identity: id.Printable(), Annotation: value}),
+					readEvent(t, reader),
+				)
+			},
+		},
+		{
+			name: "Prefix with resume",
+			test: func(t *testing.T, server *httptest.Server, _ *mux.Router, store store.Contract) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+				defer cancel()
+				prefix := test.FactoryRandomString()
+				first := url.New(prefix + "/first")
+				second := url.New(prefix + "/second")
+				firstValue := newAnnotation()
+				secondValue := newAnnotation()
+				assert.Equal(t, status.Success, store.Create(first, newAnnotation()))
+				assert.Equal(t, status.Success, store.Append(first, firstValue))
+
+				reader := open(t, ctx, server, EscapedPrefixRoute(prefix), "1")
+				assert.Equal(t, status.Success, store.Create(second, secondValue))
+
+				assert.Equal(
+					t,
+					expectedEvent(t, notify.Event{Sequence: 2, Identity: first.Printable(), Annotation: firstValue}),
+					readEvent(t, reader),
+				)
+				assert.Equal(
+					t,
+					expectedEvent(t, notify.Event{Sequence: 3, Identity: second.Printable(), Annotation: secondValue}),
+					readEvent(t, reader),
+				)
+			},
+		},
+	}
+
+	for i := range cases {
+		s := notify.New(memory.New(), 0)
+		cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, []routable.Contract{New(s).Init})
+		server := httptest.NewServer(muxRouter)
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				cases[i].test(t, server, muxRouter, s)
+				server.Close()
+				cancel()
+				wg.Wait()
+			},
+		)
+	}
+}
+
+// TestSubscribe_Authorization tests that an empty prefix only streams the events of identities the principal may use.
+func TestSubscribe_Authorization(t *testing.T) {
+	prefix := test.FactoryRandomFixedLengthAlphanumericString(16)
+	principal := &auth.Principal{Subject: test.FactoryRandomString(), Prefixes: []string{prefix}}
+	s := notify.New(memory.New(), 0)
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{authStub.Authenticated(principal), New(s).Init},
+	)
+	server := httptest.NewServer(muxRouter)
+	defer func() {
+		server.Close()
+		cancel()
+		wg.Wait()
+	}()
+	ctx, stop := context.WithTimeout(context.Background(), time.Second*5)
+	defer stop()
+	permitted := url.New(prefix + "/device")
+	value := newAnnotation()
+
+	reader := open(t, ctx, server, EscapedPrefixRoute(""), "")
+	assert.Equal(t, status.Success, s.Create(url.New(test.FactoryRandomString()), newAnnotation()))
+	assert.Equal(t, status.Success, s.Create(permitted, value))
+
+	assert.Equal(
+		t,
+		expectedEvent(t, notify.Event{Sequence: 2, Identity: permitted.Printable(), Annotation: value}),
+		readEvent(t, reader),
+	)
+}
@@ -0,0 +1,190 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/project-alvarium/go-store/internal/pkg/tenant"
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	nameParam     = "name"
+	Method        = http.MethodGet
+	CreateMethod  = http.MethodPost
+	SuspendMethod = http.MethodPost
+	ResumeMethod  = http.MethodPost
+	DeleteMethod  = http.MethodDelete
+	CodeSuccess   = http.StatusOK
+	CodeCreated   = http.StatusCreated
+	CodeDeleted   = http.StatusNoContent
+)
+
+// Request is the body of a request to create a tenant.
+type Request struct {
+	Name   string       `json:"name"`
+	Quota  tenant.Quota `json:"quota"`
+	Export []string     `json:"export"`
+}
+
+// Route creates a url.
+func Route() string {
+	return "/v1/tenants"
+}
+
+// DeleteRoute creates the url of the tenant named name.
+func DeleteRoute(name string) string {
+	return fmt.Sprintf("%s/%s", Route(), name)
+}
+
+// EscapedDeleteRoute creates the url of the tenant named name for client.
+func EscapedDeleteRoute(name string) string {
+	return DeleteRoute(url.PathEscape(name))
+}
+
+// SuspendRoute creates the url that suspends the tenant named name.
+func SuspendRoute(name string) string {
+	return DeleteRoute(name) + "/suspend"
+}
+
+// EscapedSuspendRoute creates the url that suspends the tenant named name for client.
+func EscapedSuspendRoute(name string) string {
+	return SuspendRoute(url.PathEscape(name))
+}
+
+// ResumeRoute creates the url that resumes the tenant named name.
+func ResumeRoute(name string) string {
+	return DeleteRoute(name) + "/resume"
+}
+
+// EscapedResumeRoute creates the url that resumes the tenant named name for client.
+func EscapedResumeRoute(name string) string {
+	return ResumeRoute(url.PathEscape(name))
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	tenants tenant.Contract
+}
+
+// New is a factory function that returns instance.
+func New(tenants tenant.Contract) *instance {
+	return &instance{
+		tenants: tenants,
+	}
+}
+
+// Init adds package's routes to muxRouter.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.HandleFunc(Route(), i.handle).Methods(Method)
+	muxRouter.HandleFunc(Route(), i.handleCreate).Methods(CreateMethod)
+	muxRouter.HandleFunc(SuspendRoute("{"+nameParam+"}"), i.handleSuspend).Methods(SuspendMethod)
+	muxRouter.HandleFunc(ResumeRoute("{"+nameParam+"}"), i.handleResume).Methods(ResumeMethod)
+	muxRouter.HandleFunc(DeleteRoute("{"+nameParam+"}"), i.handleDelete).Methods(DeleteMethod)
+}
+
+// write marshals value and writes it as the response body with code.
+func write(w http.ResponseWriter, r *http.Request, code int, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		problem.Write(w, r, problem.ErrInternal.WithDetail(err.Error()))
+		return
+	}
+
+	w.Header().Set(codec.ContentTypeHeader, codec.ContentTypeJSON)
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+}
+
+// fail reports err, returned by the tenants, with the problem it corresponds to.
+func fail(w http.ResponseWriter, r *http.Request, name string, err error) {
+	switch {
+	case errors.Is(err, tenant.ErrInvalid):
+		problem.Write(w, r, problem.ErrInvalidTenant.WithDetail(err.Error()))
+	case errors.Is(err, tenant.ErrExists):
+		problem.Write(w, r, problem.ErrTenantExists.WithDetail(name))
+	case errors.Is(err, tenant.ErrNotFound):
+		problem.Write(w, r, problem.ErrTenantNotFound.WithDetail(name))
+	default:
+		problem.Write(w, r, problem.ErrInternal.WithDetail(err.Error()))
+	}
+}
+
+// handle returns the tenants.
+func (i *instance) handle(w http.ResponseWriter, r *http.Request) {
+	write(w, r, CodeSuccess, i.tenants.List())
+}
+
+// handleCreate creates the tenant described by the request body.
+func (i *instance) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var request Request
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		problem.Write(w, r, problem.ErrMalformedBody.WithDetail(err.Error()))
+		return
+	}
+
+	created, err := i.tenants.Create(tenant.Tenant{Name: request.Name, Quota: request.Quota, Export: request.Export})
+	if err != nil {
+		fail(w, r, request.Name, err)
+		return
+	}
+	write(w, r, CodeCreated, created)
+}
+
+// handleSuspend suspends a tenant.
+func (i *instance) handleSuspend(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)[nameParam]
+	suspended, err := i.tenants.Suspend(name)
+	if err != nil {
+		fail(w, r, name, err)
+		return
+	}
+	write(w, r, CodeSuccess, suspended)
+}
+
+// handleResume resumes a suspended tenant.
+func (i *instance) handleResume(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)[nameParam]
+	resumed, err := i.tenants.Resume(name)
+	if err != nil {
+		fail(w, r, name, err)
+		return
+	}
+	write(w, r, CodeSuccess, resumed)
+}
+
+// handleDelete deletes a tenant and its identities.
+func (i *instance) handleDelete(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)[nameParam]
+	deleted, err := i.tenants.Delete(name)
+	switch {
+	case err != nil:
+		fail(w, r, name, err)
+		return
+	case !deleted:
+		problem.Write(w, r, problem.ErrTenantNotFound.WithDetail(name))
+		return
+	}
+
+	w.WriteHeader(CodeDeleted)
+}
@@ -0,0 +1,182 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package tenant
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	"github.com/project-alvarium/go-store/internal/pkg/tenant"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// unmarshal decodes body into v.
+func unmarshal(t *testing.T, body []byte, v interface{}) {
+	if err := json.Unmarshal(body, v); err != nil {
+		assert.FailNow(t, "Unexpected unmarshal failure:", err.Error())
+	}
+}
+
+// assertProblem asserts that response carries a problem document with code.
+func assertProblem(t *testing.T, code string, body []byte) {
+	failure, err := problem.Decode(body)
+	assert.Nil(t, err)
+	assert.Equal(t, code, failure.Code)
+}
+
+// create sends a request to create the tenant described by request.
+func create(t *testing.T, muxRouter *mux.Router, request Request) *tenant.Tenant {
+	response := testInternal.SendRequestWithBody(t, muxRouter, CreateMethod, Route(), testInternal.Marshal(t, request))
+	if response.Code != CodeCreated {
+		return nil
+	}
+	var created tenant.Tenant
+	unmarshal(t, response.Body.Bytes(), &created)
+	return &created
+}
+
+// TestTenant tests tenant management routes.
+func TestTenant(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T, muxRouter *mux.Router, tenants tenant.Contract)
+	}
+
+	cases := []testCase{
+		{
+			name: "Create malformed request",
+			test: func(t *testing.T, muxRouter *mux.Router, _ tenant.Contract) {
+				response := testInternal.SendRequestWithBody(t, muxRouter, CreateMethod, Route(), []byte("{"))
+
+				assert.Equal(t, http.StatusBadRequest, response.Code)
+				assertProblem(t, problem.CodeMalformedBody, response.Body.Bytes())
+			},
+		},
+		{
+			name: "Create invalid tenant",
+			test: func(t *testing.T, muxRouter *mux.Router, tenants tenant.Contract) {
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					CreateMethod,
+					Route(),
+					testInternal.Marshal(t, Request{Name: "Not A Label"}),
+				)
+
+				assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+				assertProblem(t, problem.CodeInvalidTenant, response.Body.Bytes())
+				assert.Empty(t, tenants.List())
+			},
+		},
+		{
+			name: "Create existing tenant",
+			test: func(t *testing.T, muxRouter *mux.Router, _ tenant.Contract) {
+				create(t, muxRouter, Request{Name: "acme"})
+
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					CreateMethod,
+					Route(),
+					testInternal.Marshal(t, Request{Name: "acme"}),
+				)
+
+				assert.Equal(t, http.StatusConflict, response.Code)
+				assertProblem(t, problem.CodeTenantExists, response.Body.Bytes())
+			},
+		},
+		{
+			name: "Create list suspend resume delete",
+			test: func(t *testing.T, muxRouter *mux.Router, tenants tenant.Contract) {
+				request := Request{
+					Name:   "acme",
+					Quota:  tenant.Quota{Identities: 10},
+					Export: []string{tenant.ExportSubscribe},
+				}
+				created := create(t, muxRouter, request)
+				assert.NotNil(t, created)
+				assert.Equal(t, request.Name, created.Name)
+				assert.Equal(t, request.Quota, created.Quota)
+				assert.Equal(t, request.Export, created.Export)
+				assert.Equal(t, tenant.StateActive, created.State)
+
+				response := testInternal.SendRequestWithoutBody(t, muxRouter, Method, Route())
+				assert.Equal(t, CodeSuccess, response.Code)
+				var listed []tenant.Tenant
+				unmarshal(t, response.Body.Bytes(), &listed)
+				assert.Equal(t, []tenant.Tenant{*created}, listed)
+
+				response = testInternal.SendRequestWithoutBody(t, muxRouter, SuspendMethod, EscapedSuspendRoute("acme"))
+				assert.Equal(t, CodeSuccess, response.Code)
+				var suspended tenant.Tenant
+				unmarshal(t, response.Body.Bytes(), &suspended)
+				assert.Equal(t, tenant.StateSuspended, suspended.State)
+
+				response = testInternal.SendRequestWithoutBody(t, muxRouter, ResumeMethod, EscapedResumeRoute("acme"))
+				assert.Equal(t, CodeSuccess, response.Code)
+				var resumed tenant.Tenant
+				unmarshal(t, response.Body.Bytes(), &resumed)
+				assert.Equal(t, tenant.StateActive, resumed.State)
+
+				response = testInternal.SendRequestWithoutBody(t, muxRouter, DeleteMethod, EscapedDeleteRoute("acme"))
+				assert.Equal(t, CodeDeleted, response.Code)
+				assert.Empty(t, response.Body.Bytes())
+				assert.Empty(t, tenants.List())
+			},
+		},
+		{
+			name: "Unknown tenant",
+			test: func(t *testing.T, muxRouter *mux.Router, _ tenant.Contract) {
+				suspend := testInternal.SendRequestWithoutBody(t, muxRouter, SuspendMethod, EscapedSuspendRoute("acme"))
+				resume := testInternal.SendRequestWithoutBody(t, muxRouter, ResumeMethod, EscapedResumeRoute("acme"))
+				remove := testInternal.SendRequestWithoutBody(t, muxRouter, DeleteMethod, EscapedDeleteRoute("acme"))
+
+				for _, response := range []int{suspend.Code, resume.Code, remove.Code} {
+					assert.Equal(t, http.StatusNotFound, response)
+				}
+				assertProblem(t, problem.CodeTenantNotFound, suspend.Body.Bytes())
+				assertProblem(t, problem.CodeTenantNotFound, resume.Body.Bytes())
+				assertProblem(t, problem.CodeTenantNotFound, remove.Body.Bytes())
+			},
+		},
+	}
+
+	for i := range cases {
+		tenants, err := tenant.New(
+			tenant.Config{Enabled: true},
+			nil,
+			func(tenant.Tenant) ([]routable.Contract, error) { return nil, nil },
+		)
+		if err != nil {
+			assert.FailNow(t, "Unexpected tenant.New failure:", err.Error())
+		}
+		cancel, wg, muxRouter := testInternal.NewSUT(pkg.Run, []routable.Contract{New(tenants).Init})
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				cases[i].test(t, muxRouter, tenants)
+				cancel()
+				wg.Wait()
+			},
+		)
+	}
+}
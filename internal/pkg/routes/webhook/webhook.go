@@ -0,0 +1,157 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/project-alvarium/go-store/internal/pkg/webhook"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	idParam            = "id"
+	Method             = http.MethodGet
+	RegisterMethod     = http.MethodPost
+	RemoveMethod       = http.MethodDelete
+	DeadLettersMethod  = http.MethodGet
+	ReplayMethod       = http.MethodPut
+	CodeInvalidHook    = http.StatusBadRequest
+	codeBodyReadFailed = http.StatusBadRequest
+	codeMarshalFailed  = http.StatusBadRequest
+	codeSaveFailed     = http.StatusInternalServerError
+	CodeSuccess        = http.StatusOK
+)
+
+// Route creates a url.
+func Route() string {
+	return "/webhooks"
+}
+
+// RemoveRoute creates the url of the hook with the given ID.
+func RemoveRoute(id string) string {
+	return fmt.Sprintf("%s/%s", Route(), id)
+}
+
+// EscapedRemoveRoute creates the url of the hook with the given ID for client.
+func EscapedRemoveRoute(id string) string {
+	return RemoveRoute(url.PathEscape(id))
+}
+
+// DeadLettersRoute creates the dead-letter queue url.
+func DeadLettersRoute() string {
+	return "/deadLetters"
+}
+
+// ReplayRoute creates the dead-letter replay url.
+func ReplayRoute() string {
+	return "/replayDeadLetters"
+}
+
+// EscapedReplayRoute creates the url that replays the dead letter with the given ID (or every dead letter if id is
+// empty) for client.
+func EscapedReplayRoute(id string) string {
+	if id == "" {
+		return ReplayRoute()
+	}
+	return ReplayRoute() + "?" + url.Values{idParam: []string{id}}.Encode()
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	webhook webhook.Contract
+}
+
+// New is a factory function that returns instance.
+func New(webhook webhook.Contract) *instance {
+	return &instance{
+		webhook: webhook,
+	}
+}
+
+// Init adds package's routes to muxRouter.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.HandleFunc(Route(), i.handle).Methods(Method)
+	muxRouter.HandleFunc(Route(), i.handleRegister).Methods(RegisterMethod)
+	muxRouter.HandleFunc(RemoveRoute("{"+idParam+"}"), i.handleRemove).Methods(RemoveMethod)
+	muxRouter.HandleFunc(DeadLettersRoute(), i.handleDeadLetters).Methods(DeadLettersMethod)
+	muxRouter.HandleFunc(ReplayRoute(), i.handleReplay).Methods(ReplayMethod)
+}
+
+// write marshals value and writes it as the response body.
+func write(w http.ResponseWriter, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		w.WriteHeader(codeMarshalFailed)
+		return
+	}
+
+	w.WriteHeader(CodeSuccess)
+	_, _ = w.Write(body)
+}
+
+// handle returns the registered hooks.
+func (i *instance) handle(w http.ResponseWriter, _ *http.Request) {
+	write(w, i.webhook.Hooks())
+}
+
+// handleRegister registers the hook contained in the request body and returns it, including its secret.
+func (i *instance) handleRegister(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(codeBodyReadFailed)
+		return
+	}
+
+	var hook webhook.Hook
+	if err := json.Unmarshal(body, &hook); err != nil {
+		w.WriteHeader(codeMarshalFailed)
+		return
+	}
+
+	registered, err := i.webhook.Register(hook)
+	if err != nil {
+		code := codeSaveFailed
+		if errors.Is(err, webhook.ErrInvalid) {
+			code = CodeInvalidHook
+		}
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+
+	write(w, registered)
+}
+
+// handleRemove unregisters a hook and returns status.
+func (i *instance) handleRemove(w http.ResponseWriter, r *http.Request) {
+	write(w, i.webhook.Remove(mux.Vars(r)[idParam]))
+}
+
+// handleDeadLetters returns the dead-letter queue.
+func (i *instance) handleDeadLetters(w http.ResponseWriter, _ *http.Request) {
+	write(w, i.webhook.DeadLetters())
+}
+
+// handleReplay replays one or every dead letter and returns status.
+func (i *instance) handleReplay(w http.ResponseWriter, r *http.Request) {
+	write(w, i.webhook.Replay(r.URL.Query().Get(idParam)))
+}
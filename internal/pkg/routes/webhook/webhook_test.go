@@ -0,0 +1,181 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	"github.com/project-alvarium/go-store/internal/pkg/runnable"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/internal/pkg/webhook"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// unmarshal decodes body into v.
+func unmarshal(t *testing.T, body []byte, v interface{}) {
+	if err := json.Unmarshal(body, v); err != nil {
+		assert.FailNow(t, "Unexpected unmarshal failure:", err.Error())
+	}
+}
+
+// TestWebhook tests webhook routes.
+func TestWebhook(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T, muxRouter *mux.Router, store store.Contract)
+	}
+
+	cases := []testCase{
+		{
+			name: "Register invalid hook",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					RegisterMethod,
+					Route(),
+					testInternal.Marshal(t, webhook.Hook{URL: test.FactoryRandomString()}),
+				)
+
+				assert.Equal(t, CodeInvalidHook, response.Code)
+				assert.NotEmpty(t, response.Body.String())
+			},
+		},
+		{
+			name: "Register list remove",
+			test: func(t *testing.T, muxRouter *mux.Router, _ store.Contract) {
+				response := testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					RegisterMethod,
+					Route(),
+					testInternal.Marshal(t, webhook.Hook{URL: "http://localhost/" + test.FactoryRandomString()}),
+				)
+				assert.Equal(t, CodeSuccess, response.Code)
+				var hook webhook.Hook
+				unmarshal(t, response.Body.Bytes(), &hook)
+				assert.NotEmpty(t, hook.Secret)
+
+				response = testInternal.SendRequestWithoutBody(t, muxRouter, Method, Route())
+				assert.Equal(t, CodeSuccess, response.Code)
+				hook.Secret = ""
+				assert.Equal(t, testInternal.Marshal(t, []webhook.Hook{hook}), response.Body.Bytes())
+
+				response = testInternal.SendRequestWithoutBody(t, muxRouter, RemoveMethod, EscapedRemoveRoute(hook.ID))
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(t, testInternal.Marshal(t, status.Success), response.Body.Bytes())
+
+				response = testInternal.SendRequestWithoutBody(t, muxRouter, RemoveMethod, EscapedRemoveRoute(hook.ID))
+				assert.Equal(t, testInternal.Marshal(t, status.NotFound), response.Body.Bytes())
+			},
+		},
+		{
+			name: "Dead letters and replay",
+			test: func(t *testing.T, muxRouter *mux.Router, store store.Contract) {
+				accept := make(chan bool, 1)
+				accept <- false
+				delivered := make(chan struct{}, 1)
+				receiver := httptest.NewServer(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, _ *http.Request) {
+							if !<-accept {
+								w.WriteHeader(http.StatusInternalServerError)
+								return
+							}
+							delivered <- struct{}{}
+						},
+					),
+				)
+				defer receiver.Close()
+				_ = testInternal.SendRequestWithBody(
+					t,
+					muxRouter,
+					RegisterMethod,
+					Route(),
+					testInternal.Marshal(t, webhook.Hook{URL: receiver.URL}),
+				)
+				value := annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, metadataStub.NewNullObject())
+				assert.Equal(t, status.Success, store.Create(url.New(test.FactoryRandomString()), value))
+
+				var deadLetters []webhook.Delivery
+				for start := time.Now(); len(deadLetters) == 0 && time.Since(start) < time.Second*5; {
+					time.Sleep(time.Millisecond * 10)
+					response := testInternal.SendRequestWithoutBody(t, muxRouter, DeadLettersMethod, DeadLettersRoute())
+					assert.Equal(t, CodeSuccess, response.Code)
+					unmarshal(t, response.Body.Bytes(), &deadLetters)
+				}
+				assert.Equal(t, 1, len(deadLetters))
+
+				response := testInternal.SendRequestWithoutBody(
+					t,
+					muxRouter,
+					ReplayMethod,
+					EscapedReplayRoute(test.FactoryRandomString()),
+				)
+				assert.Equal(t, testInternal.Marshal(t, status.NotFound), response.Body.Bytes())
+
+				accept <- true
+				response = testInternal.SendRequestWithoutBody(t, muxRouter, ReplayMethod, EscapedReplayRoute(""))
+				assert.Equal(t, CodeSuccess, response.Code)
+				assert.Equal(t, testInternal.Marshal(t, status.Success), response.Body.Bytes())
+				select {
+				case <-delivered:
+				case <-time.After(time.Second * 5):
+					assert.Fail(t, "replay not delivered")
+				}
+			},
+		},
+	}
+
+	for i := range cases {
+		s := notify.New(memory.New(), 0)
+		w, err := webhook.New(s, webhook.Config{MaxAttempts: 1})
+		if err != nil {
+			assert.FailNow(t, "Unexpected webhook.New failure:", err.Error())
+		}
+		cancel, wg, muxRouter := testInternal.NewSUTWithRunnables(
+			pkg.Run,
+			[]routable.Contract{New(w).Init},
+			[]runnable.Contract{w.Run},
+		)
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				cases[i].test(t, muxRouter, s)
+				cancel()
+				wg.Wait()
+			},
+		)
+	}
+}
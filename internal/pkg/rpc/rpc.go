@@ -0,0 +1,247 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/audit"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/author"
+	"github.com/project-alvarium/go-store/internal/pkg/authz"
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/ingest"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+	appendRoute "github.com/project-alvarium/go-store/internal/pkg/routes/append"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/create"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/find"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/subscribe"
+	"github.com/project-alvarium/go-store/pkg/grpc/storepb"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+// ReadMethods returns the full names of the methods that only read; the others write.
+func ReadMethods() []string {
+	return []string{storepb.Store_FindByIdentity_FullMethodName, storepb.Store_Subscribe_FullMethodName}
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	storepb.UnimplementedStoreServer
+	store      store.Contract
+	notify     notify.Contract
+	decoder    ingest.Contract
+	authorizer authz.Contract
+	audit      audit.Contract
+	authors    author.Contract
+}
+
+// New is a factory function that returns instance; annotations are decoded and validated by decoder, as they are
+// when posted over HTTP.
+func New(store store.Contract, notify notify.Contract, decoder ingest.Contract) *instance {
+	return &instance{
+		store:      store,
+		notify:     notify,
+		decoder:    decoder,
+		authorizer: authz.NewDefault(),
+		audit:      audit.NewDiscard(),
+		authors:    author.NewDiscard(),
+	}
+}
+
+// SetAuthorizer provides for method injection of the authorizer that decides each call's identity; methods share the
+// authorization of the HTTP routes they correspond to.  By default, calls may use the identities the caller's
+// prefixes permit.
+func (i *instance) SetAuthorizer(authorizer authz.Contract) {
+	i.authorizer = authorizer
+}
+
+// Register adds package's service to server.
+func (i *instance) Register(server *grpc.Server) {
+	storepb.RegisterStoreServer(server, i)
+}
+
+// SetAudit provides for method injection of the audit log each Create and Append call is recorded in; by default,
+// they are not recorded.
+func (i *instance) SetAudit(log audit.Contract) {
+	i.audit = log
+}
+
+// SetAuthors provides for method injection of the record each stored annotation's author is noted in; by default,
+// authors are not noted.
+func (i *instance) SetAuthors(authors author.Contract) {
+	i.authors = authors
+}
+
+// permit returns the status that rejects a call made with ctx using route on id if the caller may not.
+func (i *instance) permit(ctx context.Context, route, id string) error {
+	principal, _ := auth.FromContext(ctx)
+	if !i.authorizer.Allows(principal, route, id) {
+		return grpcStatus.Error(codes.PermissionDenied, route+" is not allowed on "+id)
+	}
+	return nil
+}
+
+// write decodes and validates request's annotation and stores it using fn, which is authorized as route, and records
+// the call in the audit log whatever its outcome.
+func (i *instance) write(
+	ctx context.Context,
+	route string,
+	request *storepb.WriteRequest,
+	fn func(id identity.Contract, m *annotation.Instance) status.Value) (*storepb.WriteResponse, error) {
+
+	started := time.Now()
+	response, unique, err := i.apply(ctx, route, request, fn)
+
+	event := audit.NewEvent(ctx, started, route, request.GetIdentity())
+	if p, ok := peer.FromContext(ctx); ok {
+		event.Address = audit.Host(p.Addr.String())
+	}
+	event.Unique = unique
+	event.Outcome = audit.OutcomeSuccess
+	event.Latency = audit.Latency(started, time.Now())
+	if err != nil || response.GetStatus() != storepb.Status(status.Success) {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = grpcStatus.Convert(err).Message()
+	}
+	i.audit.Record(event)
+	return response, err
+}
+
+// apply performs write's call, noting the caller as the author of the annotation it stores, and returns the
+// annotation's Unique as well as its response.
+func (i *instance) apply(
+	ctx context.Context,
+	route string,
+	request *storepb.WriteRequest,
+	fn func(id identity.Contract, m *annotation.Instance) status.Value) (*storepb.WriteResponse, string, error) {
+
+	if err := i.permit(ctx, route, request.GetIdentity()); err != nil {
+		return nil, "", err
+	}
+	if request.GetAnnotation() == nil {
+		return nil, "", grpcStatus.Error(codes.InvalidArgument, "annotation is required")
+	}
+
+	body, err := request.GetAnnotation().ToJSON()
+	if err != nil {
+		return nil, "", grpcStatus.Error(codes.InvalidArgument, err.Error())
+	}
+
+	id := urlIdentity.New(request.GetIdentity())
+	value, failure := i.decoder.Unmarshal(body)
+	if failure == nil {
+		failure = i.decoder.Validate(id, value)
+	}
+	if failure != nil {
+		return nil, "", grpcStatus.Error(codes.InvalidArgument, failure.Error())
+	}
+
+	result := fn(id, value)
+	if result == status.Success {
+		i.authors.Record(ctx, value)
+	}
+	return &storepb.WriteResponse{Status: storepb.Status(result)}, value.Unique, nil
+}
+
+// Create stores an annotation against a new identity.
+func (i *instance) Create(ctx context.Context, request *storepb.WriteRequest) (*storepb.WriteResponse, error) {
+	return i.write(ctx, create.Name, request, i.store.Create)
+}
+
+// Append stores an annotation against an existing identity.
+func (i *instance) Append(ctx context.Context, request *storepb.WriteRequest) (*storepb.WriteResponse, error) {
+	return i.write(ctx, appendRoute.Name, request, i.store.Append)
+}
+
+// FindByIdentity streams the annotations stored against an identity.
+func (i *instance) FindByIdentity(request *storepb.FindRequest, stream storepb.Store_FindByIdentityServer) error {
+	if err := i.permit(stream.Context(), find.Name, request.GetIdentity()); err != nil {
+		return err
+	}
+	annotations, result := i.store.FindByIdentity(urlIdentity.New(request.GetIdentity()))
+	if result == status.NotFound {
+		return grpcStatus.Error(codes.NotFound, request.GetIdentity())
+	}
+	if result != status.Success {
+		return grpcStatus.Error(codes.Unknown, request.GetIdentity())
+	}
+
+	for key := range annotations {
+		value, err := storepb.FromAnnotation(annotations[key])
+		if err != nil {
+			return grpcStatus.Error(codes.Internal, err.Error())
+		}
+		if err := stream.Send(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe streams events until the client cancels or the service stops; a prefix subscription only streams events
+// on the identities the caller may subscribe to.
+func (i *instance) Subscribe(request *storepb.SubscribeRequest, stream storepb.Store_SubscribeServer) error {
+	if !request.GetPrefix() {
+		if err := i.permit(stream.Context(), subscribe.Name, request.GetIdentity()); err != nil {
+			return err
+		}
+	}
+
+	principal, _ := auth.FromContext(stream.Context())
+	subscription, err := i.notify.Subscribe(
+		notify.Filter{
+			Identity: request.GetIdentity(),
+			Prefix:   request.GetPrefix(),
+			Permit:   func(id string) bool { return i.authorizer.Allows(principal, subscribe.Name, id) },
+		},
+		request.GetAfter(),
+	)
+	if err != nil {
+		// the client has missed events and may subscribe again from new ones.
+		return grpcStatus.Error(codes.OutOfRange, err.Error())
+	}
+	defer subscription.Close()
+
+	for {
+		event, ok := subscription.Next(stream.Context())
+		if !ok {
+			if err := stream.Context().Err(); err != nil {
+				return grpcStatus.FromContextError(err).Err()
+			}
+			// the subscriber fell behind; the client resumes from the last event it received.
+			return grpcStatus.Error(codes.ResourceExhausted, "subscription ended")
+		}
+
+		value, err := storepb.FromAnnotation(event.Annotation)
+		if err != nil {
+			return grpcStatus.Error(codes.Internal, err.Error())
+		}
+		message := &storepb.Event{Sequence: event.Sequence, Identity: event.Identity, Annotation: value}
+		if err := stream.Send(message); err != nil {
+			return err
+		}
+	}
+}
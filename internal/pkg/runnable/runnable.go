@@ -0,0 +1,24 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package runnable
+
+import (
+	"context"
+	"sync"
+)
+
+// Contract defines the runnable contract; a runnable starts its background work, registers it with wg, and stops
+// it when ctx is done.
+type Contract func(ctx context.Context, wg *sync.WaitGroup)
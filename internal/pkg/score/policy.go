@@ -0,0 +1,78 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package score
+
+import (
+	"errors"
+
+	assessMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
+	publishMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata"
+)
+
+// Rule assigns a weight to the annotations of a metadata kind.
+type Rule struct {
+	MetadataKind string  `json:"metadataKind"`
+	Weight       float64 `json:"weight"`
+}
+
+// Policy defines a weighting policy; Version is assigned by the service when the policy is installed.
+type Policy struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+	Rules   []Rule `json:"rules"`
+}
+
+// NewDefaultPolicy returns a policy that weighs assess, pki, and publish annotations equally.
+func NewDefaultPolicy() *Policy {
+	return &Policy{
+		Name: "default",
+		Rules: []Rule{
+			{MetadataKind: assessMetadata.Kind, Weight: 1},
+			{MetadataKind: pkiMetadata.Kind, Weight: 1},
+			{MetadataKind: publishMetadata.Kind, Weight: 1},
+		},
+	}
+}
+
+// validate returns an error if policy cannot be used to compute a score.
+func (p *Policy) validate() error {
+	if p.Name == "" {
+		return errors.New("policy name is required")
+	}
+	if len(p.Rules) == 0 {
+		return errors.New("policy requires at least one rule")
+	}
+
+	var total float64
+	kinds := make(map[string]bool, len(p.Rules))
+	for key := range p.Rules {
+		if p.Rules[key].MetadataKind == "" {
+			return errors.New("rule metadataKind is required")
+		}
+		if kinds[p.Rules[key].MetadataKind] {
+			return errors.New("duplicate rule for metadataKind " + p.Rules[key].MetadataKind)
+		}
+		if p.Rules[key].Weight < 0 {
+			return errors.New("rule weight must not be negative")
+		}
+		kinds[p.Rules[key].MetadataKind] = true
+		total += p.Rules[key].Weight
+	}
+	if total == 0 {
+		return errors.New("policy weights must not all be zero")
+	}
+	return nil
+}
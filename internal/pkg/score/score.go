@@ -0,0 +1,186 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package score
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/annotator"
+	assessMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
+	publishMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+// nestedResults maps metadata kinds to the field that holds their annotator-specific result.
+var nestedResults = map[string]string{
+	assessMetadata.Kind:  "assessorMetadata",
+	pkiMetadata.Kind:     "signerMetadata",
+	publishMetadata.Kind: "publisherMetadata",
+}
+
+// PolicyReference identifies the policy that produced a score.
+type PolicyReference struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+// Annotator is the contribution of a single metadata kind to a score.
+type Annotator struct {
+	MetadataKind string   `json:"metadataKind"`
+	Weight       float64  `json:"weight"`
+	Score        float64  `json:"score"`
+	Annotations  []string `json:"annotations"`
+}
+
+// Result is a trust score and the evidence it was computed from.
+type Result struct {
+	Identity    string                 `json:"identity"`
+	Policy      PolicyReference        `json:"policy"`
+	Score       float64                `json:"score"`
+	Annotators  []Annotator            `json:"annotators"`
+	Annotations []*annotation.Instance `json:"annotations"`
+}
+
+// Contract defines the trust score abstraction.
+type Contract interface {
+	// Score returns the trust score of identity under the current policy and status.
+	Score(id identity.Contract) (*Result, status.Value)
+
+	// Policy returns the current policy.
+	Policy() Policy
+
+	// Swap validates and installs policy and returns the installed policy.
+	Swap(policy Policy) (Policy, error)
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	m      sync.RWMutex
+	store  store.Contract
+	policy Policy
+}
+
+// New is a factory function that returns instance using the default policy as version 1.
+func New(store store.Contract) *instance {
+	policy := *NewDefaultPolicy()
+	policy.Version = 1
+	return &instance{
+		m:      sync.RWMutex{},
+		store:  store,
+		policy: policy,
+	}
+}
+
+// Policy returns the current policy.
+func (i *instance) Policy() Policy {
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	return i.policy
+}
+
+// Swap validates and installs policy and returns the installed policy.  The policy's version is ignored and replaced
+// with the version after the current policy's, so that versions count and identify the installed policies.
+func (i *instance) Swap(policy Policy) (Policy, error) {
+	if err := policy.validate(); err != nil {
+		return Policy{}, err
+	}
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	policy.Version = i.policy.Version + 1
+	policy.Rules = append([]Rule(nil), policy.Rules...)
+	i.policy = policy
+	return policy, nil
+}
+
+// evaluate returns 1 if m records a successful outcome and 0 otherwise.
+func evaluate(m *annotation.Instance) float64 {
+	data, err := json.Marshal(m.Metadata)
+	if err != nil {
+		return 0
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return 0
+	}
+	if field, exists := nestedResults[m.MetadataKind]; exists {
+		nested, ok := document[field].(map[string]interface{})
+		if !ok {
+			return 0
+		}
+		document = nested
+	}
+
+	if result, _ := document["result"].(string); result != annotator.SuccessKind {
+		return 0
+	}
+	if valid, exists := document["validSignature"].(bool); exists && !valid {
+		return 0
+	}
+	return 1
+}
+
+// Score returns the trust score of identity under the current policy and status.  Each rule scores the mean
+// outcome of its kind's annotations; the overall score is the weighted mean of the rule scores, so that kinds
+// without evidence lower the score.
+func (i *instance) Score(id identity.Contract) (*Result, status.Value) {
+	annotations, result := i.store.FindByIdentity(id)
+	if result != status.Success {
+		return nil, result
+	}
+
+	policy := i.Policy()
+	score := &Result{
+		Identity:    id.Printable(),
+		Policy:      PolicyReference{Name: policy.Name, Version: policy.Version},
+		Annotators:  make([]Annotator, len(policy.Rules)),
+		Annotations: make([]*annotation.Instance, 0),
+	}
+
+	var total, weights float64
+	for key := range policy.Rules {
+		a := Annotator{
+			MetadataKind: policy.Rules[key].MetadataKind,
+			Weight:       policy.Rules[key].Weight,
+			Annotations:  make([]string, 0),
+		}
+		var sum float64
+		for annotationKey := range annotations {
+			if annotations[annotationKey].MetadataKind != a.MetadataKind {
+				continue
+			}
+			sum += evaluate(annotations[annotationKey])
+			a.Annotations = append(a.Annotations, annotations[annotationKey].Unique)
+			score.Annotations = append(score.Annotations, annotations[annotationKey])
+		}
+		if len(a.Annotations) > 0 {
+			a.Score = sum / float64(len(a.Annotations))
+		}
+
+		total += a.Weight * a.Score
+		weights += a.Weight
+		score.Annotators[key] = a
+	}
+	score.Score = total / weights
+	return score, status.Success
+}
@@ -0,0 +1,218 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package score
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/metadata"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	pkiAssessorMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/assessor/pki/metadata"
+	assessMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
+	signerMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/signer/signpkcs1v15/metadata"
+	publishMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata"
+	iotaMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/publisher/iota/metadata"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAnnotation returns a new annotation for id with metadata m.
+func newAnnotation(id identity.Contract, m metadata.Contract) *annotation.Instance {
+	return annotation.New(ulid.New().Get(), id, nil, m)
+}
+
+// TestInstance_Score tests instance.Score.
+func TestInstance_Score(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	validAssess := assessMetadata.New(nil, pkiAssessorMetadata.NewSuccess(true, []string{}))
+	invalidAssess := assessMetadata.New(nil, pkiAssessorMetadata.NewSuccess(false, []string{}))
+	failedAssess := assessMetadata.New(nil, pkiAssessorMetadata.NewFailure(test.FactoryRandomString()))
+	signed := pkiMetadata.New(
+		nil,
+		test.FactoryRandomByteSlice(),
+		test.FactoryRandomByteSlice(),
+		test.FactoryRandomByteSlice(),
+		signerMetadata.NewSuccess(crypto.SHA256, test.FactoryRandomString()),
+	)
+	published := publishMetadata.New(
+		nil,
+		iotaMetadata.NewSuccess(test.FactoryRandomString(), test.FactoryRandomString(), test.FactoryRandomString()),
+	)
+
+	cases := []testCase{
+		{
+			name: "identity not found",
+			test: func(t *testing.T) {
+				sut := New(memory.New())
+
+				result, findResult := sut.Score(hash.New(test.FactoryRandomByteSlice()))
+
+				assert.Nil(t, result)
+				assert.Equal(t, status.NotFound, findResult)
+			},
+		},
+		{
+			name: "all annotators succeed",
+			test: func(t *testing.T) {
+				s := memory.New()
+				id := hash.New(test.FactoryRandomByteSlice())
+				assess := newAnnotation(id, validAssess)
+				pki := newAnnotation(id, signed)
+				publish := newAnnotation(id, published)
+				assert.Equal(t, status.Success, s.Create(id, pki))
+				assert.Equal(t, status.Success, s.Append(id, assess))
+				assert.Equal(t, status.Success, s.Append(id, publish))
+				sut := New(s)
+
+				result, findResult := sut.Score(id)
+
+				assert.Equal(t, status.Success, findResult)
+				assert.Equal(t, id.Printable(), result.Identity)
+				assert.Equal(t, PolicyReference{Name: "default", Version: 1}, result.Policy)
+				assert.Equal(t, float64(1), result.Score)
+				assert.Equal(
+					t,
+					[]Annotator{
+						{MetadataKind: assessMetadata.Kind, Weight: 1, Score: 1, Annotations: []string{assess.Unique}},
+						{MetadataKind: pkiMetadata.Kind, Weight: 1, Score: 1, Annotations: []string{pki.Unique}},
+						{MetadataKind: publishMetadata.Kind, Weight: 1, Score: 1, Annotations: []string{publish.Unique}},
+					},
+					result.Annotators,
+				)
+				assert.Equal(t, []*annotation.Instance{assess, pki, publish}, result.Annotations)
+			},
+		},
+		{
+			name: "failed assessments and missing evidence lower the score",
+			test: func(t *testing.T) {
+				s := memory.New()
+				id := hash.New(test.FactoryRandomByteSlice())
+				assert.Equal(t, status.Success, s.Create(id, newAnnotation(id, validAssess)))
+				assert.Equal(t, status.Success, s.Append(id, newAnnotation(id, invalidAssess)))
+				assert.Equal(t, status.Success, s.Append(id, newAnnotation(id, failedAssess)))
+				assert.Equal(t, status.Success, s.Append(id, newAnnotation(id, validAssess)))
+				assert.Equal(t, status.Success, s.Append(id, newAnnotation(id, signed)))
+				sut := New(s)
+				_, err := sut.Swap(
+					Policy{
+						Name: "weighted",
+						Rules: []Rule{
+							{MetadataKind: assessMetadata.Kind, Weight: 2},
+							{MetadataKind: pkiMetadata.Kind, Weight: 1},
+							{MetadataKind: publishMetadata.Kind, Weight: 1},
+						},
+					},
+				)
+				assert.Nil(t, err)
+
+				result, findResult := sut.Score(id)
+
+				assert.Equal(t, status.Success, findResult)
+				assert.Equal(t, PolicyReference{Name: "weighted", Version: 2}, result.Policy)
+				assert.Equal(t, 0.5, result.Annotators[0].Score)
+				assert.Equal(t, float64(1), result.Annotators[1].Score)
+				assert.Equal(t, float64(0), result.Annotators[2].Score)
+				assert.Equal(t, (2*0.5+1*1)/float64(4), result.Score)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
+
+// TestInstance_Swap tests instance.Swap.
+func TestInstance_Swap(t *testing.T) {
+	type testCase struct {
+		name     string
+		policy   Policy
+		valid    bool
+		expected int
+	}
+
+	rules := []Rule{{MetadataKind: test.FactoryRandomString(), Weight: 1}}
+	cases := []testCase{
+		{name: "name required", policy: Policy{Rules: rules}},
+		{name: "rules required", policy: Policy{Name: test.FactoryRandomString()}},
+		{
+			name:   "kind required",
+			policy: Policy{Name: test.FactoryRandomString(), Rules: []Rule{{Weight: 1}}},
+		},
+		{
+			name:   "duplicate kind",
+			policy: Policy{Name: test.FactoryRandomString(), Rules: []Rule{rules[0], rules[0]}},
+		},
+		{
+			name: "negative weight",
+			policy: Policy{
+				Name:  test.FactoryRandomString(),
+				Rules: []Rule{{MetadataKind: test.FactoryRandomString(), Weight: -1}},
+			},
+		},
+		{
+			name: "zero weights",
+			policy: Policy{
+				Name:  test.FactoryRandomString(),
+				Rules: []Rule{{MetadataKind: test.FactoryRandomString()}},
+			},
+		},
+		{
+			name:     "next version assigned",
+			policy:   Policy{Name: test.FactoryRandomString(), Rules: rules},
+			valid:    true,
+			expected: 2,
+		},
+		{
+			name:     "supplied version ignored",
+			policy:   Policy{Name: test.FactoryRandomString(), Version: 7, Rules: rules},
+			valid:    true,
+			expected: 2,
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				sut := New(memory.New())
+
+				result, err := sut.Swap(cases[i].policy)
+
+				if !cases[i].valid {
+					assert.NotNil(t, err)
+					assert.Equal(t, 1, sut.Policy().Version)
+					return
+				}
+				assert.Nil(t, err)
+				assert.Equal(t, cases[i].expected, result.Version)
+				assert.Equal(t, result, sut.Policy())
+			},
+		)
+	}
+}
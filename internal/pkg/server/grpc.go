@@ -0,0 +1,87 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// stream is a grpc.ServerStream whose context ends when the service stops.
+type stream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the stream's context.
+func (s *stream) Context() context.Context {
+	return s.ctx
+}
+
+// ServeGRPC creates a gRPC server with options, lets register add its services, and serves it on listener; the
+// server uses TLS if tlsConfig is not nil.
+func ServeGRPC(
+	ctx context.Context,
+	register func(server *grpc.Server),
+	wg *sync.WaitGroup,
+	listener net.Listener,
+	tlsConfig *tls.Config,
+	options ...grpc.ServerOption) {
+
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	options = append(
+		options,
+		grpc.StreamInterceptor(
+			func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				// long-lived streams end when the service stops.
+				streamCtx, cancel := context.WithCancel(ss.Context())
+				defer cancel()
+				go func() {
+					select {
+					case <-ctx.Done():
+						cancel()
+					case <-streamCtx.Done():
+					}
+				}()
+
+				return handler(srv, &stream{ServerStream: ss, ctx: streamCtx})
+			},
+		),
+	)
+	server := grpc.NewServer(options...)
+	register(server)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		_ = server.Serve(listener)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+}
@@ -16,6 +16,8 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"net"
 	"net/http"
 	"sync"
 	"time"
@@ -23,20 +25,30 @@ import (
 	"github.com/gorilla/mux"
 )
 
-// Serve creates corresponding routes and starts an HTTP server.
-func Serve(ctx context.Context, muxRouter *mux.Router, wg *sync.WaitGroup, address string) {
+// Serve creates corresponding routes and starts an HTTP server, or an HTTPS server if tlsConfig is not nil.
+func Serve(ctx context.Context, muxRouter *mux.Router, wg *sync.WaitGroup, address string, tlsConfig *tls.Config) {
 	timeout := time.Second * time.Duration(30)
 	server := &http.Server{
 		Addr:         address,
 		Handler:      muxRouter,
 		ReadTimeout:  timeout,
 		WriteTimeout: timeout,
+		TLSConfig:    tlsConfig,
+		BaseContext: func(net.Listener) context.Context {
+			// long-lived requests end when the service stops.
+			return ctx
+		},
 	}
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 
+		if tlsConfig != nil {
+			// the certificates come from tlsConfig.
+			_ = server.ListenAndServeTLS("", "")
+			return
+		}
 		_ = server.ListenAndServe()
 	}()
 
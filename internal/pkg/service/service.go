@@ -0,0 +1,402 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/project-alvarium/go-store/internal/pkg/audit"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/auth/apikey"
+	"github.com/project-alvarium/go-store/internal/pkg/auth/interceptor"
+	"github.com/project-alvarium/go-store/internal/pkg/auth/jwt"
+	"github.com/project-alvarium/go-store/internal/pkg/auth/mtls"
+	"github.com/project-alvarium/go-store/internal/pkg/author"
+	"github.com/project-alvarium/go-store/internal/pkg/authz"
+	"github.com/project-alvarium/go-store/internal/pkg/certificate"
+	"github.com/project-alvarium/go-store/internal/pkg/config"
+	"github.com/project-alvarium/go-store/internal/pkg/graph"
+	"github.com/project-alvarium/go-store/internal/pkg/index"
+	"github.com/project-alvarium/go-store/internal/pkg/ingest"
+	"github.com/project-alvarium/go-store/internal/pkg/logging"
+	"github.com/project-alvarium/go-store/internal/pkg/metadata/registry"
+	"github.com/project-alvarium/go-store/internal/pkg/metrics"
+	"github.com/project-alvarium/go-store/internal/pkg/mqtt"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+	"github.com/project-alvarium/go-store/internal/pkg/openapi"
+	"github.com/project-alvarium/go-store/internal/pkg/ratelimit"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	appendRoute "github.com/project-alvarium/go-store/internal/pkg/routes/append"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/create"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/find"
+	graphqlRoute "github.com/project-alvarium/go-store/internal/pkg/routes/graphql"
+	indexRoute "github.com/project-alvarium/go-store/internal/pkg/routes/index"
+	keyRoute "github.com/project-alvarium/go-store/internal/pkg/routes/key"
+	metricsRoute "github.com/project-alvarium/go-store/internal/pkg/routes/metrics"
+	openapiRoute "github.com/project-alvarium/go-store/internal/pkg/routes/openapi"
+	scoreRoute "github.com/project-alvarium/go-store/internal/pkg/routes/score"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/socket"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/subscribe"
+	tenantRoute "github.com/project-alvarium/go-store/internal/pkg/routes/tenant"
+	webhookRoute "github.com/project-alvarium/go-store/internal/pkg/routes/webhook"
+	"github.com/project-alvarium/go-store/internal/pkg/rpc"
+	"github.com/project-alvarium/go-store/internal/pkg/runnable"
+	"github.com/project-alvarium/go-store/internal/pkg/score"
+	"github.com/project-alvarium/go-store/internal/pkg/server"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+	"github.com/project-alvarium/go-store/internal/pkg/tenant"
+	"github.com/project-alvarium/go-store/internal/pkg/webhook"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+)
+
+// Instance is the wired service: the routables of its HTTP router, the runnables started beside its server, and the
+// TLS configuration its servers use, if any.
+type Instance struct {
+	Routables []routable.Contract
+	Runnables []runnable.Contract
+	TLSConfig *tls.Config
+	closers   []func()
+}
+
+// New is a factory function that returns the service cfg describes; legacyStatus selects the original responses of
+// the create, append and find routes, and grpcAddress, if not empty, is the address the gRPC server listens on.  The
+// caller releases the service's resources with Close.
+func New(cfg *config.Instance, legacyStatus bool, grpcAddress string) (*Instance, error) {
+	i := &Instance{}
+	if err := i.wire(cfg, legacyStatus, grpcAddress); err != nil {
+		i.Close()
+		return nil, err
+	}
+	return i, nil
+}
+
+// Close releases the resources the service holds, such as its MQTT connection and audit log, in reverse order.
+func (i *Instance) Close() {
+	for key := len(i.closers) - 1; key >= 0; key-- {
+		i.closers[key]()
+	}
+}
+
+// wire builds the service's components from cfg and connects them.
+func (i *Instance) wire(cfg *config.Instance, legacyStatus bool, grpcAddress string) error {
+	measures := metrics.New()
+	backing := memory.New()
+	var stored store.Contract = backing
+	if cfg.Metrics.Enabled {
+		stored = measures.Store("", backing)
+	}
+	indexed := index.New(stored, cfg.Indexes)
+	s := notify.New(indexed, cfg.SubscriptionHistory)
+	scorer := score.New(s)
+	if cfg.ScorePolicy != nil {
+		if _, err := scorer.Swap(*cfg.ScorePolicy); err != nil {
+			return fmt.Errorf("invalid score policy: %w", err)
+		}
+	}
+	webhooks, err := webhook.New(s, cfg.Webhooks)
+	if err != nil {
+		return fmt.Errorf("unable to load webhooks: %w", err)
+	}
+	webhooks.SetRecorder(func(err error) { log.Printf("unable to save webhook dead letters: %v", err) })
+	authors := author.New()
+	queries, err := graph.New(indexed, cfg.GraphQL)
+	if err != nil {
+		return fmt.Errorf("unable to build graphql schema: %w", err)
+	}
+	queries.SetAuthors(authors)
+	document, err := openapi.Load()
+	if err != nil {
+		return fmt.Errorf("invalid openapi document: %w", err)
+	}
+	mFactory, err := registry.New(cfg.Metadata)
+	if err != nil {
+		return fmt.Errorf("invalid metadata configuration: %w", err)
+	}
+	iFactory := identityFactory.New()
+	if err := cfg.Ingest.Validate(); err != nil {
+		return fmt.Errorf("invalid ingest configuration: %w", err)
+	}
+	decoder := ingest.New(mFactory, iFactory, cfg.Ingest)
+	decoder.SetKinds(mFactory)
+	decoder.SetRecorder(func(mismatch ingest.Mismatch) { log.Printf("accepted %s", mismatch) })
+	keys, err := apikey.New(cfg.Auth.KeysPath)
+	if err != nil {
+		return fmt.Errorf("unable to load api keys: %w", err)
+	}
+	authenticators := []auth.Authenticator{keys}
+	var refreshers []runnable.Contract
+	if cfg.JWT.Enabled() {
+		tokens, err := jwt.New(cfg.JWT)
+		if err != nil {
+			return fmt.Errorf("unable to load jwks: %w", err)
+		}
+		tokens.SetRecorder(func(err error) { log.Printf("unable to refresh jwks: %v", err) })
+		authenticators = append(authenticators, tokens)
+		refreshers = append(refreshers, tokens.Run)
+	}
+	var tlsConfig *tls.Config
+	if cfg.TLS.Enabled() {
+		loaded, err := certificate.New(cfg.TLS)
+		if err != nil {
+			return fmt.Errorf("unable to load certificates: %w", err)
+		}
+		loaded.SetRecorder(func(err error) { log.Printf("unable to reload certificates: %v", err) })
+		tlsConfig = loaded.TLSConfig()
+		refreshers = append(refreshers, loaded.Run)
+
+		if cfg.TLS.ClientAuth != certificate.ClientAuthNone {
+			if err := cfg.ClientIdentity.Validate(); err != nil {
+				return fmt.Errorf("invalid client identity configuration: %w", err)
+			}
+			authenticators = append(authenticators, mtls.New(cfg.ClientIdentity))
+		}
+	}
+	authenticator := auth.New(
+		cfg.Auth,
+		auth.Policy{
+			Public: []string{openapiRoute.Route()},
+			Read:   append([]string{graphqlRoute.Route()}, rpc.ReadMethods()...),
+			Admin: []string{
+				keyRoute.Route(),
+				webhookRoute.Route(),
+				webhookRoute.DeadLettersRoute(),
+				webhookRoute.ReplayRoute(),
+				indexRoute.RebuildRoute(),
+				scoreRoute.PolicyRoute(),
+				tenantRoute.Route(),
+				metricsRoute.Route(),
+			},
+		},
+		authenticators,
+	)
+	var auditLog audit.Contract = audit.NewWriter(os.Stderr)
+	var writeLog audit.Contract = audit.NewDiscard()
+	var auditors []routable.Contract
+	if cfg.Audit.Enabled() {
+		sink, err := audit.NewFile(cfg.Audit)
+		if err != nil {
+			return fmt.Errorf("unable to open audit log: %w", err)
+		}
+		i.closers = append(i.closers, func() { _ = sink.Close() })
+		chained, err := audit.NewLog(sink)
+		if err != nil {
+			return fmt.Errorf("unable to resume audit log: %w", err)
+		}
+		chained.SetRecorder(func(err error) { log.Printf("unable to write audit log: %v", err) })
+		auditLog = chained
+		writeLog = chained
+		auditors = append(auditors, audit.NewMiddleware(chained).Init)
+	}
+	var authorizers []routable.Contract
+	var authorizer authz.Contract = authz.NewDefault()
+	if cfg.Authorization.Enabled() {
+		if !cfg.Auth.Enabled {
+			return errors.New("authorization requires auth to be enabled")
+		}
+		policy, err := authz.New(
+			cfg.Authorization,
+			[]string{
+				create.Name,
+				appendRoute.Name,
+				find.Name,
+				subscribe.Name,
+				graphqlRoute.Name,
+				indexRoute.Name,
+				scoreRoute.Name,
+			},
+			auditLog,
+		)
+		if err != nil {
+			return fmt.Errorf("unable to load authorization policy: %w", err)
+		}
+		policy.SetRecorder(func(err error) { log.Printf("unable to reload authorization policy: %v", err) })
+		authorizer = policy
+		authorizers = append(authorizers, policy.Init)
+		refreshers = append(refreshers, policy.Run)
+	}
+	limiter, err := ratelimit.New(cfg.RateLimit, []string{subscribe.Name, socket.Name})
+	if err != nil {
+		return fmt.Errorf("invalid rate limit configuration: %w", err)
+	}
+	logger, err := logging.New(cfg.Logging, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("invalid logging configuration: %w", err)
+	}
+	guards := []routable.Contract{logger.Init}
+	if cfg.Metrics.Enabled {
+		guards = append(guards, measures.Init)
+	}
+	guards = append(append(guards, authenticator.Init), auditors...)
+	var admins []routable.Contract
+	if cfg.Tenants.Enabled {
+		if !cfg.Auth.Enabled {
+			return errors.New("tenants require auth to be enabled")
+		}
+		tenants, err := tenant.New(
+			cfg.Tenants,
+			map[string]tenant.Usage{
+				create.Name:      {Identities: 1, Annotations: 1},
+				appendRoute.Name: {Annotations: 1},
+			},
+			func(t tenant.Tenant) ([]routable.Contract, error) {
+				backing := memory.New()
+				var stored store.Contract = backing
+				routables := []routable.Contract{limiter.Init}
+				if cfg.Metrics.Enabled {
+					stored = measures.Store(t.Name, backing)
+					routables = append([]routable.Contract{measures.Init}, routables...)
+				}
+				indexed := index.New(stored, cfg.Indexes)
+				isolated := notify.New(indexed, cfg.SubscriptionHistory)
+				authors := author.New()
+				creator := create.New(isolated, decoder, legacyStatus)
+				creator.SetAuthors(authors)
+				appender := appendRoute.New(isolated, decoder, legacyStatus)
+				appender.SetAuthors(authors)
+				routables = append(
+					append(routables, authorizers...),
+					find.New(isolated, legacyStatus).Init,
+					creator.Init,
+					appender.Init,
+				)
+				if t.Exports(tenant.ExportGraphQL) {
+					queries, err := graph.New(indexed, cfg.GraphQL)
+					if err != nil {
+						return nil, err
+					}
+					queries.SetAuthors(authors)
+					graphql := graphqlRoute.New(queries, cfg.Ingest.MaxBodySize)
+					graphql.SetAuthorizer(authorizer)
+					routables = append(routables, graphql.Init)
+				}
+				if t.Exports(tenant.ExportSubscribe) {
+					subscriber := subscribe.New(isolated)
+					subscriber.SetAuthorizer(authorizer)
+					routables = append(routables, subscriber.Init)
+				}
+				return append(routables, openapi.New(document, cfg.OpenAPI).Init), nil
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to load tenants: %w", err)
+		}
+		if cfg.Metrics.Enabled {
+			tenants.SetRelease(measures.Forget)
+		}
+		guards = append(guards, tenants.Init)
+		admins = append(admins, tenantRoute.New(tenants).Init)
+	}
+	guards = append(append(guards, limiter.Init), authorizers...)
+	runnables := append([]runnable.Contract{webhooks.Run}, refreshers...)
+	if cfg.MQTT.Broker.URL != "" {
+		client, err := mqtt.Connect(cfg.MQTT.Broker)
+		if err != nil {
+			return fmt.Errorf("unable to connect to mqtt broker: %w", err)
+		}
+		i.closers = append(i.closers, client.Disconnect)
+
+		bridge := mqtt.New(client, s, s, decoder, cfg.MQTT)
+		bridge.SetAudit(writeLog)
+		if err := bridge.Subscribe(); err != nil {
+			return fmt.Errorf("unable to subscribe to mqtt topics: %w", err)
+		}
+		runnables = append(runnables, bridge.Run)
+	}
+	if grpcAddress != "" {
+		listener, err := net.Listen("tcp", grpcAddress)
+		if err != nil {
+			return fmt.Errorf("unable to listen for grpc: %w", err)
+		}
+		i.closers = append(i.closers, func() { _ = listener.Close() })
+
+		service := rpc.New(s, s, decoder)
+		service.SetAuthorizer(authorizer)
+		service.SetAudit(writeLog)
+		service.SetAuthors(authors)
+		runnables = append(
+			runnables,
+			func(ctx context.Context, wg *sync.WaitGroup) {
+				server.ServeGRPC(
+					ctx,
+					service.Register,
+					wg,
+					listener,
+					tlsConfig,
+					grpc.ChainUnaryInterceptor(interceptor.Unary(authenticator)),
+					grpc.ChainStreamInterceptor(interceptor.Stream(authenticator)),
+				)
+			},
+		)
+	}
+	lookups := indexRoute.New(indexed)
+	lookups.SetAuthorizer(authorizer)
+	subscriber := subscribe.New(s)
+	subscriber.SetAuthorizer(authorizer)
+	sockets := socket.New(s, s, decoder)
+	sockets.SetAuthorizer(authorizer)
+	sockets.SetAudit(writeLog)
+	sockets.SetAuthors(authors)
+	graphql := graphqlRoute.New(queries, cfg.Ingest.MaxBodySize)
+	graphql.SetAuthorizer(authorizer)
+	creator := create.New(s, decoder, legacyStatus)
+	creator.SetAuthors(authors)
+	appender := appendRoute.New(s, decoder, legacyStatus)
+	appender.SetAuthors(authors)
+	routables := append(
+		guards,
+		find.New(s, legacyStatus).Init,
+		creator.Init,
+		appender.Init,
+		lookups.Init,
+		scoreRoute.New(scorer, cfg.Ingest.MaxBodySize).Init,
+		subscriber.Init,
+		sockets.Init,
+		webhookRoute.New(webhooks).Init,
+		graphql.Init,
+		openapiRoute.New(openapi.JSON()).Init,
+		keyRoute.New(keys).Init,
+	)
+	routables = append(routables, admins...)
+	if cfg.Metrics.Enabled {
+		if cfg.Metrics.Address == "" {
+			routables = append(routables, metricsRoute.New(measures).Init)
+		} else {
+			adminRouter := mux.NewRouter()
+			metricsRoute.New(measures).Init(adminRouter)
+			runnables = append(
+				runnables,
+				func(ctx context.Context, wg *sync.WaitGroup) {
+					server.Serve(ctx, adminRouter, wg, cfg.Metrics.Address, nil)
+				},
+			)
+		}
+	}
+	i.Routables = append(routables, openapi.New(document, cfg.OpenAPI).Init)
+	i.Runnables = runnables
+	i.TLSConfig = tlsConfig
+	return nil
+}
@@ -0,0 +1,101 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg/config"
+	"github.com/project-alvarium/go-store/internal/pkg/openapi"
+	"github.com/project-alvarium/go-store/internal/pkg/tenant"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDrift fails if a route the service registers is not documented or a documented operation is not registered;
+// the configuration enables every optional route served beside the others.
+func TestDrift(t *testing.T) {
+	directory, err := ioutil.TempDir("", "openapi")
+	if err != nil {
+		assert.FailNow(t, "Unexpected ioutil.TempDir failure:", err.Error())
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+
+	document, err := openapi.Load()
+	if err != nil {
+		assert.FailNow(t, "Unexpected openapi.Load failure:", err.Error())
+	}
+
+	cfg := config.New()
+	cfg.Webhooks.Directory = directory
+	cfg.Auth.Enabled = true
+	cfg.Tenants.Enabled = true
+	cfg.Metrics.Enabled = true
+	sut, err := New(cfg, false, "")
+	if err != nil {
+		assert.FailNow(t, "Unexpected New failure:", err.Error())
+	}
+	defer sut.Close()
+	muxRouter := mux.NewRouter().UseEncodedPath()
+	for key := range sut.Routables {
+		sut.Routables[key](muxRouter)
+	}
+
+	registered := make([]string, 0)
+	err = muxRouter.Walk(
+		func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			// the tenant prefix forwards every method to a tenant's own routes, which are documented unprefixed.
+			if route.GetName() == tenant.PrefixName {
+				return nil
+			}
+			template, err := route.GetPathTemplate()
+			if err != nil {
+				return err
+			}
+			methods, err := route.GetMethods()
+			if err != nil {
+				return err
+			}
+			for key := range methods {
+				registered = append(registered, methods[key]+" "+template)
+			}
+			return nil
+		},
+	)
+	assert.Nil(t, err)
+
+	documented := make([]string, 0)
+	for path := range document.Paths {
+		for method := range document.Paths[path] {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(registered)
+	sort.Strings(documented)
+	assert.Equal(t, documented, registered)
+
+	for path := range document.Paths {
+		for method, operation := range document.Paths[path] {
+			assert.NotEmpty(t, operation.OperationID, "%s %s has no operationId", method, path)
+			assert.NotEmpty(t, operation.Responses, "%s %s has no responses", method, path)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSUT returns a new system under test.
+func newSUT() *instance {
+	return New()
+}
+
+// newAnnotation returns a new annotation for id.
+func newAnnotation(id identity.Contract, previous identity.Contract) *annotation.Instance {
+	return annotation.New(ulid.New().Get(), id, previous, metadataStub.NewNullObject())
+}
+
+// TestInstance_Create tests instance.Create.
+func TestInstance_Create(t *testing.T) {
+	sut := newSUT()
+	id := hash.New(test.FactoryRandomByteSlice())
+	value := newAnnotation(id, nil)
+
+	assert.Equal(t, status.Success, sut.Create(id, value))
+	assert.Equal(t, status.Exists, sut.Create(id, value))
+
+	result, findResult := sut.FindByIdentity(id)
+	assert.Equal(t, status.Success, findResult)
+	assert.Equal(t, []*annotation.Instance{value}, result)
+}
+
+// TestInstance_Append tests instance.Append.
+func TestInstance_Append(t *testing.T) {
+	sut := newSUT()
+	id := hash.New(test.FactoryRandomByteSlice())
+	created := newAnnotation(id, nil)
+	appended := newAnnotation(id, nil)
+
+	assert.Equal(t, status.NotFound, sut.Append(id, appended))
+	assert.Equal(t, status.Success, sut.Create(id, created))
+	assert.Equal(t, status.Success, sut.Append(id, appended))
+
+	result, findResult := sut.FindByIdentity(id)
+	assert.Equal(t, status.Success, findResult)
+	assert.Equal(t, []*annotation.Instance{created, appended}, result)
+}
+
+// TestInstance_FindByIdentity tests instance.FindByIdentity.
+func TestInstance_FindByIdentity(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "not found",
+			test: func(t *testing.T) {
+				sut := newSUT()
+
+				result, findResult := sut.FindByIdentity(url.New(test.FactoryRandomString()))
+
+				assert.Equal(t, status.NotFound, findResult)
+				assert.Equal(t, []*annotation.Instance{}, result)
+			},
+		},
+		{
+			name: "chain of custody",
+			test: func(t *testing.T) {
+				sut := newSUT()
+				previousID := hash.New(test.FactoryRandomByteSlice())
+				currentID := hash.New(test.FactoryRandomByteSlice())
+				previous := newAnnotation(previousID, nil)
+				current := newAnnotation(currentID, previousID)
+				assert.Equal(t, status.Success, sut.Create(previousID, previous))
+				assert.Equal(t, status.Success, sut.Create(currentID, current))
+
+				result, findResult := sut.FindByIdentity(currentID)
+
+				assert.Equal(t, status.Success, findResult)
+				assert.Equal(t, []*annotation.Instance{current, previous}, result)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
+
+// TestInstance_Walk tests instance.Walk.
+func TestInstance_Walk(t *testing.T) {
+	sut := newSUT()
+	firstID := hash.New(test.FactoryRandomByteSlice())
+	secondID := hash.New(test.FactoryRandomByteSlice())
+	first := newAnnotation(firstID, nil)
+	second := newAnnotation(secondID, firstID)
+	appended := newAnnotation(secondID, nil)
+	assert.Equal(t, status.Success, sut.Create(firstID, first))
+	assert.Equal(t, status.Success, sut.Create(secondID, second))
+	assert.Equal(t, status.Success, sut.Append(secondID, appended))
+
+	var ids []string
+	var annotations [][]*annotation.Instance
+	sut.Walk(
+		func(id identity.Contract, values []*annotation.Instance) {
+			ids = append(ids, id.Printable())
+			annotations = append(annotations, values)
+		},
+	)
+
+	assert.Equal(t, []string{firstID.Printable(), secondID.Printable()}, ids)
+	assert.Equal(
+		t,
+		[][]*annotation.Instance{
+			{first},
+			{second, appended},
+		},
+		annotations,
+	)
+}
+
+// TestInstance_Stats tests instance.Stats.
+func TestInstance_Stats(t *testing.T) {
+	sut := newSUT()
+	first := hash.New(test.FactoryRandomByteSlice())
+	second := hash.New(test.FactoryRandomByteSlice())
+	sut.Create(first, newAnnotation(first, nil))
+	sut.Append(first, newAnnotation(first, nil))
+	sut.Create(second, newAnnotation(second, nil))
+
+	assert.Equal(t, map[string]float64{"identities": 2, "annotations": 3}, sut.Stats())
+}
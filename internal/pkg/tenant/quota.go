@@ -0,0 +1,114 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package tenant
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/project-alvarium/go-store/internal/pkg/recorder"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/gorilla/mux"
+)
+
+// String describes the limits q places.
+func (q Quota) String() string {
+	var limits []string
+	if q.Identities != 0 {
+		limits = append(limits, strconv.Itoa(q.Identities)+" identities")
+	}
+	if q.Annotations != 0 {
+		limits = append(limits, strconv.Itoa(q.Annotations)+" annotations")
+	}
+	return "at most " + strings.Join(limits, " and ")
+}
+
+// usage tracks what a tenant has stored against its quota.
+type usage struct {
+	m      sync.Mutex
+	quota  Quota
+	stored Usage
+}
+
+// within returns whether stored is within limit, which is unlimited if zero.
+func within(stored, limit int) bool {
+	return limit == 0 || stored <= limit
+}
+
+// reserve adds charge to the stored usage and returns true if the result is within quota; otherwise it returns false
+// and leaves the stored usage unchanged.
+func (u *usage) reserve(charge Usage) bool {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	identities := u.stored.Identities + charge.Identities
+	annotations := u.stored.Annotations + charge.Annotations
+	if !within(identities, u.quota.Identities) || !within(annotations, u.quota.Annotations) {
+		return false
+	}
+	u.stored = Usage{Identities: identities, Annotations: annotations}
+	return true
+}
+
+// release returns a reserved charge that was not stored.
+func (u *usage) release(charge Usage) {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	u.stored.Identities -= charge.Identities
+	u.stored.Annotations -= charge.Annotations
+}
+
+// current returns the stored usage.
+func (u *usage) current() Usage {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	return u.stored
+}
+
+// middleware returns middleware that reserves the charge of requests to the routes named in charges before serving
+// them, rejecting requests that would exceed the quota, and releases it unless the request succeeds.
+func (u *usage) middleware(charges map[string]Usage) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				route := mux.CurrentRoute(r)
+				if route == nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+				charge, charged := charges[route.GetName()]
+				if !charged {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				if !u.reserve(charge) {
+					problem.Write(w, r, problem.ErrQuotaExceeded.WithDetail(u.quota.String()))
+					return
+				}
+				recorded := recorder.New(w)
+				next.ServeHTTP(recorded, r)
+				if recorded.Code() < http.StatusOK || recorded.Code() >= http.StatusMultipleChoices {
+					u.release(charge)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,433 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	urlIdentity "github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/persist"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	nameParam  = "tenant"
+	PrefixName = "tenant"
+)
+
+// State is a tenant's place in its lifecycle.
+type State string
+
+const (
+	StateActive    State = "active"
+	StateSuspended State = "suspended"
+)
+
+// Listing surfaces a tenant may export its identities through.
+const (
+	ExportGraphQL   = "graphql"
+	ExportSubscribe = "subscribe"
+)
+
+var (
+	// ErrInvalid reports a tenant that cannot be created as described.
+	ErrInvalid = errors.New("invalid tenant")
+
+	// ErrExists reports a tenant whose name is taken.
+	ErrExists = errors.New("tenant already exists")
+
+	// ErrNotFound reports a tenant that does not exist.
+	ErrNotFound = errors.New("tenant not found")
+)
+
+// validName matches tenant names, which are DNS labels so that they never need escaping in a url.
+var validName = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// exports are the known listing surfaces.
+var exports = map[string]bool{
+	ExportGraphQL:   true,
+	ExportSubscribe: true,
+}
+
+// PrefixRoute creates the url prefix that addresses the tenant named name.
+func PrefixRoute(name string) string {
+	return fmt.Sprintf("/t/%s", name)
+}
+
+// Usage counts the identities and annotations stored by a tenant, or charged for a request.
+type Usage struct {
+	Identities  int `json:"identities"`
+	Annotations int `json:"annotations"`
+}
+
+// Quota limits a tenant's usage; a zero limit places no restriction.
+type Quota struct {
+	Identities  int `json:"identities"`
+	Annotations int `json:"annotations"`
+}
+
+// Tenant describes an isolated namespace of identities.  Export lists the surfaces, beyond reading and writing
+// single identities, through which its identities can be listed.  Usage is reported with a tenant but not persisted.
+type Tenant struct {
+	Name    string    `json:"name"`
+	State   State     `json:"state"`
+	Quota   Quota     `json:"quota"`
+	Export  []string  `json:"export"`
+	Created time.Time `json:"created"`
+	Usage   *Usage    `json:"usage,omitempty"`
+}
+
+// Exports returns whether t exports its identities through surface.
+func (t Tenant) Exports(surface string) bool {
+	for _, export := range t.Export {
+		if export == surface {
+			return true
+		}
+	}
+	return false
+}
+
+// validate returns an error wrapping ErrInvalid if t cannot be created.
+func (t Tenant) validate() error {
+	if !validName.MatchString(t.Name) {
+		return fmt.Errorf("%w: name must be a lowercase DNS label", ErrInvalid)
+	}
+	if t.Quota.Identities < 0 || t.Quota.Annotations < 0 {
+		return fmt.Errorf("%w: quota must not be negative", ErrInvalid)
+	}
+	for _, export := range t.Export {
+		if !exports[export] {
+			return fmt.Errorf("%w: unknown export %q", ErrInvalid, export)
+		}
+	}
+	return nil
+}
+
+// Config enables tenants; Path is the file holding their descriptions, which are only kept in memory if it is empty.
+// Each tenant's identities are kept in memory by a store of its own, so a restart keeps its description but not its
+// identities.  Tenants are reached over HTTP; gRPC, MQTT, websockets and webhooks serve the default namespace only.
+// Principals bound to a tenant are refused over gRPC; their HTTP requests, including websocket upgrades and webhook
+// registrations, go to the tenant's router, which serves neither.  MQTT carries no principal.
+type Config struct {
+	Enabled bool   `json:"enabled"`
+	Path    string `json:"path"`
+}
+
+// Factory returns the routables that serve t from a store of its own; they are installed on a router that uses
+// encoded paths.
+type Factory func(t Tenant) ([]routable.Contract, error)
+
+// Contract defines the management of tenants.
+type Contract interface {
+	// Create returns a new active tenant as described by t.
+	Create(t Tenant) (Tenant, error)
+
+	// Suspend rejects further requests to the tenant named name until it is resumed and returns the tenant.
+	Suspend(name string) (Tenant, error)
+
+	// Resume accepts requests to the suspended tenant named name again and returns the tenant.
+	Resume(name string) (Tenant, error)
+
+	// Delete removes the tenant named name, discarding its identities, and returns whether it existed.
+	Delete(name string) (bool, error)
+
+	// List returns every tenant in order of creation.
+	List() []Tenant
+}
+
+// entry is a tenant and the router that serves it.
+type entry struct {
+	tenant Tenant
+	router *mux.Router
+	usage  *usage
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	m       sync.RWMutex
+	path    string
+	charges map[string]Usage
+	factory Factory
+	release func(name string)
+	entries map[string]*entry
+}
+
+// New is a factory function that returns instance holding the tenants described in config.  Charges maps route names
+// to the usage a successful request to that route adds, which is checked against the tenant's quota before the
+// request is served.
+func New(config Config, charges map[string]Usage, factory Factory) (*instance, error) {
+	i := &instance{
+		path:    config.Path,
+		charges: charges,
+		factory: factory,
+		release: func(string) {},
+		entries: make(map[string]*entry),
+	}
+
+	tenants := make(map[string]Tenant)
+	if err := persist.Load(config.Path, &tenants); err != nil {
+		return nil, err
+	}
+	for name := range tenants {
+		e, err := i.build(tenants[name])
+		if err != nil {
+			return nil, err
+		}
+		i.entries[name] = e
+	}
+	return i, nil
+}
+
+// SetRelease provides for method injection of the function told the name of each tenant discarded, so that what the
+// factory built for it can be released; the default does nothing.
+func (i *instance) SetRelease(release func(name string)) {
+	i.release = release
+}
+
+// build returns the entry that serves t.
+func (i *instance) build(t Tenant) (*entry, error) {
+	routables, err := i.factory(t)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &entry{
+		tenant: t,
+		router: mux.NewRouter().UseEncodedPath(),
+		usage:  &usage{quota: t.Quota},
+	}
+	for key := range routables {
+		routables[key](e.router)
+	}
+	e.router.Use(permit, e.usage.middleware(i.charges))
+	return e, nil
+}
+
+// permit rejects requests whose route carries an identity outside the principal's prefixes; a request addressed by
+// url prefix only matches its identity's route here, so authentication could not check it.
+func permit(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.FromContext(r.Context())
+			if id, exists := urlIdentity.FromRequest(r); ok && exists && !principal.Permits(id) {
+				problem.Write(w, r, problem.ErrForbidden.WithDetail("identity is outside the principal's prefixes"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		},
+	)
+}
+
+// save persists the tenants; it must be called with the lock held.
+func (i *instance) save() error {
+	tenants := make(map[string]Tenant, len(i.entries))
+	for name, e := range i.entries {
+		tenants[name] = e.tenant
+	}
+	return persist.Save(i.path, tenants)
+}
+
+// report returns e's tenant with its usage.
+func report(e *entry) Tenant {
+	result := e.tenant
+	usage := e.usage.current()
+	result.Usage = &usage
+	return result
+}
+
+// Create returns a new active tenant as described by t.
+func (i *instance) Create(t Tenant) (Tenant, error) {
+	if err := t.validate(); err != nil {
+		return Tenant{}, err
+	}
+	t.State = StateActive
+	t.Created = time.Now().UTC()
+	t.Usage = nil
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	if _, exists := i.entries[t.Name]; exists {
+		return Tenant{}, ErrExists
+	}
+	e, err := i.build(t)
+	if err != nil {
+		return Tenant{}, err
+	}
+	i.entries[t.Name] = e
+	if err := i.save(); err != nil {
+		delete(i.entries, t.Name)
+		i.release(t.Name)
+		return Tenant{}, err
+	}
+	return report(e), nil
+}
+
+// transition moves the tenant named name to state and returns it.
+func (i *instance) transition(name string, state State) (Tenant, error) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	e, exists := i.entries[name]
+	if !exists {
+		return Tenant{}, ErrNotFound
+	}
+	previous := e.tenant.State
+	e.tenant.State = state
+	if err := i.save(); err != nil {
+		e.tenant.State = previous
+		return Tenant{}, err
+	}
+	return report(e), nil
+}
+
+// Suspend rejects further requests to the tenant named name until it is resumed and returns the tenant.
+func (i *instance) Suspend(name string) (Tenant, error) {
+	return i.transition(name, StateSuspended)
+}
+
+// Resume accepts requests to the suspended tenant named name again and returns the tenant.
+func (i *instance) Resume(name string) (Tenant, error) {
+	return i.transition(name, StateActive)
+}
+
+// Delete removes the tenant named name, discarding its identities, and returns whether it existed.
+func (i *instance) Delete(name string) (bool, error) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	e, exists := i.entries[name]
+	if !exists {
+		return false, nil
+	}
+	delete(i.entries, name)
+	if err := i.save(); err != nil {
+		i.entries[name] = e
+		return false, err
+	}
+	i.release(name)
+	return true, nil
+}
+
+// List returns every tenant in order of creation.
+func (i *instance) List() []Tenant {
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	result := make([]Tenant, 0, len(i.entries))
+	for _, e := range i.entries {
+		result = append(result, report(e))
+	}
+	sort.Slice(
+		result,
+		func(a, b int) bool {
+			if result[a].Created.Equal(result[b].Created) {
+				return result[a].Name < result[b].Name
+			}
+			return result[a].Created.Before(result[b].Created)
+		},
+	)
+	return result
+}
+
+// Init adds the route that addresses a tenant by url prefix to muxRouter and installs the middleware that serves
+// requests of principals bound to a tenant from that tenant.  It must be installed after authentication and before
+// any middleware that should only see requests to the default namespace.
+func (i *instance) Init(muxRouter *mux.Router) {
+	muxRouter.Use(i.Middleware)
+	muxRouter.PathPrefix(PrefixRoute("{" + nameParam + "}")).HandlerFunc(i.handlePrefix).Name(PrefixName)
+}
+
+// Middleware serves requests whose principal is bound to a tenant from that tenant, so that they never reach the
+// default namespace; other requests are passed to next.
+func (i *instance) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if route := mux.CurrentRoute(r); route != nil && route.GetName() == PrefixName {
+				next.ServeHTTP(w, r)
+				return
+			}
+			principal, ok := auth.FromContext(r.Context())
+			if !ok || principal.Tenant == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			i.serve(w, r, principal.Tenant, r)
+		},
+	)
+}
+
+// handlePrefix serves a request addressed to a tenant by url prefix from that tenant; only principals bound to that
+// tenant, or bound to none and holding the admin scope, are served.
+func (i *instance) handlePrefix(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)[nameParam]
+	if !validName.MatchString(name) {
+		problem.Write(w, r, problem.ErrTenantNotFound.WithDetail(name))
+		return
+	}
+	principal, ok := auth.FromContext(r.Context())
+	switch {
+	case !ok:
+		problem.Write(w, r, problem.ErrForbidden.WithDetail("tenants require an authenticated principal"))
+		return
+	case principal.Tenant != "" && principal.Tenant != name:
+		problem.Write(w, r, problem.ErrForbidden.WithDetail("principal belongs to another tenant"))
+		return
+	case principal.Tenant == "" && !principal.Allows(auth.ScopeAdmin):
+		problem.Write(w, r, problem.ErrForbidden.WithDetail("requires the "+string(auth.ScopeAdmin)+" scope"))
+		return
+	}
+
+	// valid names need no escaping, so the prefix is the same length in both forms of the path.
+	prefix := PrefixRoute(name)
+	stripped := r.Clone(r.Context())
+	stripped.URL.Path = "/" + strings.TrimPrefix(r.URL.Path[len(prefix):], "/")
+	if r.URL.RawPath != "" {
+		stripped.URL.RawPath = "/" + strings.TrimPrefix(r.URL.RawPath[len(prefix):], "/")
+	}
+	i.serve(w, r, name, stripped)
+}
+
+// serve serves forwarded, a request to the tenant named name, from that tenant's router; problems are reported
+// against original.
+func (i *instance) serve(w http.ResponseWriter, original *http.Request, name string, forwarded *http.Request) {
+	i.m.RLock()
+	e, exists := i.entries[name]
+	var state State
+	if exists {
+		state = e.tenant.State
+	}
+	i.m.RUnlock()
+
+	switch {
+	case !exists:
+		problem.Write(w, original, problem.ErrTenantNotFound.WithDetail(name))
+	case state == StateSuspended:
+		problem.Write(w, original, problem.ErrTenantSuspended.WithDetail(name))
+	default:
+		e.router.ServeHTTP(w, forwarded)
+	}
+}
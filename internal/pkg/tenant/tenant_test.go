@@ -0,0 +1,429 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package tenant
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	routeCreate = "create"
+	routeAppend = "append"
+	routeFind   = "find"
+)
+
+// identities returns a routable that serves identities of its own under the route names the service uses.
+func identities() routable.Contract {
+	var m sync.Mutex
+	stored := make(map[string]bool)
+	return func(muxRouter *mux.Router) {
+		muxRouter.HandleFunc(
+			"/v1/identities/{identity}",
+			func(w http.ResponseWriter, r *http.Request) {
+				m.Lock()
+				defer m.Unlock()
+				id := mux.Vars(r)["identity"]
+				if stored[id] {
+					w.WriteHeader(http.StatusConflict)
+					return
+				}
+				stored[id] = true
+				w.WriteHeader(http.StatusCreated)
+			},
+		).Methods(http.MethodPut).Name(routeCreate)
+		muxRouter.HandleFunc(
+			"/v1/identities/{identity}/annotations",
+			func(w http.ResponseWriter, r *http.Request) {
+				m.Lock()
+				defer m.Unlock()
+				if !stored[mux.Vars(r)["identity"]] {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			},
+		).Methods(http.MethodPost).Name(routeAppend)
+		muxRouter.HandleFunc(
+			"/v1/identities/{identity}",
+			func(w http.ResponseWriter, r *http.Request) {
+				m.Lock()
+				defer m.Unlock()
+				if !stored[mux.Vars(r)["identity"]] {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+		).Methods(http.MethodGet).Name(routeFind)
+	}
+}
+
+// admin is a principal bound to no tenant that may address every tenant by url prefix.
+var admin = &auth.Principal{Subject: "s", Scopes: []auth.Scope{auth.ScopeAdmin}}
+
+// principal returns a routable that authenticates every request as p, if it is not nil.
+func principal(p *auth.Principal) routable.Contract {
+	return func(muxRouter *mux.Router) {
+		muxRouter.Use(
+			func(next http.Handler) http.Handler {
+				return http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						if p != nil {
+							r = r.WithContext(auth.NewContext(r.Context(), p))
+						}
+						next.ServeHTTP(w, r)
+					},
+				)
+			},
+		)
+	}
+}
+
+// newSUT returns a new system under test holding the tenants in the file at path.
+func newSUT(t *testing.T, path string) *instance {
+	sut, err := New(
+		Config{Enabled: true, Path: path},
+		map[string]Usage{routeCreate: {Identities: 1, Annotations: 1}, routeAppend: {Annotations: 1}},
+		func(Tenant) ([]routable.Contract, error) {
+			return []routable.Contract{identities()}, nil
+		},
+	)
+	if err != nil {
+		assert.FailNow(t, "Unexpected New failure:", err.Error())
+	}
+	return sut
+}
+
+// create returns a new tenant as described by t.
+func create(t *testing.T, sut *instance, tenant Tenant) Tenant {
+	created, err := sut.Create(tenant)
+	if err != nil {
+		assert.FailNow(t, "Unexpected Create failure:", err.Error())
+	}
+	return created
+}
+
+// serve returns a router serving sut's tenants and a default namespace to requests authenticated as p.
+func serve(sut *instance, p *auth.Principal) (*mux.Router, func()) {
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{principal(p), sut.Init, identities()},
+	)
+	muxRouter.UseEncodedPath()
+	return muxRouter, func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// code returns the problem code of response's body, if any.
+func code(t *testing.T, body []byte) string {
+	p, err := problem.Decode(body)
+	if err != nil {
+		assert.FailNow(t, "Unexpected problem.Decode failure:", err.Error())
+	}
+	return p.Code
+}
+
+// TestInstance tests tenant management.
+func TestInstance(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "invalid",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+
+				for _, tenant := range []Tenant{
+					{},
+					{Name: "Upper"},
+					{Name: "a/b"},
+					{Name: "-a"},
+					{Name: "a", Quota: Quota{Identities: -1}},
+					{Name: "a", Export: []string{"unknown"}},
+				} {
+					_, err := sut.Create(tenant)
+
+					assert.True(t, errors.Is(err, ErrInvalid), tenant.Name)
+				}
+				assert.Empty(t, sut.List())
+			},
+		},
+		{
+			name: "create and list",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				first := create(t, sut, Tenant{Name: "first", Export: []string{ExportGraphQL}})
+				second := create(t, sut, Tenant{Name: "second", Quota: Quota{Annotations: 10}})
+
+				_, err := sut.Create(Tenant{Name: "first"})
+
+				assert.Equal(t, ErrExists, err)
+				assert.Equal(t, StateActive, first.State)
+				assert.Equal(t, &Usage{}, first.Usage)
+				assert.True(t, first.Exports(ExportGraphQL))
+				assert.False(t, first.Exports(ExportSubscribe))
+				assert.Equal(t, []Tenant{first, second}, sut.List())
+			},
+		},
+		{
+			name: "lifecycle",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				var released []string
+				sut.SetRelease(func(name string) { released = append(released, name) })
+				create(t, sut, Tenant{Name: "acme"})
+
+				suspended, suspendErr := sut.Suspend("acme")
+				resumed, resumeErr := sut.Resume("acme")
+				deleted, deleteErr := sut.Delete("acme")
+				deletedAgain, _ := sut.Delete("acme")
+				_, unknownErr := sut.Suspend("acme")
+
+				assert.Nil(t, suspendErr)
+				assert.Equal(t, StateSuspended, suspended.State)
+				assert.Nil(t, resumeErr)
+				assert.Equal(t, StateActive, resumed.State)
+				assert.Nil(t, deleteErr)
+				assert.True(t, deleted)
+				assert.False(t, deletedAgain)
+				assert.Equal(t, []string{"acme"}, released)
+				assert.Equal(t, ErrNotFound, unknownErr)
+				assert.Empty(t, sut.List())
+			},
+		},
+		{
+			name: "persisted",
+			test: func(t *testing.T) {
+				directory, err := ioutil.TempDir("", "tenant")
+				if err != nil {
+					assert.FailNow(t, "Unexpected ioutil.TempDir failure:", err.Error())
+				}
+				defer func() { _ = os.RemoveAll(directory) }()
+				path := filepath.Join(directory, "tenants.json")
+				sut := newSUT(t, path)
+				create(t, sut, Tenant{Name: "acme", Quota: Quota{Identities: 5}})
+				expected, _ := sut.Suspend("acme")
+
+				assert.Equal(t, []Tenant{expected}, newSUT(t, path).List())
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
+
+// TestInit tests serving requests from tenants.
+func TestInit(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "prefix isolates identities",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				create(t, sut, Tenant{Name: "a"})
+				create(t, sut, Tenant{Name: "b"})
+				muxRouter, stop := serve(sut, admin)
+				defer stop()
+
+				created := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/t/a/v1/identities/x")
+				inA := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/t/a/v1/identities/x")
+				inB := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/t/b/v1/identities/x")
+				inDefault := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/v1/identities/x")
+				createdInB := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/t/b/v1/identities/x")
+
+				assert.Equal(t, http.StatusCreated, created.Code)
+				assert.Equal(t, http.StatusOK, inA.Code)
+				assert.Equal(t, http.StatusNotFound, inB.Code)
+				assert.Equal(t, http.StatusNotFound, inDefault.Code)
+				assert.Equal(t, http.StatusCreated, createdInB.Code)
+			},
+		},
+		{
+			name: "principal bound to tenant",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				create(t, sut, Tenant{Name: "a"})
+				create(t, sut, Tenant{Name: "b"})
+				muxRouter, stop := serve(sut, &auth.Principal{Subject: "s", Tenant: "a"})
+				defer stop()
+
+				created := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/v1/identities/x")
+				prefixed := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/t/a/v1/identities/x")
+				other := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/t/b/v1/identities/x")
+
+				assert.Equal(t, http.StatusCreated, created.Code)
+				assert.Equal(t, http.StatusOK, prefixed.Code)
+				assert.Equal(t, http.StatusForbidden, other.Code)
+				assert.Equal(t, problem.CodeForbidden, code(t, other.Body.Bytes()))
+			},
+		},
+		{
+			name: "prefix enforces principal prefixes",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				create(t, sut, Tenant{Name: "a"})
+				p := &auth.Principal{Subject: "s", Tenant: "a", Prefixes: []string{"x"}}
+				muxRouter, stop := serve(sut, p)
+				defer stop()
+
+				permitted := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/t/a/v1/identities/x1")
+				outside := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/t/a/v1/identities/y1")
+				escaped := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/t/a/v1/identities/y%2Fx")
+
+				assert.Equal(t, http.StatusCreated, permitted.Code)
+				assert.Equal(t, http.StatusForbidden, outside.Code)
+				assert.Equal(t, problem.CodeForbidden, code(t, outside.Body.Bytes()))
+				assert.Equal(t, http.StatusForbidden, escaped.Code)
+			},
+		},
+		{
+			name: "prefix requires tenant or admin scope",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				create(t, sut, Tenant{Name: "a"})
+				for _, p := range []*auth.Principal{nil, {Subject: "s", Scopes: []auth.Scope{auth.ScopeWrite}}} {
+					muxRouter, stop := serve(sut, p)
+
+					result := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/t/a/v1/identities/x")
+
+					assert.Equal(t, http.StatusForbidden, result.Code)
+					assert.Equal(t, problem.CodeForbidden, code(t, result.Body.Bytes()))
+					stop()
+				}
+			},
+		},
+		{
+			name: "unknown tenant",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				prefixRouter, stopPrefix := serve(sut, admin)
+				defer stopPrefix()
+				boundRouter, stopBound := serve(sut, &auth.Principal{Subject: "s", Tenant: "a"})
+				defer stopBound()
+
+				prefixed := testInternal.SendRequestWithoutBody(t, prefixRouter, http.MethodGet, "/t/a/v1/identities/x")
+				invalid := testInternal.SendRequestWithoutBody(t, prefixRouter, http.MethodGet, "/t/A%20/v1/identities/x")
+				bound := testInternal.SendRequestWithoutBody(t, boundRouter, http.MethodGet, "/v1/identities/x")
+
+				assert.Equal(t, http.StatusNotFound, prefixed.Code)
+				assert.Equal(t, problem.CodeTenantNotFound, code(t, prefixed.Body.Bytes()))
+				assert.Equal(t, http.StatusNotFound, invalid.Code)
+				assert.Equal(t, http.StatusNotFound, bound.Code)
+				assert.Equal(t, problem.CodeTenantNotFound, code(t, bound.Body.Bytes()))
+			},
+		},
+		{
+			name: "suspended",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				create(t, sut, Tenant{Name: "a"})
+				muxRouter, stop := serve(sut, admin)
+				defer stop()
+
+				_, _ = sut.Suspend("a")
+				suspended := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/t/a/v1/identities/x")
+				_, _ = sut.Resume("a")
+				resumed := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/t/a/v1/identities/x")
+
+				assert.Equal(t, http.StatusForbidden, suspended.Code)
+				assert.Equal(t, problem.CodeTenantSuspended, code(t, suspended.Body.Bytes()))
+				assert.Equal(t, http.StatusCreated, resumed.Code)
+			},
+		},
+		{
+			name: "deleted discards identities",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				create(t, sut, Tenant{Name: "a"})
+				muxRouter, stop := serve(sut, admin)
+				defer stop()
+
+				_ = testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/t/a/v1/identities/x")
+				_, _ = sut.Delete("a")
+				deleted := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/t/a/v1/identities/x")
+				create(t, sut, Tenant{Name: "a"})
+				recreated := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodGet, "/t/a/v1/identities/x")
+
+				assert.Equal(t, http.StatusNotFound, deleted.Code)
+				assert.Equal(t, problem.CodeTenantNotFound, code(t, deleted.Body.Bytes()))
+				assert.Equal(t, http.StatusNotFound, recreated.Code)
+			},
+		},
+		{
+			name: "quota",
+			test: func(t *testing.T) {
+				sut := newSUT(t, "")
+				create(t, sut, Tenant{Name: "a", Quota: Quota{Identities: 1, Annotations: 3}})
+				muxRouter, stop := serve(sut, admin)
+				defer stop()
+
+				first := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/t/a/v1/identities/x")
+				second := testInternal.SendRequestWithoutBody(t, muxRouter, http.MethodPut, "/t/a/v1/identities/y")
+				missing := testInternal.SendRequestWithoutBody(
+					t,
+					muxRouter,
+					http.MethodPost,
+					"/t/a/v1/identities/y/annotations",
+				)
+				appended := make([]int, 3)
+				for key := range appended {
+					appended[key] = testInternal.SendRequestWithoutBody(
+						t,
+						muxRouter,
+						http.MethodPost,
+						"/t/a/v1/identities/x/annotations",
+					).Code
+				}
+
+				assert.Equal(t, http.StatusCreated, first.Code)
+				assert.Equal(t, http.StatusForbidden, second.Code)
+				assert.Equal(t, problem.CodeQuotaExceeded, code(t, second.Body.Bytes()))
+				assert.Equal(t, http.StatusNotFound, missing.Code)
+				assert.Equal(t, []int{http.StatusCreated, http.StatusCreated, http.StatusForbidden}, appended)
+				assert.Equal(t, &Usage{Identities: 1, Annotations: 3}, sut.List()[0].Usage)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
@@ -16,9 +16,11 @@ package test
 
 import (
 	"context"
+	"crypto/tls"
 	"sync"
 
 	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	"github.com/project-alvarium/go-store/internal/pkg/runnable"
 
 	"github.com/gorilla/mux"
 )
@@ -27,18 +29,32 @@ import (
 type RunFunc func(
 	ctx context.Context,
 	cancel context.CancelFunc,
+	wg *sync.WaitGroup,
 	muxRouter *mux.Router,
 	routables []routable.Contract,
+	runnables []runnable.Contract,
 	serverAddress *string,
+	tlsConfig *tls.Config,
 )
 
 // NewSUT returns a new system under test for acceptance testing.
 func NewSUT(runFunc RunFunc, routables []routable.Contract) (context.CancelFunc, *sync.WaitGroup, *mux.Router) {
+	return NewSUTWithRunnables(runFunc, routables, nil)
+}
+
+// NewSUTWithRunnables returns a new system under test for acceptance testing that also starts runnables; callers
+// cancel and then wait on the returned WaitGroup to stop them.
+func NewSUTWithRunnables(
+	runFunc RunFunc,
+	routables []routable.Contract,
+	runnables []runnable.Contract) (context.CancelFunc, *sync.WaitGroup, *mux.Router) {
+
 	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(context.Background())
-	muxRouter := mux.NewRouter()
+	// as the service does, so that identities may contain escaped slashes.
+	muxRouter := mux.NewRouter().UseEncodedPath()
 
-	runFunc(ctx, cancel, muxRouter, routables, nil)
+	runFunc(ctx, cancel, &wg, muxRouter, routables, runnables, nil, nil)
 
 	return cancel, &wg, muxRouter
 }
@@ -0,0 +1,160 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Authority is a locally generated certificate authority for tests.
+type Authority struct {
+	Certificate *x509.Certificate
+	key         crypto.Signer
+}
+
+// Issued is a certificate issued by an Authority and the files its PEM certificate and key were written to.
+type Issued struct {
+	Certificate *x509.Certificate
+	CertFile    string
+	KeyFile     string
+}
+
+// newKey returns a new private key and its PEM encoding.
+func newKey(t *testing.T) (crypto.Signer, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		assert.FailNow(t, "Unexpected ecdsa.GenerateKey failure:", err.Error())
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		assert.FailNow(t, "Unexpected x509.MarshalPKCS8PrivateKey failure:", err.Error())
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+// serial returns a random certificate serial number.
+func serial(t *testing.T) *big.Int {
+	result, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		assert.FailNow(t, "Unexpected rand.Int failure:", err.Error())
+	}
+	return result
+}
+
+// write writes body to name in directory and returns its path.
+func write(t *testing.T, directory, name string, body []byte) string {
+	path := filepath.Join(directory, name)
+	if err := ioutil.WriteFile(path, body, 0600); err != nil {
+		assert.FailNow(t, "Unexpected ioutil.WriteFile failure:", err.Error())
+	}
+	return path
+}
+
+// NewAuthority returns a new certificate authority named name whose PEM certificate is written to name.pem in
+// directory.
+func NewAuthority(t *testing.T, directory, name string) (*Authority, string) {
+	key, _ := newKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          serial(t),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		assert.FailNow(t, "Unexpected x509.CreateCertificate failure:", err.Error())
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		assert.FailNow(t, "Unexpected x509.ParseCertificate failure:", err.Error())
+	}
+
+	path := write(t, directory, name+".pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	return &Authority{Certificate: certificate, key: key}, path
+}
+
+// IssueServer issues a server certificate for localhost and 127.0.0.1 and writes it to name.pem and name-key.pem in
+// directory.
+func (a *Authority) IssueServer(t *testing.T, directory, name string) Issued {
+	return a.issue(
+		t,
+		directory,
+		name,
+		&x509.Certificate{
+			Subject:     pkix.Name{CommonName: name},
+			DNSNames:    []string{"localhost"},
+			IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		},
+	)
+}
+
+// IssueClient issues a client certificate whose common name is name and whose URI SANs are uris, and writes it to
+// name.pem and name-key.pem in directory.
+func (a *Authority) IssueClient(t *testing.T, directory, name string, uris ...string) Issued {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, uri := range uris {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			assert.FailNow(t, "Unexpected url.Parse failure:", err.Error())
+		}
+		template.URIs = append(template.URIs, parsed)
+	}
+	return a.issue(t, directory, name, template)
+}
+
+// issue signs template and writes the certificate and its key to directory.
+func (a *Authority) issue(t *testing.T, directory, name string, template *x509.Certificate) Issued {
+	key, keyPEM := newKey(t)
+	template.SerialNumber = serial(t)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, template, a.Certificate, key.Public(), a.key)
+	if err != nil {
+		assert.FailNow(t, "Unexpected x509.CreateCertificate failure:", err.Error())
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		assert.FailNow(t, "Unexpected x509.ParseCertificate failure:", err.Error())
+	}
+
+	return Issued{
+		Certificate: certificate,
+		CertFile:    write(t, directory, name+".pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		KeyFile:     write(t, directory, name+"-key.pem", keyPEM),
+	}
+}
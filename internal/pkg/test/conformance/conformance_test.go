@@ -0,0 +1,568 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package conformance
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/auth/apikey"
+	"github.com/project-alvarium/go-store/internal/pkg/auth/jwt"
+	"github.com/project-alvarium/go-store/internal/pkg/auth/mtls"
+	authStub "github.com/project-alvarium/go-store/internal/pkg/auth/stub"
+	"github.com/project-alvarium/go-store/internal/pkg/certificate"
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/ingest"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/append"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/create"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/find"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/subscribe"
+	"github.com/project-alvarium/go-store/internal/pkg/rpc"
+	"github.com/project-alvarium/go-store/internal/pkg/server"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+	"github.com/project-alvarium/go-store/internal/pkg/tenant"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	grpcClient "github.com/project-alvarium/go-store/pkg/grpc/client"
+	"github.com/project-alvarium/go-store/pkg/http/client"
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/requestor"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	metadataStubFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub/factory"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const identityLength = 32
+
+// Contract defines the client behaviour every transport must provide.
+type Contract interface {
+	store.Contract
+	Subscribe(ctx context.Context, id identity.Contract, fn func(event *client.Event)) status.Value
+	SubscribePrefix(ctx context.Context, prefix string, fn func(event *client.Event)) status.Value
+}
+
+// transport starts a service backed by s and n and returns a client connected to it and a function that stops both.
+type transport func(
+	t *testing.T,
+	s store.Contract,
+	n notify.Contract,
+	mFactory metadataFactory.Contract) (Contract, func())
+
+// httpTransport serves the HTTP routes and targets the legacy API.
+func httpTransport(
+	t *testing.T,
+	s store.Contract,
+	n notify.Contract,
+	mFactory metadataFactory.Contract) (Contract, func()) {
+
+	return httpVersionTransport(client.Legacy, codec.NewJSON(), false)(t, s, n, mFactory)
+}
+
+// httpVersionTransport returns a transport that serves the HTTP routes, reporting legacy status codes if legacy is
+// set, and targets version using format.
+func httpVersionTransport(version client.Version, format codec.Contract, legacy bool) transport {
+	return func(
+		t *testing.T,
+		s store.Contract,
+		n notify.Contract,
+		mFactory metadataFactory.Contract) (Contract, func()) {
+
+		iFactory := identityFactory.New()
+		decoder := ingest.New(mFactory, iFactory, ingest.NewDefaultConfig())
+		cancel, wg, muxRouter := testInternal.NewSUT(
+			pkg.Run,
+			[]routable.Contract{
+				find.New(s, legacy).Init,
+				create.New(s, decoder, legacy).Init,
+				append.New(s, decoder, legacy).Init,
+				subscribe.New(n).Init,
+			},
+		)
+		httpServer := httptest.NewServer(muxRouter)
+
+		r := requestor.New(httpServer.URL)
+		sut := client.New(r.Handler, mFactory, iFactory)
+		sut.SetStreamer(r.Stream)
+		sut.SetVersion(version)
+		sut.SetCodec(format, r.MediaHandler)
+		return sut, func() {
+			httpServer.Close()
+			cancel()
+			wg.Wait()
+		}
+	}
+}
+
+// httpAPIKeyTransport serves the HTTP routes with authentication enabled and targets the V1 API presenting an API key
+// with the write scope.
+func httpAPIKeyTransport(
+	t *testing.T,
+	s store.Contract,
+	n notify.Contract,
+	mFactory metadataFactory.Contract) (Contract, func()) {
+
+	keys, err := apikey.New("")
+	if err != nil {
+		assert.FailNow(t, "Unexpected apikey.New failure:", err.Error())
+	}
+	issued, err := keys.Create(test.FactoryRandomString(), []auth.Scope{auth.ScopeWrite}, "")
+	if err != nil {
+		assert.FailNow(t, "Unexpected Create failure:", err.Error())
+	}
+
+	iFactory := identityFactory.New()
+	decoder := ingest.New(mFactory, iFactory, ingest.NewDefaultConfig())
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{
+			auth.New(auth.Config{Enabled: true}, auth.Policy{}, []auth.Authenticator{keys}).Init,
+			find.New(s, false).Init,
+			create.New(s, decoder, false).Init,
+			append.New(s, decoder, false).Init,
+			subscribe.New(n).Init,
+		},
+	)
+	httpServer := httptest.NewServer(muxRouter)
+
+	r := requestor.New(httpServer.URL)
+	r.SetAPIKey(issued.Secret)
+	sut := client.New(r.Handler, mFactory, iFactory)
+	sut.SetStreamer(r.Stream)
+	sut.SetVersion(client.V1)
+	return sut, func() {
+		httpServer.Close()
+		cancel()
+		wg.Wait()
+	}
+}
+
+// httpTenantTransport serves the HTTP routes from a tenant to a principal bound to it and targets the V1 API beneath
+// the tenant's url prefix.
+func httpTenantTransport(
+	t *testing.T,
+	s store.Contract,
+	n notify.Contract,
+	mFactory metadataFactory.Contract) (Contract, func()) {
+
+	iFactory := identityFactory.New()
+	decoder := ingest.New(mFactory, iFactory, ingest.NewDefaultConfig())
+	tenants, err := tenant.New(
+		tenant.Config{Enabled: true},
+		nil,
+		func(tenant.Tenant) ([]routable.Contract, error) {
+			return []routable.Contract{
+				find.New(s, false).Init,
+				create.New(s, decoder, false).Init,
+				append.New(s, decoder, false).Init,
+				subscribe.New(n).Init,
+			}, nil
+		},
+	)
+	if err != nil {
+		assert.FailNow(t, "Unexpected tenant.New failure:", err.Error())
+	}
+	if _, err := tenants.Create(tenant.Tenant{Name: "conformance"}); err != nil {
+		assert.FailNow(t, "Unexpected Create failure:", err.Error())
+	}
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{
+			authStub.Authenticated(&auth.Principal{Subject: "conformance", Tenant: "conformance"}),
+			tenants.Init,
+		},
+	)
+	httpServer := httptest.NewServer(muxRouter)
+
+	r := requestor.New(httpServer.URL + tenant.PrefixRoute("conformance"))
+	sut := client.New(r.Handler, mFactory, iFactory)
+	sut.SetStreamer(r.Stream)
+	sut.SetVersion(client.V1)
+	return sut, func() {
+		httpServer.Close()
+		cancel()
+		wg.Wait()
+	}
+}
+
+// httpJWTTransport serves the HTTP routes with bearer token authentication and targets the V1 API presenting a token
+// whose role grants the write scope.
+func httpJWTTransport(
+	t *testing.T,
+	s store.Contract,
+	n notify.Contract,
+	mFactory metadataFactory.Contract) (Contract, func()) {
+
+	directory, err := ioutil.TempDir("", "conformance")
+	if err != nil {
+		assert.FailNow(t, "Unexpected ioutil.TempDir failure:", err.Error())
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		assert.FailNow(t, "Unexpected ecdsa.GenerateKey failure:", err.Error())
+	}
+	config := jwt.NewDefaultConfig()
+	config.JWKSPath = filepath.Join(directory, "jwks.json")
+	config.Issuer = "https://issuer.example.org"
+	config.Audience = "go-store"
+	config.Roles = map[string][]auth.Scope{"writer": {auth.ScopeWrite}}
+	testInternal.WriteJWKS(t, config.JWKSPath, map[string]crypto.Signer{"key": key})
+	tokens, err := jwt.New(config)
+	if err != nil {
+		assert.FailNow(t, "Unexpected jwt.New failure:", err.Error())
+	}
+	token := testInternal.SignToken(
+		t,
+		key,
+		"ES256",
+		"key",
+		map[string]interface{}{
+			"iss":   config.Issuer,
+			"aud":   config.Audience,
+			"sub":   "client",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"writer"},
+		},
+	)
+
+	iFactory := identityFactory.New()
+	decoder := ingest.New(mFactory, iFactory, ingest.NewDefaultConfig())
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{
+			auth.New(auth.Config{Enabled: true}, auth.Policy{}, []auth.Authenticator{tokens}).Init,
+			find.New(s, false).Init,
+			create.New(s, decoder, false).Init,
+			append.New(s, decoder, false).Init,
+			subscribe.New(n).Init,
+		},
+	)
+	httpServer := httptest.NewServer(muxRouter)
+
+	r := requestor.New(httpServer.URL)
+	r.SetBearerToken(token)
+	sut := client.New(r.Handler, mFactory, iFactory)
+	sut.SetStreamer(r.Stream)
+	sut.SetVersion(client.V1)
+	return sut, func() {
+		httpServer.Close()
+		cancel()
+		wg.Wait()
+		_ = os.RemoveAll(directory)
+	}
+}
+
+// httpsTransport serves the HTTP routes over HTTPS, requiring client certificates that grant the write scope, and
+// targets the V1 API presenting a locally generated client certificate.
+func httpsTransport(
+	t *testing.T,
+	s store.Contract,
+	n notify.Contract,
+	mFactory metadataFactory.Contract) (Contract, func()) {
+
+	directory, err := ioutil.TempDir("", "conformance")
+	if err != nil {
+		assert.FailNow(t, "Unexpected ioutil.TempDir failure:", err.Error())
+	}
+	authority, caFile := testInternal.NewAuthority(t, directory, "ca")
+	server := authority.IssueServer(t, directory, "server")
+	issued := authority.IssueClient(t, directory, "client", "spiffe://example.org/client")
+	certificates, err := certificate.New(
+		certificate.Config{
+			CertFile:     server.CertFile,
+			KeyFile:      server.KeyFile,
+			ClientCAFile: caFile,
+			ClientAuth:   certificate.ClientAuthRequired,
+		},
+	)
+	if err != nil {
+		assert.FailNow(t, "Unexpected certificate.New failure:", err.Error())
+	}
+	identities := mtls.New(
+		mtls.Config{
+			Source: mtls.SourceSAN,
+			Scopes: map[string][]auth.Scope{"spiffe://example.org/client": {auth.ScopeWrite}},
+		},
+	)
+
+	iFactory := identityFactory.New()
+	decoder := ingest.New(mFactory, iFactory, ingest.NewDefaultConfig())
+	cancel, wg, muxRouter := testInternal.NewSUT(
+		pkg.Run,
+		[]routable.Contract{
+			auth.New(auth.Config{Enabled: true}, auth.Policy{}, []auth.Authenticator{identities}).Init,
+			find.New(s, false).Init,
+			create.New(s, decoder, false).Init,
+			append.New(s, decoder, false).Init,
+			subscribe.New(n).Init,
+		},
+	)
+	httpServer := httptest.NewUnstartedServer(muxRouter)
+	httpServer.TLS = certificates.TLSConfig()
+	httpServer.StartTLS()
+
+	config, err := requestor.NewTLSConfig(
+		requestor.TLS{
+			CAFile:   caFile,
+			CertFile: issued.CertFile,
+			KeyFile:  issued.KeyFile,
+			Pins:     []string{requestor.Pin(server.Certificate)},
+		},
+	)
+	if err != nil {
+		assert.FailNow(t, "Unexpected requestor.NewTLSConfig failure:", err.Error())
+	}
+	r := requestor.New(httpServer.URL)
+	r.SetTLSConfig(config)
+	sut := client.New(r.Handler, mFactory, iFactory)
+	sut.SetStreamer(r.Stream)
+	sut.SetVersion(client.V1)
+	return sut, func() {
+		httpServer.Close()
+		cancel()
+		wg.Wait()
+		_ = os.RemoveAll(directory)
+	}
+}
+
+// grpcTransport serves the gRPC service.
+func grpcTransport(
+	t *testing.T,
+	s store.Contract,
+	n notify.Contract,
+	mFactory metadataFactory.Contract) (Contract, func()) {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		assert.FailNow(t, "Unexpected net.Listen failure:", err.Error())
+	}
+
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	decoder := ingest.New(mFactory, identityFactory.New(), ingest.NewDefaultConfig())
+	server.ServeGRPC(ctx, rpc.New(s, n, decoder).Register, &wg, listener, nil)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		assert.FailNow(t, "Unexpected grpc.NewClient failure:", err.Error())
+	}
+	return grpcClient.New(conn, mFactory, identityFactory.New()), func() {
+		_ = conn.Close()
+		cancel()
+		wg.Wait()
+	}
+}
+
+// newIdentity returns a random identity; unlike FactoryRandomString it is never empty, which no route would match.
+func newIdentity() identity.Contract {
+	return url.New(test.FactoryRandomFixedLengthAlphanumericString(identityLength))
+}
+
+// newAnnotation returns a new annotation carrying m.
+func newAnnotation(m *metadataStub.Instance) *annotation.Instance {
+	return annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, m)
+}
+
+// receive returns the next event from events for id, skipping others, or fails the test after a timeout.
+func receive(t *testing.T, events chan *client.Event, id identity.Contract) *client.Event {
+	timeout := time.After(time.Second * 5)
+	for {
+		select {
+		case event := <-events:
+			if event.Identity == id.Printable() {
+				return event
+			}
+		case <-timeout:
+			assert.FailNow(t, "event not received")
+			return nil
+		}
+	}
+}
+
+// TestConformance runs the same scenarios against every transport.
+func TestConformance(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T, sut Contract, s store.Contract, m *metadataStub.Instance)
+	}
+
+	cases := []testCase{
+		{
+			name: "Create new",
+			test: func(t *testing.T, sut Contract, s store.Contract, m *metadataStub.Instance) {
+				id := newIdentity()
+				value := newAnnotation(m)
+
+				assert.Equal(t, status.Success, sut.Create(id, value))
+
+				annotations, result := s.FindByIdentity(id)
+				assert.Equal(t, status.Success, result)
+				assert.Equal(t, testInternal.Marshal(t, []*annotation.Instance{value}), testInternal.Marshal(t, annotations))
+			},
+		},
+		{
+			name: "Create existing",
+			test: func(t *testing.T, sut Contract, s store.Contract, m *metadataStub.Instance) {
+				id := newIdentity()
+				assert.Equal(t, status.Success, s.Create(id, newAnnotation(m)))
+
+				assert.Equal(t, status.Exists, sut.Create(id, newAnnotation(m)))
+			},
+		},
+		{
+			name: "Append new",
+			test: func(t *testing.T, sut Contract, _ store.Contract, m *metadataStub.Instance) {
+				assert.Equal(t, status.NotFound, sut.Append(newIdentity(), newAnnotation(m)))
+			},
+		},
+		{
+			name: "Append existing and find",
+			test: func(t *testing.T, sut Contract, s store.Contract, m *metadataStub.Instance) {
+				id := newIdentity()
+				first := newAnnotation(m)
+				second := newAnnotation(m)
+				assert.Equal(t, status.Success, s.Create(id, first))
+
+				assert.Equal(t, status.Success, sut.Append(id, second))
+				annotations, result := sut.FindByIdentity(id)
+
+				assert.Equal(t, status.Success, result)
+				assert.Equal(
+					t,
+					testInternal.Marshal(t, []*annotation.Instance{first, second}),
+					testInternal.Marshal(t, annotations),
+				)
+			},
+		},
+		{
+			name: "Identity with a slash",
+			test: func(t *testing.T, sut Contract, s store.Contract, m *metadataStub.Instance) {
+				id := url.New(newIdentity().Printable() + "/" + newIdentity().Printable())
+				first := newAnnotation(m)
+				second := newAnnotation(m)
+
+				assert.Equal(t, status.Success, sut.Create(id, first))
+				assert.Equal(t, status.Success, sut.Append(id, second))
+				stored, result := s.FindByIdentity(id)
+				assert.Equal(t, status.Success, result)
+				annotations, result := sut.FindByIdentity(id)
+
+				assert.Equal(t, status.Success, result)
+				expected := testInternal.Marshal(t, []*annotation.Instance{first, second})
+				assert.Equal(t, expected, testInternal.Marshal(t, stored))
+				assert.Equal(t, expected, testInternal.Marshal(t, annotations))
+			},
+		},
+		{
+			name: "Find unknown",
+			test: func(t *testing.T, sut Contract, _ store.Contract, _ *metadataStub.Instance) {
+				annotations, result := sut.FindByIdentity(newIdentity())
+
+				assert.Nil(t, annotations)
+				assert.NotEqual(t, status.Success, result)
+			},
+		},
+		{
+			name: "Subscribe",
+			test: func(t *testing.T, sut Contract, s store.Contract, m *metadataStub.Instance) {
+				ctx, cancel := context.WithCancel(context.Background())
+				done := make(chan status.Value)
+				events := make(chan *client.Event, 8)
+				prefix := test.FactoryRandomString()
+				id := url.New(prefix + "/" + test.FactoryRandomString())
+				value := newAnnotation(m)
+				go func() {
+					done <- sut.SubscribePrefix(ctx, prefix, func(event *client.Event) { events <- event })
+				}()
+
+				// the subscription is established asynchronously; writes repeat until one is observed.
+				var event *client.Event
+				for attempts := 0; event == nil && attempts < 50; attempts++ {
+					assert.Equal(t, status.Success, s.Create(url.New(prefix+"/"+test.FactoryRandomString()), newAnnotation(m)))
+					select {
+					case event = <-events:
+					case <-time.After(time.Millisecond * 100):
+					}
+				}
+				assert.NotNil(t, event)
+				assert.Equal(t, status.Success, s.Create(id, value))
+
+				event = receive(t, events, id)
+				assert.Equal(t, testInternal.Marshal(t, value), testInternal.Marshal(t, event.Annotation))
+				cancel()
+				assert.Equal(t, status.Success, <-done)
+			},
+		},
+	}
+
+	transports := map[string]transport{
+		"http":              httpTransport,
+		"http/legacyStatus": httpVersionTransport(client.Legacy, codec.NewJSON(), true),
+		"http/v1":           httpVersionTransport(client.V1, codec.NewJSON(), false),
+		"http/v1/cbor":      httpVersionTransport(client.V1, codec.NewCBOR(), false),
+		"http/v1/msgpack":   httpVersionTransport(client.V1, codec.NewMsgPack(), false),
+		"http/v1/apikey":    httpAPIKeyTransport,
+		"http/v1/jwt":       httpJWTTransport,
+		"http/v1/tenant":    httpTenantTransport,
+		"https/mtls":        httpsTransport,
+		"grpc":              grpcTransport,
+	}
+
+	for name := range transports {
+		for i := range cases {
+			n := notify.New(memory.New(), 0)
+			m := metadataStub.NewNullObject()
+			sut, stop := transports[name](
+				t,
+				n,
+				n,
+				metadataFactory.New([]metadataFactory.Contract{metadataStubFactory.New(m)}),
+			)
+			t.Run(
+				name+"/"+cases[i].name,
+				func(t *testing.T) {
+					cases[i].test(t, sut, n, m)
+					stop()
+				},
+			)
+		}
+	}
+}
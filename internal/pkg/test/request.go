@@ -40,6 +40,7 @@ func sendRequest(
 	router *mux.Router,
 	method string,
 	url string,
+	header http.Header,
 	body []byte) *httptest.ResponseRecorder {
 
 	w := httptest.NewRecorder()
@@ -49,6 +50,9 @@ func sendRequest(
 		assert.FailNow(t, "Unexpected http.NewRequest failure:", e.Error())
 		return nil
 	}
+	for key := range header {
+		r.Header[key] = header[key]
+	}
 
 	router.ServeHTTP(w, r)
 	return w
@@ -62,11 +66,24 @@ func SendRequestWithBody(
 	url string,
 	body []byte) *httptest.ResponseRecorder {
 
-	return sendRequest(t, router, method, url, body)
+	return sendRequest(t, router, method, url, nil, body)
+}
+
+// SendRequestWithHeader is common implementation to create recorder, send a request with header and body, and return
+// recorder for evaluation.
+func SendRequestWithHeader(
+	t *testing.T,
+	router *mux.Router,
+	method string,
+	url string,
+	header http.Header,
+	body []byte) *httptest.ResponseRecorder {
+
+	return sendRequest(t, router, method, url, header, body)
 }
 
 // SendRequestWithoutBody is common implementation to create recorder, send a request that has no body, and return
 // recorder for evaluation.
 func SendRequestWithoutBody(t *testing.T, router *mux.Router, method, url string) *httptest.ResponseRecorder {
-	return sendRequest(t, router, method, url, []byte{})
+	return sendRequest(t, router, method, url, nil, []byte{})
 }
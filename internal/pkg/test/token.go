@@ -0,0 +1,127 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hashes maps the JWS algorithms SignToken supports to their hashes.
+var hashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"PS256": crypto.SHA256,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+}
+
+// encode returns v marshalled and base64url encoded.
+func encode(t *testing.T, v interface{}) string {
+	return base64.RawURLEncoding.EncodeToString(Marshal(t, v))
+}
+
+// SignToken returns a JWT carrying claims signed by key with alg (RS256, PS256, ES256 or ES384); an empty kid is
+// omitted from the header.
+func SignToken(t *testing.T, key crypto.Signer, alg, kid string, claims map[string]interface{}) string {
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	input := encode(t, header) + "." + encode(t, claims)
+
+	hash, ok := hashes[alg]
+	if !ok {
+		assert.FailNow(t, "Unsupported alg:", alg)
+	}
+	digest := hash.New()
+	_, _ = digest.Write([]byte(input))
+
+	var options crypto.SignerOpts = hash
+	if alg == "PS256" {
+		options = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+	}
+	signature, err := key.Sign(rand.Reader, digest.Sum(nil), options)
+	if err != nil {
+		assert.FailNow(t, "Unexpected Sign failure:", err.Error())
+	}
+
+	if ecKey, ok := key.(*ecdsa.PrivateKey); ok {
+		// JWS encodes ECDSA signatures as fixed-size r and s rather than ASN.1.
+		var parsed struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(signature, &parsed); err != nil {
+			assert.FailNow(t, "Unexpected asn1.Unmarshal failure:", err.Error())
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		signature = make([]byte, 2*size)
+		parsed.R.FillBytes(signature[:size])
+		parsed.S.FillBytes(signature[size:])
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// jwk returns the JSON web key of public with key ID kid.
+func jwk(t *testing.T, kid string, public crypto.PublicKey) map[string]string {
+	switch typed := public.(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(typed.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(typed.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		size := (typed.Curve.Params().BitSize + 7) / 8
+		return map[string]string{
+			"kty": "EC",
+			"kid": kid,
+			"use": "sig",
+			"crv": typed.Curve.Params().Name,
+			"x":   base64.RawURLEncoding.EncodeToString(typed.X.FillBytes(make([]byte, size))),
+			"y":   base64.RawURLEncoding.EncodeToString(typed.Y.FillBytes(make([]byte, size))),
+		}
+	}
+	assert.FailNow(t, "Unsupported public key")
+	return nil
+}
+
+// WriteJWKS writes a JWKS holding the public keys of keys (by key ID) to path.
+func WriteJWKS(t *testing.T, path string, keys map[string]crypto.Signer) {
+	set := struct {
+		Keys []map[string]string `json:"keys"`
+	}{}
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jwk(t, kid, key.Public()))
+	}
+
+	body, err := json.Marshal(set)
+	if err != nil {
+		assert.FailNow(t, "Unexpected json.Marshal failure:", err.Error())
+	}
+	if err := ioutil.WriteFile(path, body, 0600); err != nil {
+		assert.FailNow(t, "Unexpected ioutil.WriteFile failure:", err.Error())
+	}
+}
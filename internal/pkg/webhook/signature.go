@@ -0,0 +1,55 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+const (
+	DeliveryHeader  = "X-Alvarium-Delivery"
+	TimestampHeader = "X-Alvarium-Timestamp"
+	SignatureHeader = "X-Alvarium-Signature"
+	signaturePrefix = "sha256="
+
+	// Tolerance is how far a delivery's timestamp may be from the receiver's clock for Verify to accept it.
+	Tolerance = 5 * time.Minute
+)
+
+// Sign returns the signature header value for body sent at timestamp, in seconds since the Unix epoch: the
+// hex-encoded HMAC-SHA256 of the timestamp, a period and body, keyed with secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	_, _ = mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if signature is the valid signature of body sent at timestamp, the timestamp header value, for
+// secret and timestamp is within Tolerance of now; receivers use it to authenticate deliveries and reject replays.
+func Verify(secret, timestamp string, body []byte, signature string, now time.Time) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > Tolerance || skew < -Tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, seconds, body)), []byte(signature))
+}
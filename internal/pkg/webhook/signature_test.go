@@ -0,0 +1,66 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVerify tests signature verification.
+func TestVerify(t *testing.T) {
+	secret := test.FactoryRandomString()
+	body := test.FactoryRandomByteSlice()
+	now := time.Now()
+	sent := now.Add(-time.Minute).Unix()
+	signature := Sign(secret, sent, body)
+
+	timestamp := strconv.FormatInt(sent, 10)
+
+	type testCase struct {
+		name      string
+		secret    string
+		timestamp string
+		body      []byte
+		now       time.Time
+		expected  bool
+	}
+
+	cases := []testCase{
+		{name: "valid", secret: secret, timestamp: timestamp, body: body, now: now, expected: true},
+		{name: "other secret", secret: secret + "x", timestamp: timestamp, body: body, now: now},
+		{name: "other body", secret: secret, timestamp: timestamp, body: append(body, 'x'), now: now},
+		{name: "other timestamp", secret: secret, timestamp: strconv.FormatInt(sent+1, 10), body: body, now: now},
+		{name: "malformed timestamp", secret: secret, timestamp: "soon", body: body, now: now},
+		{name: "replayed", secret: secret, timestamp: timestamp, body: body, now: now.Add(Tolerance)},
+		{name: "from the future", secret: secret, timestamp: timestamp, body: body, now: now.Add(-2 * Tolerance)},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				result := Verify(cases[i].secret, cases[i].timestamp, cases[i].body, signature, cases[i].now)
+
+				assert.Equal(t, cases[i].expected, result)
+			},
+		)
+	}
+}
@@ -0,0 +1,524 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+	"github.com/project-alvarium/go-store/internal/pkg/persist"
+	"github.com/project-alvarium/go-store/pkg/http/resource"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+const (
+	hooksFile       = "hooks.json"
+	deadLettersFile = "deadLetters.json"
+	secretSize      = 32
+)
+
+// ErrInvalid reports a hook that cannot be registered as described.
+var ErrInvalid = errors.New("invalid hook")
+
+// Config defines the webhook subsystem's configuration.  Directory holds registered hooks and the dead-letter queue
+// so that both survive a restart; an empty Directory keeps them in memory only.  Backoffs are in milliseconds.  Each
+// hook's deliveries are made in turn; QueueSize bounds those waiting, and a delivery that finds its hook's queue full
+// is dead-lettered.
+type Config struct {
+	Directory      string `json:"directory"`
+	MaxAttempts    int    `json:"maxAttempts"`
+	InitialBackoff int    `json:"initialBackoff"`
+	MaxBackoff     int    `json:"maxBackoff"`
+	Timeout        int    `json:"timeout"`
+	QueueSize      int    `json:"queueSize"`
+}
+
+// NewDefaultConfig returns the default configuration.
+func NewDefaultConfig() Config {
+	return Config{
+		MaxAttempts:    5,
+		InitialBackoff: 1000,
+		MaxBackoff:     60000,
+		Timeout:        10000,
+		QueueSize:      1000,
+	}
+}
+
+// Hook is a registered webhook.  An annotation is delivered to URL when its identity begins with one of Prefixes and
+// its metadata kind is one of MetadataKinds; an empty list matches everything.  Secret signs every delivery.
+type Hook = resource.Hook
+
+// matches returns true if h selects event.
+func matches(h Hook, event notify.Event) bool {
+	return matchesAny(h.Prefixes, func(prefix string) bool { return strings.HasPrefix(event.Identity, prefix) }) &&
+		matchesAny(h.MetadataKinds, func(kind string) bool { return kind == event.Annotation.MetadataKind })
+}
+
+// matchesAny returns true if values is empty or fn is true for any of its values.
+func matchesAny(values []string, fn func(value string) bool) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for i := range values {
+		if fn(values[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the body of every delivery.
+type Payload struct {
+	Delivery string       `json:"delivery"`
+	Hook     string       `json:"hook"`
+	Event    notify.Event `json:"event"`
+}
+
+// Delivery is a payload that could not be delivered to its hook.
+type Delivery = resource.Delivery
+
+// Contract defines the webhook abstraction.
+type Contract interface {
+	// Register validates and stores hook, assigning its ID (and a secret if it has none), and returns it.
+	Register(hook Hook) (Hook, error)
+
+	// Hooks returns the registered hooks without their secrets.
+	Hooks() []Hook
+
+	// Remove unregisters the hook with the given ID and returns status.
+	Remove(id string) status.Value
+
+	// DeadLetters returns the deliveries that exhausted their retries.
+	DeadLetters() []Delivery
+
+	// Replay removes the dead letter with the given ID (or every dead letter if id is empty) and delivers it again,
+	// returning status.
+	Replay(id string) status.Value
+}
+
+// Recorder is called with each failure to persist dead letters that no caller is waiting on.
+type Recorder func(err error)
+
+// worker delivers one hook's queued deliveries in turn until removed is closed.
+type worker struct {
+	queue   chan Delivery
+	removed chan struct{}
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	m           sync.Mutex
+	notify      notify.Contract
+	config      Config
+	client      *http.Client
+	recorder    Recorder
+	hooks       map[string]Hook
+	workers     map[string]*worker
+	deadLetters []Delivery
+	ctx         context.Context
+	wg          *sync.WaitGroup
+	stopped     bool
+}
+
+// New is a factory function that returns instance; hooks and dead letters persisted in config.Directory are loaded.
+// Deliveries begin once Run is called.
+func New(notify notify.Contract, config Config) (*instance, error) {
+	defaults := NewDefaultConfig()
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = config.InitialBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+
+	i := &instance{
+		notify: notify,
+		config: config,
+		client: &http.Client{
+			Timeout: time.Millisecond * time.Duration(config.Timeout),
+		},
+		recorder:    func(error) {},
+		hooks:       make(map[string]Hook),
+		workers:     make(map[string]*worker),
+		deadLetters: make([]Delivery, 0),
+	}
+
+	var hooks []Hook
+	if err := i.load(hooksFile, &hooks); err != nil {
+		return nil, err
+	}
+	for key := range hooks {
+		i.hooks[hooks[key].ID] = hooks[key]
+	}
+	if err := i.load(deadLettersFile, &i.deadLetters); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// SetRecorder provides for method injection of the function told about dead letters that could not be persisted; the
+// default discards them.
+func (i *instance) SetRecorder(recorder Recorder) {
+	i.recorder = recorder
+}
+
+// path returns the location of the named persisted file.
+func (i *instance) path(name string) string {
+	if i.config.Directory == "" {
+		return ""
+	}
+	return filepath.Join(i.config.Directory, name)
+}
+
+// load reads the named persisted file into v.
+func (i *instance) load(name string, v interface{}) error {
+	return persist.Load(i.path(name), v)
+}
+
+// saveHooks writes the hooks.  Callers must hold the lock.
+func (i *instance) saveHooks() error {
+	hooks := make([]Hook, 0, len(i.hooks))
+	for id := range i.hooks {
+		hooks = append(hooks, i.hooks[id])
+	}
+	return persist.Save(i.path(hooksFile), hooks)
+}
+
+// saveDeadLetters writes the dead letters.  Callers must hold the lock.
+func (i *instance) saveDeadLetters() error {
+	return persist.Save(i.path(deadLettersFile), i.deadLetters)
+}
+
+// deadLetter adds delivery, which failed with err, to the dead letters.  Callers must hold the lock.
+func (i *instance) deadLetter(delivery Delivery, err error) {
+	delivery.LastError = err.Error()
+	delivery.Failed = time.Now().UTC().Format(time.RFC3339)
+	i.deadLetters = append(i.deadLetters, delivery)
+	if err := i.saveDeadLetters(); err != nil {
+		i.recorder(err)
+	}
+}
+
+// Register validates and stores hook, assigning its ID (and a secret if it has none), and returns it.
+func (i *instance) Register(hook Hook) (Hook, error) {
+	target, err := url.Parse(hook.URL)
+	if err != nil {
+		return Hook{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return Hook{}, fmt.Errorf("%w: url must be an absolute http or https url", ErrInvalid)
+	}
+
+	hook.ID = ulid.New().Get()
+	if hook.Secret == "" {
+		secret := make([]byte, secretSize)
+		if _, err := rand.Read(secret); err != nil {
+			return Hook{}, err
+		}
+		hook.Secret = hex.EncodeToString(secret)
+	}
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.hooks[hook.ID] = hook
+	if err := i.saveHooks(); err != nil {
+		delete(i.hooks, hook.ID)
+		return Hook{}, err
+	}
+	return hook, nil
+}
+
+// Hooks returns the registered hooks without their secrets.
+func (i *instance) Hooks() []Hook {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	results := make([]Hook, 0, len(i.hooks))
+	for id := range i.hooks {
+		hook := i.hooks[id]
+		hook.Secret = ""
+		results = append(results, hook)
+	}
+	return results
+}
+
+// Remove unregisters the hook with the given ID and returns status.
+func (i *instance) Remove(id string) status.Value {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	hook, exists := i.hooks[id]
+	if !exists {
+		return status.NotFound
+	}
+	delete(i.hooks, id)
+	if err := i.saveHooks(); err != nil {
+		i.hooks[id] = hook
+		return status.Unknown
+	}
+	if w, exists := i.workers[id]; exists {
+		close(w.removed)
+		delete(i.workers, id)
+	}
+	return status.Success
+}
+
+// DeadLetters returns the deliveries that exhausted their retries.
+func (i *instance) DeadLetters() []Delivery {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	results := make([]Delivery, len(i.deadLetters))
+	copy(results, i.deadLetters)
+	return results
+}
+
+// Replay removes the dead letter with the given ID (or every dead letter if id is empty) and delivers it again,
+// returning status.  Dead letters whose hook has been removed are left in place.
+func (i *instance) Replay(id string) status.Value {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	if i.wg == nil || i.stopped {
+		return status.Unknown
+	}
+
+	var replayed []Delivery
+	remaining := make([]Delivery, 0, len(i.deadLetters))
+	for key := range i.deadLetters {
+		delivery := i.deadLetters[key]
+		if _, exists := i.hooks[delivery.Hook]; (id != "" && delivery.ID != id) || !exists {
+			remaining = append(remaining, delivery)
+			continue
+		}
+		replayed = append(replayed, delivery)
+	}
+	if id != "" && len(replayed) == 0 {
+		return status.NotFound
+	}
+
+	previous := i.deadLetters
+	i.deadLetters = remaining
+	if err := i.saveDeadLetters(); err != nil {
+		i.deadLetters = previous
+		return status.Unknown
+	}
+	for key := range replayed {
+		replayed[key].Attempts = 0
+		i.start(i.hooks[replayed[key].Hook], replayed[key])
+	}
+	return status.Success
+}
+
+// Run starts delivering events and is this package's runnable; in-flight deliveries are dead-lettered when ctx is done
+// so that they can be replayed after a restart.
+func (i *instance) Run(ctx context.Context, wg *sync.WaitGroup) {
+	i.m.Lock()
+	i.ctx = ctx
+	i.wg = wg
+	i.m.Unlock()
+
+	// subscribing before returning ensures no write made after Run is missed.
+	filter := notify.Filter{Prefix: true}
+	subscription, _ := i.notify.Subscribe(filter, 0)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			// deliveries are only started while the dispatcher holds wg open.
+			i.m.Lock()
+			i.stopped = true
+			i.m.Unlock()
+		}()
+
+		var last uint64
+		for {
+			event, ok := subscription.Next(ctx)
+			if ok {
+				last = event.Sequence
+				i.dispatch(event)
+				continue
+			}
+
+			subscription.Close()
+			if ctx.Err() != nil {
+				return
+			}
+			var err error
+			if subscription, err = i.notify.Subscribe(filter, last); err != nil {
+				// events written since last are no longer retained; carry on with new events.
+				subscription, _ = i.notify.Subscribe(filter, 0)
+			}
+		}
+	}()
+}
+
+// dispatch starts a delivery of event to every matching hook.
+func (i *instance) dispatch(event notify.Event) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	for id := range i.hooks {
+		hook := i.hooks[id]
+		if !matches(hook, event) {
+			continue
+		}
+
+		delivery := Delivery{ID: ulid.New().Get(), Hook: hook.ID}
+		body, err := json.Marshal(Payload{Delivery: delivery.ID, Hook: hook.ID, Event: event})
+		if err != nil {
+			continue
+		}
+		delivery.Body = body
+		i.start(hook, delivery)
+	}
+}
+
+// start queues delivery for hook's worker, starting the worker if hook has none; a delivery that cannot be queued
+// is dead-lettered.  Callers must hold the lock.
+func (i *instance) start(hook Hook, delivery Delivery) {
+	if err := i.ctx.Err(); err != nil {
+		i.deadLetter(delivery, err)
+		return
+	}
+
+	w, exists := i.workers[hook.ID]
+	if !exists {
+		w = &worker{queue: make(chan Delivery, i.config.QueueSize), removed: make(chan struct{})}
+		i.workers[hook.ID] = w
+		i.wg.Add(1)
+		go i.work(hook, w)
+	}
+
+	select {
+	case w.queue <- delivery:
+	default:
+		i.deadLetter(delivery, errors.New("delivery queue is full"))
+	}
+}
+
+// work delivers w's queued deliveries to hook in turn, dead-lettering those that fail, until hook is removed or the
+// instance stops; deliveries still queued when it stops are dead-lettered.
+func (i *instance) work(hook Hook, w *worker) {
+	defer i.wg.Done()
+
+	for {
+		select {
+		case delivery := <-w.queue:
+			err := i.ctx.Err()
+			if err == nil {
+				err = i.deliver(i.ctx, hook, &delivery)
+			}
+			if err != nil {
+				i.m.Lock()
+				i.deadLetter(delivery, err)
+				i.m.Unlock()
+			}
+		case <-w.removed:
+			return
+		case <-i.ctx.Done():
+			i.m.Lock()
+			defer i.m.Unlock()
+			for {
+				select {
+				case delivery := <-w.queue:
+					i.deadLetter(delivery, i.ctx.Err())
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver posts delivery to hook, retrying with exponential backoff until it is accepted, attempts are exhausted,
+// or ctx is done.
+func (i *instance) deliver(ctx context.Context, hook Hook, delivery *Delivery) error {
+	backoff := time.Millisecond * time.Duration(i.config.InitialBackoff)
+	maxBackoff := time.Millisecond * time.Duration(i.config.MaxBackoff)
+
+	for {
+		delivery.Attempts++
+		err := i.post(ctx, hook, delivery)
+		if err == nil {
+			return nil
+		}
+		if delivery.Attempts >= i.config.MaxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// post makes a single signed delivery attempt; any status other than 2xx is a failure.
+func (i *instance) post(ctx context.Context, hook Hook, delivery *Delivery) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	timestamp := time.Now().Unix()
+	request.Header.Set(DeliveryHeader, delivery.ID)
+	request.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(SignatureHeader, Sign(hook.Secret, timestamp, delivery.Body))
+
+	response, err := i.client.Do(request)
+	if err != nil {
+		return err
+	}
+	_ = response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return errors.New("unexpected response " + response.Status)
+	}
+	return nil
+}
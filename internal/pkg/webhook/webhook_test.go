@@ -0,0 +1,360 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/store"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// received is a delivery observed by receiver.
+type received struct {
+	payload   Payload
+	body      []byte
+	timestamp string
+	signature string
+}
+
+// verify returns whether delivery is signed with secret.
+func (delivery received) verify(secret string) bool {
+	return Verify(secret, delivery.timestamp, delivery.body, delivery.signature, time.Now())
+}
+
+// receiver is an httptest server that records deliveries, failing the first failures of them.
+type receiver struct {
+	m          sync.Mutex
+	server     *httptest.Server
+	failures   int
+	deliveries chan received
+}
+
+// newReceiver is a factory function that returns a started receiver.
+func newReceiver(failures int) *receiver {
+	r := &receiver{failures: failures, deliveries: make(chan received, 16)}
+	r.server = httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, request *http.Request) {
+				r.m.Lock()
+				fail := r.failures > 0
+				r.failures--
+				r.m.Unlock()
+				if fail {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+
+				body, _ := ioutil.ReadAll(request.Body)
+				var payload Payload
+				_ = json.Unmarshal(body, &payload)
+				r.deliveries <- received{
+					payload:   payload,
+					body:      body,
+					timestamp: request.Header.Get(TimestampHeader),
+					signature: request.Header.Get(SignatureHeader),
+				}
+			},
+		),
+	)
+	return r
+}
+
+// setFailures changes the number of deliveries the receiver rejects.
+func (r *receiver) setFailures(failures int) {
+	r.m.Lock()
+	r.failures = failures
+	r.m.Unlock()
+}
+
+// next returns the next delivery or fails the test after a timeout.
+func (r *receiver) next(t *testing.T) received {
+	select {
+	case delivery := <-r.deliveries:
+		return delivery
+	case <-time.After(time.Second * 5):
+		assert.FailNow(t, "delivery not received")
+		return received{}
+	}
+}
+
+// none asserts that no delivery arrives within a short interval.
+func (r *receiver) none(t *testing.T) {
+	select {
+	case <-r.deliveries:
+		assert.Fail(t, "unexpected delivery")
+	case <-time.After(time.Millisecond * 100):
+	}
+}
+
+// newAnnotation returns a new annotation carrying m.
+func newAnnotation(m *metadataStub.Instance) *annotation.Instance {
+	return annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, m)
+}
+
+// newSUT returns a running system under test and the store that feeds it.
+func newSUT(t *testing.T, config Config) (*instance, store.Contract, context.CancelFunc, *sync.WaitGroup) {
+	s := notify.New(memory.New(), 0)
+	sut, err := New(s, config)
+	if err != nil {
+		assert.FailNow(t, "Unexpected New failure:", err.Error())
+	}
+
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	sut.Run(ctx, &wg)
+	return sut, s, cancel, &wg
+}
+
+// fastConfig returns a configuration with short backoffs.
+func fastConfig(directory string) Config {
+	return Config{Directory: directory, MaxAttempts: 3, InitialBackoff: 1, MaxBackoff: 5}
+}
+
+// TestInstance tests webhook registration, delivery, retry, and dead letters.
+func TestInstance(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "register rejects invalid url",
+			test: func(t *testing.T) {
+				sut, _, cancel, wg := newSUT(t, fastConfig(""))
+				defer wg.Wait()
+				defer cancel()
+
+				_, err := sut.Register(Hook{URL: "/" + test.FactoryRandomString()})
+
+				assert.True(t, errors.Is(err, ErrInvalid))
+				assert.Equal(t, 0, len(sut.Hooks()))
+			},
+		},
+		{
+			name: "register list remove",
+			test: func(t *testing.T) {
+				sut, _, cancel, wg := newSUT(t, fastConfig(""))
+				defer wg.Wait()
+				defer cancel()
+
+				hook, err := sut.Register(Hook{URL: "http://localhost/" + test.FactoryRandomString()})
+				assert.Nil(t, err)
+				assert.NotEmpty(t, hook.ID)
+				assert.NotEmpty(t, hook.Secret)
+
+				listed := hook
+				listed.Secret = ""
+				assert.Equal(t, []Hook{listed}, sut.Hooks())
+				assert.Equal(t, status.Success, sut.Remove(hook.ID))
+				assert.Equal(t, status.NotFound, sut.Remove(hook.ID))
+				assert.Equal(t, 0, len(sut.Hooks()))
+			},
+		},
+		{
+			name: "delivers signed payload to matching hooks only",
+			test: func(t *testing.T) {
+				sut, s, cancel, wg := newSUT(t, fastConfig(""))
+				defer wg.Wait()
+				defer cancel()
+				r := newReceiver(0)
+				defer r.server.Close()
+				m := metadataStub.NewNullObject()
+				prefix := test.FactoryRandomString()
+				hook, _ := sut.Register(Hook{URL: r.server.URL, Prefixes: []string{prefix}, MetadataKinds: []string{m.Kind()}})
+				id := url.New(prefix + "/" + test.FactoryRandomString())
+				value := newAnnotation(m)
+
+				assert.Equal(t, status.Success, s.Create(url.New(test.FactoryRandomString()), newAnnotation(m)))
+				assert.Equal(t, status.Success, s.Create(url.New(prefix), newAnnotation(metadataStub.NewNullObject())))
+				assert.Equal(t, status.Success, s.Create(id, value))
+
+				delivery := r.next(t)
+				assert.Equal(t, hook.ID, delivery.payload.Hook)
+				assert.Equal(t, id.Printable(), delivery.payload.Event.Identity)
+				assert.Equal(t, uint64(3), delivery.payload.Event.Sequence)
+				assert.True(t, delivery.verify(hook.Secret))
+				assert.False(t, delivery.verify(test.FactoryRandomString()))
+				r.none(t)
+			},
+		},
+		{
+			name: "register reports save failure",
+			test: func(t *testing.T) {
+				directory := filepath.Join(os.TempDir(), test.FactoryRandomFixedLengthAlphanumericString(16))
+				sut, _, cancel, wg := newSUT(t, fastConfig(directory))
+				defer wg.Wait()
+				defer cancel()
+
+				_, err := sut.Register(Hook{URL: "http://localhost/" + test.FactoryRandomString()})
+
+				assert.Error(t, err)
+				assert.False(t, errors.Is(err, ErrInvalid))
+				assert.Equal(t, 0, len(sut.Hooks()))
+			},
+		},
+		{
+			name: "full queue dead-letters deliveries",
+			test: func(t *testing.T) {
+				config := fastConfig("")
+				config.QueueSize = 1
+				config.InitialBackoff = 60000
+				config.MaxBackoff = 60000
+				sut, s, cancel, wg := newSUT(t, config)
+				r := newReceiver(1)
+				defer r.server.Close()
+				hook, _ := sut.Register(Hook{URL: r.server.URL})
+				create := func() {
+					value := newAnnotation(metadataStub.NewNullObject())
+					assert.Equal(t, status.Success, s.Create(url.New(test.FactoryRandomString()), value))
+				}
+
+				// the first delivery waits out its backoff, the second waits in the queue and the third is refused.
+				create()
+				for start := time.Now(); time.Since(start) < time.Second*5; time.Sleep(time.Millisecond * 10) {
+					r.m.Lock()
+					attempted := r.failures < 1
+					r.m.Unlock()
+					if attempted {
+						break
+					}
+				}
+				create()
+				create()
+				for start := time.Now(); len(sut.DeadLetters()) == 0 && time.Since(start) < time.Second*5; {
+					time.Sleep(time.Millisecond * 10)
+				}
+
+				deadLetters := sut.DeadLetters()
+				assert.Equal(t, 1, len(deadLetters))
+				assert.Equal(t, hook.ID, deadLetters[0].Hook)
+				assert.Equal(t, 0, deadLetters[0].Attempts)
+				cancel()
+				wg.Wait()
+				assert.Equal(t, 3, len(sut.DeadLetters()))
+				r.setFailures(0)
+			},
+		},
+		{
+			name: "retries until accepted",
+			test: func(t *testing.T) {
+				sut, s, cancel, wg := newSUT(t, fastConfig(""))
+				defer wg.Wait()
+				defer cancel()
+				r := newReceiver(2)
+				defer r.server.Close()
+				_, _ = sut.Register(Hook{URL: r.server.URL})
+
+				assert.Equal(t, status.Success, s.Create(url.New(test.FactoryRandomString()), newAnnotation(metadataStub.NewNullObject())))
+
+				r.next(t)
+				assert.Equal(t, 0, len(sut.DeadLetters()))
+			},
+		},
+		{
+			name: "dead letters persist and replay",
+			test: func(t *testing.T) {
+				directory, err := ioutil.TempDir("", "webhook")
+				if err != nil {
+					assert.FailNow(t, "Unexpected TempDir failure:", err.Error())
+				}
+				defer func() { _ = os.RemoveAll(directory) }()
+				r := newReceiver(3)
+				defer r.server.Close()
+
+				sut, s, cancel, wg := newSUT(t, fastConfig(directory))
+				hook, _ := sut.Register(Hook{URL: r.server.URL})
+				assert.Equal(t, status.Success, s.Create(url.New(test.FactoryRandomString()), newAnnotation(metadataStub.NewNullObject())))
+				for start := time.Now(); len(sut.DeadLetters()) == 0 && time.Since(start) < time.Second*5; {
+					time.Sleep(time.Millisecond * 10)
+				}
+				cancel()
+				wg.Wait()
+
+				restarted, _, cancel, wg := newSUT(t, fastConfig(directory))
+				defer wg.Wait()
+				defer cancel()
+				deadLetters := restarted.DeadLetters()
+				assert.Equal(t, 1, len(deadLetters))
+				assert.Equal(t, hook.ID, deadLetters[0].Hook)
+				assert.Equal(t, 3, deadLetters[0].Attempts)
+				assert.Equal(t, status.NotFound, restarted.Replay(test.FactoryRandomString()))
+
+				assert.Equal(t, status.Success, restarted.Replay(deadLetters[0].ID))
+
+				delivery := r.next(t)
+				assert.Equal(t, deadLetters[0].ID, delivery.payload.Delivery)
+				assert.True(t, delivery.verify(hook.Secret))
+				assert.Equal(t, 0, len(restarted.DeadLetters()))
+			},
+		},
+		{
+			name: "shutdown dead-letters pending deliveries",
+			test: func(t *testing.T) {
+				config := fastConfig("")
+				config.InitialBackoff = 60000
+				config.MaxBackoff = 60000
+				sut, s, cancel, wg := newSUT(t, config)
+				r := newReceiver(1)
+				defer r.server.Close()
+				_, _ = sut.Register(Hook{URL: r.server.URL})
+				assert.Equal(t, status.Success, s.Create(url.New(test.FactoryRandomString()), newAnnotation(metadataStub.NewNullObject())))
+				for start := time.Now(); time.Since(start) < time.Second*5; time.Sleep(time.Millisecond * 10) {
+					r.m.Lock()
+					attempted := r.failures < 1
+					r.m.Unlock()
+					if attempted {
+						break
+					}
+				}
+
+				cancel()
+				wg.Wait()
+
+				deadLetters := sut.DeadLetters()
+				assert.Equal(t, 1, len(deadLetters))
+				assert.Equal(t, context.Canceled.Error(), deadLetters[0].LastError)
+				assert.Equal(t, status.Unknown, sut.Replay(""))
+				r.setFailures(0)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
@@ -0,0 +1,194 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package client
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/project-alvarium/go-store/pkg/grpc/storepb"
+	httpClient "github.com/project-alvarium/go-store/pkg/http/client"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+const (
+	requestTimeout        = time.Second * time.Duration(30)
+	marshalFailure        = status.Unknown
+	transportFailure      = status.Unknown
+	unmarshalFailure      = status.Unknown
+	subscribeSuccess      = status.Success
+	subscribeInitialRetry = time.Second
+	subscribeMaximumRetry = time.Second * time.Duration(30)
+)
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	client   storepb.StoreClient
+	mFactory metadataFactory.Contract
+	iFactory identityFactory.Contract
+}
+
+// New is a factory function that returns instance.
+func New(
+	conn grpc.ClientConnInterface,
+	mFactory metadataFactory.Contract,
+	iFactory identityFactory.Contract) *instance {
+
+	return &instance{
+		client:   storepb.NewStoreClient(conn),
+		mFactory: mFactory,
+		iFactory: iFactory,
+	}
+}
+
+// writer defines the generated client method used to send a write.
+type writer func(ctx context.Context, in *storepb.WriteRequest, opts ...grpc.CallOption) (*storepb.WriteResponse, error)
+
+// write sends m for id using fn and returns status.
+func (i *instance) write(id identity.Contract, m *annotation.Instance, fn writer) status.Value {
+	value, err := storepb.FromAnnotation(m)
+	if err != nil {
+		return marshalFailure
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	response, err := fn(ctx, &storepb.WriteRequest{Identity: id.Printable(), Annotation: value})
+	if err != nil {
+		return transportFailure
+	}
+	return status.Value(response.GetStatus())
+}
+
+// Create stores annotation corresponding to a new identity and returns status.
+func (i *instance) Create(id identity.Contract, m *annotation.Instance) status.Value {
+	return i.write(id, m, i.client.Create)
+}
+
+// Append stores annotation corresponding to identity and returns status.
+func (i *instance) Append(id identity.Contract, m *annotation.Instance) status.Value {
+	return i.write(id, m, i.client.Append)
+}
+
+// FindByIdentity returns annotations and status corresponding to identity.
+func (i *instance) FindByIdentity(id identity.Contract) ([]*annotation.Instance, status.Value) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	stream, err := i.client.FindByIdentity(ctx, &storepb.FindRequest{Identity: id.Printable()})
+	if err != nil {
+		return nil, transportFailure
+	}
+
+	results := make([]*annotation.Instance, 0)
+	for {
+		message, err := stream.Recv()
+		if err == io.EOF {
+			return results, status.Success
+		}
+		if grpcStatus.Code(err) == codes.NotFound {
+			return nil, status.NotFound
+		}
+		if err != nil {
+			return nil, transportFailure
+		}
+
+		value, err := message.ToAnnotation(i.mFactory, i.iFactory)
+		if err != nil {
+			return nil, unmarshalFailure
+		}
+		results = append(results, value)
+	}
+}
+
+// Subscribe calls fn with each annotation stored against id until ctx is done, reconnecting and resuming from the
+// last received event whenever the stream fails; fn receives a reset event if that event is no longer retained.
+func (i *instance) Subscribe(ctx context.Context, id identity.Contract, fn func(event *httpClient.Event)) status.Value {
+	return i.subscribe(ctx, &storepb.SubscribeRequest{Identity: id.Printable()}, fn)
+}
+
+// SubscribePrefix calls fn with each annotation stored against an identity beginning with prefix until ctx is done,
+// reconnecting and resuming from the last received event whenever the stream fails; fn receives a reset event if
+// that event is no longer retained.
+func (i *instance) SubscribePrefix(ctx context.Context, prefix string, fn func(event *httpClient.Event)) status.Value {
+	return i.subscribe(ctx, &storepb.SubscribeRequest{Identity: prefix, Prefix: true}, fn)
+}
+
+// subscribe implements Subscribe and SubscribePrefix.
+func (i *instance) subscribe(
+	ctx context.Context,
+	request *storepb.SubscribeRequest,
+	fn func(event *httpClient.Event)) status.Value {
+
+	delay := subscribeInitialRetry
+	for {
+		if stream, err := i.client.Subscribe(ctx, request); err == nil {
+			received, err := i.read(stream, request, fn)
+			if received {
+				delay = subscribeInitialRetry
+			}
+			if request.After > 0 && grpcStatus.Code(err) == codes.OutOfRange {
+				request.After = 0
+				fn(&httpClient.Event{Reset: true})
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return subscribeSuccess
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > subscribeMaximumRetry {
+			delay = subscribeMaximumRetry
+		}
+	}
+}
+
+// read delivers events from stream to fn until it ends, advancing request past each event; it returns true if any
+// event was received and the error that ended the stream.
+func (i *instance) read(
+	stream storepb.Store_SubscribeClient,
+	request *storepb.SubscribeRequest,
+	fn func(event *httpClient.Event)) (bool, error) {
+
+	received := false
+	for {
+		message, err := stream.Recv()
+		if err != nil {
+			return received, err
+		}
+		received = true
+		request.After = message.GetSequence()
+
+		value, err := message.GetAnnotation().ToAnnotation(i.mFactory, i.iFactory)
+		if err != nil {
+			continue
+		}
+		fn(&httpClient.Event{Sequence: message.GetSequence(), Identity: message.GetIdentity(), Annotation: value})
+	}
+}
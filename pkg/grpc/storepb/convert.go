@@ -0,0 +1,110 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package storepb
+
+import (
+	"encoding/json"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+)
+
+// document is an annotation's JSON representation with its identities and metadata left undecoded.
+type document struct {
+	Unique               string          `json:"unique"`
+	Created              string          `json:"created"`
+	CurrentIdentityKind  string          `json:"identityCurrentType"`
+	CurrentIdentity      json.RawMessage `json:"identityCurrent"`
+	PreviousIdentityKind string          `json:"identityPreviousType"`
+	PreviousIdentity     json.RawMessage `json:"identityPrevious"`
+	MetadataKind         string          `json:"metadataType"`
+	Metadata             json.RawMessage `json:"metadata"`
+}
+
+// raw returns value's JSON, treating an absent value as null.
+func raw(value []byte) json.RawMessage {
+	if len(value) == 0 {
+		return json.RawMessage("null")
+	}
+	return value
+}
+
+// bytes returns value's JSON, treating null as absent.
+func bytes(value json.RawMessage) []byte {
+	if string(value) == "null" {
+		return nil
+	}
+	return value
+}
+
+// FromAnnotation returns the message representation of m.
+func FromAnnotation(m *annotation.Instance) (*Annotation, error) {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var d document
+	if err := json.Unmarshal(body, &d); err != nil {
+		return nil, err
+	}
+
+	return &Annotation{
+		Unique:               d.Unique,
+		Created:              d.Created,
+		IdentityCurrentType:  d.CurrentIdentityKind,
+		IdentityCurrent:      bytes(d.CurrentIdentity),
+		IdentityPreviousType: d.PreviousIdentityKind,
+		IdentityPrevious:     bytes(d.PreviousIdentity),
+		MetadataType:         d.MetadataKind,
+		Metadata:             bytes(d.Metadata),
+	}, nil
+}
+
+// ToJSON returns the JSON representation of the annotation the message describes.
+func (x *Annotation) ToJSON() ([]byte, error) {
+	return json.Marshal(
+		document{
+			Unique:               x.GetUnique(),
+			Created:              x.GetCreated(),
+			CurrentIdentityKind:  x.GetIdentityCurrentType(),
+			CurrentIdentity:      raw(x.GetIdentityCurrent()),
+			PreviousIdentityKind: x.GetIdentityPreviousType(),
+			PreviousIdentity:     raw(x.GetIdentityPrevious()),
+			MetadataKind:         x.GetMetadataType(),
+			Metadata:             raw(x.GetMetadata()),
+		},
+	)
+}
+
+// ToAnnotation decodes the message into an annotation using the given factories.
+func (x *Annotation) ToAnnotation(
+	mFactory metadataFactory.Contract,
+	iFactory identityFactory.Contract) (*annotation.Instance, error) {
+
+	body, err := x.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var value annotation.Instance
+	value.SetMetadataFactory(mFactory)
+	value.SetIdentityFactory(iFactory)
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
@@ -0,0 +1,99 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package storepb
+
+import (
+	"crypto"
+	"encoding/json"
+	"testing"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
+	pkiMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata/factory"
+	signerMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/signer/signpkcs1v15/metadata"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// marshal returns the json encoding of v.
+func marshal(t *testing.T, v interface{}) []byte {
+	body, err := json.Marshal(v)
+	if err != nil {
+		assert.FailNow(t, "Unexpected marshal failure:", err.Error())
+	}
+	return body
+}
+
+// newMetadata returns new pki metadata.
+func newMetadata() *pkiMetadata.Instance {
+	return pkiMetadata.New(
+		nil,
+		test.FactoryRandomByteSlice(),
+		test.FactoryRandomByteSlice(),
+		test.FactoryRandomByteSlice(),
+		signerMetadata.NewSuccess(crypto.SHA256, test.FactoryRandomString()),
+	)
+}
+
+// TestConvert tests FromAnnotation and ToAnnotation.
+func TestConvert(t *testing.T) {
+	type testCase struct {
+		name  string
+		value *annotation.Instance
+	}
+
+	cases := []testCase{
+		{
+			name:  "without previous identity",
+			value: annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, newMetadata()),
+		},
+		{
+			name: "with previous identity and metadata",
+			value: annotation.New(
+				ulid.New().Get(),
+				hash.New(test.FactoryRandomByteSlice()),
+				hash.New(test.FactoryRandomByteSlice()),
+				newMetadata(),
+			),
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				message, err := FromAnnotation(cases[i].value)
+				assert.Nil(t, err)
+
+				value, err := message.ToAnnotation(
+					metadataFactory.New([]metadataFactory.Contract{pkiMetadataFactory.NewDefault()}),
+					identityFactory.New(),
+				)
+
+				assert.Nil(t, err)
+				assert.Equal(t, marshal(t, cases[i].value), marshal(t, value))
+
+				body, err := message.ToJSON()
+				assert.Nil(t, err)
+				assert.JSONEq(t, string(marshal(t, cases[i].value)), string(body))
+			},
+		)
+	}
+}
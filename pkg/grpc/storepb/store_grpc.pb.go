@@ -0,0 +1,297 @@
+//******************************************************************************
+// Copyright 2020 Dell Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//*****************************************************************************
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: pkg/grpc/storepb/store.proto
+
+package storepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Store_Create_FullMethodName         = "/alvarium.store.v1.Store/Create"
+	Store_Append_FullMethodName         = "/alvarium.store.v1.Store/Append"
+	Store_FindByIdentity_FullMethodName = "/alvarium.store.v1.Store/FindByIdentity"
+	Store_Subscribe_FullMethodName      = "/alvarium.store.v1.Store/Subscribe"
+)
+
+// StoreClient is the client API for Store service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StoreClient interface {
+	// Create stores an annotation against a new identity.
+	Create(ctx context.Context, in *WriteRequest, opts ...grpc.CallOption) (*WriteResponse, error)
+	// Append stores an annotation against an existing identity.
+	Append(ctx context.Context, in *WriteRequest, opts ...grpc.CallOption) (*WriteResponse, error)
+	// FindByIdentity streams the annotations stored against an identity; an unknown identity fails with NOT_FOUND.
+	FindByIdentity(ctx context.Context, in *FindRequest, opts ...grpc.CallOption) (Store_FindByIdentityClient, error)
+	// Subscribe streams events until the client cancels or the service stops.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Store_SubscribeClient, error)
+}
+
+type storeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStoreClient(cc grpc.ClientConnInterface) StoreClient {
+	return &storeClient{cc}
+}
+
+func (c *storeClient) Create(ctx context.Context, in *WriteRequest, opts ...grpc.CallOption) (*WriteResponse, error) {
+	out := new(WriteResponse)
+	err := c.cc.Invoke(ctx, Store_Create_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storeClient) Append(ctx context.Context, in *WriteRequest, opts ...grpc.CallOption) (*WriteResponse, error) {
+	out := new(WriteResponse)
+	err := c.cc.Invoke(ctx, Store_Append_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storeClient) FindByIdentity(ctx context.Context, in *FindRequest, opts ...grpc.CallOption) (Store_FindByIdentityClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Store_ServiceDesc.Streams[0], Store_FindByIdentity_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storeFindByIdentityClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Store_FindByIdentityClient interface {
+	Recv() (*Annotation, error)
+	grpc.ClientStream
+}
+
+type storeFindByIdentityClient struct {
+	grpc.ClientStream
+}
+
+func (x *storeFindByIdentityClient) Recv() (*Annotation, error) {
+	m := new(Annotation)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storeClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Store_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Store_ServiceDesc.Streams[1], Store_Subscribe_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storeSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Store_SubscribeClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type storeSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *storeSubscribeClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// StoreServer is the server API for Store service.
+// All implementations must embed UnimplementedStoreServer
+// for forward compatibility
+type StoreServer interface {
+	// Create stores an annotation against a new identity.
+	Create(context.Context, *WriteRequest) (*WriteResponse, error)
+	// Append stores an annotation against an existing identity.
+	Append(context.Context, *WriteRequest) (*WriteResponse, error)
+	// FindByIdentity streams the annotations stored against an identity; an unknown identity fails with NOT_FOUND.
+	FindByIdentity(*FindRequest, Store_FindByIdentityServer) error
+	// Subscribe streams events until the client cancels or the service stops.
+	Subscribe(*SubscribeRequest, Store_SubscribeServer) error
+	mustEmbedUnimplementedStoreServer()
+}
+
+// UnimplementedStoreServer must be embedded to have forward compatible implementations.
+type UnimplementedStoreServer struct {
+}
+
+func (UnimplementedStoreServer) Create(context.Context, *WriteRequest) (*WriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedStoreServer) Append(context.Context, *WriteRequest) (*WriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Append not implemented")
+}
+func (UnimplementedStoreServer) FindByIdentity(*FindRequest, Store_FindByIdentityServer) error {
+	return status.Errorf(codes.Unimplemented, "method FindByIdentity not implemented")
+}
+func (UnimplementedStoreServer) Subscribe(*SubscribeRequest, Store_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedStoreServer) mustEmbedUnimplementedStoreServer() {}
+
+// UnsafeStoreServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StoreServer will
+// result in compilation errors.
+type UnsafeStoreServer interface {
+	mustEmbedUnimplementedStoreServer()
+}
+
+func RegisterStoreServer(s grpc.ServiceRegistrar, srv StoreServer) {
+	s.RegisterService(&Store_ServiceDesc, srv)
+}
+
+func _Store_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StoreServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Store_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StoreServer).Create(ctx, req.(*WriteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Store_Append_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StoreServer).Append(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Store_Append_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StoreServer).Append(ctx, req.(*WriteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Store_FindByIdentity_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FindRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StoreServer).FindByIdentity(m, &storeFindByIdentityServer{stream})
+}
+
+type Store_FindByIdentityServer interface {
+	Send(*Annotation) error
+	grpc.ServerStream
+}
+
+type storeFindByIdentityServer struct {
+	grpc.ServerStream
+}
+
+func (x *storeFindByIdentityServer) Send(m *Annotation) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Store_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StoreServer).Subscribe(m, &storeSubscribeServer{stream})
+}
+
+type Store_SubscribeServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type storeSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *storeSubscribeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Store_ServiceDesc is the grpc.ServiceDesc for Store service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Store_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "alvarium.store.v1.Store",
+	HandlerType: (*StoreServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _Store_Create_Handler,
+		},
+		{
+			MethodName: "Append",
+			Handler:    _Store_Append_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "FindByIdentity",
+			Handler:       _Store_FindByIdentity_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Store_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/grpc/storepb/store.proto",
+}
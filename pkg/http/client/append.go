@@ -16,8 +16,10 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 
 	"github.com/project-alvarium/go-store/internal/pkg/routes/append"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
 
 	"github.com/project-alvarium/go-sdk/pkg/annotation"
 	"github.com/project-alvarium/go-sdk/pkg/identity"
@@ -25,28 +27,51 @@ import (
 )
 
 const (
+	appendInvalid          = status.Unknown
 	appendMarshalFailure   = status.Unknown
 	appendRequestorFailure = status.Unknown
 	appendUnmarshalFailure = status.Unknown
+	appendNotFound         = status.NotFound
 	appendSuccess          = status.Success
 )
 
 // Append stores annotation corresponding to identity and returns status.
-func (i *instance) Append(id identity.Contract, m *annotation.Instance) (result status.Value) {
+func (i *instance) Append(id identity.Contract, m *annotation.Instance) status.Value {
+	result, _ := i.AppendWithError(id, m)
+	return result
+}
+
+// AppendWithError stores annotation corresponding to identity and returns status and, if it did not succeed,
+// the reason: the *problem.Instance the service reported, if any.
+func (i *instance) AppendWithError(id identity.Contract, m *annotation.Instance) (result status.Value, err error) {
 	var body, response []byte
-	var err error
 
-	if body, err = json.Marshal(m); err != nil {
-		return appendMarshalFailure
+	if err = i.validate(m); err != nil {
+		return appendInvalid, err
+	}
+
+	if body, err = i.codec.Marshal(m); err != nil {
+		return appendMarshalFailure, err
+	}
+
+	method, path := append.Method, append.EscapedRoute(id)
+	if i.version == V1 {
+		method, path = append.V1Method, append.EscapedV1Route(id)
+	}
+	if response, err = i.request(method, path, body); err != nil {
+		if errors.Is(err, problem.ErrIdentityNotFound) {
+			return appendNotFound, err
+		}
+		return appendRequestorFailure, err
 	}
 
-	if response, err = i.requestor(append.Method, append.EscapedRoute(id), body); err != nil {
-		return appendRequestorFailure
+	if response, err = i.codec.ToJSON(response); err != nil {
+		return appendUnmarshalFailure, err
 	}
 
-	if err := json.Unmarshal(response, &result); err != nil {
-		return appendUnmarshalFailure
+	if err = json.Unmarshal(response, &result); err != nil {
+		return appendUnmarshalFailure, err
 	}
 
-	return
+	return result, nil
 }
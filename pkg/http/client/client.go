@@ -15,6 +15,11 @@
 package client
 
 import (
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+	"github.com/project-alvarium/go-store/pkg/schema"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
 	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
 	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
 )
@@ -22,9 +27,29 @@ import (
 // Requestor defines the contract used to delegate http requests.
 type Requestor func(method, path string, body []byte) (responseBody []byte, err error)
 
+// MediaRequestor defines the contract used to delegate http requests whose body is, and whose response should be,
+// encoded as contentType.
+type MediaRequestor func(method, path, contentType string, body []byte) (responseBody []byte, err error)
+
+// Version identifies the API version a client targets.
+type Version int
+
+const (
+	// Legacy targets the original /create, /append and /findByIdentity routes.
+	Legacy Version = iota
+
+	// V1 targets the resource-oriented /v1 routes.
+	V1
+)
+
 // instance is a receiver that encapsulates required dependencies.
 type instance struct {
 	requestor Requestor
+	media     MediaRequestor
+	codec     codec.Contract
+	streamer  Streamer
+	version   Version
+	schema    schema.Contract
 	mFactory  metadataFactory.Contract
 	iFactory  identityFactory.Contract
 }
@@ -33,7 +58,47 @@ type instance struct {
 func New(requestor Requestor, mFactory metadataFactory.Contract, iFactory identityFactory.Contract) *instance {
 	return &instance{
 		requestor: requestor,
+		codec:     codec.NewJSON(),
+		schema:    schema.NewDefault(),
 		mFactory:  mFactory,
 		iFactory:  iFactory,
 	}
 }
+
+// SetVersion provides for method injection of the API version targeted by Create, Append and FindByIdentity; the
+// default is Legacy.
+func (i *instance) SetVersion(version Version) {
+	i.version = version
+}
+
+// SetCodec provides for method injection of the wire format used by Create, Append and FindByIdentity and of the
+// requestor that sends it; the default is JSON sent through the instance's requestor.
+func (i *instance) SetCodec(codec codec.Contract, requestor MediaRequestor) {
+	i.codec = codec
+	i.media = requestor
+}
+
+// SetSchema provides for method injection of the metadata validators Create and Append apply before sending; the
+// default is schema.NewDefault().
+func (i *instance) SetSchema(schema schema.Contract) {
+	i.schema = schema
+}
+
+// validate returns the problem the instance's validators find with m's metadata, if any.
+func (i *instance) validate(m *annotation.Instance) error {
+	if m == nil {
+		return nil
+	}
+	if errs := i.schema.Validate(m); len(errs) > 0 {
+		return problem.ErrInvalidAnnotation.WithDetail(errs.Error())
+	}
+	return nil
+}
+
+// request delegates an http request whose body is encoded with the instance's codec.
+func (i *instance) request(method, path string, body []byte) ([]byte, error) {
+	if i.media == nil {
+		return i.requestor(method, path, body)
+	}
+	return i.media(method, path, i.codec.ContentType(), body)
+}
@@ -16,8 +16,10 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 
 	"github.com/project-alvarium/go-store/internal/pkg/routes/create"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
 
 	"github.com/project-alvarium/go-sdk/pkg/annotation"
 	"github.com/project-alvarium/go-sdk/pkg/identity"
@@ -25,28 +27,51 @@ import (
 )
 
 const (
+	createInvalid          = status.Unknown
 	createMarshalFailure   = status.Unknown
 	createRequestorFailure = status.Unknown
 	createUnmarshalFailure = status.Unknown
+	createExists           = status.Exists
 	createSuccess          = status.Success
 )
 
 // Create stores annotation corresponding to a new identity and returns status.
-func (i *instance) Create(id identity.Contract, m *annotation.Instance) (result status.Value) {
+func (i *instance) Create(id identity.Contract, m *annotation.Instance) status.Value {
+	result, _ := i.CreateWithError(id, m)
+	return result
+}
+
+// CreateWithError stores annotation corresponding to a new identity and returns status and, if it did not succeed,
+// the reason: the *problem.Instance the service reported, if any.
+func (i *instance) CreateWithError(id identity.Contract, m *annotation.Instance) (result status.Value, err error) {
 	var body, response []byte
-	var err error
 
-	if body, err = json.Marshal(m); err != nil {
-		return createMarshalFailure
+	if err = i.validate(m); err != nil {
+		return createInvalid, err
+	}
+
+	if body, err = i.codec.Marshal(m); err != nil {
+		return createMarshalFailure, err
+	}
+
+	method, path := create.Method, create.EscapedRoute(id)
+	if i.version == V1 {
+		method, path = create.V1Method, create.EscapedV1Route(id)
+	}
+	if response, err = i.request(method, path, body); err != nil {
+		if errors.Is(err, problem.ErrIdentityExists) {
+			return createExists, err
+		}
+		return createRequestorFailure, err
 	}
 
-	if response, err = i.requestor(create.Method, create.EscapedRoute(id), body); err != nil {
-		return createRequestorFailure
+	if response, err = i.codec.ToJSON(response); err != nil {
+		return createUnmarshalFailure, err
 	}
 
-	if err := json.Unmarshal(response, &result); err != nil {
-		return createUnmarshalFailure
+	if err = json.Unmarshal(response, &result); err != nil {
+		return createUnmarshalFailure, err
 	}
 
-	return
+	return result, nil
 }
@@ -21,11 +21,16 @@ import (
 	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
 	"github.com/project-alvarium/go-store/internal/pkg/routes/create"
 	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
 	"github.com/project-alvarium/go-store/pkg/http/stub"
+	"github.com/project-alvarium/go-store/pkg/schema"
 
 	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/metadata"
 	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
 	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
 	identityHash "github.com/project-alvarium/go-sdk/pkg/identity/hash"
 	"github.com/project-alvarium/go-sdk/pkg/status"
 	"github.com/project-alvarium/go-sdk/pkg/test"
@@ -80,6 +85,116 @@ func TestInstance_Create(t *testing.T) {
 				assert.Equal(t, createSuccess, result)
 			},
 		},
+		{
+			name: "success (version 1)",
+			test: func(t *testing.T) {
+				id := identityHash.New(test.FactoryRandomByteSlice())
+				idContract := url.New(id.Printable())
+				m := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+				requestor := stub.New(testInternal.Marshal(t, status.Success), nil)
+				sut := newSUT(requestor.Request)
+				sut.SetVersion(V1)
+
+				result := sut.Create(idContract, m)
+
+				assert.Equal(t, create.V1Method, requestor.RequestMethod)
+				assert.Equal(t, create.EscapedV1Route(idContract), requestor.RequestURL)
+				assert.Equal(t, testInternal.Marshal(t, m), requestor.RequestBody)
+				assert.Equal(t, createSuccess, result)
+			},
+		},
+		{
+			name: "success (CBOR)",
+			test: func(t *testing.T) {
+				id := identityHash.New(test.FactoryRandomByteSlice())
+				idContract := url.New(id.Printable())
+				m := annotation.New(ulid.New().Get(), id, nil, metadataStub.NewNullObject())
+				format := codec.NewCBOR()
+				response, _ := format.Marshal(status.Success)
+				requestor := stub.New(response, nil)
+				sut := newSUT(requestor.Request)
+				sut.SetCodec(format, requestor.MediaRequest)
+
+				result := sut.Create(idContract, m)
+
+				body, _ := format.Marshal(m)
+				assert.Equal(t, codec.ContentTypeCBOR, requestor.RequestContentType)
+				assert.Equal(t, body, requestor.RequestBody)
+				assert.Equal(t, createSuccess, result)
+			},
+		},
+		{
+			name: "invalid metadata",
+			test: func(t *testing.T) {
+				id := identityHash.New(test.FactoryRandomByteSlice())
+				m := annotation.New(ulid.New().Get(), id, nil, &pkiMetadata.Instance{SignerKind: test.FactoryRandomString()})
+				requestor := stub.New(testInternal.Marshal(t, status.Success), nil)
+				sut := newSUT(requestor.Request)
+
+				result := sut.Create(url.New(id.Printable()), m)
+
+				assert.Equal(t, createInvalid, result)
+				assert.Empty(t, requestor.RequestMethod)
+			},
+		},
+		{
+			name: "custom schema",
+			test: func(t *testing.T) {
+				id := identityHash.New(test.FactoryRandomByteSlice())
+				stubMetadata := metadataStub.NewNullObject()
+				m := annotation.New(ulid.New().Get(), id, nil, stubMetadata)
+				requestor := stub.New(testInternal.Marshal(t, status.Success), nil)
+				custom := schema.New()
+				custom.Register(
+					stubMetadata.Kind(),
+					func(metadata.Contract) schema.Errors { return schema.Errors{{Message: "is rejected"}} },
+				)
+				sut := newSUT(requestor.Request)
+				sut.SetSchema(custom)
+
+				result := sut.Create(url.New(id.Printable()), m)
+
+				assert.Equal(t, createInvalid, result)
+				assert.Empty(t, requestor.RequestMethod)
+			},
+		},
+		{
+			name: "exists",
+			test: func(t *testing.T) {
+				requestor := stub.New(nil, problem.ErrIdentityExists.WithDetail(test.FactoryRandomString()))
+				sut := newSUT(requestor.Request)
+
+				result := sut.Create(url.New(test.FactoryRandomString()), nil)
+
+				assert.Equal(t, createExists, result)
+			},
+		},
+		{
+			name: "with error reports problem",
+			test: func(t *testing.T) {
+				failure := problem.ErrIdentityExists.WithDetail(test.FactoryRandomString())
+				requestor := stub.New(nil, failure)
+				sut := newSUT(requestor.Request)
+
+				result, err := sut.CreateWithError(url.New(test.FactoryRandomString()), nil)
+
+				assert.Equal(t, createExists, result)
+				assert.Equal(t, failure, err)
+			},
+		},
+		{
+			name: "with error reports invalid metadata",
+			test: func(t *testing.T) {
+				id := identityHash.New(test.FactoryRandomByteSlice())
+				m := annotation.New(ulid.New().Get(), id, nil, &pkiMetadata.Instance{SignerKind: test.FactoryRandomString()})
+				sut := newSUT(stub.New(nil, nil).Request)
+
+				result, err := sut.CreateWithError(url.New(id.Printable()), m)
+
+				assert.Equal(t, createInvalid, result)
+				assert.True(t, errors.Is(err, problem.ErrInvalidAnnotation))
+			},
+		},
 	}
 
 	for i := range cases {
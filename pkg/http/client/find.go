@@ -16,8 +16,10 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 
 	"github.com/project-alvarium/go-store/internal/pkg/routes/find"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
 
 	"github.com/project-alvarium/go-sdk/pkg/annotation"
 	"github.com/project-alvarium/go-sdk/pkg/identity"
@@ -27,21 +29,40 @@ import (
 const (
 	findRequestorFailure = status.Unknown
 	findUnmarshalFailure = status.Unknown
+	findNotFound         = status.NotFound
 	findSuccess          = status.Success
 )
 
 // FindByIdentity returns annotations and status corresponding to identity.
 func (i *instance) FindByIdentity(id identity.Contract) ([]*annotation.Instance, status.Value) {
+	results, result, _ := i.FindByIdentityWithError(id)
+	return results, result
+}
+
+// FindByIdentityWithError returns annotations and status corresponding to identity and, if it did not succeed, the
+// reason: the *problem.Instance the service reported, if any.
+func (i *instance) FindByIdentityWithError(id identity.Contract) ([]*annotation.Instance, status.Value, error) {
 	var response []byte
 	var err error
 
-	if response, err = i.requestor(find.Method, find.EscapedRoute(id), nil); err != nil {
-		return nil, findRequestorFailure
+	method, path := find.Method, find.EscapedRoute(id)
+	if i.version == V1 {
+		method, path = find.V1Method, find.EscapedV1Route(id)
+	}
+	if response, err = i.request(method, path, nil); err != nil {
+		if errors.Is(err, problem.ErrIdentityNotFound) {
+			return nil, findNotFound, err
+		}
+		return nil, findRequestorFailure, err
+	}
+
+	if response, err = i.codec.ToJSON(response); err != nil {
+		return nil, findUnmarshalFailure, err
 	}
 
 	var values []json.RawMessage
 	if err := json.Unmarshal(response, &values); err != nil {
-		return nil, findUnmarshalFailure
+		return nil, findUnmarshalFailure, err
 	}
 
 	results := make([]*annotation.Instance, len(values))
@@ -50,11 +71,11 @@ func (i *instance) FindByIdentity(id identity.Contract) ([]*annotation.Instance,
 		value.SetMetadataFactory(i.mFactory)
 		value.SetIdentityFactory(i.iFactory)
 		if err := json.Unmarshal(values[valueIndex], &value); err != nil {
-			return nil, findUnmarshalFailure
+			return nil, findUnmarshalFailure, err
 		}
 
 		results[valueIndex] = &value
 	}
 
-	return results, findSuccess
+	return results, findSuccess, nil
 }
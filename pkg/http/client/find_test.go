@@ -21,6 +21,8 @@ import (
 	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
 	"github.com/project-alvarium/go-store/internal/pkg/routes/find"
 	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
 	"github.com/project-alvarium/go-store/pkg/http/stub"
 
 	"github.com/project-alvarium/go-sdk/pkg/annotation"
@@ -92,6 +94,74 @@ func TestInstance_FindByIdentity(t *testing.T) {
 				assert.Equal(t, findSuccess, result)
 			},
 		},
+		{
+			name: "Success (version 1)",
+			test: func(t *testing.T) {
+				requestor := stub.New(testInternal.Marshal(t, []interface{}{}), nil)
+				sut := newSUT(requestor.Request)
+				sut.SetVersion(V1)
+				idContract := url.New(test.FactoryRandomString())
+
+				value, result := sut.FindByIdentity(idContract)
+
+				assert.Equal(t, find.V1Method, requestor.RequestMethod)
+				assert.Equal(t, find.EscapedV1Route(idContract), requestor.RequestURL)
+				assert.Nil(t, requestor.RequestBody)
+				assert.Empty(t, value)
+				assert.Equal(t, findSuccess, result)
+			},
+		},
+		{
+			name: "Success (CBOR)",
+			test: func(t *testing.T) {
+				s := metadataStub.NewNullObject()
+				expected := []interface{}{
+					annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, s),
+				}
+				format := codec.NewCBOR()
+				response, _ := format.Marshal(expected)
+				requestor := stub.New(response, nil)
+				sut := newSUTWithFactories(
+					requestor.Request,
+					[]metadataFactory.Contract{
+						metadataStubFactory.New(s),
+					},
+				)
+				sut.SetCodec(format, requestor.MediaRequest)
+
+				value, result := sut.FindByIdentity(url.New(test.FactoryRandomString()))
+
+				assert.Equal(t, codec.ContentTypeCBOR, requestor.RequestContentType)
+				assert.Equal(t, testInternal.Marshal(t, expected), testInternal.Marshal(t, value))
+				assert.Equal(t, findSuccess, result)
+			},
+		},
+		{
+			name: "Not found",
+			test: func(t *testing.T) {
+				requestor := stub.New(nil, problem.ErrIdentityNotFound.WithDetail(test.FactoryRandomString()))
+				sut := newSUT(requestor.Request)
+
+				value, result := sut.FindByIdentity(url.New(test.FactoryRandomString()))
+
+				assert.Nil(t, value)
+				assert.Equal(t, findNotFound, result)
+			},
+		},
+		{
+			name: "With error reports problem",
+			test: func(t *testing.T) {
+				failure := problem.ErrForbidden.WithDetail(test.FactoryRandomString())
+				requestor := stub.New(nil, failure)
+				sut := newSUT(requestor.Request)
+
+				value, result, err := sut.FindByIdentityWithError(url.New(test.FactoryRandomString()))
+
+				assert.Nil(t, value)
+				assert.Equal(t, findRequestorFailure, result)
+				assert.Equal(t, failure, err)
+			},
+		},
 	}
 
 	for i := range cases {
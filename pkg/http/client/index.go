@@ -0,0 +1,65 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package client
+
+import (
+	"encoding/json"
+
+	indexRoute "github.com/project-alvarium/go-store/internal/pkg/routes/index"
+	"github.com/project-alvarium/go-store/pkg/http/resource"
+
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+const (
+	findByIndexRequestorFailure    = status.Unknown
+	findByIndexUnmarshalFailure    = status.Unknown
+	findByIndexSuccess             = status.Success
+	rebuildIndexesRequestorFailure = status.Unknown
+	rebuildIndexesUnmarshalFailure = status.Unknown
+)
+
+// FindByIndex returns the entries of the named secondary index matching value and status.
+func (i *instance) FindByIndex(name, value string) ([]resource.Entry, status.Value) {
+	var response []byte
+	var err error
+
+	if response, err = i.requestor(indexRoute.Method, indexRoute.EscapedRoute(name, value), nil); err != nil {
+		return nil, findByIndexRequestorFailure
+	}
+
+	var results []resource.Entry
+	if err := json.Unmarshal(response, &results); err != nil {
+		return nil, findByIndexUnmarshalFailure
+	}
+
+	return results, findByIndexSuccess
+}
+
+// RebuildIndexes recomputes every secondary index from existing data and returns status.
+func (i *instance) RebuildIndexes() (result status.Value) {
+	var response []byte
+	var err error
+
+	if response, err = i.requestor(indexRoute.RebuildMethod, indexRoute.RebuildRoute(), nil); err != nil {
+		return rebuildIndexesRequestorFailure
+	}
+
+	if err := json.Unmarshal(response, &result); err != nil {
+		return rebuildIndexesUnmarshalFailure
+	}
+
+	return
+}
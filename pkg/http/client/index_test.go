@@ -0,0 +1,133 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg/index"
+	indexRoute "github.com/project-alvarium/go-store/internal/pkg/routes/index"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/stub"
+
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInstance_FindByIndex tests FindByIndex client method.
+func TestInstance_FindByIndex(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "requestor failure",
+			test: func(t *testing.T) {
+				requestor := stub.New(nil, errors.New(""))
+				sut := newSUT(requestor.Request)
+
+				value, result := sut.FindByIndex(test.FactoryRandomString(), test.FactoryRandomString())
+
+				assert.Nil(t, value)
+				assert.Equal(t, findByIndexRequestorFailure, result)
+			},
+		},
+		{
+			name: "unmarshal failure",
+			test: func(t *testing.T) {
+				requestor := stub.New(nil, nil)
+				sut := newSUT(requestor.Request)
+
+				value, result := sut.FindByIndex(test.FactoryRandomString(), test.FactoryRandomString())
+
+				assert.Nil(t, value)
+				assert.Equal(t, findByIndexUnmarshalFailure, result)
+			},
+		},
+		{
+			name: "success",
+			test: func(t *testing.T) {
+				name := test.FactoryRandomString()
+				value := test.FactoryRandomString()
+				response := []index.Entry{{Identity: test.FactoryRandomString(), Unique: test.FactoryRandomString()}}
+				requestor := stub.New(testInternal.Marshal(t, response), nil)
+				sut := newSUT(requestor.Request)
+
+				entries, result := sut.FindByIndex(name, value)
+
+				assert.Equal(t, indexRoute.Method, requestor.RequestMethod)
+				assert.Equal(t, indexRoute.EscapedRoute(name, value), requestor.RequestURL)
+				assert.Nil(t, requestor.RequestBody)
+				assert.Equal(t, response, entries)
+				assert.Equal(t, findByIndexSuccess, result)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
+
+// TestInstance_RebuildIndexes tests RebuildIndexes client method.
+func TestInstance_RebuildIndexes(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "requestor failure",
+			test: func(t *testing.T) {
+				requestor := stub.New(nil, errors.New(""))
+				sut := newSUT(requestor.Request)
+
+				assert.Equal(t, rebuildIndexesRequestorFailure, sut.RebuildIndexes())
+			},
+		},
+		{
+			name: "unmarshal failure",
+			test: func(t *testing.T) {
+				requestor := stub.New(nil, nil)
+				sut := newSUT(requestor.Request)
+
+				assert.Equal(t, rebuildIndexesUnmarshalFailure, sut.RebuildIndexes())
+			},
+		},
+		{
+			name: "success",
+			test: func(t *testing.T) {
+				requestor := stub.New(testInternal.Marshal(t, status.Success), nil)
+				sut := newSUT(requestor.Request)
+
+				result := sut.RebuildIndexes()
+
+				assert.Equal(t, indexRoute.RebuildMethod, requestor.RequestMethod)
+				assert.Equal(t, indexRoute.RebuildRoute(), requestor.RequestURL)
+				assert.Equal(t, status.Success, result)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
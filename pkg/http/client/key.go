@@ -0,0 +1,79 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+
+	keyRoute "github.com/project-alvarium/go-store/internal/pkg/routes/key"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+	"github.com/project-alvarium/go-store/pkg/http/resource"
+
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+const (
+	keyMarshalFailure   = status.Unknown
+	keyRequestorFailure = status.Unknown
+	keyUnmarshalFailure = status.Unknown
+	keyNotFound         = status.NotFound
+	keySuccess          = status.Success
+)
+
+// CreateKey creates an API key named name with scopes, bound to tenant unless it is empty, and returns it (including
+// its secret) and status.
+func (i *instance) CreateKey(name string, scopes []resource.Scope, tenant string) (resource.Issued, status.Value) {
+	body, err := json.Marshal(keyRoute.Request{Name: name, Scopes: scopes, Tenant: tenant})
+	if err != nil {
+		return resource.Issued{}, keyMarshalFailure
+	}
+
+	response, err := i.requestor(keyRoute.CreateMethod, keyRoute.Route(), body)
+	if err != nil {
+		return resource.Issued{}, keyRequestorFailure
+	}
+
+	var issued resource.Issued
+	if err := json.Unmarshal(response, &issued); err != nil {
+		return resource.Issued{}, keyUnmarshalFailure
+	}
+	return issued, keySuccess
+}
+
+// Keys returns the API keys (without their secrets) and status.
+func (i *instance) Keys() ([]resource.Key, status.Value) {
+	response, err := i.requestor(keyRoute.Method, keyRoute.Route(), nil)
+	if err != nil {
+		return nil, keyRequestorFailure
+	}
+
+	var keys []resource.Key
+	if err := json.Unmarshal(response, &keys); err != nil {
+		return nil, keyUnmarshalFailure
+	}
+	return keys, keySuccess
+}
+
+// RevokeKey revokes the API key with the given ID and returns status.
+func (i *instance) RevokeKey(id string) status.Value {
+	if _, err := i.requestor(keyRoute.RevokeMethod, keyRoute.EscapedRevokeRoute(id), nil); err != nil {
+		if errors.Is(err, problem.ErrKeyNotFound) {
+			return keyNotFound
+		}
+		return keyRequestorFailure
+	}
+	return keySuccess
+}
@@ -0,0 +1,143 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/auth"
+	"github.com/project-alvarium/go-store/internal/pkg/auth/apikey"
+	keyRoute "github.com/project-alvarium/go-store/internal/pkg/routes/key"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+	"github.com/project-alvarium/go-store/pkg/http/stub"
+
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInstance_Keys tests API key client methods.
+func TestInstance_Keys(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "requestor failure",
+			test: func(t *testing.T) {
+				sut := newSUT(stub.New(nil, errors.New("")).Request)
+
+				_, createResult := sut.CreateKey(test.FactoryRandomString(), []auth.Scope{auth.ScopeRead}, "")
+				keys, keysResult := sut.Keys()
+
+				assert.Equal(t, keyRequestorFailure, createResult)
+				assert.Nil(t, keys)
+				assert.Equal(t, keyRequestorFailure, keysResult)
+				assert.Equal(t, keyRequestorFailure, sut.RevokeKey(test.FactoryRandomString()))
+			},
+		},
+		{
+			name: "unmarshal failure",
+			test: func(t *testing.T) {
+				sut := newSUT(stub.New(nil, nil).Request)
+
+				_, createResult := sut.CreateKey(test.FactoryRandomString(), []auth.Scope{auth.ScopeRead}, "")
+				_, keysResult := sut.Keys()
+
+				assert.Equal(t, keyUnmarshalFailure, createResult)
+				assert.Equal(t, keyUnmarshalFailure, keysResult)
+			},
+		},
+		{
+			name: "create",
+			test: func(t *testing.T) {
+				name := test.FactoryRandomString()
+				scopes := []auth.Scope{auth.ScopeRead, auth.ScopeWrite}
+				tenant := test.FactoryRandomString()
+				issued := apikey.Issued{
+					Key:    apikey.Key{ID: test.FactoryRandomString(), Name: name, Scopes: scopes, Tenant: tenant},
+					Secret: test.FactoryRandomString(),
+				}
+				requestor := stub.New(testInternal.Marshal(t, issued), nil)
+				sut := newSUT(requestor.Request)
+
+				value, result := sut.CreateKey(name, scopes, tenant)
+
+				assert.Equal(t, keyRoute.CreateMethod, requestor.RequestMethod)
+				assert.Equal(t, keyRoute.Route(), requestor.RequestURL)
+				assert.Equal(
+					t,
+					testInternal.Marshal(t, keyRoute.Request{Name: name, Scopes: scopes, Tenant: tenant}),
+					requestor.RequestBody,
+				)
+				assert.Equal(t, issued, value)
+				assert.Equal(t, keySuccess, result)
+			},
+		},
+		{
+			name: "list",
+			test: func(t *testing.T) {
+				keys := []apikey.Key{
+					{
+						ID:      test.FactoryRandomString(),
+						Name:    test.FactoryRandomString(),
+						Scopes:  []auth.Scope{auth.ScopeAdmin},
+						Created: time.Now().UTC().Truncate(time.Second),
+					},
+				}
+				requestor := stub.New(testInternal.Marshal(t, keys), nil)
+				sut := newSUT(requestor.Request)
+
+				value, result := sut.Keys()
+
+				assert.Equal(t, keyRoute.Method, requestor.RequestMethod)
+				assert.Equal(t, keyRoute.Route(), requestor.RequestURL)
+				assert.Equal(t, keys, value)
+				assert.Equal(t, keySuccess, result)
+			},
+		},
+		{
+			name: "revoke",
+			test: func(t *testing.T) {
+				id := test.FactoryRandomString()
+				requestor := stub.New(nil, nil)
+				sut := newSUT(requestor.Request)
+
+				result := sut.RevokeKey(id)
+
+				assert.Equal(t, keyRoute.RevokeMethod, requestor.RequestMethod)
+				assert.Equal(t, keyRoute.EscapedRevokeRoute(id), requestor.RequestURL)
+				assert.Equal(t, keySuccess, result)
+			},
+		},
+		{
+			name: "revoke not found",
+			test: func(t *testing.T) {
+				sut := newSUT(stub.New(nil, problem.ErrKeyNotFound.WithDetail(test.FactoryRandomString())).Request)
+
+				assert.Equal(t, keyNotFound, sut.RevokeKey(test.FactoryRandomString()))
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
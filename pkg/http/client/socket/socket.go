@@ -0,0 +1,433 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package socket
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	socketRoute "github.com/project-alvarium/go-store/internal/pkg/routes/socket"
+	"github.com/project-alvarium/go-store/pkg/http/client"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	requestTimeout   = time.Second * time.Duration(30)
+	writeWait        = time.Second * time.Duration(10)
+	marshalFailure   = status.Unknown
+	transportFailure = status.Unknown
+	unmarshalFailure = status.Unknown
+)
+
+// response is a server message whose annotations have not yet been decoded.
+type response struct {
+	ID          string            `json:"id"`
+	Kind        string            `json:"kind"`
+	Status      status.Value      `json:"status"`
+	Annotations []json.RawMessage `json:"annotations"`
+	Event       *struct {
+		Sequence   uint64          `json:"sequence"`
+		Identity   string          `json:"identity"`
+		Annotation json.RawMessage `json:"annotation"`
+	} `json:"event"`
+	Sequence uint64 `json:"sequence"`
+	Error    string `json:"error"`
+}
+
+// subscription records what a subscription selects so that it can be resumed after the server ends it.  last is
+// written as events arrive and read when resubscribing, so it is guarded by m.
+type subscription struct {
+	identity string
+	prefix   bool
+	fn       func(event *client.Event)
+	m        sync.Mutex
+	last     uint64
+}
+
+// advance records sequence as the last event received unless a later one has been.
+func (s *subscription) advance(sequence uint64) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if sequence > s.last {
+		s.last = sequence
+	}
+}
+
+// after returns the sequence of the last event received.
+func (s *subscription) after() uint64 {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.last
+}
+
+// reset forgets the last event received.
+func (s *subscription) reset() {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.last = 0
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	url           string
+	header        http.Header
+	dialer        *websocket.Dialer
+	mFactory      metadataFactory.Contract
+	iFactory      identityFactory.Contract
+	conn          *websocket.Conn
+	window        chan struct{}
+	writeM        sync.Mutex
+	m             sync.Mutex
+	pending       map[string]chan response
+	subscriptions map[string]*subscription
+	done          chan struct{}
+}
+
+// New is a factory function that returns instance; url is the service's base url (for example
+// http://localhost:8080).
+func New(url string, mFactory metadataFactory.Contract, iFactory identityFactory.Contract) *instance {
+	url = strings.Replace(strings.Replace(url, "https://", "wss://", 1), "http://", "ws://", 1)
+	return &instance{
+		url:           url + socketRoute.Route(),
+		dialer:        websocket.DefaultDialer,
+		mFactory:      mFactory,
+		iFactory:      iFactory,
+		window:        make(chan struct{}, socketRoute.Window),
+		pending:       make(map[string]chan response),
+		subscriptions: make(map[string]*subscription),
+		done:          make(chan struct{}),
+	}
+}
+
+// SetHeader provides for method injection of the header (for example, credentials) sent when the connection is
+// opened.
+func (i *instance) SetHeader(header http.Header) {
+	i.header = header
+}
+
+// SetTLSConfig provides for method injection of the TLS configuration (see requestor.NewTLSConfig) used for https
+// urls; the default trusts the system roots and presents no client certificate.
+func (i *instance) SetTLSConfig(config *tls.Config) {
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = config
+	i.dialer = &dialer
+}
+
+// Connect opens the persistent connection.
+func (i *instance) Connect(ctx context.Context) error {
+	conn, _, err := i.dialer.DialContext(ctx, i.url, i.header)
+	if err != nil {
+		return err
+	}
+	i.conn = conn
+
+	go i.read()
+	go i.ping()
+	return nil
+}
+
+// Close closes the persistent connection.
+func (i *instance) Close() error {
+	if i.conn == nil {
+		return errors.New("not connected")
+	}
+
+	i.writeM.Lock()
+	_ = i.conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(writeWait),
+	)
+	i.writeM.Unlock()
+	return i.conn.Close()
+}
+
+// read dispatches server messages until the connection fails.
+func (i *instance) read() {
+	defer func() {
+		i.m.Lock()
+		for id := range i.pending {
+			close(i.pending[id])
+			delete(i.pending, id)
+		}
+		i.m.Unlock()
+		close(i.done)
+	}()
+
+	_ = i.conn.SetReadDeadline(time.Now().Add(socketRoute.PongWait))
+	i.conn.SetPongHandler(
+		func(string) error {
+			return i.conn.SetReadDeadline(time.Now().Add(socketRoute.PongWait))
+		},
+	)
+
+	for {
+		var message response
+		if err := i.conn.ReadJSON(&message); err != nil {
+			if _, ok := err.(*json.SyntaxError); ok {
+				continue
+			}
+			return
+		}
+		_ = i.conn.SetReadDeadline(time.Now().Add(socketRoute.PongWait))
+
+		switch message.Kind {
+		case socketRoute.KindEvent:
+			i.deliver(message)
+		case socketRoute.KindEnded:
+			i.resume(message)
+		default:
+			i.m.Lock()
+			pending, exists := i.pending[message.ID]
+			delete(i.pending, message.ID)
+			i.m.Unlock()
+			if exists {
+				pending <- message
+			}
+		}
+	}
+}
+
+// ping sends keepalive pings until the connection fails.
+func (i *instance) ping() {
+	ticker := time.NewTicker(socketRoute.PingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := i.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		case <-i.done:
+			return
+		}
+	}
+}
+
+// send writes request and returns its response, waiting for room in the connection's window first.
+func (i *instance) send(request socketRoute.Request) (response, bool) {
+	if i.conn == nil {
+		return response{}, false
+	}
+
+	timeout := time.NewTimer(requestTimeout)
+	defer timeout.Stop()
+
+	select {
+	case i.window <- struct{}{}:
+		defer func() { <-i.window }()
+	case <-timeout.C:
+		return response{}, false
+	case <-i.done:
+		return response{}, false
+	}
+
+	if request.ID == "" {
+		request.ID = ulid.New().Get()
+	}
+	result := make(chan response, 1)
+	i.m.Lock()
+	i.pending[request.ID] = result
+	i.m.Unlock()
+
+	i.writeM.Lock()
+	_ = i.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	err := i.conn.WriteJSON(request)
+	i.writeM.Unlock()
+	if err != nil {
+		i.m.Lock()
+		delete(i.pending, request.ID)
+		i.m.Unlock()
+		return response{}, false
+	}
+
+	select {
+	case message, ok := <-result:
+		return message, ok
+	case <-timeout.C:
+		i.m.Lock()
+		delete(i.pending, request.ID)
+		i.m.Unlock()
+		return response{}, false
+	}
+}
+
+// decode converts a raw annotation using the instance's factories.
+func (i *instance) decode(data json.RawMessage) (*annotation.Instance, bool) {
+	var value annotation.Instance
+	value.SetMetadataFactory(i.mFactory)
+	value.SetIdentityFactory(i.iFactory)
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, false
+	}
+	return &value, true
+}
+
+// write sends an annotation-carrying operation and returns its status.
+func (i *instance) write(operation string, id identity.Contract, m *annotation.Instance) status.Value {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return marshalFailure
+	}
+
+	message, ok := i.send(
+		socketRoute.Request{
+			Operation:  operation,
+			Identity:   id.Printable(),
+			Annotation: body,
+		},
+	)
+	if !ok || message.Kind != socketRoute.KindResult {
+		return transportFailure
+	}
+	return message.Status
+}
+
+// Create stores annotation corresponding to a new identity and returns status.
+func (i *instance) Create(id identity.Contract, m *annotation.Instance) status.Value {
+	return i.write(socketRoute.OperationCreate, id, m)
+}
+
+// Append stores annotation corresponding to identity and returns status.
+func (i *instance) Append(id identity.Contract, m *annotation.Instance) status.Value {
+	return i.write(socketRoute.OperationAppend, id, m)
+}
+
+// FindByIdentity returns annotations and status corresponding to identity.
+func (i *instance) FindByIdentity(id identity.Contract) ([]*annotation.Instance, status.Value) {
+	message, ok := i.send(socketRoute.Request{Operation: socketRoute.OperationFind, Identity: id.Printable()})
+	if !ok || message.Kind != socketRoute.KindResult {
+		return nil, transportFailure
+	}
+	if message.Status != status.Success {
+		return nil, message.Status
+	}
+
+	results := make([]*annotation.Instance, len(message.Annotations))
+	for key := range message.Annotations {
+		value, ok := i.decode(message.Annotations[key])
+		if !ok {
+			return nil, unmarshalFailure
+		}
+		results[key] = value
+	}
+	return results, status.Success
+}
+
+// subscribe sends the subscribe request for s under id.
+func (i *instance) subscribe(id string, s *subscription) status.Value {
+	after := s.after()
+	message, ok := i.send(
+		socketRoute.Request{
+			ID:        id,
+			Operation: socketRoute.OperationSubscribe,
+			Identity:  s.identity,
+			Prefix:    s.prefix,
+			After:     after,
+		},
+	)
+	if ok && message.Kind == socketRoute.KindExpired && after > 0 {
+		// the events following after are no longer retained; carry on with new events.
+		s.reset()
+		s.fn(&client.Event{Reset: true})
+		return i.subscribe(id, s)
+	}
+	if !ok || message.Kind != socketRoute.KindResult {
+		return transportFailure
+	}
+	return message.Status
+}
+
+// Subscribe calls fn with each new annotation stored against an identity matching identity (or, if prefix is true,
+// beginning with identity) and returns the subscription's ID and status.  A subscription the server ends because
+// the client fell behind is resumed automatically from the last event received; fn receives a reset event if that
+// event is no longer retained.
+func (i *instance) Subscribe(identity string, prefix bool, fn func(event *client.Event)) (string, status.Value) {
+	id := ulid.New().Get()
+	s := &subscription{identity: identity, prefix: prefix, fn: fn}
+
+	i.m.Lock()
+	i.subscriptions[id] = s
+	i.m.Unlock()
+
+	result := i.subscribe(id, s)
+	if result != status.Success {
+		i.m.Lock()
+		delete(i.subscriptions, id)
+		i.m.Unlock()
+	}
+	return id, result
+}
+
+// Unsubscribe ends the subscription with the given ID and returns status.
+func (i *instance) Unsubscribe(id string) status.Value {
+	i.m.Lock()
+	delete(i.subscriptions, id)
+	i.m.Unlock()
+
+	message, ok := i.send(socketRoute.Request{Operation: socketRoute.OperationUnsubscribe, Subscription: id})
+	if !ok || message.Kind != socketRoute.KindResult {
+		return transportFailure
+	}
+	return message.Status
+}
+
+// deliver passes an event to its subscription's callback.
+func (i *instance) deliver(message response) {
+	i.m.Lock()
+	s, exists := i.subscriptions[message.ID]
+	i.m.Unlock()
+	if !exists || message.Event == nil {
+		return
+	}
+
+	value, ok := i.decode(message.Event.Annotation)
+	if !ok {
+		return
+	}
+	s.advance(message.Event.Sequence)
+	s.fn(&client.Event{Sequence: message.Event.Sequence, Identity: message.Event.Identity, Annotation: value})
+}
+
+// resume resubscribes a subscription the server ended unless the client has unsubscribed.
+func (i *instance) resume(message response) {
+	i.m.Lock()
+	s, exists := i.subscriptions[message.ID]
+	i.m.Unlock()
+	if !exists {
+		return
+	}
+
+	s.advance(message.Sequence)
+	go i.subscribe(message.ID, s)
+}
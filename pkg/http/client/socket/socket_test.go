@@ -0,0 +1,165 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package socket
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg"
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/ingest"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+	"github.com/project-alvarium/go-store/internal/pkg/routable"
+	socketRoute "github.com/project-alvarium/go-store/internal/pkg/routes/socket"
+	"github.com/project-alvarium/go-store/internal/pkg/store/memory"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/client"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	metadataStubFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub/factory"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAnnotation returns a new annotation carrying m.
+func newAnnotation(m *metadataStub.Instance) *annotation.Instance {
+	return annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, m)
+}
+
+// TestSubscription tests that a subscription's last sequence only advances and may be read while events arrive.
+func TestSubscription(t *testing.T) {
+	s := &subscription{}
+	var wg sync.WaitGroup
+	for n := uint64(1); n <= 8; n++ {
+		wg.Add(1)
+		go func(sequence uint64) {
+			defer wg.Done()
+			s.advance(sequence)
+			_ = s.after()
+		}(n)
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(8), s.after())
+	s.advance(3)
+	assert.Equal(t, uint64(8), s.after())
+	s.reset()
+	assert.Equal(t, uint64(0), s.after())
+}
+
+// TestInstance tests socket client methods against a running service.
+func TestInstance(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T, serverURL string, m *metadataStub.Instance, mFactory metadataFactory.Contract)
+	}
+
+	cases := []testCase{
+		{
+			name: "not connected",
+			test: func(t *testing.T, serverURL string, m *metadataStub.Instance, mFactory metadataFactory.Contract) {
+				sut := New(serverURL, mFactory, identityFactory.New())
+
+				assert.Equal(t, transportFailure, sut.Create(url.New(test.FactoryRandomString()), newAnnotation(m)))
+				assert.Error(t, sut.Close())
+			},
+		},
+		{
+			name: "create append find",
+			test: func(t *testing.T, serverURL string, m *metadataStub.Instance, mFactory metadataFactory.Contract) {
+				sut := New(serverURL, mFactory, identityFactory.New())
+				assert.Nil(t, sut.Connect(context.Background()))
+				defer func() { _ = sut.Close() }()
+				id := url.New(test.FactoryRandomString())
+				first := newAnnotation(m)
+				second := newAnnotation(m)
+
+				_, result := sut.FindByIdentity(id)
+				assert.Equal(t, status.NotFound, result)
+				assert.Equal(t, status.Success, sut.Create(id, first))
+				assert.Equal(t, status.Exists, sut.Create(id, first))
+				assert.Equal(t, status.Success, sut.Append(id, second))
+				annotations, result := sut.FindByIdentity(id)
+
+				assert.Equal(t, status.Success, result)
+				assert.Equal(
+					t,
+					testInternal.Marshal(t, []*annotation.Instance{first, second}),
+					testInternal.Marshal(t, annotations),
+				)
+			},
+		},
+		{
+			name: "subscribe and unsubscribe",
+			test: func(t *testing.T, serverURL string, m *metadataStub.Instance, mFactory metadataFactory.Contract) {
+				sut := New(serverURL, mFactory, identityFactory.New())
+				assert.Nil(t, sut.Connect(context.Background()))
+				defer func() { _ = sut.Close() }()
+				prefix := test.FactoryRandomString()
+				id := url.New(prefix + "/" + test.FactoryRandomString())
+				value := newAnnotation(m)
+				events := make(chan *client.Event, 1)
+
+				subscription, result := sut.Subscribe(prefix, true, func(event *client.Event) { events <- event })
+				assert.Equal(t, status.Success, result)
+				assert.Equal(t, status.Success, sut.Create(id, value))
+
+				select {
+				case event := <-events:
+					assert.Equal(t, uint64(1), event.Sequence)
+					assert.Equal(t, id.Printable(), event.Identity)
+					assert.Equal(t, testInternal.Marshal(t, value), testInternal.Marshal(t, event.Annotation))
+				case <-time.After(time.Second * 5):
+					assert.Fail(t, "event not received")
+				}
+				assert.Equal(t, status.Success, sut.Unsubscribe(subscription))
+				assert.Equal(t, status.NotFound, sut.Unsubscribe(subscription))
+			},
+		},
+	}
+
+	for i := range cases {
+		s := notify.New(memory.New(), 0)
+		m := metadataStub.NewNullObject()
+		mFactory := metadataFactory.New([]metadataFactory.Contract{metadataStubFactory.New(m)})
+		cancel, wg, muxRouter := testInternal.NewSUT(
+			pkg.Run,
+			[]routable.Contract{
+				socketRoute.New(s, s, ingest.New(mFactory, identityFactory.New(), ingest.NewDefaultConfig())).Init,
+			},
+		)
+		server := httptest.NewServer(muxRouter)
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				cases[i].test(t, server.URL, m, mFactory)
+				server.Close()
+				cancel()
+				wg.Wait()
+			},
+		)
+	}
+}
@@ -0,0 +1,178 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/routes/subscribe"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/identity"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+const (
+	subscribeStreamerMissing = status.Unknown
+	subscribeSuccess         = status.Success
+	subscribeInitialRetry    = time.Second
+	subscribeMaximumRetry    = time.Second * time.Duration(30)
+)
+
+// Streamer defines the contract used to open a long-lived http response stream.
+type Streamer func(ctx context.Context, path string, header http.Header) (io.ReadCloser, error)
+
+// Event is an annotation delivered to a subscriber.  An event with Reset set carries no annotation; it reports that
+// the service no longer retains the events following the last one delivered (for example, because it restarted), so
+// the subscriber has missed some and must reconcile by reading the store.
+type Event struct {
+	Sequence   uint64
+	Identity   string
+	Annotation *annotation.Instance
+	Reset      bool
+}
+
+// SetStreamer provides for method injection of the streamer required by Subscribe and SubscribePrefix.
+func (i *instance) SetStreamer(streamer Streamer) {
+	i.streamer = streamer
+}
+
+// Subscribe calls fn with each annotation stored against id until ctx is done, reconnecting and resuming from the
+// last received event whenever the stream fails; fn receives a reset event if that event is no longer retained.
+func (i *instance) Subscribe(ctx context.Context, id identity.Contract, fn func(event *Event)) status.Value {
+	return i.subscribe(ctx, subscribe.EscapedRoute(id), fn)
+}
+
+// SubscribePrefix calls fn with each annotation stored against an identity beginning with prefix until ctx is done,
+// reconnecting and resuming from the last received event whenever the stream fails; fn receives a reset event if
+// that event is no longer retained.
+func (i *instance) SubscribePrefix(ctx context.Context, prefix string, fn func(event *Event)) status.Value {
+	return i.subscribe(ctx, subscribe.EscapedPrefixRoute(prefix), fn)
+}
+
+// subscribe implements Subscribe and SubscribePrefix.
+func (i *instance) subscribe(ctx context.Context, path string, fn func(event *Event)) status.Value {
+	if i.streamer == nil {
+		return subscribeStreamerMissing
+	}
+
+	var lastEventID uint64
+	retry := subscribeInitialRetry
+	delay := retry
+	for {
+		header := http.Header{}
+		if lastEventID > 0 {
+			header.Set(subscribe.LastEventIDHeader, strconv.FormatUint(lastEventID, 10))
+		}
+
+		body, err := i.streamer(ctx, path, header)
+		switch {
+		case err == nil:
+			i.read(body, &lastEventID, &retry, fn)
+			_ = body.Close()
+			delay = retry
+		case lastEventID > 0 && errors.Is(err, problem.ErrEventsExpired):
+			lastEventID = 0
+			fn(&Event{Reset: true})
+			continue
+		default:
+			delay *= 2
+			if delay > subscribeMaximumRetry {
+				delay = subscribeMaximumRetry
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return subscribeSuccess
+		case <-time.After(delay):
+		}
+	}
+}
+
+// read parses server-sent events from body until it ends, delivering annotation events to fn and recording the
+// last event ID and the server's reconnection delay.
+func (i *instance) read(body io.Reader, lastEventID *uint64, retry *time.Duration, fn func(event *Event)) {
+	reader := bufio.NewReader(body)
+	var id, kind string
+	var data []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if kind == subscribe.EventName && len(data) > 0 {
+				if event, ok := i.decode(strings.Join(data, "\n")); ok {
+					fn(event)
+				}
+			}
+			if sequence, err := strconv.ParseUint(id, 10, 64); err == nil {
+				*lastEventID = sequence
+			}
+			id, kind, data = "", "", nil
+			continue
+		}
+
+		field, value := line, ""
+		if position := strings.Index(line, ":"); position >= 0 {
+			field, value = line[:position], strings.TrimPrefix(line[position+1:], " ")
+		}
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			kind = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if milliseconds, err := strconv.Atoi(value); err == nil && milliseconds > 0 {
+				*retry = time.Millisecond * time.Duration(milliseconds)
+			}
+		}
+	}
+}
+
+// decode converts an event's data into an Event.
+func (i *instance) decode(data string) (*Event, bool) {
+	var value struct {
+		Sequence   uint64          `json:"sequence"`
+		Identity   string          `json:"identity"`
+		Annotation json.RawMessage `json:"annotation"`
+	}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return nil, false
+	}
+
+	var a annotation.Instance
+	a.SetMetadataFactory(i.mFactory)
+	a.SetIdentityFactory(i.iFactory)
+	if err := json.Unmarshal(value.Annotation, &a); err != nil {
+		return nil, false
+	}
+
+	return &Event{Sequence: value.Sequence, Identity: value.Identity, Annotation: &a}, true
+}
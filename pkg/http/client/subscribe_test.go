@@ -0,0 +1,182 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/internal/pkg/identity/url"
+	"github.com/project-alvarium/go-store/internal/pkg/notify"
+	"github.com/project-alvarium/go-store/internal/pkg/routes/subscribe"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+	"github.com/project-alvarium/go-store/pkg/http/stub"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	metadataStubFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub/factory"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sseEvent returns the wire representation of event.
+func sseEvent(t *testing.T, event notify.Event) string {
+	return fmt.Sprintf(
+		"id: %d\nevent: %s\ndata: %s\n\n",
+		event.Sequence,
+		subscribe.EventName,
+		testInternal.Marshal(t, event),
+	)
+}
+
+// TestInstance_Subscribe tests Subscribe and SubscribePrefix client methods.
+func TestInstance_Subscribe(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "streamer missing",
+			test: func(t *testing.T) {
+				sut := newSUT(stub.New(nil, nil).Request)
+
+				result := sut.Subscribe(context.Background(), url.New(test.FactoryRandomString()), nil)
+
+				assert.Equal(t, subscribeStreamerMissing, result)
+			},
+		},
+		{
+			name: "reconnects and resumes",
+			test: func(t *testing.T) {
+				s := metadataStub.NewNullObject()
+				id := url.New(test.FactoryRandomString())
+				first := annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, s)
+				second := annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, s)
+				streamer := stub.NewStreamer(
+					"retry: 1\n\n"+sseEvent(t, notify.Event{Sequence: 4, Identity: id.Printable(), Annotation: first}),
+					": keepalive\n\n"+sseEvent(t, notify.Event{Sequence: 9, Identity: id.Printable(), Annotation: second}),
+				)
+				sut := newSUTWithFactories(stub.New(nil, nil).Request, []metadataFactory.Contract{metadataStubFactory.New(s)})
+				sut.SetStreamer(streamer.Stream)
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+				defer cancel()
+
+				var events []*Event
+				result := sut.Subscribe(
+					ctx,
+					id,
+					func(event *Event) {
+						events = append(events, event)
+						if len(events) == 2 {
+							cancel()
+						}
+					},
+				)
+
+				assert.Equal(t, subscribeSuccess, result)
+				assert.Equal(t, []string{subscribe.EscapedRoute(id), subscribe.EscapedRoute(id)}, streamer.RequestPaths)
+				assert.Equal(t, http.Header{}, streamer.RequestHeaders[0])
+				assert.Equal(t, "4", streamer.RequestHeaders[1].Get(subscribe.LastEventIDHeader))
+				assert.Equal(t, 2, len(events))
+				assert.Equal(t, uint64(4), events[0].Sequence)
+				assert.Equal(t, id.Printable(), events[0].Identity)
+				assert.Equal(t, testInternal.Marshal(t, first), testInternal.Marshal(t, events[0].Annotation))
+				assert.Equal(t, uint64(9), events[1].Sequence)
+				assert.Equal(t, testInternal.Marshal(t, second), testInternal.Marshal(t, events[1].Annotation))
+			},
+		},
+		{
+			name: "resets when history expired",
+			test: func(t *testing.T) {
+				s := metadataStub.NewNullObject()
+				id := url.New(test.FactoryRandomString())
+				value := annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, s)
+				bodies := []string{
+					"retry: 1\n\n" + sseEvent(t, notify.Event{Sequence: 4, Identity: id.Printable(), Annotation: value}),
+					"",
+					sseEvent(t, notify.Event{Sequence: 1, Identity: id.Printable(), Annotation: value}),
+				}
+				var headers []http.Header
+				streamer := func(_ context.Context, _ string, header http.Header) (io.ReadCloser, error) {
+					headers = append(headers, header)
+					body := bodies[len(headers)-1]
+					if body == "" {
+						return nil, problem.ErrEventsExpired
+					}
+					return ioutil.NopCloser(strings.NewReader(body)), nil
+				}
+				sut := newSUTWithFactories(stub.New(nil, nil).Request, []metadataFactory.Contract{metadataStubFactory.New(s)})
+				sut.SetStreamer(streamer)
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+				defer cancel()
+
+				var events []*Event
+				result := sut.Subscribe(
+					ctx,
+					id,
+					func(event *Event) {
+						events = append(events, event)
+						if len(events) == 3 {
+							cancel()
+						}
+					},
+				)
+
+				assert.Equal(t, subscribeSuccess, result)
+				assert.Equal(t, 3, len(headers))
+				assert.Equal(t, "4", headers[1].Get(subscribe.LastEventIDHeader))
+				assert.Equal(t, http.Header{}, headers[2])
+				assert.Equal(t, 3, len(events))
+				assert.Equal(t, uint64(4), events[0].Sequence)
+				assert.Equal(t, &Event{Reset: true}, events[1])
+				assert.Equal(t, uint64(1), events[2].Sequence)
+			},
+		},
+		{
+			name: "prefix",
+			test: func(t *testing.T) {
+				prefix := test.FactoryRandomString()
+				streamer := stub.NewStreamer("")
+				sut := newSUT(stub.New(nil, nil).Request)
+				sut.SetStreamer(streamer.Stream)
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				result := sut.SubscribePrefix(ctx, prefix, nil)
+
+				assert.Equal(t, status.Success, result)
+				assert.Equal(t, []string{subscribe.EscapedPrefixRoute(prefix)}, streamer.RequestPaths)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
@@ -0,0 +1,98 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package client
+
+import (
+	"encoding/json"
+
+	webhookRoute "github.com/project-alvarium/go-store/internal/pkg/routes/webhook"
+	"github.com/project-alvarium/go-store/pkg/http/resource"
+
+	"github.com/project-alvarium/go-sdk/pkg/status"
+)
+
+const (
+	webhookMarshalFailure   = status.Unknown
+	webhookRequestorFailure = status.Unknown
+	webhookUnmarshalFailure = status.Unknown
+	webhookSuccess          = status.Success
+)
+
+// webhookRequest sends body to path using method and unmarshals the response into result.
+func (i *instance) webhookRequest(method, path string, body []byte, result interface{}) status.Value {
+	response, err := i.requestor(method, path, body)
+	if err != nil {
+		return webhookRequestorFailure
+	}
+
+	if err := json.Unmarshal(response, result); err != nil {
+		return webhookUnmarshalFailure
+	}
+
+	return webhookSuccess
+}
+
+// RegisterWebhook registers hook and returns it as stored (including its ID and secret) and status.
+func (i *instance) RegisterWebhook(hook resource.Hook) (resource.Hook, status.Value) {
+	body, err := json.Marshal(hook)
+	if err != nil {
+		return resource.Hook{}, webhookMarshalFailure
+	}
+
+	var registered resource.Hook
+	result := i.webhookRequest(webhookRoute.RegisterMethod, webhookRoute.Route(), body, &registered)
+	if result != webhookSuccess {
+		return resource.Hook{}, result
+	}
+	return registered, webhookSuccess
+}
+
+// Webhooks returns the registered hooks (without their secrets) and status.
+func (i *instance) Webhooks() ([]resource.Hook, status.Value) {
+	var hooks []resource.Hook
+	if result := i.webhookRequest(webhookRoute.Method, webhookRoute.Route(), nil, &hooks); result != webhookSuccess {
+		return nil, result
+	}
+	return hooks, webhookSuccess
+}
+
+// RemoveWebhook unregisters the hook with the given ID and returns status.
+func (i *instance) RemoveWebhook(id string) (result status.Value) {
+	value := i.webhookRequest(webhookRoute.RemoveMethod, webhookRoute.EscapedRemoveRoute(id), nil, &result)
+	if value != webhookSuccess {
+		return value
+	}
+	return
+}
+
+// DeadLetters returns the deliveries that exhausted their retries and status.
+func (i *instance) DeadLetters() ([]resource.Delivery, status.Value) {
+	var deadLetters []resource.Delivery
+	result := i.webhookRequest(webhookRoute.DeadLettersMethod, webhookRoute.DeadLettersRoute(), nil, &deadLetters)
+	if result != webhookSuccess {
+		return nil, result
+	}
+	return deadLetters, webhookSuccess
+}
+
+// ReplayDeadLetters delivers the dead letter with the given ID (or every dead letter if id is empty) again and
+// returns status.
+func (i *instance) ReplayDeadLetters(id string) (result status.Value) {
+	value := i.webhookRequest(webhookRoute.ReplayMethod, webhookRoute.EscapedReplayRoute(id), nil, &result)
+	if value != webhookSuccess {
+		return value
+	}
+	return
+}
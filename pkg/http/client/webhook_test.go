@@ -0,0 +1,153 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package client
+
+import (
+	"errors"
+	"testing"
+
+	webhookRoute "github.com/project-alvarium/go-store/internal/pkg/routes/webhook"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+	"github.com/project-alvarium/go-store/internal/pkg/webhook"
+	"github.com/project-alvarium/go-store/pkg/http/stub"
+
+	"github.com/project-alvarium/go-sdk/pkg/status"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInstance_Webhooks tests webhook client methods.
+func TestInstance_Webhooks(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	cases := []testCase{
+		{
+			name: "requestor failure",
+			test: func(t *testing.T) {
+				sut := newSUT(stub.New(nil, errors.New("")).Request)
+
+				_, registerResult := sut.RegisterWebhook(webhook.Hook{URL: test.FactoryRandomString()})
+				hooks, hooksResult := sut.Webhooks()
+				deadLetters, deadLettersResult := sut.DeadLetters()
+
+				assert.Equal(t, webhookRequestorFailure, registerResult)
+				assert.Nil(t, hooks)
+				assert.Equal(t, webhookRequestorFailure, hooksResult)
+				assert.Nil(t, deadLetters)
+				assert.Equal(t, webhookRequestorFailure, deadLettersResult)
+				assert.Equal(t, webhookRequestorFailure, sut.RemoveWebhook(test.FactoryRandomString()))
+				assert.Equal(t, webhookRequestorFailure, sut.ReplayDeadLetters(""))
+			},
+		},
+		{
+			name: "unmarshal failure",
+			test: func(t *testing.T) {
+				sut := newSUT(stub.New(nil, nil).Request)
+
+				_, registerResult := sut.RegisterWebhook(webhook.Hook{URL: test.FactoryRandomString()})
+				_, hooksResult := sut.Webhooks()
+
+				assert.Equal(t, webhookUnmarshalFailure, registerResult)
+				assert.Equal(t, webhookUnmarshalFailure, hooksResult)
+				assert.Equal(t, webhookUnmarshalFailure, sut.RemoveWebhook(test.FactoryRandomString()))
+			},
+		},
+		{
+			name: "register",
+			test: func(t *testing.T) {
+				hook := webhook.Hook{URL: test.FactoryRandomString(), Prefixes: []string{test.FactoryRandomString()}}
+				registered := hook
+				registered.ID = test.FactoryRandomString()
+				registered.Secret = test.FactoryRandomString()
+				requestor := stub.New(testInternal.Marshal(t, registered), nil)
+				sut := newSUT(requestor.Request)
+
+				value, result := sut.RegisterWebhook(hook)
+
+				assert.Equal(t, webhookRoute.RegisterMethod, requestor.RequestMethod)
+				assert.Equal(t, webhookRoute.Route(), requestor.RequestURL)
+				assert.Equal(t, testInternal.Marshal(t, hook), requestor.RequestBody)
+				assert.Equal(t, registered, value)
+				assert.Equal(t, webhookSuccess, result)
+			},
+		},
+		{
+			name: "list",
+			test: func(t *testing.T) {
+				hooks := []webhook.Hook{{ID: test.FactoryRandomString(), URL: test.FactoryRandomString()}}
+				requestor := stub.New(testInternal.Marshal(t, hooks), nil)
+				sut := newSUT(requestor.Request)
+
+				value, result := sut.Webhooks()
+
+				assert.Equal(t, webhookRoute.Method, requestor.RequestMethod)
+				assert.Equal(t, webhookRoute.Route(), requestor.RequestURL)
+				assert.Equal(t, hooks, value)
+				assert.Equal(t, webhookSuccess, result)
+			},
+		},
+		{
+			name: "remove",
+			test: func(t *testing.T) {
+				id := test.FactoryRandomString()
+				requestor := stub.New(testInternal.Marshal(t, status.NotFound), nil)
+				sut := newSUT(requestor.Request)
+
+				result := sut.RemoveWebhook(id)
+
+				assert.Equal(t, webhookRoute.RemoveMethod, requestor.RequestMethod)
+				assert.Equal(t, webhookRoute.EscapedRemoveRoute(id), requestor.RequestURL)
+				assert.Equal(t, status.NotFound, result)
+			},
+		},
+		{
+			name: "dead letters",
+			test: func(t *testing.T) {
+				deadLetters := []webhook.Delivery{{ID: test.FactoryRandomString(), Body: []byte("{}"), Attempts: 5}}
+				requestor := stub.New(testInternal.Marshal(t, deadLetters), nil)
+				sut := newSUT(requestor.Request)
+
+				value, result := sut.DeadLetters()
+
+				assert.Equal(t, webhookRoute.DeadLettersMethod, requestor.RequestMethod)
+				assert.Equal(t, webhookRoute.DeadLettersRoute(), requestor.RequestURL)
+				assert.Equal(t, deadLetters, value)
+				assert.Equal(t, webhookSuccess, result)
+			},
+		},
+		{
+			name: "replay",
+			test: func(t *testing.T) {
+				id := test.FactoryRandomString()
+				requestor := stub.New(testInternal.Marshal(t, status.Success), nil)
+				sut := newSUT(requestor.Request)
+
+				result := sut.ReplayDeadLetters(id)
+
+				assert.Equal(t, webhookRoute.ReplayMethod, requestor.RequestMethod)
+				assert.Equal(t, webhookRoute.EscapedReplayRoute(id), requestor.RequestURL)
+				assert.Equal(t, status.Success, result)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
@@ -0,0 +1,203 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	ugorji "github.com/ugorji/go/codec"
+)
+
+const (
+	ContentTypeHeader  = "Content-Type"
+	AcceptHeader       = "Accept"
+	ContentTypeJSON    = "application/json"
+	ContentTypeCBOR    = "application/cbor"
+	ContentTypeMsgPack = "application/msgpack"
+)
+
+// Contract defines a wire format.  Values are encoded directly from their JSON-tagged fields; encoded data is
+// decoded by transcoding it to JSON so that annotations are still decoded through the registered metadata and
+// identity factories.
+type Contract interface {
+	// ContentType returns the format's media type.
+	ContentType() string
+
+	// Marshal returns value encoded in the format.
+	Marshal(value interface{}) ([]byte, error)
+
+	// ToJSON returns data, encoded in the format, transcoded to JSON.
+	ToJSON(data []byte) ([]byte, error)
+}
+
+// jsonCodec is a receiver that implements the JSON format.
+type jsonCodec struct{}
+
+// NewJSON is a factory function that returns the JSON format.
+func NewJSON() *jsonCodec {
+	return &jsonCodec{}
+}
+
+// ContentType returns the format's media type.
+func (*jsonCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+// Marshal returns value encoded in the format.
+func (*jsonCodec) Marshal(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// ToJSON returns data, encoded in the format, transcoded to JSON.
+func (*jsonCodec) ToJSON(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// binary is a receiver that implements a binary format; byte slices are encoded natively rather than as base64.
+type binary struct {
+	contentType string
+	handle      ugorji.Handle
+}
+
+// mapType is the type into which binary maps are decoded so that they can be transcoded to JSON.
+var mapType = reflect.TypeOf(map[string]interface{}(nil))
+
+// NewCBOR is a factory function that returns the CBOR format.
+func NewCBOR() *binary {
+	handle := &ugorji.CborHandle{}
+	handle.MapType = mapType
+	return &binary{
+		contentType: ContentTypeCBOR,
+		handle:      handle,
+	}
+}
+
+// NewMsgPack is a factory function that returns the MessagePack format.
+func NewMsgPack() *binary {
+	handle := &ugorji.MsgpackHandle{WriteExt: true}
+	handle.MapType = mapType
+	return &binary{
+		contentType: ContentTypeMsgPack,
+		handle:      handle,
+	}
+}
+
+// ContentType returns the format's media type.
+func (b *binary) ContentType() string {
+	return b.contentType
+}
+
+// Marshal returns value encoded in the format.
+func (b *binary) Marshal(value interface{}) ([]byte, error) {
+	var result []byte
+	if err := ugorji.NewEncoderBytes(&result, b.handle).Encode(value); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ToJSON returns data, encoded in the format, transcoded to JSON; byte slices become base64 strings.  Data that
+// continues after the first value is rejected.
+func (b *binary) ToJSON(data []byte) ([]byte, error) {
+	var value interface{}
+	decoder := ugorji.NewDecoderBytes(data, b.handle)
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.NumBytesRead() != len(data) {
+		return nil, errors.New("unexpected data after " + b.contentType + " value")
+	}
+	return json.Marshal(value)
+}
+
+// codecs defines the supported formats; the first is the default.
+var codecs = []Contract{
+	NewJSON(),
+	NewCBOR(),
+	NewMsgPack(),
+}
+
+// ForContentType returns the format named by a Content-Type header value and whether it is supported; an empty
+// value selects JSON.
+func ForContentType(value string) (Contract, bool) {
+	if value == "" {
+		return codecs[0], true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		return nil, false
+	}
+	for key := range codecs {
+		if codecs[key].ContentType() == mediaType {
+			return codecs[key], true
+		}
+	}
+	return nil, false
+}
+
+// ForAccept returns the supported format most preferred by an Accept header value; fallback is returned if value is
+// empty or names no supported format, and wildcards select fallback.
+func ForAccept(value string, fallback Contract) Contract {
+	var result Contract
+	best := 0.0
+	for _, accepted := range strings.Split(value, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, exists := params["q"]; exists {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality <= best {
+			continue
+		}
+
+		if mediaType == "*/*" || mediaType == "application/*" {
+			result, best = fallback, quality
+			continue
+		}
+		for key := range codecs {
+			if codecs[key].ContentType() == mediaType {
+				result, best = codecs[key], quality
+				break
+			}
+		}
+	}
+
+	if result == nil {
+		return fallback
+	}
+	return result
+}
+
+// Negotiate returns the format of r's body and whether it is supported, and the format its response should use:
+// the one preferred by r's Accept header, or otherwise the format of r's body.
+func Negotiate(r *http.Request) (decoder, encoder Contract, ok bool) {
+	if decoder, ok = ForContentType(r.Header.Get(ContentTypeHeader)); !ok {
+		return nil, ForAccept(r.Header.Get(AcceptHeader), codecs[0]), false
+	}
+	return decoder, ForAccept(r.Header.Get(AcceptHeader), decoder), true
+}
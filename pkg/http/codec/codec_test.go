@@ -0,0 +1,213 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package codec
+
+import (
+	"crypto"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/metadata"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	assessorMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/assessor/pki/metadata"
+	assessMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata"
+	assessMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata/factory"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
+	pkiMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata/factory"
+	signerMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/signer/signpkcs1v15/metadata"
+	publishMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata"
+	publishMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata/factory"
+	publisherMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/publisher/example/metadata"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// marshal returns value marshalled to JSON.
+func marshal(t *testing.T, value interface{}) []byte {
+	result, err := json.Marshal(value)
+	if err != nil {
+		assert.FailNow(t, "Unexpected marshal failure:", err.Error())
+	}
+	return result
+}
+
+// TestRoundTrip tests that annotations of every metadata kind survive encoding and decoding in every format.
+func TestRoundTrip(t *testing.T) {
+	kinds := map[string]metadata.Contract{
+		assessMetadata.Kind: assessMetadata.New(
+			nil,
+			assessorMetadata.NewSuccess(true, []string{test.FactoryRandomString()}),
+		),
+		pkiMetadata.Kind: pkiMetadata.New(
+			nil,
+			test.FactoryRandomByteSlice(),
+			test.FactoryRandomByteSlice(),
+			test.FactoryRandomByteSlice(),
+			signerMetadata.NewSuccess(crypto.SHA256, test.FactoryRandomString()),
+		),
+		publishMetadata.Kind: publishMetadata.New(nil, publisherMetadata.NewSuccess()),
+	}
+	mFactory := metadataFactory.New(
+		[]metadataFactory.Contract{
+			assessMetadataFactory.NewDefault(),
+			pkiMetadataFactory.NewDefault(),
+			publishMetadataFactory.NewDefault(),
+		},
+	)
+
+	for _, format := range []Contract{NewJSON(), NewCBOR(), NewMsgPack()} {
+		for kind := range kinds {
+			current := hash.New(test.FactoryRandomByteSlice())
+			previous := hash.New(test.FactoryRandomByteSlice())
+			value := annotation.New(ulid.New().Get(), current, previous, kinds[kind])
+			format := format
+			t.Run(
+				format.ContentType()+"/"+kind,
+				func(t *testing.T) {
+					encoded, err := format.Marshal(value)
+					assert.Nil(t, err)
+
+					transcoded, err := format.ToJSON(encoded)
+					assert.Nil(t, err)
+
+					var decoded annotation.Instance
+					decoded.SetMetadataFactory(mFactory)
+					decoded.SetIdentityFactory(identityFactory.New())
+					assert.Nil(t, json.Unmarshal(transcoded, &decoded))
+					assert.Equal(t, marshal(t, value), marshal(t, &decoded))
+				},
+			)
+		}
+	}
+}
+
+// TestBinaryIsNotJSON tests that binary formats do not emit JSON.
+func TestBinaryIsNotJSON(t *testing.T) {
+	for _, format := range []Contract{NewCBOR(), NewMsgPack()} {
+		encoded, err := format.Marshal(map[string]interface{}{"key": []byte{1, 2, 3}})
+
+		assert.Nil(t, err)
+		assert.False(t, json.Valid(encoded), format.ContentType())
+	}
+}
+
+// TestToJSONFailure tests that truncated data is rejected.
+func TestToJSONFailure(t *testing.T) {
+	for _, format := range []Contract{NewCBOR(), NewMsgPack()} {
+		encoded, _ := format.Marshal(map[string]interface{}{"key": test.FactoryRandomString()})
+		_, err := format.ToJSON(encoded[:len(encoded)-1])
+
+		assert.NotNil(t, err, format.ContentType())
+	}
+}
+
+// TestToJSONTrailingData tests that data after the first value is rejected.
+func TestToJSONTrailingData(t *testing.T) {
+	for _, format := range []Contract{NewCBOR(), NewMsgPack()} {
+		encoded, _ := format.Marshal(test.FactoryRandomString())
+		_, err := format.ToJSON(append(encoded, encoded...))
+
+		assert.NotNil(t, err, format.ContentType())
+	}
+}
+
+// TestForContentType tests selection of a format by Content-Type.
+func TestForContentType(t *testing.T) {
+	type testCase struct {
+		name     string
+		value    string
+		expected string
+		ok       bool
+	}
+
+	cases := []testCase{
+		{name: "empty", value: "", expected: ContentTypeJSON, ok: true},
+		{name: "json", value: "application/json; charset=utf-8", expected: ContentTypeJSON, ok: true},
+		{name: "cbor", value: ContentTypeCBOR, expected: ContentTypeCBOR, ok: true},
+		{name: "msgpack", value: ContentTypeMsgPack, expected: ContentTypeMsgPack, ok: true},
+		{name: "unsupported", value: "text/plain", ok: false},
+		{name: "malformed", value: ";", ok: false},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				result, ok := ForContentType(cases[i].value)
+
+				assert.Equal(t, cases[i].ok, ok)
+				if ok {
+					assert.Equal(t, cases[i].expected, result.ContentType())
+				}
+			},
+		)
+	}
+}
+
+// TestForAccept tests selection of a format by Accept.
+func TestForAccept(t *testing.T) {
+	type testCase struct {
+		name     string
+		value    string
+		expected string
+	}
+
+	cases := []testCase{
+		{name: "empty", value: "", expected: ContentTypeCBOR},
+		{name: "exact", value: ContentTypeMsgPack, expected: ContentTypeMsgPack},
+		{name: "wildcard", value: "*/*", expected: ContentTypeCBOR},
+		{name: "unsupported", value: "text/html", expected: ContentTypeCBOR},
+		{
+			name:     "quality",
+			value:    ContentTypeJSON + ";q=0.5, " + ContentTypeMsgPack + ";q=0.9, */*;q=0.1",
+			expected: ContentTypeMsgPack,
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				assert.Equal(t, cases[i].expected, ForAccept(cases[i].value, NewCBOR()).ContentType())
+			},
+		)
+	}
+}
+
+// TestNegotiate tests selection of request and response formats.
+func TestNegotiate(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set(ContentTypeHeader, ContentTypeCBOR)
+
+	decoder, encoder, ok := Negotiate(r)
+	assert.True(t, ok)
+	assert.Equal(t, ContentTypeCBOR, decoder.ContentType())
+	assert.Equal(t, ContentTypeCBOR, encoder.ContentType())
+
+	r.Header.Set(AcceptHeader, ContentTypeMsgPack)
+	_, encoder, ok = Negotiate(r)
+	assert.True(t, ok)
+	assert.Equal(t, ContentTypeMsgPack, encoder.ContentType())
+
+	r.Header.Set(ContentTypeHeader, "text/plain")
+	_, _, ok = Negotiate(r)
+	assert.False(t, ok)
+}
@@ -0,0 +1,65 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package header
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewRequestID tests request ID generation.
+func TestNewRequestID(t *testing.T) {
+	first := NewRequestID()
+
+	assert.Len(t, first, 32)
+	assert.NotEqual(t, first, NewRequestID())
+}
+
+// TestParseRetryAfter tests reading the delay a Retry-After header value asks for.
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	type testCase struct {
+		name  string
+		value string
+		delay time.Duration
+		ok    bool
+	}
+
+	cases := []testCase{
+		{name: "seconds", value: "2", delay: 2 * time.Second, ok: true},
+		{name: "zero", value: "0", ok: true},
+		{name: "date", value: now.Add(5 * time.Second).Format(http.TimeFormat), delay: 5 * time.Second, ok: true},
+		{name: "past date", value: now.Add(-time.Second).Format(http.TimeFormat), ok: true},
+		{name: "negative", value: "-1"},
+		{name: "empty", value: ""},
+		{name: "malformed", value: "soon"},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				delay, ok := ParseRetryAfter(cases[i].value, now)
+
+				assert.Equal(t, cases[i].delay, delay)
+				assert.Equal(t, cases[i].ok, ok)
+			},
+		)
+	}
+}
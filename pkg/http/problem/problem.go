@@ -0,0 +1,161 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	ContentType       = "application/problem+json"
+	contentTypeHeader = "Content-Type"
+	typePrefix        = "urn:alvarium:store:problem:"
+)
+
+// Machine-readable problem codes.
+const (
+	CodeMalformedBody        = "malformed-body"
+	CodeInvalidAnnotation    = "invalid-annotation"
+	CodeUnsupportedMediaType = "unsupported-media-type"
+	CodeBodyTooLarge         = "body-too-large"
+	CodeIdentityExists       = "identity-exists"
+	CodeIdentityNotFound     = "identity-not-found"
+	CodeIdentityMismatch     = "identity-mismatch"
+	CodeUnauthenticated      = "unauthenticated"
+	CodeForbidden            = "forbidden"
+	CodeKeyNotFound          = "key-not-found"
+	CodeInvalidKey           = "invalid-key"
+	CodeTenantExists         = "tenant-exists"
+	CodeTenantNotFound       = "tenant-not-found"
+	CodeTenantSuspended      = "tenant-suspended"
+	CodeInvalidTenant        = "invalid-tenant"
+	CodeQuotaExceeded        = "quota-exceeded"
+	CodeRateLimited          = "rate-limited"
+	CodeOverloaded           = "overloaded"
+	CodeEventsExpired        = "events-expired"
+	CodeInvalidQuery         = "invalid-query"
+	CodeIndexNotFound        = "index-not-found"
+	CodeInvalidPolicy        = "invalid-policy"
+	CodeInternal             = "internal"
+)
+
+// InvalidParam identifies a request field and why it was rejected.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Instance is an RFC 7807 problem document; Code is an extension member that identifies the kind of problem and
+// InvalidParams one that lists the fields responsible for it.  It implements error so that clients can return it, and
+// errors.Is matches any Instance with the same Code.
+type Instance struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	Code          string         `json:"code"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+// Known problems; responses refine them with WithDetail.
+var (
+	ErrMalformedBody        = New(http.StatusBadRequest, CodeMalformedBody, "request body is malformed")
+	ErrInvalidAnnotation    = New(http.StatusUnprocessableEntity, CodeInvalidAnnotation, "annotation is invalid")
+	ErrUnsupportedMediaType = New(http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "media type is not supported")
+	ErrBodyTooLarge         = New(http.StatusRequestEntityTooLarge, CodeBodyTooLarge, "request body is too large")
+	ErrIdentityExists       = New(http.StatusConflict, CodeIdentityExists, "identity already exists")
+	ErrIdentityNotFound     = New(http.StatusNotFound, CodeIdentityNotFound, "identity not found")
+	ErrIdentityMismatch     = New(http.StatusUnprocessableEntity, CodeIdentityMismatch, "identity does not match url")
+	ErrUnauthenticated      = New(http.StatusUnauthorized, CodeUnauthenticated, "authentication required")
+	ErrForbidden            = New(http.StatusForbidden, CodeForbidden, "access denied")
+	ErrKeyNotFound          = New(http.StatusNotFound, CodeKeyNotFound, "key not found")
+	ErrInvalidKey           = New(http.StatusUnprocessableEntity, CodeInvalidKey, "key request is invalid")
+	ErrTenantExists         = New(http.StatusConflict, CodeTenantExists, "tenant already exists")
+	ErrTenantNotFound       = New(http.StatusNotFound, CodeTenantNotFound, "tenant not found")
+	ErrTenantSuspended      = New(http.StatusForbidden, CodeTenantSuspended, "tenant is suspended")
+	ErrInvalidTenant        = New(http.StatusUnprocessableEntity, CodeInvalidTenant, "tenant request is invalid")
+	ErrQuotaExceeded        = New(http.StatusForbidden, CodeQuotaExceeded, "tenant quota exceeded")
+	ErrRateLimited          = New(http.StatusTooManyRequests, CodeRateLimited, "rate limit exceeded")
+	ErrOverloaded           = New(http.StatusTooManyRequests, CodeOverloaded, "too many requests in flight")
+	ErrEventsExpired        = New(http.StatusGone, CodeEventsExpired, "events are no longer retained")
+	ErrInvalidQuery         = New(http.StatusBadRequest, CodeInvalidQuery, "request query is invalid")
+	ErrIndexNotFound        = New(http.StatusNotFound, CodeIndexNotFound, "index not found")
+	ErrInvalidPolicy        = New(http.StatusUnprocessableEntity, CodeInvalidPolicy, "score policy is invalid")
+	ErrInternal             = New(http.StatusInternalServerError, CodeInternal, "internal error")
+)
+
+// New is a factory function that returns a problem of kind code reported with status.
+func New(status int, code, title string) *Instance {
+	return &Instance{
+		Type:   typePrefix + code,
+		Title:  title,
+		Status: status,
+		Code:   code,
+	}
+}
+
+// WithDetail returns a copy of i that explains this occurrence of the problem.
+func (i *Instance) WithDetail(detail string) *Instance {
+	result := *i
+	result.Detail = detail
+	return &result
+}
+
+// WithInvalidParams returns a copy of i that lists the fields responsible for this occurrence of the problem.
+func (i *Instance) WithInvalidParams(params []InvalidParam) *Instance {
+	result := *i
+	result.InvalidParams = params
+	return &result
+}
+
+// Error returns the problem's title and detail.
+func (i *Instance) Error() string {
+	if i.Detail == "" {
+		return i.Title
+	}
+	return i.Title + ": " + i.Detail
+}
+
+// Is returns whether target is a problem of the same kind.
+func (i *Instance) Is(target error) bool {
+	t, ok := target.(*Instance)
+	return ok && t.Code == i.Code
+}
+
+// Write writes p as the response to r.
+func Write(w http.ResponseWriter, r *http.Request, p *Instance) {
+	result := *p
+	result.Instance = r.URL.Path
+	body, err := json.Marshal(&result)
+	if err != nil {
+		w.WriteHeader(p.Status)
+		return
+	}
+
+	w.Header().Set(contentTypeHeader, ContentType)
+	w.WriteHeader(p.Status)
+	_, _ = w.Write(body)
+}
+
+// Decode returns the problem document contained in body.
+func Decode(body []byte) (*Instance, error) {
+	var result Instance
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,76 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package problem
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInstance_Is tests that problems match by code.
+func TestInstance_Is(t *testing.T) {
+	var err error = ErrIdentityExists.WithDetail(test.FactoryRandomString())
+
+	assert.True(t, errors.Is(err, ErrIdentityExists))
+	assert.False(t, errors.Is(err, ErrIdentityNotFound))
+	assert.False(t, errors.Is(errors.New(ErrIdentityExists.Title), ErrIdentityExists))
+}
+
+// TestInstance_WithDetail tests that details do not modify the original problem.
+func TestInstance_WithDetail(t *testing.T) {
+	detail := test.FactoryRandomString()
+
+	result := ErrMalformedBody.WithDetail(detail)
+
+	assert.Equal(t, detail, result.Detail)
+	assert.Empty(t, ErrMalformedBody.Detail)
+	assert.Equal(t, ErrMalformedBody.Title+": "+detail, result.Error())
+}
+
+// TestInstance_WithInvalidParams tests that invalid params do not modify the original problem and survive encoding.
+func TestInstance_WithInvalidParams(t *testing.T) {
+	params := []InvalidParam{{Name: test.FactoryRandomString(), Reason: test.FactoryRandomString()}}
+	w := httptest.NewRecorder()
+
+	Write(w, httptest.NewRequest(http.MethodPost, "/", nil), ErrInvalidAnnotation.WithInvalidParams(params))
+
+	assert.Empty(t, ErrInvalidAnnotation.InvalidParams)
+	result, err := Decode(w.Body.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, params, result.InvalidParams)
+}
+
+// TestWrite tests that a written problem decodes to the original.
+func TestWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/v1/identities/x", nil)
+	p := ErrIdentityNotFound.WithDetail(test.FactoryRandomString())
+
+	Write(w, r, p)
+
+	assert.Equal(t, p.Status, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get(contentTypeHeader))
+	result, err := Decode(w.Body.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, "/v1/identities/x", result.Instance)
+	result.Instance = ""
+	assert.Equal(t, p, result)
+}
@@ -16,42 +16,133 @@ package requestor
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"errors"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"time"
+
+	"github.com/project-alvarium/go-store/pkg/http/codec"
+	"github.com/project-alvarium/go-store/pkg/http/header"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
 )
 
+// maxRetryAfter is the longest delay a response may ask for and still be retried.
+const maxRetryAfter = time.Minute
+
 // instance is a receiver that encapsulates required dependencies.
 type instance struct {
-	url string
+	url     string
+	apiKey  string
+	token   string
+	client  *http.Client
+	stream  *http.Client
+	retries int
+	sleep   func(ctx context.Context, d time.Duration) error
 }
 
 // New is a factory function that returns instance.
 func New(url string) *instance {
 	return &instance{
 		url: url,
+		client: &http.Client{
+			Timeout: time.Second * time.Duration(30),
+		},
+		stream:  &http.Client{},
+		retries: 3,
+		sleep:   sleep,
 	}
 }
 
-// Handler encapsulates making an http request of method to url with body.
-func (i *instance) Handler(method, path string, body []byte) (responseBody []byte, err error) {
-	var reader io.Reader
-	var request *http.Request
-	var response *http.Response
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-	if len(body) > 0 {
-		reader = bytes.NewReader(body)
-	}
-	if request, err = http.NewRequest(method, i.url+path, reader); err != nil {
-		return
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
+}
+
+// SetTLSConfig provides for method injection of the TLS configuration (see NewTLSConfig) used for https urls; the
+// default trusts the system roots and presents no client certificate.
+func (i *instance) SetTLSConfig(config *tls.Config) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	i.client.Transport = transport
+	i.stream.Transport = transport
+}
+
+// SetAPIKey provides for method injection of the API key sent with every request; the default is to send none.
+func (i *instance) SetAPIKey(key string) {
+	i.apiKey = key
+}
+
+// SetBearerToken provides for method injection of the bearer token (for example, an OIDC access token) sent with
+// every request; the default is to send none.
+func (i *instance) SetBearerToken(token string) {
+	i.token = token
+}
 
-	client := &http.Client{
-		Timeout: time.Second * time.Duration(30),
+// SetRetries provides for method injection of the number of times a request is retried when the service refuses it
+// with 429 Too Many Requests or 503 Service Unavailable and a Retry-After header, after the delay the header asks for
+// (delays over a minute are not waited for); the default is 3.
+func (i *instance) SetRetries(retries int) {
+	i.retries = retries
+}
+
+// authorize adds the instance's credentials, if any, to request.
+func (i *instance) authorize(request *http.Request) {
+	if i.apiKey != "" {
+		request.Header.Set(header.APIKey, i.apiKey)
 	}
-	if response, err = client.Do(request); err != nil {
+	if i.token != "" {
+		request.Header.Set("Authorization", "Bearer "+i.token)
+	}
+}
+
+// Handler encapsulates making an http request of method to url with body.
+func (i *instance) Handler(method, path string, body []byte) (responseBody []byte, err error) {
+	return i.do(method, path, nil, body)
+}
+
+// MediaHandler encapsulates making an http request of method to url with body encoded as contentType; the response
+// is requested in the same format.
+func (i *instance) MediaHandler(method, path, contentType string, body []byte) (responseBody []byte, err error) {
+	header := http.Header{}
+	header.Set(codec.ContentTypeHeader, contentType)
+	header.Set(codec.AcceptHeader, contentType)
+	return i.do(method, path, header, body)
+}
+
+// do makes an http request of method to url with header and body and returns the response body.
+func (i *instance) do(method, path string, header http.Header, body []byte) (responseBody []byte, err error) {
+	response, err := i.send(
+		context.Background(),
+		i.client,
+		func() (*http.Request, error) {
+			var reader io.Reader
+			if len(body) > 0 {
+				reader = bytes.NewReader(body)
+			}
+			request, err := http.NewRequest(method, i.url+path, reader)
+			if err != nil {
+				return nil, err
+			}
+			for key := range header {
+				request.Header[key] = header[key]
+			}
+			i.authorize(request)
+			return request, nil
+		},
+	)
+	if err != nil {
 		return
 	}
 
@@ -59,13 +150,103 @@ func (i *instance) Handler(method, path string, body []byte) (responseBody []byt
 		_ = response.Body.Close()
 	}()
 
-	if response.StatusCode != http.StatusOK {
-		return nil, errors.New("response.StatusCode != http.StatusOK")
-	}
-
 	if responseBody, err = ioutil.ReadAll(response.Body); err != nil {
 		return
 	}
 
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return nil, failure(response, responseBody)
+	}
+
 	return responseBody, nil
 }
+
+// send makes the request build returns with client, building and making it again after the delay a response's
+// Retry-After header asks for, up to the configured number of retries.  Every attempt carries the same X-Request-ID
+// header, unless build sets its own.
+func (i *instance) send(
+	ctx context.Context,
+	client *http.Client,
+	build func() (*http.Request, error)) (*http.Response, error) {
+
+	id := header.NewRequestID()
+	for attempt := 0; ; attempt++ {
+		request, err := build()
+		if err != nil {
+			return nil, err
+		}
+		if request.Header.Get(header.RequestID) == "" {
+			request.Header.Set(header.RequestID, id)
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			return nil, err
+		}
+
+		delay, retry := retryAfter(response, time.Now())
+		if !retry || attempt >= i.retries {
+			return response, nil
+		}
+		_, _ = io.Copy(ioutil.Discard, response.Body)
+		_ = response.Body.Close()
+		if err := i.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter returns the delay, as of now, that response asks for before its request is retried, or false if the
+// request should not be retried.
+func retryAfter(response *http.Response, now time.Time) (time.Duration, bool) {
+	if response.StatusCode != http.StatusTooManyRequests && response.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	delay, ok := header.ParseRetryAfter(response.Header.Get(header.RetryAfter), now)
+	if !ok {
+		return 0, false
+	}
+	return delay, delay <= maxRetryAfter
+}
+
+// failure returns the error reported by an unsuccessful response: the problem its body describes, if any.
+func failure(response *http.Response, body []byte) error {
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get(codec.ContentTypeHeader))
+	if mediaType == problem.ContentType {
+		if result, err := problem.Decode(body); err == nil {
+			return result
+		}
+	}
+	return errors.New("unexpected response " + response.Status)
+}
+
+// Stream opens a long-lived GET request to path with header and returns the response body; the request ends when
+// ctx is done or the caller closes the body.  An unsuccessful response's error is the problem it describes, if any.
+func (i *instance) Stream(ctx context.Context, path string, header http.Header) (io.ReadCloser, error) {
+	response, err := i.send(
+		ctx,
+		i.stream,
+		func() (*http.Request, error) {
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, i.url+path, nil)
+			if err != nil {
+				return nil, err
+			}
+			for key := range header {
+				request.Header[key] = header[key]
+			}
+			i.authorize(request)
+			return request, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(response.Body)
+		_ = response.Body.Close()
+		return nil, failure(response, body)
+	}
+
+	return response.Body, nil
+}
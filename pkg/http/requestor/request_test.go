@@ -0,0 +1,200 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package requestor
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/project-alvarium/go-store/pkg/http/header"
+	"github.com/project-alvarium/go-store/pkg/http/problem"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRetryAfter tests reading the delay a refused request asks for.
+func TestRetryAfter(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	response := func(code int, value string) *http.Response {
+		h := http.Header{}
+		if value != "" {
+			h.Set(header.RetryAfter, value)
+		}
+		return &http.Response{StatusCode: code, Header: h}
+	}
+
+	type testCase struct {
+		name     string
+		response *http.Response
+		delay    time.Duration
+		retry    bool
+	}
+
+	cases := []testCase{
+		{name: "seconds", response: response(http.StatusTooManyRequests, "2"), delay: 2 * time.Second, retry: true},
+		{name: "unavailable", response: response(http.StatusServiceUnavailable, "0"), retry: true},
+		{
+			name:     "date",
+			response: response(http.StatusTooManyRequests, now.Add(5*time.Second).Format(http.TimeFormat)),
+			delay:    5 * time.Second,
+			retry:    true,
+		},
+		{
+			name:     "past date",
+			response: response(http.StatusTooManyRequests, now.Add(-time.Second).Format(http.TimeFormat)),
+			retry:    true,
+		},
+		{name: "too long", response: response(http.StatusTooManyRequests, "3600"), delay: time.Hour},
+		{name: "no header", response: response(http.StatusTooManyRequests, "")},
+		{name: "malformed", response: response(http.StatusTooManyRequests, "soon")},
+		{name: "other status", response: response(http.StatusBadRequest, "1")},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				delay, retry := retryAfter(cases[i].response, now)
+
+				assert.Equal(t, cases[i].delay, delay)
+				assert.Equal(t, cases[i].retry, retry)
+			},
+		)
+	}
+}
+
+// TestInstance_Retry tests retrying requests the service refuses with Retry-After.
+func TestInstance_Retry(t *testing.T) {
+	type testCase struct {
+		name string
+		test func(t *testing.T)
+	}
+
+	// refuse returns a server that refuses the first refusals requests and counts the requests it receives.
+	refuse := func(refusals int32, requests *int32) *httptest.Server {
+		return httptest.NewServer(
+			http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					body, _ := ioutil.ReadAll(r.Body)
+					if atomic.AddInt32(requests, 1) <= refusals {
+						w.Header().Set(header.RetryAfter, "1")
+						problem.Write(w, r, problem.ErrRateLimited)
+						return
+					}
+					_, _ = w.Write(body)
+				},
+			),
+		)
+	}
+
+	cases := []testCase{
+		{
+			name: "retried",
+			test: func(t *testing.T) {
+				var requests int32
+				httpServer := refuse(2, &requests)
+				defer httpServer.Close()
+				sut := New(httpServer.URL)
+				var delays []time.Duration
+				sut.sleep = func(_ context.Context, d time.Duration) error {
+					delays = append(delays, d)
+					return nil
+				}
+
+				body, err := sut.Handler(http.MethodPost, "/", []byte("body"))
+
+				assert.Nil(t, err)
+				assert.Equal(t, []byte("body"), body)
+				assert.Equal(t, int32(3), requests)
+				assert.Equal(t, []time.Duration{time.Second, time.Second}, delays)
+			},
+		},
+		{
+			name: "retries exhausted",
+			test: func(t *testing.T) {
+				var requests int32
+				httpServer := refuse(10, &requests)
+				defer httpServer.Close()
+				sut := New(httpServer.URL)
+				sut.SetRetries(1)
+				sut.sleep = func(context.Context, time.Duration) error { return nil }
+
+				_, err := sut.Handler(http.MethodGet, "/", nil)
+
+				assert.True(t, errors.Is(err, problem.ErrRateLimited))
+				assert.Equal(t, int32(2), requests)
+			},
+		},
+		{
+			name: "stream cancelled while waiting",
+			test: func(t *testing.T) {
+				var requests int32
+				httpServer := refuse(10, &requests)
+				defer httpServer.Close()
+				sut := New(httpServer.URL)
+				ctx, cancel := context.WithCancel(context.Background())
+				sut.sleep = func(ctx context.Context, d time.Duration) error {
+					cancel()
+					return sleep(ctx, d)
+				}
+
+				_, err := sut.Stream(ctx, "/", nil)
+
+				assert.Equal(t, context.Canceled, err)
+				assert.Equal(t, int32(1), requests)
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(cases[i].name, cases[i].test)
+	}
+}
+
+// TestInstance_RequestID tests sending the same request ID with each attempt at a request.
+func TestInstance_RequestID(t *testing.T) {
+	var ids []string
+	var requests int32
+	httpServer := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ids = append(ids, r.Header.Get(header.RequestID))
+				if atomic.AddInt32(&requests, 1) == 1 {
+					w.Header().Set(header.RetryAfter, "1")
+					problem.Write(w, r, problem.ErrRateLimited)
+				}
+			},
+		),
+	)
+	defer httpServer.Close()
+	sut := New(httpServer.URL)
+	sut.sleep = func(context.Context, time.Duration) error { return nil }
+
+	_, err := sut.Handler(http.MethodGet, "/", nil)
+	assert.Nil(t, err)
+	_, err = sut.Handler(http.MethodGet, "/", nil)
+	assert.Nil(t, err)
+
+	assert.Len(t, ids, 3)
+	assert.NotEqual(t, "", ids[0])
+	assert.Equal(t, ids[0], ids[1])
+	assert.NotEqual(t, ids[1], ids[2])
+}
@@ -0,0 +1,94 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package requestor
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// errPinMismatch reports a server whose certificate chain does not include a pinned public key.
+var errPinMismatch = errors.New("server certificate does not match a pinned public key")
+
+// TLS describes how a requestor verifies the server and identifies itself over HTTPS.  CAFile names PEM certificates
+// trusted in addition to the system roots; CertFile and KeyFile name the PEM client certificate and key presented to
+// servers that ask for one; Pins lists base64 encoded SHA-256 digests of the public keys (SubjectPublicKeyInfo) the
+// server's verified chain must include one of.
+type TLS struct {
+	CAFile   string   `json:"caFile"`
+	CertFile string   `json:"certFile"`
+	KeyFile  string   `json:"keyFile"`
+	Pins     []string `json:"pins"`
+}
+
+// Pin returns the pin of certificate's public key.
+func Pin(certificate *x509.Certificate) string {
+	digest := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// NewTLSConfig returns the client TLS configuration options describes.
+func NewTLSConfig(options TLS) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if options.CAFile != "" {
+		body, err := ioutil.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(body) {
+			return nil, fmt.Errorf("no certificates found in %s", options.CAFile)
+		}
+		config.RootCAs = roots
+	}
+
+	if options.CertFile != "" || options.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	if len(options.Pins) > 0 {
+		pins := make(map[string]bool, len(options.Pins))
+		for _, pin := range options.Pins {
+			pins[pin] = true
+		}
+		config.VerifyPeerCertificate = func(_ [][]byte, chains [][]*x509.Certificate) error {
+			for _, chain := range chains {
+				for _, certificate := range chain {
+					if pins[Pin(certificate)] {
+						return nil
+					}
+				}
+			}
+			return errPinMismatch
+		}
+	}
+
+	return config, nil
+}
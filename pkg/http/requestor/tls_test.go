@@ -0,0 +1,150 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package requestor
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/project-alvarium/go-store/internal/pkg/certificate"
+	testInternal "github.com/project-alvarium/go-store/internal/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewTLSConfig tests requests over HTTPS with a custom CA, client certificates and pinning.
+func TestNewTLSConfig(t *testing.T) {
+	directory, err := ioutil.TempDir("", "requestor")
+	if err != nil {
+		assert.FailNow(t, "Unexpected ioutil.TempDir failure:", err.Error())
+	}
+	defer func() { _ = os.RemoveAll(directory) }()
+
+	authority, caFile := testInternal.NewAuthority(t, directory, "ca")
+	server := authority.IssueServer(t, directory, "server")
+	client := authority.IssueClient(t, directory, "client")
+	other, _ := testInternal.NewAuthority(t, directory, "other")
+	certificates, err := certificate.New(
+		certificate.Config{
+			CertFile:     server.CertFile,
+			KeyFile:      server.KeyFile,
+			ClientCAFile: caFile,
+			ClientAuth:   certificate.ClientAuthRequired,
+		},
+	)
+	if err != nil {
+		assert.FailNow(t, "Unexpected certificate.New failure:", err.Error())
+	}
+	httpServer := httptest.NewUnstartedServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+			},
+		),
+	)
+	httpServer.TLS = certificates.TLSConfig()
+	httpServer.StartTLS()
+	defer httpServer.Close()
+
+	type testCase struct {
+		name    string
+		options TLS
+		success bool
+	}
+
+	cases := []testCase{
+		{name: "untrusted server", options: TLS{CertFile: client.CertFile, KeyFile: client.KeyFile}},
+		{name: "no client certificate", options: TLS{CAFile: caFile}},
+		{
+			name:    "trusted",
+			options: TLS{CAFile: caFile, CertFile: client.CertFile, KeyFile: client.KeyFile},
+			success: true,
+		},
+		{
+			name: "pinned",
+			options: TLS{
+				CAFile:   caFile,
+				CertFile: client.CertFile,
+				KeyFile:  client.KeyFile,
+				Pins:     []string{Pin(server.Certificate)},
+			},
+			success: true,
+		},
+		{
+			name: "pinned authority",
+			options: TLS{
+				CAFile:   caFile,
+				CertFile: client.CertFile,
+				KeyFile:  client.KeyFile,
+				Pins:     []string{Pin(authority.Certificate)},
+			},
+			success: true,
+		},
+		{
+			name: "pin mismatch",
+			options: TLS{
+				CAFile:   caFile,
+				CertFile: client.CertFile,
+				KeyFile:  client.KeyFile,
+				Pins:     []string{Pin(other.Certificate)},
+			},
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				config, err := NewTLSConfig(cases[i].options)
+				if err != nil {
+					assert.FailNow(t, "Unexpected NewTLSConfig failure:", err.Error())
+				}
+				sut := New(httpServer.URL)
+				sut.SetTLSConfig(config)
+
+				body, err := sut.Handler(http.MethodGet, "/", nil)
+
+				if !cases[i].success {
+					assert.NotNil(t, err)
+					return
+				}
+				assert.Nil(t, err)
+				assert.Equal(t, "client", string(body))
+			},
+		)
+	}
+
+	t.Run(
+		"invalid options",
+		func(t *testing.T) {
+			empty := filepath.Join(directory, "empty.pem")
+			if err := ioutil.WriteFile(empty, nil, 0600); err != nil {
+				assert.FailNow(t, "Unexpected ioutil.WriteFile failure:", err.Error())
+			}
+
+			_, missingCA := NewTLSConfig(TLS{CAFile: filepath.Join(directory, "missing.pem")})
+			_, emptyCA := NewTLSConfig(TLS{CAFile: empty})
+			_, missingKey := NewTLSConfig(TLS{CertFile: client.CertFile})
+
+			assert.NotNil(t, missingCA)
+			assert.NotNil(t, emptyCA)
+			assert.NotNil(t, missingKey)
+		},
+	)
+}
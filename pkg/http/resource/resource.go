@@ -0,0 +1,90 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package resource
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Scope grants access to a class of operations; each scope includes those ranked below it.
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"
+	ScopeWrite Scope = "write"
+	ScopeAdmin Scope = "admin"
+)
+
+// ranks orders the scopes.
+var ranks = map[Scope]int{
+	ScopeRead:  1,
+	ScopeWrite: 2,
+	ScopeAdmin: 3,
+}
+
+// Valid returns whether s is a known scope.
+func (s Scope) Valid() bool {
+	_, ok := ranks[s]
+	return ok
+}
+
+// Includes returns whether s grants access to operations that require required.
+func (s Scope) Includes(required Scope) bool {
+	return s.Valid() && ranks[s] >= ranks[required]
+}
+
+// Key describes an API key; only a hash of its secret is kept.  A key with a Tenant only grants access to that
+// tenant's identities.
+type Key struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Scopes  []Scope   `json:"scopes"`
+	Tenant  string    `json:"tenant,omitempty"`
+	Created time.Time `json:"created"`
+	Hash    string    `json:"hash,omitempty"`
+}
+
+// Issued is a newly created key and the credentials that present it, which are not available again.
+type Issued struct {
+	Key
+	Secret string `json:"secret"`
+}
+
+// Entry identifies an annotation matched by an index lookup.
+type Entry struct {
+	Identity string `json:"identity"`
+	Unique   string `json:"unique"`
+}
+
+// Hook is a registered webhook.  An annotation is delivered to URL when its identity begins with one of Prefixes and
+// its metadata kind is one of MetadataKinds; an empty list matches everything.  Secret signs every delivery.
+type Hook struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Secret        string   `json:"secret,omitempty"`
+	Prefixes      []string `json:"prefixes,omitempty"`
+	MetadataKinds []string `json:"metadataKinds,omitempty"`
+}
+
+// Delivery is a payload that could not be delivered to its hook.
+type Delivery struct {
+	ID        string          `json:"id"`
+	Hook      string          `json:"hook"`
+	Body      json.RawMessage `json:"body"`
+	Attempts  int             `json:"attempts"`
+	LastError string          `json:"lastError"`
+	Failed    string          `json:"failed"`
+}
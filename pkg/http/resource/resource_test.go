@@ -0,0 +1,32 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestScope tests scope ordering.
+func TestScope(t *testing.T) {
+	assert.True(t, ScopeAdmin.Includes(ScopeWrite))
+	assert.True(t, ScopeWrite.Includes(ScopeRead))
+	assert.True(t, ScopeRead.Includes(ScopeRead))
+	assert.False(t, ScopeRead.Includes(ScopeWrite))
+	assert.False(t, ScopeWrite.Includes(ScopeAdmin))
+	assert.False(t, Scope("unknown").Includes(ScopeRead))
+	assert.False(t, Scope("unknown").Valid())
+}
@@ -0,0 +1,57 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package stub
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// streamer is a receiver that encapsulates required dependencies.
+type streamer struct {
+	m              sync.Mutex
+	RequestPaths   []string
+	RequestHeaders []http.Header
+	bodies         []string
+}
+
+// NewStreamer returns a streamer whose successive streams return bodies in order; once bodies are exhausted each
+// stream fails.
+func NewStreamer(bodies ...string) *streamer {
+	return &streamer{
+		bodies: bodies,
+	}
+}
+
+// Stream encapsulates opening an http response stream for path with header.
+func (s *streamer) Stream(_ context.Context, path string, header http.Header) (io.ReadCloser, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.RequestPaths = append(s.RequestPaths, path)
+	s.RequestHeaders = append(s.RequestHeaders, header)
+	if len(s.bodies) == 0 {
+		return nil, errors.New("no more streams")
+	}
+
+	body := s.bodies[0]
+	s.bodies = s.bodies[1:]
+	return ioutil.NopCloser(strings.NewReader(body)), nil
+}
@@ -16,11 +16,12 @@ package stub
 
 // instance is a receiver that encapsulates required dependencies.
 type instance struct {
-	RequestMethod string
-	RequestURL    string
-	RequestBody   []byte
-	responseBody  []byte
-	err           error
+	RequestMethod      string
+	RequestURL         string
+	RequestBody        []byte
+	RequestContentType string
+	responseBody       []byte
+	err                error
 }
 
 func New(responseBody []byte, err error) *instance {
@@ -37,3 +38,9 @@ func (i *instance) Request(method, url string, body []byte) (responseBody []byte
 	i.RequestBody = body
 	return i.responseBody, i.err
 }
+
+// MediaRequest encapsulates an http request of method to url with body encoded as contentType.
+func (i *instance) MediaRequest(method, url, contentType string, body []byte) (responseBody []byte, err error) {
+	i.RequestContentType = contentType
+	return i.Request(method, url, body)
+}
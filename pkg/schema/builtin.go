@@ -0,0 +1,83 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package schema
+
+import (
+	"github.com/project-alvarium/go-sdk/pkg/annotation/metadata"
+	assessMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
+	publishMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata"
+)
+
+const (
+	assessKind  = assessMetadata.Kind
+	pkiKind     = pkiMetadata.Kind
+	publishKind = publishMetadata.Kind
+)
+
+// unexpected reports metadata whose concrete type is not the one its kind decodes to.
+var unexpected = Errors{{Message: "has an unexpected type"}}
+
+// nested returns the problems with nested metadata identified by kindField and metadataField.
+func nested(kind, kindField string, m metadata.Contract, metadataField string) Errors {
+	var result Errors
+	switch {
+	case kind == "":
+		result = append(result, required(kindField))
+	case m == nil:
+		result = append(result, FieldError{Field: metadataField, Message: "is not valid " + kind + " metadata"})
+	case m.Kind() != kind:
+		result = append(result, FieldError{Field: kindField, Message: "does not match " + metadataField})
+	}
+	return result
+}
+
+// validateAssess validates assess metadata.
+func validateAssess(m metadata.Contract) Errors {
+	concrete, ok := m.(*assessMetadata.Instance)
+	if !ok {
+		return unexpected
+	}
+	return nested(concrete.AssessorKind, "assessorType", concrete.AssessorMetadata, "assessorMetadata")
+}
+
+// validatePKI validates pki metadata.
+func validatePKI(m metadata.Contract) Errors {
+	concrete, ok := m.(*pkiMetadata.Instance)
+	if !ok {
+		return unexpected
+	}
+
+	var result Errors
+	if len(concrete.IdentitySignature) == 0 {
+		result = append(result, required("identitySignature"))
+	}
+	if len(concrete.DataSignature) == 0 {
+		result = append(result, required("dataSignature"))
+	}
+	if len(concrete.PublicKey) == 0 {
+		result = append(result, required("publicKey"))
+	}
+	return append(result, nested(concrete.SignerKind, "signerType", concrete.SignerMetadata, "signerMetadata")...)
+}
+
+// validatePublish validates publish metadata.
+func validatePublish(m metadata.Contract) Errors {
+	concrete, ok := m.(*publishMetadata.Instance)
+	if !ok {
+		return unexpected
+	}
+	return nested(concrete.PublisherKind, "publisherType", concrete.PublisherMetadata, "publisherMetadata")
+}
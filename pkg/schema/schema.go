@@ -0,0 +1,119 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package schema
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/metadata"
+)
+
+const metadataField = "metadata"
+
+// FieldError reports a problem with one field; Field is the field's JSON path within the annotation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors reports every problem found with an annotation; it is empty if the annotation is valid.
+type Errors []FieldError
+
+// Error returns the field errors separated by semicolons.
+func (e Errors) Error() string {
+	result := make([]string, len(e))
+	for i := range e {
+		result[i] = e[i].Field + ": " + e[i].Message
+	}
+	return strings.Join(result, "; ")
+}
+
+// Validator returns the problems found with metadata of the kind it is registered for; fields are named relative to
+// the metadata.
+type Validator func(m metadata.Contract) Errors
+
+// Contract defines the validation of annotation metadata.
+type Contract interface {
+	// Validate returns the problems found with value's metadata.
+	Validate(value *annotation.Instance) Errors
+}
+
+// instance is a receiver that encapsulates required dependencies.
+type instance struct {
+	mutex      sync.RWMutex
+	validators map[string]Validator
+}
+
+// New is a factory function that returns instance without any validators; metadata of kinds without a validator is
+// accepted.
+func New() *instance {
+	return &instance{
+		validators: make(map[string]Validator),
+	}
+}
+
+// NewDefault is a factory function that returns instance with validators for the assess, pki and publish kinds.
+func NewDefault() *instance {
+	i := New()
+	i.Register(assessKind, validateAssess)
+	i.Register(pkiKind, validatePKI)
+	i.Register(publishKind, validatePublish)
+	return i
+}
+
+// Register validates metadata of kind with validator, replacing any validator already registered for kind.
+func (i *instance) Register(kind string, validator Validator) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.validators[kind] = validator
+}
+
+// Validate returns the problems found with value's metadata by the validator registered for its kind; metadata
+// that could not be decoded is reported as a problem with the metadata field.
+func (i *instance) Validate(value *annotation.Instance) Errors {
+	i.mutex.RLock()
+	validator, ok := i.validators[value.MetadataKind]
+	i.mutex.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	if value.Metadata == nil {
+		return Errors{{Field: metadataField, Message: "is not valid " + value.MetadataKind + " metadata"}}
+	}
+
+	var result Errors
+	for _, e := range validator(value.Metadata) {
+		e.Field = join(metadataField, e.Field)
+		result = append(result, e)
+	}
+	return result
+}
+
+// join returns the path of field within parent.
+func join(parent, field string) string {
+	if field == "" {
+		return parent
+	}
+	return parent + "." + field
+}
+
+// required returns a problem with field.
+func required(field string) FieldError {
+	return FieldError{Field: field, Message: "is required"}
+}
@@ -0,0 +1,196 @@
+/*******************************************************************************
+ * Copyright 2020 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package schema
+
+import (
+	"crypto"
+	"encoding/json"
+	"testing"
+
+	"github.com/project-alvarium/go-sdk/pkg/annotation"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/metadata"
+	metadataFactory "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/factory"
+	metadataStub "github.com/project-alvarium/go-sdk/pkg/annotation/metadata/stub"
+	"github.com/project-alvarium/go-sdk/pkg/annotation/uniqueprovider/ulid"
+	assessorMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/assessor/pki/metadata"
+	assessMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata"
+	assessMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/assess/metadata/factory"
+	pkiMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata"
+	pkiMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/pki/metadata/factory"
+	signerMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/pki/signer/signpkcs1v15/metadata"
+	publishMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata"
+	publishMetadataFactory "github.com/project-alvarium/go-sdk/pkg/annotator/publish/metadata/factory"
+	publisherMetadata "github.com/project-alvarium/go-sdk/pkg/annotator/publish/publisher/example/metadata"
+	identityFactory "github.com/project-alvarium/go-sdk/pkg/identity/factory"
+	"github.com/project-alvarium/go-sdk/pkg/identity/hash"
+	"github.com/project-alvarium/go-sdk/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAnnotation returns an annotation carrying m.
+func newAnnotation(m metadata.Contract) *annotation.Instance {
+	return annotation.New(ulid.New().Get(), hash.New(test.FactoryRandomByteSlice()), nil, m)
+}
+
+// decode returns the annotation carrying the metadata of kind encoded as data, decoded as the service would.
+func decode(t *testing.T, kind, data string) *annotation.Instance {
+	body, err := json.Marshal(
+		map[string]interface{}{
+			"unique":              ulid.New().Get(),
+			"identityCurrentType": hash.Kind,
+			"identityCurrent":     hash.New(test.FactoryRandomByteSlice()),
+			"metadataType":        kind,
+			"metadata":            json.RawMessage(data),
+		},
+	)
+	if err != nil {
+		assert.FailNow(t, "Unexpected marshal failure:", err.Error())
+	}
+
+	var result annotation.Instance
+	result.SetIdentityFactory(identityFactory.New())
+	result.SetMetadataFactory(
+		metadataFactory.New(
+			[]metadataFactory.Contract{
+				assessMetadataFactory.NewDefault(),
+				pkiMetadataFactory.NewDefault(),
+				publishMetadataFactory.NewDefault(),
+			},
+		),
+	)
+	if err := json.Unmarshal(body, &result); err != nil {
+		assert.FailNow(t, "Unexpected unmarshal failure:", err.Error())
+	}
+	return &result
+}
+
+// TestInstance_Validate tests Validate.
+func TestInstance_Validate(t *testing.T) {
+	type testCase struct {
+		name     string
+		value    func(t *testing.T) *annotation.Instance
+		expected Errors
+	}
+
+	cases := []testCase{
+		{
+			name: "valid assess",
+			value: func(*testing.T) *annotation.Instance {
+				return newAnnotation(
+					assessMetadata.New(nil, assessorMetadata.NewSuccess(true, []string{test.FactoryRandomString()})),
+				)
+			},
+		},
+		{
+			name: "valid pki",
+			value: func(*testing.T) *annotation.Instance {
+				return newAnnotation(
+					pkiMetadata.New(
+						nil,
+						test.FactoryRandomByteSlice(),
+						test.FactoryRandomByteSlice(),
+						test.FactoryRandomByteSlice(),
+						signerMetadata.NewSuccess(crypto.SHA256, test.FactoryRandomString()),
+					),
+				)
+			},
+		},
+		{
+			name: "valid publish",
+			value: func(*testing.T) *annotation.Instance {
+				return newAnnotation(publishMetadata.New(nil, publisherMetadata.NewSuccess()))
+			},
+		},
+		{
+			name: "kind without validator",
+			value: func(*testing.T) *annotation.Instance {
+				return newAnnotation(metadataStub.NewNullObject())
+			},
+		},
+		{
+			name: "assess without assessor",
+			value: func(t *testing.T) *annotation.Instance {
+				return decode(t, assessMetadata.Kind, `{}`)
+			},
+			expected: Errors{required("metadata.assessorType")},
+		},
+		{
+			name: "assess with unknown assessor",
+			value: func(t *testing.T) *annotation.Instance {
+				return decode(t, assessMetadata.Kind, `{"assessorType": "unknown", "assessorMetadata": {}}`)
+			},
+			expected: Errors{{Field: "metadata.assessorMetadata", Message: "is not valid unknown metadata"}},
+		},
+		{
+			name: "pki without fields",
+			value: func(t *testing.T) *annotation.Instance {
+				return decode(t, pkiMetadata.Kind, `{}`)
+			},
+			expected: Errors{
+				required("metadata.identitySignature"),
+				required("metadata.dataSignature"),
+				required("metadata.publicKey"),
+				required("metadata.signerType"),
+			},
+		},
+		{
+			name: "publish without publisher",
+			value: func(t *testing.T) *annotation.Instance {
+				return decode(t, publishMetadata.Kind, `{}`)
+			},
+			expected: Errors{required("metadata.publisherType")},
+		},
+		{
+			name: "undecodable metadata",
+			value: func(t *testing.T) *annotation.Instance {
+				return decode(t, publishMetadata.Kind, `{"publisherType": 1}`)
+			},
+			expected: Errors{{Field: "metadata", Message: "is not valid publish metadata"}},
+		},
+	}
+
+	for i := range cases {
+		t.Run(
+			cases[i].name,
+			func(t *testing.T) {
+				sut := NewDefault()
+
+				result := sut.Validate(cases[i].value(t))
+
+				assert.Equal(t, cases[i].expected, result)
+			},
+		)
+	}
+}
+
+// TestInstance_Register tests Register.
+func TestInstance_Register(t *testing.T) {
+	m := metadataStub.NewNullObject()
+	expected := FieldError{Field: test.FactoryRandomString(), Message: test.FactoryRandomString()}
+	sut := New()
+	sut.Register(m.Kind(), func(metadata.Contract) Errors { return Errors{expected} })
+
+	result := sut.Validate(newAnnotation(m))
+
+	assert.Equal(t, Errors{{Field: join(metadataField, expected.Field), Message: expected.Message}}, result)
+}
+
+// TestErrors_Error tests Errors.Error.
+func TestErrors_Error(t *testing.T) {
+	sut := Errors{required("a"), {Field: "b", Message: "is invalid"}}
+
+	assert.Equal(t, "a: is required; b: is invalid", sut.Error())
+}